@@ -0,0 +1,223 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"text/tabwriter"
+	"time"
+
+	configs "github.com/latiiLA/coop-forex-server/configs"
+	"github.com/latiiLA/coop-forex-server/internal/infrastructure/migration"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const usage = `Usage: migrate [flags] COMMAND [ARG]
+
+Commands:
+  up [N]            Apply all or N pending migrations
+  down [N]          Roll back the last N applied migrations (default 1)
+  goto VERSION      Apply or roll back migrations to reach VERSION (0 rolls back everything)
+  status            List migrations and whether they are applied
+  force VERSION     Record VERSION as the current version without running anything
+  validate          Report applied migrations whose files were edited or deleted
+  create NAME       Scaffold a timestamped up/down pair in the migrations directory
+
+Flags:
+`
+
+func main() {
+	dir := flag.String("dir", "", "migrations directory (default MIGRATIONS_DIR or db/migrations)")
+	dryRun := flag.Bool("dry-run", false, "print the commands that would run instead of running them")
+	flag.Usage = func() {
+		fmt.Fprint(flag.CommandLine.Output(), usage)
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	if flag.NArg() == 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	command, args := flag.Arg(0), flag.Args()[1:]
+
+	// Scaffolding files needs no database, so it works without a .env.
+	if command == "create" {
+		if len(args) != 1 {
+			fail(errors.New("create needs a NAME"))
+		}
+		migrationsDir := *dir
+		if migrationsDir == "" {
+			migrationsDir = os.Getenv("MIGRATIONS_DIR")
+		}
+		if migrationsDir == "" {
+			migrationsDir = "db/migrations"
+		}
+
+		created, err := migration.Create(migrationsDir, args[0], time.Now())
+		if err != nil {
+			fail(err)
+		}
+		fmt.Println(created.UpFile)
+		fmt.Println(created.DownFile)
+		return
+	}
+
+	configs.LoadConfig()
+	if *dir == "" {
+		*dir = configs.MigrationsDir
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	client, err := mongo.Connect(ctx, options.Client().ApplyURI(configs.MongoURL))
+	if err != nil {
+		fail(fmt.Errorf("mongo connection error: %w", err))
+	}
+	defer client.Disconnect(context.Background())
+
+	if err := client.Ping(ctx, nil); err != nil {
+		fail(fmt.Errorf("mongo ping error: %w", err))
+	}
+
+	dbName := "forex_db"
+	if configs.DBName != "" {
+		dbName = configs.DBName
+	}
+
+	migrator := migration.New(client.Database(dbName), *dir)
+	migrator.DryRun = *dryRun
+
+	if err := run(ctx, migrator, command, args); err != nil {
+		fail(err)
+	}
+}
+
+func run(ctx context.Context, migrator *migration.Migrator, command string, args []string) error {
+	switch command {
+	case "up":
+		n, err := optionalCount(args, 0)
+		if err != nil {
+			return err
+		}
+		applied, err := migrator.Up(ctx, n)
+		report("applied", applied, migrator.DryRun)
+		return err
+
+	case "down":
+		n, err := optionalCount(args, 1)
+		if err != nil {
+			return err
+		}
+		rolledBack, err := migrator.Down(ctx, n)
+		report("rolled back", rolledBack, migrator.DryRun)
+		return err
+
+	case "goto":
+		version, err := requiredVersion(command, args)
+		if err != nil {
+			return err
+		}
+		rolledBack, applied, err := migrator.Goto(ctx, version)
+		report("rolled back", rolledBack, migrator.DryRun)
+		report("applied", applied, migrator.DryRun)
+		return err
+
+	case "force":
+		version, err := requiredVersion(command, args)
+		if err != nil {
+			return err
+		}
+		if err := migrator.Force(ctx, version); err != nil {
+			return err
+		}
+		if !migrator.DryRun {
+			fmt.Printf("forced version %d\n", version)
+		}
+		return nil
+
+	case "status":
+		statuses, err := migrator.Status(ctx)
+		if err != nil {
+			return err
+		}
+		printStatuses(statuses)
+		return nil
+
+	case "validate":
+		problems, err := migrator.Validate(ctx)
+		if err != nil {
+			return err
+		}
+		if len(problems) == 0 {
+			fmt.Println("all applied migrations match their files")
+			return nil
+		}
+		printStatuses(problems)
+		return fmt.Errorf("%d migrations need attention", len(problems))
+	}
+
+	flag.Usage()
+	return fmt.Errorf("unknown command %q", command)
+}
+
+func optionalCount(args []string, fallback int) (int, error) {
+	if len(args) == 0 {
+		return fallback, nil
+	}
+
+	n, err := strconv.Atoi(args[0])
+	if err != nil || n < 1 {
+		return 0, fmt.Errorf("N must be a positive number, got %q", args[0])
+	}
+
+	return n, nil
+}
+
+func requiredVersion(command string, args []string) (int64, error) {
+	if len(args) != 1 {
+		return 0, fmt.Errorf("%s needs a VERSION", command)
+	}
+
+	version, err := strconv.ParseInt(args[0], 10, 64)
+	if err != nil || version < 0 {
+		return 0, fmt.Errorf("invalid VERSION %q", args[0])
+	}
+
+	return version, nil
+}
+
+func report(verb string, migrations []migration.Migration, dryRun bool) {
+	if dryRun {
+		return
+	}
+	for _, m := range migrations {
+		fmt.Printf("%s %s\n", verb, m)
+	}
+}
+
+func printStatuses(statuses []migration.Status) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "VERSION\tNAME\tSTATE\tAPPLIED AT")
+
+	for _, s := range statuses {
+		appliedAt := "-"
+		if s.AppliedAt != nil {
+			appliedAt = s.AppliedAt.Format(time.RFC3339)
+		}
+		fmt.Fprintf(w, "%d\t%s\t%s\t%s\n", s.Version, s.Name, s.State(), appliedAt)
+	}
+
+	w.Flush()
+}
+
+func fail(err error) {
+	fmt.Fprintln(os.Stderr, "migrate:", err)
+	os.Exit(1)
+}
@@ -0,0 +1,41 @@
+package main
+
+import (
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// background tracks the workers that run beside the HTTP server so shutdown
+// can wait for them to finish what they started.
+type background struct {
+	wg sync.WaitGroup
+}
+
+// Go runs worker until it returns.
+func (b *background) Go(name string, worker func()) {
+	b.wg.Add(1)
+	go func() {
+		defer b.wg.Done()
+		worker()
+		logrus.WithField("worker", name).Info("Background worker stopped")
+	}()
+}
+
+// Wait waits for every worker to return and reports whether they did within
+// timeout.
+func (b *background) Wait(timeout time.Duration) bool {
+	done := make(chan struct{})
+	go func() {
+		b.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return true
+	case <-time.After(timeout):
+		return false
+	}
+}
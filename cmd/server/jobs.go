@@ -0,0 +1,54 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	configs "github.com/latiiLA/coop-forex-server/configs"
+	"github.com/latiiLA/coop-forex-server/internal/delivery/http/router"
+	"github.com/latiiLA/coop-forex-server/internal/domain/model"
+	"github.com/latiiLA/coop-forex-server/internal/infrastructure/scheduler"
+	"github.com/latiiLA/coop-forex-server/internal/repository"
+	"github.com/latiiLA/coop-forex-server/internal/usecase"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// registerJobs adds the server's housekeeping jobs to the scheduler.
+func registerJobs(s *scheduler.Scheduler, db *mongo.Database, timeout time.Duration, notificationRenderer model.NotificationRenderer) error {
+	requestUsecase := router.BuildRequestUsecase(db, timeout, notificationRenderer)
+	tokenUsecase := usecase.NewTokenUsecase(repository.NewTokenBlacklistRepository(db), timeout)
+	fileUsecase := usecase.NewFileUsecase(repository.NewFileRepository(db), timeout)
+
+	if err := s.Register("lock_expiry", configs.JobLockExpirySchedule, func(ctx context.Context) (string, error) {
+		released, err := requestUsecase.ReleaseExpiredLocks(ctx)
+		return fmt.Sprintf("released %d expired request locks", released), err
+	}); err != nil {
+		return err
+	}
+
+	if err := s.Register("sla_check", configs.JobSLASchedule, func(ctx context.Context) (string, error) {
+		run, err := requestUsecase.CheckDeadlines(ctx)
+		if run == nil {
+			return "", err
+		}
+		return fmt.Sprintf("reminded %d, escalated %d and declined %d requests", run.Reminded, run.Escalated, run.Declined), err
+	}); err != nil {
+		return err
+	}
+
+	if err := s.Register("token_cleanup", configs.JobTokenCleanupSchedule, func(ctx context.Context) (string, error) {
+		purged, err := tokenUsecase.PurgeExpired(ctx)
+		return fmt.Sprintf("purged %d expired tokens", purged), err
+	}); err != nil {
+		return err
+	}
+
+	return s.Register("orphan_files", configs.JobOrphanFilesSchedule, func(ctx context.Context) (string, error) {
+		sweep, err := fileUsecase.SweepOrphans(ctx, time.Now().Add(-configs.OrphanFileGrace))
+		if sweep == nil {
+			return "", err
+		}
+		return fmt.Sprintf("deleted %d unreferenced file records and %d unrecorded files", sweep.Records, sweep.Files), err
+	})
+}
@@ -2,7 +2,12 @@ package main
 
 import (
 	"context"
+	"net"
+	"net/http"
 	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
@@ -10,7 +15,19 @@ import (
 
 	configs "github.com/latiiLA/coop-forex-server/configs"
 	"github.com/latiiLA/coop-forex-server/internal/delivery/http/router"
+	"github.com/latiiLA/coop-forex-server/internal/domain/model"
+	"github.com/latiiLA/coop-forex-server/internal/infrastructure"
+	"github.com/latiiLA/coop-forex-server/internal/infrastructure/health"
+	"github.com/latiiLA/coop-forex-server/internal/infrastructure/ipbinding"
+	"github.com/latiiLA/coop-forex-server/internal/infrastructure/metrics"
 	"github.com/latiiLA/coop-forex-server/internal/infrastructure/middleware"
+	"github.com/latiiLA/coop-forex-server/internal/infrastructure/migration"
+	"github.com/latiiLA/coop-forex-server/internal/infrastructure/notification"
+	"github.com/latiiLA/coop-forex-server/internal/infrastructure/scheduler"
+	"github.com/latiiLA/coop-forex-server/internal/infrastructure/session"
+	"github.com/latiiLA/coop-forex-server/internal/infrastructure/signing"
+	"github.com/latiiLA/coop-forex-server/internal/infrastructure/utils"
+	"github.com/latiiLA/coop-forex-server/internal/repository"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
 
@@ -47,9 +64,16 @@ func main() {
 	configs.LoadConfig()
 	setupLogger()
 
+	signingKeys, err := signing.LoadDir(configs.JwtKeysDir, configs.JwtSigningKeyID)
+	if err != nil {
+		logrus.Fatalf("Failed to load JWT signing keys: %v", err)
+	}
+	infrastructure.UseAccessTokenKeys(signingKeys)
+	logrus.WithField("kid", signingKeys.ActiveID()).Info("Signing access tokens")
+
 	ctx := context.TODO()
 
-	clientOptions := options.Client().ApplyURI(configs.MongoURL)
+	clientOptions := options.Client().ApplyURI(configs.MongoURL).SetMonitor(metrics.MongoMonitor())
 	client, err := mongo.Connect(ctx, clientOptions)
 	if err != nil {
 		logrus.Fatal("Mongo connection error:", err)
@@ -68,6 +92,10 @@ func main() {
 		logrus.Fatal("Ping error:", err)
 	}
 
+	if err := requireTransactions(ctx, client); err != nil {
+		logrus.WithError(err).Fatal("MongoDB does not support transactions")
+	}
+
 	logrus.Info("✅ Connected to DB!")
 
 	db_name := "forex_db"
@@ -78,8 +106,106 @@ func main() {
 	db := client.Database(db_name)
 	timeout := configs.Timeout
 
+	if configs.DisableMigration {
+		logrus.Info("Migrations are disabled")
+	} else {
+		applied, err := migration.New(db, configs.MigrationsDir).Up(ctx, 0)
+		if err != nil {
+			logrus.Fatal("Migration error:", err)
+		}
+		logrus.Infof("✅ Database is up to date, %d migrations applied", len(applied))
+	}
+
+	templates, err := notification.LoadTemplates(configs.MailTemplatesDir)
+	if err != nil {
+		logrus.Fatal("Email template error:", err)
+	}
+
+	emailNotifier, err := notification.NewEmailNotifier()
+	if err != nil {
+		logrus.Fatal("Notifier error:", err)
+	}
+	dispatcher := notification.NewDispatcher(
+		repository.NewNotificationRepository(db),
+		repository.NewRequestEventRepository(db),
+		model.NotificationRetryPolicy{
+			MaxAttempts: configs.NotificationMaxAttempts,
+			BaseDelay:   configs.NotificationRetryBase,
+			MaxDelay:    configs.NotificationRetryMax,
+		},
+		emailNotifier,
+	)
+	dispatcher.PollInterval = configs.NotificationPollInterval
+
+	// SIGINT and SIGTERM start a graceful shutdown.
+	signalCtx, stopSignals := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+	defer stopSignals()
+
+	// The workers outlive the signal: they are stopped once the HTTP server
+	// has drained, as its requests may still leave work for them.
+	workerCtx, stopWorkers := context.WithCancel(context.Background())
+	defer stopWorkers()
+	var workers background
+
+	workers.Go("dispatcher", func() { dispatcher.Run(workerCtx) })
+
+	inboxHub := notification.NewInboxHub(repository.NewInboxRepository(db))
+	workers.Go("inbox", func() { inboxHub.Run(workerCtx) })
+
+	jobScheduler := scheduler.New(repository.NewJobRepository(db), repository.NewJobRunRepository(db))
+	jobScheduler.PollInterval = configs.JobPollInterval
+	jobScheduler.LeaseTTL = configs.JobLeaseTTL
+	if err := registerJobs(jobScheduler, db, timeout, templates); err != nil {
+		logrus.Fatal("Job scheduler error:", err)
+	}
+	workers.Go("scheduler", func() { jobScheduler.Run(workerCtx) })
+
+	sessionRevocations := session.NewRevocations(repository.NewSessionRepository(db))
+	sessionRevocations.PollInterval = configs.SessionRevocationPollInterval
+	if err := sessionRevocations.Load(ctx); err != nil {
+		logrus.WithError(err).Fatal("Failed to load revoked sessions")
+	}
+	middleware.UseSessionRevocations(sessionRevocations)
+	workers.Go("sessions", func() { sessionRevocations.Run(workerCtx) })
+
+	permissionVersions := session.NewPermissionVersions(repository.NewUserRepository(db), configs.AccessTokenExpiry)
+	permissionVersions.PollInterval = configs.PermissionsPollInterval
+	if err := permissionVersions.Load(ctx); err != nil {
+		logrus.WithError(err).Fatal("Failed to load permission changes")
+	}
+	middleware.UsePermissionVersions(permissionVersions)
+	workers.Go("permissions", func() { permissionVersions.Run(workerCtx) })
+
+	ipBindingPolicy := model.IPBindingPolicy{Default: model.IPBindingMode(configs.IPBindingMode), Roles: map[string]model.IPBindingMode{}}
+	for role, mode := range configs.IPBindingRoleModes {
+		ipBindingPolicy.Roles[role] = model.IPBindingMode(mode)
+	}
+	ipBindingGuard := ipbinding.NewGuard(ipBindingPolicy, repository.NewSecurityEventRepository(db))
+	middleware.UseIPBindingGuard(ipBindingGuard)
+
+	metrics.RegisterBacklog(repository.NewRequestRepository(db))
+
+	readiness := health.NewChecker()
+	readiness.Timeout = configs.HealthCheckTimeout
+	readiness.Require("mongo", health.Mongo(client))
+	readiness.Require("uploads", health.WritableDir(configs.FileUploadPath))
+	// Only logins need LDAP and mail waits in the outbox, so an outage of
+	// either degrades the server without taking it out of rotation.
+	readiness.Optional("ldap", health.Dial(net.JoinHostPort(configs.LDAPHost, configs.LDAPPort)))
+	readiness.Optional("smtp", health.SMTP(net.JoinHostPort(configs.MailServer, configs.MailPort)))
+
 	// Start the routes
-	r := gin.Default()
+	// gin.Default's logger would print query strings; requests are logged by
+	// RequestLogger instead, which leaves them out.
+	r := gin.New()
+	r.Use(gin.Recovery())
+
+	// The probes and the scrape endpoint come before the middleware so they
+	// are neither rate limited, logged nor timed.
+	router.NewHealthRouter(&r.RouterGroup, readiness)
+	router.NewMetricsRouter(&r.RouterGroup, configs.MetricsToken)
+
+	r.Use(middleware.HTTPMetrics())
 
 	// Cors policy
 	allowed := configs.AllowedOrigins
@@ -91,12 +217,14 @@ func main() {
 	r.Use(cors.New(cors.Config{
 		AllowOrigins:     allowed,
 		AllowMethods:     []string{"GET", "POST", "PUT", "DELETE", "PATCH", "OPTIONS"},
-		AllowHeaders:     []string{"Origin", "Content-Type", "Authorization", "Content-Disposition"},
+		AllowHeaders:     []string{"Origin", "Content-Type", "Authorization", "Content-Disposition", utils.DeviceIDHeader},
 		AllowCredentials: true,
 	}))
 
-	// Only trust localhost
-	r.SetTrustedProxies([]string{"127.0.0.1"})
+	// Client IPs come from forwarding headers only when set by a trusted proxy
+	if err := r.SetTrustedProxies(configs.TrustedProxies); err != nil {
+		logrus.WithError(err).Fatal("Invalid TRUSTED_PROXIES")
+	}
 	r.ForwardedByClientIP = true
 
 	// add logger middleware
@@ -104,14 +232,55 @@ func main() {
 	r.Use(middleware.SecurityHeaders())
 	r.Use(middleware.RateLimitMiddleware())
 
+	router.NewJWKSRouter(&r.RouterGroup, signingKeys)
+
 	// create an API group
 	api := r.Group("/api")
 
 	api.Static("/uploads", "./uploads") // allow upload access
 
-	router.RouterSetup(api, timeout, db)
+	router.RouterSetup(api, timeout, db, templates, inboxHub, jobScheduler, sessionRevocations, permissionVersions, ipBindingGuard)
 
-	if err := r.RunTLS(":8080", configs.CertFile, configs.KeyFile); err != nil {
+	server := &http.Server{
+		Addr:              ":8080",
+		Handler:           r,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	// Open inbox streams never finish by themselves.
+	server.RegisterOnShutdown(inboxHub.Close)
+
+	serverErr := make(chan error, 1)
+	go func() {
+		serverErr <- server.ListenAndServeTLS(configs.CertFile, configs.KeyFile)
+	}()
+
+	select {
+	case err := <-serverErr:
 		logrus.Fatalf("Server failed to start: %v", err)
+	case <-signalCtx.Done():
+	}
+	stopSignals()
+	logrus.Info("Shutting down, draining requests")
+
+	// The load balancer stops sending traffic while the requests in flight
+	// finish.
+	readiness.Drain()
+
+	// Requests and workers share one shutdown timeout.
+	shutdownDeadline := time.Now().Add(configs.ShutdownTimeout)
+	shutdownCtx, cancelShutdown := context.WithDeadline(context.Background(), shutdownDeadline)
+	defer cancelShutdown()
+
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		logrus.WithError(err).Warn("Requests were still running when the shutdown timeout ran out")
+	}
+
+	// Running jobs are cancelled and recorded, and a notification being
+	// sent is finished and recorded, before the database is disconnected.
+	stopWorkers()
+	if !workers.Wait(time.Until(shutdownDeadline)) {
+		logrus.Warn("Background workers were still running when the shutdown timeout ran out")
+	} else {
+		logrus.Info("Background workers stopped")
 	}
 }
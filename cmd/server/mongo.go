@@ -0,0 +1,29 @@
+package main
+
+import (
+	"context"
+	"errors"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// requireTransactions fails unless the deployment behind client supports
+// multi-document transactions, which workflow transitions are written in.
+// Only replica set members and mongos routers do; a standalone mongod must
+// be started as a single-node replica set.
+func requireTransactions(ctx context.Context, client *mongo.Client) error {
+	var hello struct {
+		SetName string `bson:"setName"`
+		Msg     string `bson:"msg"`
+	}
+	if err := client.Database("admin").RunCommand(ctx, bson.D{{Key: "hello", Value: 1}}).Decode(&hello); err != nil {
+		return err
+	}
+
+	if hello.SetName == "" && hello.Msg != "isdbgrid" {
+		return errors.New("MongoDB is a standalone server; transactions need a replica set, start mongod with --replSet and run rs.initiate()")
+	}
+
+	return nil
+}
@@ -3,6 +3,7 @@ package configs
 import (
 	"log"
 	"os"
+	"strconv"
 	"strings"
 	"time"
 
@@ -10,16 +11,54 @@ import (
 )
 
 var (
-	JwtSecret          string
+	JwtKeysDir         string
+	JwtSigningKeyID    string
 	RefreshJwtSecret   string
 	AccessTokenExpiry  time.Duration
 	RefreshTokenExpiry time.Duration
 	DBName             string
 	MongoURL           string
 	Timeout            time.Duration
-	DisableMigration   string
+	DisableMigration   bool
+	MigrationsDir      string
 	FileUploadPath     string
 	LogLevel           string
+	RequestLockTTL     time.Duration
+
+	// Shutdown and health checks
+	ShutdownTimeout    time.Duration
+	HealthCheckTimeout time.Duration
+	MetricsToken       string
+
+	// Session revocation
+	SessionRevocationPollInterval time.Duration
+	PermissionsPollInterval       time.Duration
+
+	// Client binding of tokens
+	TrustedProxies     []string
+	IPBindingMode      string
+	IPBindingRoleModes map[string]string
+
+	// Duplicate request detection
+	DuplicateRequestPolicy   string
+	DuplicateRequestLookback time.Duration
+	DuplicateNameThreshold   float64
+	DuplicateVelocityMax     int
+
+	// Notification outbox delivery
+	NotificationMaxAttempts  int
+	NotificationRetryBase    time.Duration
+	NotificationRetryMax     time.Duration
+	NotificationPollInterval time.Duration
+
+	// Background jobs
+	JobPollInterval         time.Duration
+	JobLeaseTTL             time.Duration
+	JobLockExpirySchedule   string
+	JobSLASchedule          string
+	JobTokenCleanupSchedule string
+	JobOrphanFilesSchedule  string
+	OrphanFileGrace         time.Duration
 
 	// Mail env
 	MailServer   string
@@ -27,37 +66,64 @@ var (
 	MailPassword string
 	MailPort     string
 
+	MailFromAddress  string
+	MailFromName     string
+	MailReplyTo      string
+	MailTemplatesDir string
+
 	MailRequestCreatedTo  []string
 	MailRequestCreatedCc  []string
 	MailRequestCreatedBcc []string
 
-	MailRequestSentTo  []string
-	MailRequestSentCc  []string
-	MailRequestSentBcc []string
-
-	MailRequestAuthorizedTo  []string
-	MailRequestAuthorizedCc  []string
-	MailRequestAuthorizedBcc []string
-
-	MailRequestValidatedTo  []string
-	MailRequestValidatedCc  []string
-	MailRequestValidatedBcc []string
-
-	MailRequestRejectedTo  []string
-	MailRequestRejectedCc  []string
-	MailRequestRejectedBcc []string
-
-	MailRequestApprovedTo  []string
-	MailRequestApprovedCc  []string
-	MailRequestApprovedBcc []string
-
-	MailRequestAcceptedTo  []string
-	MailRequestAcceptedCc  []string
-	MailRequestAcceptedBcc []string
-
-	MailRequestDeclinedTo  []string
-	MailRequestDeclinedCc  []string
-	MailRequestDeclinedBcc []string
+	MailRequestSentTo     []string
+	MailRequestSentCc     []string
+	MailRequestSentBcc    []string
+	MailRequestSentNotify []string
+
+	MailRequestAuthorizedTo     []string
+	MailRequestAuthorizedCc     []string
+	MailRequestAuthorizedBcc    []string
+	MailRequestAuthorizedNotify []string
+
+	MailRequestValidatedTo     []string
+	MailRequestValidatedCc     []string
+	MailRequestValidatedBcc    []string
+	MailRequestValidatedNotify []string
+
+	MailRequestRejectedTo     []string
+	MailRequestRejectedCc     []string
+	MailRequestRejectedBcc    []string
+	MailRequestRejectedNotify []string
+
+	MailRequestApprovedTo     []string
+	MailRequestApprovedCc     []string
+	MailRequestApprovedBcc    []string
+	MailRequestApprovedNotify []string
+
+	MailRequestAcceptedTo     []string
+	MailRequestAcceptedCc     []string
+	MailRequestAcceptedBcc    []string
+	MailRequestAcceptedNotify []string
+
+	MailRequestDeclinedTo     []string
+	MailRequestDeclinedCc     []string
+	MailRequestDeclinedBcc    []string
+	MailRequestDeclinedNotify []string
+
+	MailRequestDueSoonTo     []string
+	MailRequestDueSoonCc     []string
+	MailRequestDueSoonBcc    []string
+	MailRequestDueSoonNotify []string
+
+	MailRequestOverdueTo     []string
+	MailRequestOverdueCc     []string
+	MailRequestOverdueBcc    []string
+	MailRequestOverdueNotify []string
+
+	MailRequestExpiredTo     []string
+	MailRequestExpiredCc     []string
+	MailRequestExpiredBcc    []string
+	MailRequestExpiredNotify []string
 
 	// Ldap configs
 	LDAPHost         string
@@ -89,18 +155,34 @@ func LoadConfig() {
 		log.Fatal("Mongo url is required but not set")
 	}
 
-	DisableMigration = os.Getenv("DISABLE_MIGRATION")
-	if DisableMigration == "" {
-		log.Fatal("DisableMigration status is required but not set")
+	disableMigrationStr := os.Getenv("DISABLE_MIGRATION")
+	if disableMigrationStr == "" {
+		log.Print("Info: DISABLE_MIGRATION is not set, migrations run on startup")
+	} else {
+		DisableMigration, err = strconv.ParseBool(disableMigrationStr)
+		if err != nil {
+			log.Fatalf("Invalid DISABLE_MIGRATION value: %v", err)
+		}
+	}
+
+	MigrationsDir = os.Getenv("MIGRATIONS_DIR")
+	if MigrationsDir == "" {
+		MigrationsDir = "db/migrations"
+		log.Print("Info: MIGRATIONS_DIR is not set, defaulting to db/migrations")
 	}
 
-	JwtSecret = os.Getenv("JWT_SECRET")
-	if JwtSecret == "" {
-		log.Fatal("JWT_SECRET is required but not set")
+	JwtKeysDir = os.Getenv("JWT_KEYS_DIR")
+	if JwtKeysDir == "" {
+		log.Fatal("JWT_KEYS_DIR is required but not set")
+	}
+
+	JwtSigningKeyID = os.Getenv("JWT_SIGNING_KEY_ID")
+	if JwtSigningKeyID == "" {
+		log.Print("Info: JWT_SIGNING_KEY_ID is not set, signing with the key whose name sorts last")
 	}
 
 	RefreshJwtSecret = os.Getenv("REFRESH_JWT_SECRET")
-	if JwtSecret == "" {
+	if RefreshJwtSecret == "" {
 		log.Fatal("REFRESH_JWT_SECRET is required but not set")
 	}
 
@@ -134,6 +216,223 @@ func LoadConfig() {
 		log.Fatalf("Invalid APP_TIMEOUT format: %v", err)
 	}
 
+	ShutdownTimeout = 30 * time.Second
+	shutdownTimeoutStr := os.Getenv("SHUTDOWN_TIMEOUT")
+	if shutdownTimeoutStr == "" {
+		log.Print("Info: SHUTDOWN_TIMEOUT is not set, defaulting to 30s")
+	} else {
+		ShutdownTimeout, err = time.ParseDuration(shutdownTimeoutStr)
+		if err != nil || ShutdownTimeout <= 0 {
+			log.Fatalf("Invalid SHUTDOWN_TIMEOUT %q, expected a positive duration", shutdownTimeoutStr)
+		}
+	}
+
+	HealthCheckTimeout = 5 * time.Second
+	healthCheckTimeoutStr := os.Getenv("HEALTH_CHECK_TIMEOUT")
+	if healthCheckTimeoutStr == "" {
+		log.Print("Info: HEALTH_CHECK_TIMEOUT is not set, defaulting to 5s")
+	} else {
+		HealthCheckTimeout, err = time.ParseDuration(healthCheckTimeoutStr)
+		if err != nil || HealthCheckTimeout <= 0 {
+			log.Fatalf("Invalid HEALTH_CHECK_TIMEOUT %q, expected a positive duration", healthCheckTimeoutStr)
+		}
+	}
+
+	MetricsToken = os.Getenv("METRICS_TOKEN")
+	if MetricsToken == "" {
+		log.Print("Info: METRICS_TOKEN is not set, /metrics is served without authentication")
+	}
+
+	SessionRevocationPollInterval = 10 * time.Second
+	sessionPollStr := os.Getenv("SESSION_REVOCATION_POLL_INTERVAL")
+	if sessionPollStr == "" {
+		log.Print("Info: SESSION_REVOCATION_POLL_INTERVAL is not set, defaulting to 10s")
+	} else {
+		SessionRevocationPollInterval, err = time.ParseDuration(sessionPollStr)
+		if err != nil || SessionRevocationPollInterval <= 0 {
+			log.Fatalf("Invalid SESSION_REVOCATION_POLL_INTERVAL %q, expected a positive duration", sessionPollStr)
+		}
+	}
+
+	PermissionsPollInterval = 5 * time.Second
+	permissionsPollStr := os.Getenv("PERMISSIONS_POLL_INTERVAL")
+	if permissionsPollStr == "" {
+		log.Print("Info: PERMISSIONS_POLL_INTERVAL is not set, defaulting to 5s")
+	} else {
+		PermissionsPollInterval, err = time.ParseDuration(permissionsPollStr)
+		if err != nil || PermissionsPollInterval <= 0 {
+			log.Fatalf("Invalid PERMISSIONS_POLL_INTERVAL %q, expected a positive duration", permissionsPollStr)
+		}
+	}
+
+	// Forwarding headers are only believed from these addresses or CIDRs
+	TrustedProxies = loadList("TRUSTED_PROXIES")
+	if len(TrustedProxies) == 0 {
+		TrustedProxies = []string{"127.0.0.1"}
+		log.Print("Info: TRUSTED_PROXIES is not set, defaulting to 127.0.0.1")
+	}
+
+	IPBindingMode = loadIPBindingMode("IP_BINDING_MODE", strings.ToLower(strings.TrimSpace(os.Getenv("IP_BINDING_MODE"))))
+	if IPBindingMode == "" {
+		IPBindingMode = "strict"
+		log.Print("Info: IP_BINDING_MODE is not set, defaulting to strict")
+	}
+
+	IPBindingRoleModes = map[string]string{}
+	for _, p := range loadList("IP_BINDING_ROLE_MODES") {
+		role, mode, ok := strings.Cut(p, "=")
+		role = strings.ToUpper(strings.TrimSpace(role))
+		if !ok || role == "" {
+			log.Fatalf("Invalid IP_BINDING_ROLE_MODES entry %q, expected ROLE=mode", p)
+		}
+		IPBindingRoleModes[role] = loadIPBindingMode("IP_BINDING_ROLE_MODES", strings.ToLower(strings.TrimSpace(mode)))
+	}
+	for role, mode := range IPBindingRoleModes {
+		if mode == "device" {
+			log.Printf("Warning: %s tokens use device binding, which a stolen token can pass by copying the client's headers", role)
+		}
+	}
+	if IPBindingMode == "device" {
+		log.Print("Warning: IP_BINDING_MODE is device, which a stolen token can pass by copying the client's headers; keep privileged roles on strict or subnet with IP_BINDING_ROLE_MODES")
+	}
+
+	RequestLockTTL = 15 * time.Minute
+	lockTTLStr := os.Getenv("REQUEST_LOCK_TTL")
+	if lockTTLStr == "" {
+		log.Print("Info: REQUEST_LOCK_TTL is not set, defaulting to 15m")
+	} else {
+		RequestLockTTL, err = time.ParseDuration(lockTTLStr)
+		if err != nil {
+			log.Fatalf("Invalid REQUEST_LOCK_TTL format: %v", err)
+		}
+	}
+
+	DuplicateRequestPolicy = strings.ToLower(os.Getenv("DUPLICATE_REQUEST_POLICY"))
+	switch DuplicateRequestPolicy {
+	case "":
+		DuplicateRequestPolicy = "flag"
+		log.Print("Info: DUPLICATE_REQUEST_POLICY is not set, defaulting to flag")
+	case "off", "flag", "block":
+	default:
+		log.Fatalf("Invalid DUPLICATE_REQUEST_POLICY %q, expected off, flag or block", DuplicateRequestPolicy)
+	}
+
+	DuplicateRequestLookback = 30 * 24 * time.Hour
+	lookbackStr := os.Getenv("DUPLICATE_REQUEST_LOOKBACK")
+	if lookbackStr == "" {
+		log.Print("Info: DUPLICATE_REQUEST_LOOKBACK is not set, defaulting to 720h")
+	} else {
+		DuplicateRequestLookback, err = time.ParseDuration(lookbackStr)
+		if err != nil {
+			log.Fatalf("Invalid DUPLICATE_REQUEST_LOOKBACK format: %v", err)
+		}
+	}
+
+	DuplicateNameThreshold = 0.85
+	thresholdStr := os.Getenv("DUPLICATE_NAME_THRESHOLD")
+	if thresholdStr == "" {
+		log.Print("Info: DUPLICATE_NAME_THRESHOLD is not set, defaulting to 0.85")
+	} else {
+		DuplicateNameThreshold, err = strconv.ParseFloat(thresholdStr, 64)
+		if err != nil || DuplicateNameThreshold <= 0 || DuplicateNameThreshold > 1 {
+			log.Fatalf("Invalid DUPLICATE_NAME_THRESHOLD %q, expected a number above 0 and at most 1", thresholdStr)
+		}
+	}
+
+	DuplicateVelocityMax = 3
+	velocityStr := os.Getenv("DUPLICATE_VELOCITY_MAX")
+	if velocityStr == "" {
+		log.Print("Info: DUPLICATE_VELOCITY_MAX is not set, defaulting to 3")
+	} else {
+		DuplicateVelocityMax, err = strconv.Atoi(velocityStr)
+		if err != nil || DuplicateVelocityMax < 0 {
+			log.Fatalf("Invalid DUPLICATE_VELOCITY_MAX %q, expected a non-negative number", velocityStr)
+		}
+	}
+
+	NotificationMaxAttempts = 5
+	maxAttemptsStr := os.Getenv("NOTIFICATION_MAX_ATTEMPTS")
+	if maxAttemptsStr == "" {
+		log.Print("Info: NOTIFICATION_MAX_ATTEMPTS is not set, defaulting to 5")
+	} else {
+		NotificationMaxAttempts, err = strconv.Atoi(maxAttemptsStr)
+		if err != nil || NotificationMaxAttempts < 1 {
+			log.Fatalf("Invalid NOTIFICATION_MAX_ATTEMPTS %q, expected a positive number", maxAttemptsStr)
+		}
+	}
+
+	NotificationRetryBase = 30 * time.Second
+	retryBaseStr := os.Getenv("NOTIFICATION_RETRY_BASE")
+	if retryBaseStr == "" {
+		log.Print("Info: NOTIFICATION_RETRY_BASE is not set, defaulting to 30s")
+	} else {
+		NotificationRetryBase, err = time.ParseDuration(retryBaseStr)
+		if err != nil {
+			log.Fatalf("Invalid NOTIFICATION_RETRY_BASE format: %v", err)
+		}
+	}
+
+	NotificationRetryMax = time.Hour
+	retryMaxStr := os.Getenv("NOTIFICATION_RETRY_MAX")
+	if retryMaxStr == "" {
+		log.Print("Info: NOTIFICATION_RETRY_MAX is not set, defaulting to 1h")
+	} else {
+		NotificationRetryMax, err = time.ParseDuration(retryMaxStr)
+		if err != nil {
+			log.Fatalf("Invalid NOTIFICATION_RETRY_MAX format: %v", err)
+		}
+	}
+
+	NotificationPollInterval = 10 * time.Second
+	pollIntervalStr := os.Getenv("NOTIFICATION_POLL_INTERVAL")
+	if pollIntervalStr == "" {
+		log.Print("Info: NOTIFICATION_POLL_INTERVAL is not set, defaulting to 10s")
+	} else {
+		NotificationPollInterval, err = time.ParseDuration(pollIntervalStr)
+		if err != nil || NotificationPollInterval <= 0 {
+			log.Fatalf("Invalid NOTIFICATION_POLL_INTERVAL %q, expected a positive duration", pollIntervalStr)
+		}
+	}
+
+	JobPollInterval = 15 * time.Second
+	jobPollIntervalStr := os.Getenv("JOB_POLL_INTERVAL")
+	if jobPollIntervalStr == "" {
+		log.Print("Info: JOB_POLL_INTERVAL is not set, defaulting to 15s")
+	} else {
+		JobPollInterval, err = time.ParseDuration(jobPollIntervalStr)
+		if err != nil || JobPollInterval <= 0 {
+			log.Fatalf("Invalid JOB_POLL_INTERVAL %q, expected a positive duration", jobPollIntervalStr)
+		}
+	}
+
+	JobLeaseTTL = time.Minute
+	jobLeaseTTLStr := os.Getenv("JOB_LEASE_TTL")
+	if jobLeaseTTLStr == "" {
+		log.Print("Info: JOB_LEASE_TTL is not set, defaulting to 1m")
+	} else {
+		JobLeaseTTL, err = time.ParseDuration(jobLeaseTTLStr)
+		if err != nil || JobLeaseTTL < 3*time.Second {
+			log.Fatalf("Invalid JOB_LEASE_TTL %q, expected a duration of at least 3s", jobLeaseTTLStr)
+		}
+	}
+
+	// Schedules are checked when the jobs are registered.
+	JobLockExpirySchedule = loadSchedule("JOB_LOCK_EXPIRY_SCHEDULE", "*/5 * * * *")
+	JobSLASchedule = loadSchedule("JOB_SLA_SCHEDULE", "*/5 * * * *")
+	JobTokenCleanupSchedule = loadSchedule("JOB_TOKEN_CLEANUP_SCHEDULE", "0 * * * *")
+	JobOrphanFilesSchedule = loadSchedule("JOB_ORPHAN_FILES_SCHEDULE", "30 2 * * *")
+
+	OrphanFileGrace = 24 * time.Hour
+	orphanFileGraceStr := os.Getenv("ORPHAN_FILE_GRACE")
+	if orphanFileGraceStr == "" {
+		log.Print("Info: ORPHAN_FILE_GRACE is not set, defaulting to 24h")
+	} else {
+		OrphanFileGrace, err = time.ParseDuration(orphanFileGraceStr)
+		if err != nil || OrphanFileGrace < time.Hour {
+			log.Fatalf("Invalid ORPHAN_FILE_GRACE %q, expected a duration of at least 1h", orphanFileGraceStr)
+		}
+	}
+
 	FileUploadPath = os.Getenv("FILE_UPLOAD_PATH")
 	if FileUploadPath == "" {
 		log.Fatal("FILE_UPLOAD_PATH is required but not set")
@@ -166,6 +465,30 @@ func LoadConfig() {
 		log.Fatal("MAIL_PORT is required but not set")
 	}
 
+	MailFromAddress = os.Getenv("MAIL_FROM_ADDRESS")
+	if MailFromAddress == "" {
+		MailFromAddress = "forexhub@coopbankoromiasc.com"
+		log.Print("Info: MAIL_FROM_ADDRESS is not set, defaulting to forexhub@coopbankoromiasc.com")
+	}
+
+	MailFromName = os.Getenv("MAIL_FROM_NAME")
+	if MailFromName == "" {
+		MailFromName = "Forex Hub"
+		log.Print("Info: MAIL_FROM_NAME is not set, defaulting to Forex Hub")
+	}
+
+	MailReplyTo = os.Getenv("MAIL_REPLY_TO")
+	if MailReplyTo == "" {
+		MailReplyTo = MailFromAddress
+		log.Print("Info: MAIL_REPLY_TO is not set, defaulting to MAIL_FROM_ADDRESS")
+	}
+
+	MailTemplatesDir = os.Getenv("MAIL_TEMPLATES_DIR")
+	if MailTemplatesDir == "" {
+		MailTemplatesDir = "templates/email"
+		log.Print("Info: MAIL_TEMPLATES_DIR is not set, defaulting to templates/email")
+	}
+
 	// ---------------------------------SEND EMAIL VARIABLES---------------------
 	MailRequestCreatedTo = LoadEmailsFromEnv("MAIL_REQUEST_CREATED_TO")
 	if len(MailRequestCreatedTo) == 0 {
@@ -192,6 +515,7 @@ func LoadConfig() {
 	if len(MailRequestSentBcc) == 0 {
 		log.Print("Info: MAIL_REQUEST_SENT_BCC is not set")
 	}
+	MailRequestSentNotify = LoadNotifyFromEnv("MAIL_REQUEST_SENT_NOTIFY")
 
 	MailRequestAuthorizedTo = LoadEmailsFromEnv("MAIL_REQUEST_AUTHORIZED_TO")
 	if len(MailRequestAuthorizedTo) == 0 {
@@ -205,6 +529,7 @@ func LoadConfig() {
 	if len(MailRequestAuthorizedBcc) == 0 {
 		log.Print("Info: MAIL_REQUEST_AUTHORIZED_BCC is not set")
 	}
+	MailRequestAuthorizedNotify = LoadNotifyFromEnv("MAIL_REQUEST_AUTHORIZED_NOTIFY")
 
 	MailRequestValidatedTo = LoadEmailsFromEnv("MAIL_REQUEST_VALIDATED_TO")
 	if len(MailRequestValidatedTo) == 0 {
@@ -218,6 +543,7 @@ func LoadConfig() {
 	if len(MailRequestValidatedBcc) == 0 {
 		log.Print("Info: MAIL_REQUEST_VALIDATED_BCC is not set")
 	}
+	MailRequestValidatedNotify = LoadNotifyFromEnv("MAIL_REQUEST_VALIDATED_NOTIFY")
 
 	MailRequestRejectedTo = LoadEmailsFromEnv("MAIL_REQUEST_REJECTED_TO")
 	if len(MailRequestRejectedTo) == 0 {
@@ -231,6 +557,7 @@ func LoadConfig() {
 	if len(MailRequestRejectedBcc) == 0 {
 		log.Print("Info: MAIL_REQUEST_REJECTED_BCC is not set")
 	}
+	MailRequestRejectedNotify = LoadNotifyFromEnv("MAIL_REQUEST_REJECTED_NOTIFY")
 
 	MailRequestApprovedTo = LoadEmailsFromEnv("MAIL_REQUEST_APPROVED_TO")
 	if len(MailRequestApprovedTo) == 0 {
@@ -244,6 +571,7 @@ func LoadConfig() {
 	if len(MailRequestApprovedBcc) == 0 {
 		log.Print("Info: MAIL_REQUEST_APPROVED_BCC is not set")
 	}
+	MailRequestApprovedNotify = LoadNotifyFromEnv("MAIL_REQUEST_APPROVED_NOTIFY")
 
 	MailRequestAcceptedTo = LoadEmailsFromEnv("MAIL_REQUEST_ACCEPTED_TO")
 	if len(MailRequestAcceptedTo) == 0 {
@@ -257,6 +585,7 @@ func LoadConfig() {
 	if len(MailRequestAcceptedBcc) == 0 {
 		log.Print("Info: MAIL_REQUEST_ACCEPTED_BCC is not set")
 	}
+	MailRequestAcceptedNotify = LoadNotifyFromEnv("MAIL_REQUEST_ACCEPTED_NOTIFY")
 
 	MailRequestDeclinedTo = LoadEmailsFromEnv("MAIL_REQUEST_DECLINED_TO")
 	if len(MailRequestDeclinedTo) == 0 {
@@ -270,6 +599,49 @@ func LoadConfig() {
 	if len(MailRequestDeclinedBcc) == 0 {
 		log.Print("Info: MAIL_REQUEST_DECLINED_BCC is not set")
 	}
+	MailRequestDeclinedNotify = LoadNotifyFromEnv("MAIL_REQUEST_DECLINED_NOTIFY")
+
+	MailRequestDueSoonTo = LoadEmailsFromEnv("MAIL_REQUEST_DUE_SOON_TO")
+	if len(MailRequestDueSoonTo) == 0 {
+		log.Print("Info: MAIL_REQUEST_DUE_SOON_TO is not set")
+	}
+	MailRequestDueSoonCc = LoadEmailsFromEnv("MAIL_REQUEST_DUE_SOON_CC")
+	if len(MailRequestDueSoonCc) == 0 {
+		log.Print("Info: MAIL_REQUEST_DUE_SOON_CC is not set")
+	}
+	MailRequestDueSoonBcc = LoadEmailsFromEnv("MAIL_REQUEST_DUE_SOON_BCC")
+	if len(MailRequestDueSoonBcc) == 0 {
+		log.Print("Info: MAIL_REQUEST_DUE_SOON_BCC is not set")
+	}
+	MailRequestDueSoonNotify = LoadNotifyFromEnv("MAIL_REQUEST_DUE_SOON_NOTIFY")
+
+	MailRequestOverdueTo = LoadEmailsFromEnv("MAIL_REQUEST_OVERDUE_TO")
+	if len(MailRequestOverdueTo) == 0 {
+		log.Print("Info: MAIL_REQUEST_OVERDUE_TO is not set")
+	}
+	MailRequestOverdueCc = LoadEmailsFromEnv("MAIL_REQUEST_OVERDUE_CC")
+	if len(MailRequestOverdueCc) == 0 {
+		log.Print("Info: MAIL_REQUEST_OVERDUE_CC is not set")
+	}
+	MailRequestOverdueBcc = LoadEmailsFromEnv("MAIL_REQUEST_OVERDUE_BCC")
+	if len(MailRequestOverdueBcc) == 0 {
+		log.Print("Info: MAIL_REQUEST_OVERDUE_BCC is not set")
+	}
+	MailRequestOverdueNotify = LoadNotifyFromEnv("MAIL_REQUEST_OVERDUE_NOTIFY")
+
+	MailRequestExpiredTo = LoadEmailsFromEnv("MAIL_REQUEST_EXPIRED_TO")
+	if len(MailRequestExpiredTo) == 0 {
+		log.Print("Info: MAIL_REQUEST_EXPIRED_TO is not set")
+	}
+	MailRequestExpiredCc = LoadEmailsFromEnv("MAIL_REQUEST_EXPIRED_CC")
+	if len(MailRequestExpiredCc) == 0 {
+		log.Print("Info: MAIL_REQUEST_EXPIRED_CC is not set")
+	}
+	MailRequestExpiredBcc = LoadEmailsFromEnv("MAIL_REQUEST_EXPIRED_BCC")
+	if len(MailRequestExpiredBcc) == 0 {
+		log.Print("Info: MAIL_REQUEST_EXPIRED_BCC is not set")
+	}
+	MailRequestExpiredNotify = LoadNotifyFromEnv("MAIL_REQUEST_EXPIRED_NOTIFY")
 
 	// --------------------------------------------------------------------------------
 
@@ -336,3 +708,63 @@ func LoadEmailsFromEnv(key string) []string {
 
 	return emails
 }
+
+// LoadNotifyFromEnv reads a list of dynamic notification recipients: branch,
+// requester, next_step and escalation. It returns nil when key is unset so
+// the default applies, and an empty list for "none".
+func LoadNotifyFromEnv(key string) []string {
+	val := os.Getenv(key)
+	if val == "" {
+		return nil
+	}
+
+	recipients := []string{}
+	for _, p := range strings.Split(val, ",") {
+		r := strings.ToLower(strings.TrimSpace(p))
+		switch r {
+		case "none":
+		case "branch", "requester", "next_step", "escalation":
+			recipients = append(recipients, r)
+		default:
+			log.Fatalf("Invalid %s recipient %q, expected branch, requester, next_step, escalation or none", key, p)
+		}
+	}
+
+	return recipients
+}
+
+// loadList reads a comma separated list, skipping empty entries.
+func loadList(key string) []string {
+	list := []string{}
+	for _, p := range strings.Split(os.Getenv(key), ",") {
+		if v := strings.TrimSpace(p); v != "" {
+			list = append(list, v)
+		}
+	}
+
+	return list
+}
+
+// loadIPBindingMode checks mode, read from key, is one the binding policy
+// knows. An empty mode is returned as is.
+func loadIPBindingMode(key, mode string) string {
+	switch mode {
+	case "", "strict", "subnet", "device", "off":
+		return mode
+	default:
+		log.Fatalf("Invalid %s mode %q, expected strict, subnet, device or off", key, mode)
+		return ""
+	}
+}
+
+// loadSchedule reads the cron schedule of a background job, falling back to
+// fallback when key is unset.
+func loadSchedule(key, fallback string) string {
+	val := strings.TrimSpace(os.Getenv(key))
+	if val == "" {
+		log.Printf("Info: %s is not set, defaulting to %q", key, fallback)
+		return fallback
+	}
+
+	return val
+}
@@ -11,6 +11,7 @@ var (
 	ErrADUserNotFound             = errors.New("User not found in AD")
 	ErrUserNotFound               = errors.New("User not found")
 	ErrBranchOrDepartmentNotFound = errors.New("either BranchID or DepartmentID must be provided")
+	ErrBranchNotFound             = errors.New("branch not found")
 	ErrUsernameAlreadyExists      = errors.New("username already exists")
 
 	ErrProfileNotFound = fmt.Errorf("profile not found")
@@ -19,16 +20,73 @@ var (
 	ErrRoleNameAlreadyExists = errors.New("role with this name already exists")
 	ErrRoleNameNotAllowed    = errors.New("role name not allowed")
 
+	ErrSessionEnded       = errors.New("session has ended")
+	ErrRefreshTokenReused = errors.New("refresh token was already used, the session has been revoked")
+	ErrIPBindingMismatch  = errors.New("token was issued to another network or device")
+
 	ErrUnauthorized   = errors.New("unauthorized")
 	ErrInternalServer = errors.New("internal server error")
 
 	ErrRequestNotFound         = errors.New("request not found")
 	ErrRequestIsLocked         = errors.New("request is already locked by another user")
+	ErrRequestLockNotHeld      = errors.New("request lock is not held or has expired")
 	ErrRequestCannotBeDeleted  = errors.New("request cannot be deleted")
 	ErrInvalidAcceptedAmount   = errors.New("accepted amount cannot be greater than approved amount")
 	ErrInvalidAcceptedCurrency = errors.New("accepted currency must be the same approved currency amount")
+	ErrAllocationLinesMismatch = errors.New("every currency must have an amount, a cash amount and a card amount")
+	ErrInvalidStatusTransition = errors.New("request status transition is not allowed")
+	ErrRequestNotEditable      = errors.New("request can no longer be edited")
+	ErrRequestConflict         = errors.New("request was modified by another user")
+	ErrInvalidRequestCursor    = errors.New("invalid or expired request cursor")
+
+	ErrCurrencyNotFound     = errors.New("currency not found")
+	ErrExchangeRateNotFound = errors.New("no exchange rate is in force for the currency")
+	ErrInvalidExchangeRate  = errors.New("selling rate must not be lower than buying rate and mid rate must lie between them")
+	ErrInvalidRateWindow    = errors.New("rate validity must end after it starts")
+	ErrInvalidRateUpload    = errors.New("rate upload contains invalid rows")
+	ErrEmptyRateUpload      = errors.New("rate upload contains no rows")
+	ErrExchangeRateInEffect = errors.New("exchange rate has taken effect and can no longer be edited, publish a new rate instead")
+
+	ErrAllocationLimitNotFound       = errors.New("allocation limit not found")
+	ErrInvalidAllocationLimit        = errors.New("invalid allocation limit")
+	ErrAllocationLimitExceeded       = errors.New("allocation limits exceeded")
+	ErrLimitOverrideNotAllowed       = errors.New("user is not allowed to override allocation limits")
+	ErrOverrideJustificationRequired = errors.New("overriding allocation limits requires a justification")
+
+	ErrDuplicateRequest = errors.New("applicant already has open or recent requests")
+
+	ErrNotificationNotFound  = errors.New("notification not found")
+	ErrNotificationNotDead   = errors.New("only dead-lettered notifications can be retried")
+	ErrNotificationClaimLost = errors.New("notification claim expired before delivery was recorded")
+
+	ErrUnknownNotificationEvent = errors.New("unknown notification event")
+
+	ErrStreamTicketInvalid = errors.New("stream ticket is invalid, expired or already used")
+
+	ErrSLAPolicyNotFound = errors.New("SLA policy not found")
+	ErrInvalidSLAPolicy  = errors.New("invalid SLA policy")
+
+	ErrJobNotFound         = errors.New("job not found")
+	ErrJobRunning          = errors.New("job is already running")
+	ErrSchedulerNotRunning = errors.New("job scheduler is not running")
 )
 
+// StatusTransitionError is returned when a workflow action is attempted on a
+// request whose current status does not allow it.
+type StatusTransitionError struct {
+	Action string
+	From   string
+	To     string
+}
+
+func (e *StatusTransitionError) Error() string {
+	return fmt.Sprintf("cannot %s request in %s status (target %s)", e.Action, e.From, e.To)
+}
+
+func (e *StatusTransitionError) Unwrap() error {
+	return ErrInvalidStatusTransition
+}
+
 var (
 	MessInternalServerError = "Internal server error"
 	MessUnauthorized        = "Unauthorized"
@@ -36,5 +94,20 @@ var (
 	MessInvalidRequestData  = "Invalid request data"
 	MessInvalidRequestFile  = "Invalid request data"
 	MessRequestLocked       = "Request is Locked by someone else"
+	MessRequestLockNotHeld  = "Request lock has expired, please lock the request again"
 	MessRequestNotFound     = "Request not found"
+	MessInvalidTransition   = "Request cannot be moved to the requested status"
+	MessForbidden           = "You are not allowed to perform this action"
+	MessAllocationLines     = "Every currency must have an amount, a cash amount and a card amount"
+	MessRequestNotEditable  = "Request can no longer be edited"
+	MessRequestConflict     = "Request was changed by someone else, please reload and try again"
+	MessExchangeRateMissing = "No exchange rate is published for one of the currencies"
+	MessAllocationExceeded  = "Allocation limits exceeded"
+	MessDuplicateRequest    = "Applicant already has open or recent requests"
+	MessNotificationMissing = "Notification not found"
+	MessNotificationNotDead = "Only notifications that ran out of attempts can be retried"
+	MessNotificationEvent   = "Unknown notification event"
+	MessJobNotFound         = "Job not found"
+	MessJobRunning          = "Job is already running"
+	MessSchedulerNotRunning = "Background jobs are not running on this server"
 )
@@ -0,0 +1,178 @@
+package controller
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"github.com/latiiLA/coop-forex-server/internal/common"
+	"github.com/latiiLA/coop-forex-server/internal/delivery/http/response"
+	"github.com/latiiLA/coop-forex-server/internal/domain/model"
+	"github.com/latiiLA/coop-forex-server/internal/infrastructure/utils"
+	"github.com/latiiLA/coop-forex-server/internal/usecase"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type AllocationLimitController interface {
+	AddAllocationLimit(c *gin.Context)
+	GetAllAllocationLimits(c *gin.Context)
+	GetAllocationLimitByID(c *gin.Context)
+	UpdateAllocationLimit(c *gin.Context)
+	DeleteAllocationLimit(c *gin.Context)
+}
+
+type allocationLimitController struct {
+	allocationLimitUsecase usecase.AllocationLimitUsecase
+}
+
+func NewAllocationLimitController(allocationLimitUsecase usecase.AllocationLimitUsecase) AllocationLimitController {
+	return &allocationLimitController{
+		allocationLimitUsecase: allocationLimitUsecase,
+	}
+}
+
+func (ac *allocationLimitController) AddAllocationLimit(c *gin.Context) {
+	userID, err := utils.GetUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, response.Status{Message: common.MessUnauthorized, Error: err.Error()})
+		return
+	}
+
+	var limit model.CreateAllocationLimitDTO
+	if !bindAllocationLimit(c, &limit) {
+		return
+	}
+
+	created, err := ac.allocationLimitUsecase.AddAllocationLimit(c, userID, &limit)
+	if err != nil {
+		writeAllocationLimitError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response.Status{IsSuccessful: true, Message: "Allocation limit created successfully", Data: created})
+}
+
+func (ac *allocationLimitController) GetAllAllocationLimits(c *gin.Context) {
+	limits, err := ac.allocationLimitUsecase.GetAllAllocationLimits(c)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, response.Status{Message: common.MessInternalServerError, Error: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, response.Status{IsSuccessful: true, Message: "Allocation limits fetched successfully", Data: limits})
+}
+
+func (ac *allocationLimitController) GetAllocationLimitByID(c *gin.Context) {
+	limitID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.Status{Message: common.MessInvalidRequestData, Error: err.Error()})
+		return
+	}
+
+	limit, err := ac.allocationLimitUsecase.GetAllocationLimitByID(c, limitID)
+	if err != nil {
+		writeAllocationLimitError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response.Status{IsSuccessful: true, Message: "Allocation limit fetched successfully", Data: limit})
+}
+
+func (ac *allocationLimitController) UpdateAllocationLimit(c *gin.Context) {
+	authUserID, err := utils.GetUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, response.Status{Message: common.MessUnauthorized, Error: err.Error()})
+		return
+	}
+
+	limitID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.Status{Message: common.MessInvalidRequestData, Error: err.Error()})
+		return
+	}
+
+	var limit model.UpdateAllocationLimitDTO
+	if !bindAllocationLimit(c, &limit) {
+		return
+	}
+
+	err = ac.allocationLimitUsecase.UpdateAllocationLimit(c, authUserID, limitID, &limit)
+	if err != nil {
+		writeAllocationLimitError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response.Status{IsSuccessful: true, Message: "Allocation limit updated successfully"})
+}
+
+func (ac *allocationLimitController) DeleteAllocationLimit(c *gin.Context) {
+	authUserID, err := utils.GetUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, response.Status{Message: common.MessUnauthorized, Error: err.Error()})
+		return
+	}
+
+	limitID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.Status{Message: common.MessInvalidRequestData, Error: err.Error()})
+		return
+	}
+
+	err = ac.allocationLimitUsecase.DeleteAllocationLimit(c, authUserID, limitID)
+	if err != nil {
+		writeAllocationLimitError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response.Status{IsSuccessful: true, Message: "Allocation limit deleted successfully"})
+}
+
+func bindAllocationLimit(c *gin.Context, limit *model.CreateAllocationLimitDTO) bool {
+	err := c.ShouldBindJSON(limit)
+	if err == nil {
+		return true
+	}
+
+	if validationErrors, ok := err.(validator.ValidationErrors); ok {
+		e := validationErrors[0]
+		message := fmt.Sprintf("%s failed on %s validation", e.Field(), e.Tag())
+
+		c.JSON(http.StatusBadRequest, response.Status{
+			Message: message,
+			Error:   err.Error(),
+		})
+
+		return false
+	}
+
+	c.JSON(http.StatusBadRequest, response.Status{
+		Message: common.MessInvalidRequest,
+		Error:   err.Error(),
+	})
+	return false
+}
+
+func writeAllocationLimitError(c *gin.Context, err error) {
+	var (
+		status  int
+		message string
+	)
+
+	switch {
+	case errors.Is(err, common.ErrAllocationLimitNotFound):
+		status = http.StatusNotFound
+		message = "Allocation limit not found"
+
+	case errors.Is(err, common.ErrInvalidAllocationLimit):
+		status = http.StatusBadRequest
+		message = common.MessInvalidRequestData
+
+	default:
+		status = http.StatusInternalServerError
+		message = common.MessInternalServerError
+	}
+
+	c.JSON(status, response.Status{Message: message, Error: err.Error()})
+}
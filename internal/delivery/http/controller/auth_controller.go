@@ -38,8 +38,9 @@ func (a *authController) Login(c *gin.Context) {
 	traceID := c.GetString("TraceID")
 
 	// Log metadata early
-	clientIP := c.ClientIP()
-	userAgent := c.Request.UserAgent()
+	client := utils.GetClientInfo(c)
+	clientIP := client.IP
+	userAgent := client.UserAgent
 
 	if err := c.ShouldBindJSON(&req); err != nil {
 		log.WithFields(log.Fields{
@@ -75,7 +76,7 @@ func (a *authController) Login(c *gin.Context) {
 	}).Info("Login attempt")
 
 	// Call the usecase to perform authentication
-	user, err := a.authUsecase.Authenticate(c, strings.ToLower(req.Username), req.Password, c.ClientIP())
+	user, err := a.authUsecase.Authenticate(c, strings.ToLower(req.Username), req.Password, client)
 	if err != nil {
 		log.WithFields(log.Fields{
 			"trace_id": traceID,
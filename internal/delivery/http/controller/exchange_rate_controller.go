@@ -0,0 +1,315 @@
+package controller
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"github.com/latiiLA/coop-forex-server/internal/common"
+	"github.com/latiiLA/coop-forex-server/internal/delivery/http/response"
+	"github.com/latiiLA/coop-forex-server/internal/domain/model"
+	"github.com/latiiLA/coop-forex-server/internal/infrastructure/utils"
+	"github.com/latiiLA/coop-forex-server/internal/usecase"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const maxRateUploadSize = 2 << 20
+
+type ExchangeRateController interface {
+	AddExchangeRate(c *gin.Context)
+	UploadExchangeRates(c *gin.Context)
+	GetExchangeRateByID(c *gin.Context)
+	GetCurrentExchangeRates(c *gin.Context)
+	GetExchangeRateHistory(c *gin.Context)
+	UpdateExchangeRate(c *gin.Context)
+	DeleteExchangeRate(c *gin.Context)
+}
+
+type exchangeRateController struct {
+	exchangeRateUsecase usecase.ExchangeRateUsecase
+}
+
+func NewExchangeRateController(exchangeRateUsecase usecase.ExchangeRateUsecase) ExchangeRateController {
+	return &exchangeRateController{
+		exchangeRateUsecase: exchangeRateUsecase,
+	}
+}
+
+func (ec *exchangeRateController) AddExchangeRate(c *gin.Context) {
+	userID, err := utils.GetUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, response.Status{Message: common.MessUnauthorized, Error: err.Error()})
+		return
+	}
+
+	var rate model.CreateExchangeRateDTO
+
+	err = c.ShouldBindJSON(&rate)
+	if err != nil {
+		if validationErrors, ok := err.(validator.ValidationErrors); ok {
+			e := validationErrors[0]
+			message := fmt.Sprintf("%s failed on %s validation", e.Field(), e.Tag())
+
+			c.JSON(http.StatusBadRequest, response.Status{
+				Message: message,
+				Error:   err.Error(),
+			})
+
+			return
+		}
+
+		c.JSON(http.StatusBadRequest, response.Status{
+			Message: common.MessInvalidRequest,
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	created, err := ec.exchangeRateUsecase.AddExchangeRate(c, userID, &rate)
+	if err != nil {
+		ec.writeError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response.Status{IsSuccessful: true, Message: "Exchange rate published successfully", Data: created})
+}
+
+// UploadExchangeRates accepts either a multipart "file" field holding a .csv
+// or .json file, or a JSON array of rows as the request body.
+func (ec *exchangeRateController) UploadExchangeRates(c *gin.Context) {
+	userID, err := utils.GetUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, response.Status{Message: common.MessUnauthorized, Error: err.Error()})
+		return
+	}
+
+	var rows []model.ExchangeRateRowDTO
+
+	if c.ContentType() == gin.MIMEJSON {
+		if err := c.ShouldBindJSON(&rows); err != nil {
+			c.JSON(http.StatusBadRequest, response.Status{Message: common.MessInvalidRequest, Error: err.Error()})
+			return
+		}
+	} else {
+		file, err := c.FormFile("file")
+		if err != nil {
+			c.JSON(http.StatusBadRequest, response.Status{Message: common.MessInvalidRequestFile, Error: "rate file is required"})
+			return
+		}
+
+		if file.Size > maxRateUploadSize {
+			c.JSON(http.StatusBadRequest, response.Status{Message: common.MessInvalidRequestFile, Error: "rate file must not exceed 2MB"})
+			return
+		}
+
+		f, err := file.Open()
+		if err != nil {
+			c.JSON(http.StatusBadRequest, response.Status{Message: common.MessInvalidRequestFile, Error: err.Error()})
+			return
+		}
+		defer f.Close()
+
+		var rowErrors []model.ExchangeRateRowError
+		rows, rowErrors, err = utils.ParseExchangeRateUpload(file.Filename, f)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, response.Status{Message: common.MessInvalidRequestFile, Error: err.Error()})
+			return
+		}
+
+		if len(rowErrors) > 0 {
+			c.JSON(http.StatusUnprocessableEntity, response.Status{
+				Message: "Rate file contains invalid rows",
+				Error:   common.ErrInvalidRateUpload.Error(),
+				Data:    model.ExchangeRateUploadResultDTO{Errors: rowErrors},
+			})
+			return
+		}
+	}
+
+	result, err := ec.exchangeRateUsecase.UploadExchangeRates(c, userID, rows)
+	if err != nil {
+		if errors.Is(err, common.ErrInvalidRateUpload) {
+			c.JSON(http.StatusUnprocessableEntity, response.Status{Message: "Rate file contains invalid rows", Error: err.Error(), Data: result})
+			return
+		}
+
+		ec.writeError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response.Status{IsSuccessful: true, Message: "Exchange rates published successfully", Data: result})
+}
+
+func (ec *exchangeRateController) GetExchangeRateByID(c *gin.Context) {
+	rateID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.Status{Message: common.MessInvalidRequestData, Error: err.Error()})
+		return
+	}
+
+	rate, err := ec.exchangeRateUsecase.GetExchangeRateByID(c, rateID)
+	if err != nil {
+		ec.writeError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response.Status{IsSuccessful: true, Message: "Exchange rate fetched successfully", Data: rate})
+}
+
+func (ec *exchangeRateController) GetCurrentExchangeRates(c *gin.Context) {
+	rates, err := ec.exchangeRateUsecase.GetCurrentExchangeRates(c)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, response.Status{Message: common.MessInternalServerError, Error: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, response.Status{IsSuccessful: true, Message: "Exchange rates fetched successfully", Data: rates})
+}
+
+func (ec *exchangeRateController) GetExchangeRateHistory(c *gin.Context) {
+	var query model.ExchangeRateHistoryQueryDTO
+	if err := c.ShouldBindQuery(&query); err != nil {
+		if validationErrors, ok := err.(validator.ValidationErrors); ok {
+			e := validationErrors[0]
+			c.JSON(http.StatusBadRequest, response.Status{
+				Message: fmt.Sprintf("%s failed on %s validation", e.Field(), e.Tag()),
+				Error:   err.Error(),
+			})
+			return
+		}
+
+		c.JSON(http.StatusBadRequest, response.Status{Message: common.MessInvalidRequest, Error: err.Error()})
+		return
+	}
+
+	currencyID, err := primitive.ObjectIDFromHex(query.CurrencyID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.Status{Message: common.MessInvalidRequestData, Error: err.Error()})
+		return
+	}
+
+	var from, to *time.Time
+	if query.From != "" {
+		if from, err = parseRequestDate(query.From, false); err != nil {
+			c.JSON(http.StatusBadRequest, response.Status{Message: common.MessInvalidRequestData, Error: err.Error()})
+			return
+		}
+	}
+	if query.To != "" {
+		if to, err = parseRequestDate(query.To, true); err != nil {
+			c.JSON(http.StatusBadRequest, response.Status{Message: common.MessInvalidRequestData, Error: err.Error()})
+			return
+		}
+	}
+
+	rates, err := ec.exchangeRateUsecase.GetExchangeRateHistory(c, currencyID, from, to)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, response.Status{Message: common.MessInternalServerError, Error: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, response.Status{IsSuccessful: true, Message: "Exchange rate history fetched successfully", Data: rates})
+}
+
+func (ec *exchangeRateController) UpdateExchangeRate(c *gin.Context) {
+	authUserID, err := utils.GetUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, response.Status{Message: common.MessUnauthorized, Error: err.Error()})
+		return
+	}
+
+	rateID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.Status{Message: common.MessInvalidRequestData, Error: err.Error()})
+		return
+	}
+
+	var rateUpdate model.UpdateExchangeRateDTO
+
+	err = c.ShouldBindJSON(&rateUpdate)
+	if err != nil {
+		if validationErrors, ok := err.(validator.ValidationErrors); ok {
+			e := validationErrors[0]
+			message := fmt.Sprintf("%s failed on %s validation", e.Field(), e.Tag())
+
+			c.JSON(http.StatusBadRequest, response.Status{
+				Message: message,
+				Error:   err.Error(),
+			})
+
+			return
+		}
+
+		c.JSON(http.StatusBadRequest, response.Status{
+			Message: common.MessInvalidRequest,
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	err = ec.exchangeRateUsecase.UpdateExchangeRate(c, authUserID, rateID, &rateUpdate)
+	if err != nil {
+		ec.writeError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response.Status{IsSuccessful: true, Message: "Exchange rate updated successfully"})
+}
+
+func (ec *exchangeRateController) DeleteExchangeRate(c *gin.Context) {
+	authUserID, err := utils.GetUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, response.Status{Message: common.MessUnauthorized, Error: err.Error()})
+		return
+	}
+
+	rateID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.Status{Message: common.MessInvalidRequestData, Error: err.Error()})
+		return
+	}
+
+	err = ec.exchangeRateUsecase.DeleteExchangeRate(c, authUserID, rateID)
+	if err != nil {
+		ec.writeError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response.Status{IsSuccessful: true, Message: "Exchange rate deleted successfully"})
+}
+
+func (ec *exchangeRateController) writeError(c *gin.Context, err error) {
+	var (
+		status  int
+		message string
+	)
+
+	switch {
+	case errors.Is(err, common.ErrExchangeRateNotFound):
+		status = http.StatusNotFound
+		message = "Exchange rate not found"
+
+	case errors.Is(err, common.ErrCurrencyNotFound):
+		status = http.StatusBadRequest
+		message = "Currency not found"
+
+	case errors.Is(err, common.ErrExchangeRateInEffect):
+		status = http.StatusConflict
+		message = "Exchange rate is already in effect, publish a new rate instead"
+
+	case errors.Is(err, common.ErrInvalidExchangeRate),
+		errors.Is(err, common.ErrInvalidRateWindow),
+		errors.Is(err, common.ErrEmptyRateUpload):
+		status = http.StatusBadRequest
+		message = common.MessInvalidRequestData
+
+	default:
+		status = http.StatusInternalServerError
+		message = common.MessInternalServerError
+	}
+
+	c.JSON(status, response.Status{Message: message, Error: err.Error()})
+}
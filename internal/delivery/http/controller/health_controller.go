@@ -0,0 +1,40 @@
+package controller
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/latiiLA/coop-forex-server/internal/delivery/http/response"
+	"github.com/latiiLA/coop-forex-server/internal/domain/model"
+)
+
+type HealthController interface {
+	Liveness(c *gin.Context)
+	Readiness(c *gin.Context)
+}
+
+type healthController struct {
+	readinessChecker model.ReadinessChecker
+}
+
+func NewHealthController(readinessChecker model.ReadinessChecker) HealthController {
+	return &healthController{
+		readinessChecker: readinessChecker,
+	}
+}
+
+// Liveness answers as long as the process serves HTTP at all; it checks no
+// dependency so an outage elsewhere does not get the server restarted.
+func (hc *healthController) Liveness(c *gin.Context) {
+	c.JSON(http.StatusOK, response.Status{IsSuccessful: true, Message: "Server is alive"})
+}
+
+func (hc *healthController) Readiness(c *gin.Context) {
+	report := hc.readinessChecker.Check(c)
+	if !report.Ready() {
+		c.JSON(http.StatusServiceUnavailable, response.Status{Message: "Server is not ready", Data: report})
+		return
+	}
+
+	c.JSON(http.StatusOK, response.Status{IsSuccessful: true, Message: "Server is ready", Data: report})
+}
@@ -0,0 +1,205 @@
+package controller
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/latiiLA/coop-forex-server/internal/common"
+	"github.com/latiiLA/coop-forex-server/internal/delivery/http/response"
+	"github.com/latiiLA/coop-forex-server/internal/domain/model"
+	"github.com/latiiLA/coop-forex-server/internal/infrastructure/utils"
+	"github.com/latiiLA/coop-forex-server/internal/usecase"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type InboxController interface {
+	GetInbox(c *gin.Context)
+	CountUnread(c *gin.Context)
+	MarkRead(c *gin.Context)
+	MarkAllRead(c *gin.Context)
+	IssueStreamTicket(c *gin.Context)
+	StreamInbox(c *gin.Context)
+}
+
+type inboxController struct {
+	inboxUsecase usecase.InboxUsecase
+	heartbeat    time.Duration
+}
+
+// NewInboxController returns the inbox controller. Open streams send a ping
+// every heartbeat, which also closes them once their session is revoked.
+func NewInboxController(inboxUsecase usecase.InboxUsecase, heartbeat time.Duration) InboxController {
+	return &inboxController{
+		inboxUsecase: inboxUsecase,
+		heartbeat:    heartbeat,
+	}
+}
+
+func (ic *inboxController) GetInbox(c *gin.Context) {
+	authUserID, err := utils.GetUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, response.Status{Message: common.MessUnauthorized, Error: err.Error()})
+		return
+	}
+
+	var query model.InboxQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.JSON(http.StatusBadRequest, response.Status{Message: common.MessInvalidRequest, Error: err.Error()})
+		return
+	}
+	if before := c.Query("before"); before != "" {
+		id, err := primitive.ObjectIDFromHex(before)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, response.Status{Message: common.MessInvalidRequestData, Error: err.Error()})
+			return
+		}
+		query.Before = &id
+	}
+
+	page, err := ic.inboxUsecase.GetInbox(c, authUserID, &query)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, response.Status{Message: common.MessInternalServerError, Error: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, response.Status{IsSuccessful: true, Message: "Inbox fetched successfully", Data: page})
+}
+
+func (ic *inboxController) CountUnread(c *gin.Context) {
+	authUserID, err := utils.GetUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, response.Status{Message: common.MessUnauthorized, Error: err.Error()})
+		return
+	}
+
+	unread, err := ic.inboxUsecase.CountUnread(c, authUserID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, response.Status{Message: common.MessInternalServerError, Error: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, response.Status{IsSuccessful: true, Message: "Unread count fetched successfully", Data: gin.H{"unread_count": unread}})
+}
+
+func (ic *inboxController) MarkRead(c *gin.Context) {
+	authUserID, err := utils.GetUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, response.Status{Message: common.MessUnauthorized, Error: err.Error()})
+		return
+	}
+
+	var read model.InboxReadDTO
+	if err := c.ShouldBindJSON(&read); err != nil {
+		c.JSON(http.StatusBadRequest, response.Status{Message: common.MessInvalidRequestData, Error: err.Error()})
+		return
+	}
+
+	marked, err := ic.inboxUsecase.MarkRead(c, authUserID, read.IDs)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, response.Status{Message: common.MessInternalServerError, Error: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, response.Status{IsSuccessful: true, Message: "Notifications marked as read", Data: gin.H{"marked": marked}})
+}
+
+func (ic *inboxController) MarkAllRead(c *gin.Context) {
+	authUserID, err := utils.GetUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, response.Status{Message: common.MessUnauthorized, Error: err.Error()})
+		return
+	}
+
+	marked, err := ic.inboxUsecase.MarkAllRead(c, authUserID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, response.Status{Message: common.MessInternalServerError, Error: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, response.Status{IsSuccessful: true, Message: "Notifications marked as read", Data: gin.H{"marked": marked}})
+}
+
+// IssueStreamTicket hands out the single-use ticket StreamInbox is opened
+// with, so the access token never appears in a URL.
+func (ic *inboxController) IssueStreamTicket(c *gin.Context) {
+	authUserID, err := utils.GetUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, response.Status{Message: common.MessUnauthorized, Error: err.Error()})
+		return
+	}
+
+	sessionID, err := utils.GetSessionID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, response.Status{Message: common.MessUnauthorized, Error: err.Error()})
+		return
+	}
+
+	ticket, err := ic.inboxUsecase.IssueStreamTicket(c, authUserID, sessionID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, response.Status{Message: common.MessInternalServerError, Error: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, response.Status{IsSuccessful: true, Message: "Stream ticket issued successfully", Data: ticket})
+}
+
+// StreamInbox pushes the caller's new inbox items as Server-Sent Events. It
+// is opened with a ticket from IssueStreamTicket in the ticket query
+// parameter. The stream opens with an "unread" event carrying the unread
+// count, sends every new item as a "notification" event and a "ping" while
+// idle. The stream ends once the session it was opened in is revoked.
+// Clients that reconnect need a new ticket and should fetch the inbox again
+// for anything sent in between.
+func (ic *inboxController) StreamInbox(c *gin.Context) {
+	authUserID, sessionID, err := ic.inboxUsecase.RedeemStreamTicket(c, c.Query("ticket"))
+	if errors.Is(err, common.ErrStreamTicketInvalid) {
+		c.JSON(http.StatusUnauthorized, response.Status{Message: common.MessUnauthorized, Error: err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, response.Status{Message: common.MessInternalServerError, Error: err.Error()})
+		return
+	}
+
+	// Subscribe before counting so no item falls between the two.
+	items, unsubscribe := ic.inboxUsecase.Subscribe(authUserID)
+	defer unsubscribe()
+
+	unread, err := ic.inboxUsecase.CountUnread(c, authUserID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, response.Status{Message: common.MessInternalServerError, Error: err.Error()})
+		return
+	}
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no")
+	c.SSEvent("unread", gin.H{"unread_count": unread})
+	c.Writer.Flush()
+
+	heartbeat := time.NewTicker(ic.heartbeat)
+	defer heartbeat.Stop()
+
+	c.Stream(func(w io.Writer) bool {
+		select {
+		case <-c.Request.Context().Done():
+			return false
+		case item, ok := <-items:
+			if !ok {
+				return false
+			}
+			c.SSEvent("notification", item)
+			return true
+		case at := <-heartbeat.C:
+			if ic.inboxUsecase.IsSessionRevoked(sessionID) {
+				return false
+			}
+			c.SSEvent("ping", gin.H{"at": at})
+			return true
+		}
+	})
+}
@@ -0,0 +1,130 @@
+package controller
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/latiiLA/coop-forex-server/internal/common"
+	"github.com/latiiLA/coop-forex-server/internal/delivery/http/response"
+	"github.com/latiiLA/coop-forex-server/internal/domain/model"
+	"github.com/latiiLA/coop-forex-server/internal/infrastructure/utils"
+	"github.com/latiiLA/coop-forex-server/internal/usecase"
+)
+
+type JobController interface {
+	GetJobs(c *gin.Context)
+	GetJobRuns(c *gin.Context)
+	TriggerJob(c *gin.Context)
+	PauseJob(c *gin.Context)
+	ResumeJob(c *gin.Context)
+}
+
+type jobController struct {
+	jobUsecase usecase.JobUsecase
+}
+
+func NewJobController(jobUsecase usecase.JobUsecase) JobController {
+	return &jobController{
+		jobUsecase: jobUsecase,
+	}
+}
+
+func (jc *jobController) GetJobs(c *gin.Context) {
+	jobs, err := jc.jobUsecase.GetJobs(c)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, response.Status{Message: common.MessInternalServerError, Error: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, response.Status{IsSuccessful: true, Message: "Jobs fetched successfully", Data: jobs})
+}
+
+func (jc *jobController) GetJobRuns(c *gin.Context) {
+	var query model.JobRunQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.JSON(http.StatusBadRequest, response.Status{Message: common.MessInvalidRequest, Error: err.Error()})
+		return
+	}
+
+	runs, err := jc.jobUsecase.GetJobRuns(c, c.Param("name"), &query)
+	if err != nil {
+		jobError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response.Status{IsSuccessful: true, Message: "Job runs fetched successfully", Data: runs})
+}
+
+func (jc *jobController) TriggerJob(c *gin.Context) {
+	authUserID, err := utils.GetUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, response.Status{Message: common.MessUnauthorized, Error: err.Error()})
+		return
+	}
+
+	run, err := jc.jobUsecase.TriggerJob(c, authUserID, c.Param("name"))
+	if err != nil {
+		jobError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusAccepted, response.Status{IsSuccessful: true, Message: "Job started", Data: run})
+}
+
+func (jc *jobController) PauseJob(c *gin.Context) {
+	authUserID, err := utils.GetUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, response.Status{Message: common.MessUnauthorized, Error: err.Error()})
+		return
+	}
+
+	if err := jc.jobUsecase.PauseJob(c, authUserID, c.Param("name")); err != nil {
+		jobError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response.Status{IsSuccessful: true, Message: "Job paused"})
+}
+
+func (jc *jobController) ResumeJob(c *gin.Context) {
+	authUserID, err := utils.GetUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, response.Status{Message: common.MessUnauthorized, Error: err.Error()})
+		return
+	}
+
+	if err := jc.jobUsecase.ResumeJob(c, authUserID, c.Param("name")); err != nil {
+		jobError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response.Status{IsSuccessful: true, Message: "Job resumed"})
+}
+
+func jobError(c *gin.Context, err error) {
+	var (
+		status  int
+		message string
+	)
+
+	switch {
+	case errors.Is(err, common.ErrJobNotFound):
+		status = http.StatusNotFound
+		message = common.MessJobNotFound
+
+	case errors.Is(err, common.ErrJobRunning):
+		status = http.StatusConflict
+		message = common.MessJobRunning
+
+	case errors.Is(err, common.ErrSchedulerNotRunning):
+		status = http.StatusServiceUnavailable
+		message = common.MessSchedulerNotRunning
+
+	default:
+		status = http.StatusInternalServerError
+		message = common.MessInternalServerError
+	}
+
+	c.JSON(status, response.Status{Message: message, Error: err.Error()})
+}
@@ -0,0 +1,29 @@
+package controller
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/latiiLA/coop-forex-server/internal/domain/model"
+)
+
+type JWKSController interface {
+	GetJWKS(c *gin.Context)
+}
+
+type jwksController struct {
+	publicKeys model.PublicKeySet
+}
+
+func NewJWKSController(publicKeys model.PublicKeySet) JWKSController {
+	return &jwksController{
+		publicKeys: publicKeys,
+	}
+}
+
+// GetJWKS serves the key set bare rather than in a response.Status, as
+// JWT libraries expect it.
+func (jc *jwksController) GetJWKS(c *gin.Context) {
+	c.Header("Cache-Control", "public, max-age=300")
+	c.JSON(http.StatusOK, jc.publicKeys.JWKS())
+}
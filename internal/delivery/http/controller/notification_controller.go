@@ -0,0 +1,161 @@
+package controller
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/latiiLA/coop-forex-server/internal/common"
+	"github.com/latiiLA/coop-forex-server/internal/delivery/http/response"
+	"github.com/latiiLA/coop-forex-server/internal/domain/model"
+	"github.com/latiiLA/coop-forex-server/internal/infrastructure/utils"
+	"github.com/latiiLA/coop-forex-server/internal/usecase"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type NotificationController interface {
+	GetRequestNotifications(c *gin.Context)
+	RetryNotification(c *gin.Context)
+	PreviewNotification(c *gin.Context)
+}
+
+type notificationController struct {
+	notificationUsecase usecase.NotificationUsecase
+}
+
+func NewNotificationController(notificationUsecase usecase.NotificationUsecase) NotificationController {
+	return &notificationController{
+		notificationUsecase: notificationUsecase,
+	}
+}
+
+func (nc *notificationController) GetRequestNotifications(c *gin.Context) {
+	requestID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.Status{Message: common.MessInvalidRequestData, Error: err.Error()})
+		return
+	}
+
+	notifications, err := nc.notificationUsecase.GetRequestNotifications(c, requestID)
+	if err != nil {
+		var (
+			status  int
+			message string
+		)
+
+		switch {
+		case errors.Is(err, common.ErrRequestNotFound):
+			status = http.StatusNotFound
+			message = common.MessRequestNotFound
+
+		default:
+			status = http.StatusInternalServerError
+			message = common.MessInternalServerError
+		}
+
+		c.JSON(status, response.Status{Message: message, Error: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, response.Status{IsSuccessful: true, Message: "Request notifications fetched successfully", Data: notifications})
+}
+
+func (nc *notificationController) RetryNotification(c *gin.Context) {
+	authUserID, err := utils.GetUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, response.Status{Message: common.MessUnauthorized, Error: err.Error()})
+		return
+	}
+
+	notificationID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.Status{Message: common.MessInvalidRequestData, Error: err.Error()})
+		return
+	}
+
+	err = nc.notificationUsecase.RetryNotification(c, authUserID, notificationID)
+	if err != nil {
+		var (
+			status  int
+			message string
+		)
+
+		switch {
+		case errors.Is(err, common.ErrNotificationNotFound):
+			status = http.StatusNotFound
+			message = common.MessNotificationMissing
+
+		case errors.Is(err, common.ErrNotificationNotDead):
+			status = http.StatusConflict
+			message = common.MessNotificationNotDead
+
+		default:
+			status = http.StatusInternalServerError
+			message = common.MessInternalServerError
+		}
+
+		c.JSON(status, response.Status{Message: message, Error: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, response.Status{IsSuccessful: true, Message: "Notification queued for delivery"})
+}
+
+// PreviewNotification renders the templates of an event for a request, or for
+// sample data without request_id. format=html or format=text returns that body
+// on its own so it can be opened in a browser.
+func (nc *notificationController) PreviewNotification(c *gin.Context) {
+	authUserID, err := utils.GetUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, response.Status{Message: common.MessUnauthorized, Error: err.Error()})
+		return
+	}
+
+	var requestID *primitive.ObjectID
+	if requestIDStr := c.Query("request_id"); requestIDStr != "" {
+		id, err := primitive.ObjectIDFromHex(requestIDStr)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, response.Status{Message: common.MessInvalidRequestData, Error: err.Error()})
+			return
+		}
+		requestID = &id
+	}
+
+	rendered, err := nc.notificationUsecase.PreviewNotification(c, authUserID, model.NotificationEvent(c.Param("event")), requestID)
+	if err != nil {
+		var (
+			status  int
+			message string
+		)
+
+		switch {
+		case errors.Is(err, common.ErrUnknownNotificationEvent):
+			status = http.StatusBadRequest
+			message = common.MessNotificationEvent
+
+		case errors.Is(err, common.ErrRequestNotFound):
+			status = http.StatusNotFound
+			message = common.MessRequestNotFound
+
+		case errors.Is(err, common.ErrUnauthorized):
+			status = http.StatusUnauthorized
+			message = common.MessUnauthorized
+
+		default:
+			status = http.StatusInternalServerError
+			message = common.MessInternalServerError
+		}
+
+		c.JSON(status, response.Status{Message: message, Error: err.Error()})
+		return
+	}
+
+	switch c.Query("format") {
+	case "html":
+		c.Data(http.StatusOK, "text/html; charset=utf-8", []byte(rendered.HTML))
+	case "text":
+		c.Data(http.StatusOK, "text/plain; charset=utf-8", []byte(rendered.Text))
+	default:
+		c.JSON(http.StatusOK, response.Status{IsSuccessful: true, Message: "Notification preview rendered successfully", Data: rendered})
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"os"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/go-playground/validator/v10"
@@ -27,13 +28,10 @@ var payload RejectRequestPayload
 
 type RequestController interface {
 	AddRequest(c *gin.Context)
-	GetAllRequests(c *gin.Context)
+	SearchRequests(c *gin.Context)
 	ValidateRequest(c *gin.Context)
 	UpdateRequest(c *gin.Context)
 	ApproveRequest(c *gin.Context)
-	GetAllOrgRequests(c *gin.Context)
-	GetAuthorizedRequests(c *gin.Context)
-	GetNewOrgRequests(c *gin.Context)
 	AuthorizeOrgRequest(c *gin.Context)
 	DeleteRequest(c *gin.Context)
 	RejectRequest(c *gin.Context)
@@ -41,18 +39,12 @@ type RequestController interface {
 	SendRequest(c *gin.Context)
 	DeclineOrgRequest(c *gin.Context)
 	LockRequest(c *gin.Context)
+	RenewRequestLock(c *gin.Context)
 	UnLockRequest(c *gin.Context)
-	GetRejectedOrgRequests(c *gin.Context)
-	GetRejectedRequests(c *gin.Context)
-	GetValidatedRequests(c *gin.Context)
-	GetApprovedRequests(c *gin.Context)
-	GetAcceptedRequests(c *gin.Context)
-	GetDeclinedRequests(c *gin.Context)
-	GetApprovedOrgRequests(c *gin.Context)
-	GetAcceptedOrgRequests(c *gin.Context)
-	GetDeclinedOrgRequests(c *gin.Context)
-	GetAuthorizedOrgRequests(c *gin.Context)
-	GetDraftedOrgRequests(c *gin.Context)
+	ForceUnlockRequest(c *gin.Context)
+	GetRequest(c *gin.Context)
+	GetRequestByCode(c *gin.Context)
+	GetRequestHistory(c *gin.Context)
 }
 
 type requestController struct {
@@ -132,6 +124,17 @@ func (rc *requestController) AddRequest(c *gin.Context) {
 		return
 	}
 
+	var customerTypeObjID *primitive.ObjectID
+	if request.CustomerTypeID != "" {
+		id, err := primitive.ObjectIDFromHex(request.CustomerTypeID)
+		if err != nil {
+			logEntry.WithField("error", err.Error()).Warn("Invalid customer type ID")
+			c.JSON(http.StatusBadRequest, response.Status{Message: common.MessInvalidRequestData, Error: "Invalid customer type ID"})
+			return
+		}
+		customerTypeObjID = &id
+	}
+
 	deposit, err := strconv.ParseFloat(request.AverageDeposit, 64)
 	if err != nil {
 		logEntry.WithField("error", err.Error()).Warn("Invalid average deposit value")
@@ -238,6 +241,7 @@ func (rc *requestController) AddRequest(c *gin.Context) {
 		TravelPurposeID:        travelPurposeObjID,
 		TravelCountryID:        travelCountryObjID,
 		RequestingAs:           request.RequestingAs,
+		CustomerTypeID:         customerTypeObjID,
 		AccountCurrencyID:      accountCurrencyObjID,
 		FcyRequestedID:         fcyRequestedObjID,
 
@@ -252,6 +256,12 @@ func (rc *requestController) AddRequest(c *gin.Context) {
 	if err != nil {
 		logEntry.WithField("error", err.Error()).Warn("Failed to add the request")
 
+		var duplicateErr *model.DuplicateRequestError
+		if errors.As(err, &duplicateErr) {
+			c.JSON(http.StatusConflict, response.Status{Message: common.MessDuplicateRequest, Error: err.Error(), Data: duplicateErr.Check})
+			return
+		}
+
 		var (
 			status  int
 			message string
@@ -262,6 +272,10 @@ func (rc *requestController) AddRequest(c *gin.Context) {
 			status = http.StatusUnauthorized
 			message = common.MessUnauthorized
 
+		case errors.Is(err, common.ErrBranchNotFound):
+			status = http.StatusBadRequest
+			message = "Your branch no longer exists, please contact an administrator"
+
 		default:
 			status = http.StatusInternalServerError
 			message = common.MessInternalServerError
@@ -276,14 +290,147 @@ func (rc *requestController) AddRequest(c *gin.Context) {
 	c.JSON(http.StatusOK, response.Status{IsSuccessful: true, Message: "Fcy Request created successfully"})
 }
 
-func (rc *requestController) GetAllRequests(c *gin.Context) {
-	requests, err := rc.requestUsecase.GetAllRequests(c)
+func (rc *requestController) SearchRequests(c *gin.Context) {
+	authUserID, err := utils.GetUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, response.Status{Message: common.MessUnauthorized, Error: err.Error()})
+		return
+	}
+
+	var query model.RequestQueryDTO
+	if err := c.ShouldBindQuery(&query); err != nil {
+		if validationErrors, ok := err.(validator.ValidationErrors); ok {
+			e := validationErrors[0]
+			message := fmt.Sprintf("%s failed on %s validation", e.Field(), e.Tag())
+
+			c.JSON(http.StatusBadRequest, response.Status{Message: message, Error: err.Error()})
+			return
+		}
+
+		c.JSON(http.StatusBadRequest, response.Status{Message: common.MessInvalidRequest, Error: err.Error()})
+		return
+	}
+
+	search, err := buildRequestSearch(&query)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.Status{Message: common.MessInvalidRequestData, Error: err.Error()})
+		return
+	}
+
+	page, err := rc.requestUsecase.SearchRequests(c, authUserID, search)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, response.Status{Message: common.MessInternalServerError, Error: err.Error()})
+		var (
+			status  int
+			message string
+		)
+
+		switch {
+		case errors.Is(err, common.ErrUnauthorized):
+			status = http.StatusUnauthorized
+			message = common.MessUnauthorized
+
+		case errors.Is(err, common.ErrInvalidRequestCursor):
+			status = http.StatusBadRequest
+			message = common.MessInvalidRequestData
+
+		default:
+			status = http.StatusInternalServerError
+			message = common.MessInternalServerError
+		}
+
+		c.JSON(status, response.Status{Message: message, Error: err.Error()})
 		return
 	}
 
-	c.JSON(http.StatusOK, response.Status{IsSuccessful: true, Message: "Requests fetched Successfully", Data: requests})
+	c.JSON(http.StatusOK, response.Status{IsSuccessful: true, Message: "Requests fetched successfully", Data: page})
+}
+
+// buildRequestSearch turns the query string of a request listing into a
+// search, applying the default page size and sort order.
+func buildRequestSearch(query *model.RequestQueryDTO) (*model.RequestSearch, error) {
+	search := &model.RequestSearch{
+		Page:     query.Page,
+		PageSize: query.PageSize,
+		Cursor:   query.Cursor,
+	}
+
+	if search.Page == 0 {
+		search.Page = 1
+	}
+	if search.PageSize == 0 {
+		search.PageSize = model.RequestDefaultPageSize
+	}
+
+	field, desc, ok := model.ParseRequestSort(query.Sort)
+	if !ok {
+		return nil, fmt.Errorf("cannot sort by %q; allowed fields are %s", field, strings.Join(model.RequestSortFields, ", "))
+	}
+	search.SortField, search.SortDesc = field, desc
+
+	statuses, ok := model.ParseRequestStatuses(query.Status)
+	if !ok {
+		return nil, errors.New("invalid request status filter")
+	}
+	search.Filter.Statuses = statuses
+
+	var err error
+	if query.From != "" {
+		if search.Filter.CreatedFrom, err = parseRequestDate(query.From, false); err != nil {
+			return nil, err
+		}
+	}
+	if query.To != "" {
+		if search.Filter.CreatedTo, err = parseRequestDate(query.To, true); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, id := range []struct {
+		value  string
+		target **primitive.ObjectID
+	}{
+		{query.BranchID, &search.Filter.BranchID},
+		{query.DepartmentID, &search.Filter.DepartmentID},
+		{query.CurrencyID, &search.Filter.CurrencyID},
+	} {
+		if id.value == "" {
+			continue
+		}
+		objID, err := primitive.ObjectIDFromHex(id.value)
+		if err != nil {
+			return nil, err
+		}
+		*id.target = &objID
+	}
+
+	if query.MinAmount != nil && query.MaxAmount != nil && *query.MinAmount > *query.MaxAmount {
+		return nil, errors.New("min_amount cannot be greater than max_amount")
+	}
+	search.Filter.MinAmount = query.MinAmount
+	search.Filter.MaxAmount = query.MaxAmount
+	search.Filter.Applicant = strings.TrimSpace(query.Applicant)
+	search.Filter.RequestCode = strings.TrimSpace(query.RequestCode)
+
+	return search, nil
+}
+
+// parseRequestDate accepts RFC 3339 timestamps and plain dates; a plain date
+// used as an upper bound covers the whole day.
+func parseRequestDate(value string, endOfDay bool) (*time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return &t, nil
+	}
+
+	t, err := time.Parse("2006-01-02", value)
+	if err != nil {
+		return nil, fmt.Errorf("invalid date %q, expected YYYY-MM-DD or RFC 3339", value)
+	}
+
+	if endOfDay {
+		t = t.Add(24*time.Hour - time.Nanosecond)
+	}
+
+	return &t, nil
 }
 
 func (rc *requestController) ValidateRequest(c *gin.Context) {
@@ -347,6 +494,18 @@ func (rc *requestController) ValidateRequest(c *gin.Context) {
 			status = http.StatusNotFound
 			message = common.MessRequestNotFound
 
+		case errors.Is(err, common.ErrRequestConflict):
+			status = http.StatusConflict
+			message = common.MessRequestConflict
+
+		case errors.Is(err, common.ErrUnauthorized):
+			status = http.StatusForbidden
+			message = common.MessForbidden
+
+		case errors.Is(err, common.ErrInvalidStatusTransition):
+			status = http.StatusConflict
+			message = common.MessInvalidTransition
+
 		case errors.Is(err, common.ErrRequestIsLocked):
 			status = http.StatusConflict
 			message = common.MessRequestLocked
@@ -462,6 +621,17 @@ func (rc *requestController) UpdateRequest(c *gin.Context) {
 		return
 	}
 
+	var customerTypeObjID *primitive.ObjectID
+	if request.CustomerTypeID != "" {
+		id, err := primitive.ObjectIDFromHex(request.CustomerTypeID)
+		if err != nil {
+			logEntry.WithField("error", err.Error()).Warn("Invalid customer type ID")
+			c.JSON(http.StatusBadRequest, response.Status{Message: common.MessInvalidRequestData, Error: "Invalid customer type ID"})
+			return
+		}
+		customerTypeObjID = &id
+	}
+
 	deposit, err := strconv.ParseFloat(request.AverageDeposit, 64)
 	if err != nil {
 		logEntry.WithField("error", err.Error()).Warn("Invalid average deposit value")
@@ -568,6 +738,7 @@ func (rc *requestController) UpdateRequest(c *gin.Context) {
 		TravelPurposeID:        travelPurposeObjID,
 		TravelCountryID:        travelCountryObjID,
 		RequestingAs:           request.RequestingAs,
+		CustomerTypeID:         customerTypeObjID,
 		AccountCurrencyID:      accountCurrencyObjID,
 		FcyRequestedID:         fcyRequestedObjID,
 	}
@@ -606,6 +777,18 @@ func (rc *requestController) UpdateRequest(c *gin.Context) {
 			status = http.StatusNotFound
 			message = common.MessRequestNotFound
 
+		case errors.Is(err, common.ErrRequestConflict):
+			status = http.StatusConflict
+			message = common.MessRequestConflict
+
+		case errors.Is(err, common.ErrInvalidStatusTransition):
+			status = http.StatusConflict
+			message = common.MessInvalidTransition
+
+		case errors.Is(err, common.ErrRequestNotEditable):
+			status = http.StatusConflict
+			message = common.MessRequestNotEditable
+
 		case errors.Is(err, common.ErrRequestIsLocked):
 			status = http.StatusConflict
 			message = common.MessRequestLocked
@@ -743,6 +926,12 @@ func (rc *requestController) ApproveRequest(c *gin.Context) {
 
 	err = rc.requestUsecase.ApproveRequest(c, userID, requestObjID, &request)
 	if err != nil {
+		var limitErr *model.AllocationLimitError
+		if errors.As(err, &limitErr) {
+			c.JSON(http.StatusUnprocessableEntity, response.Status{Message: common.MessAllocationExceeded, Error: err.Error(), Data: limitErr.Violations})
+			return
+		}
+
 		var (
 			status  int
 			message string
@@ -753,10 +942,38 @@ func (rc *requestController) ApproveRequest(c *gin.Context) {
 			status = http.StatusNotFound
 			message = common.MessRequestNotFound
 
+		case errors.Is(err, common.ErrRequestConflict):
+			status = http.StatusConflict
+			message = common.MessRequestConflict
+
+		case errors.Is(err, common.ErrUnauthorized):
+			status = http.StatusForbidden
+			message = common.MessForbidden
+
+		case errors.Is(err, common.ErrInvalidStatusTransition):
+			status = http.StatusConflict
+			message = common.MessInvalidTransition
+
 		case errors.Is(err, common.ErrRequestIsLocked):
 			status = http.StatusConflict
 			message = common.MessRequestLocked
 
+		case errors.Is(err, common.ErrAllocationLinesMismatch):
+			status = http.StatusBadRequest
+			message = common.MessAllocationLines
+
+		case errors.Is(err, common.ErrExchangeRateNotFound):
+			status = http.StatusUnprocessableEntity
+			message = common.MessExchangeRateMissing
+
+		case errors.Is(err, common.ErrLimitOverrideNotAllowed):
+			status = http.StatusForbidden
+			message = "You are not allowed to override allocation limits"
+
+		case errors.Is(err, common.ErrOverrideJustificationRequired):
+			status = http.StatusBadRequest
+			message = "A justification is required to override allocation limits"
+
 		default:
 			status = http.StatusInternalServerError
 			message = common.MessInternalServerError
@@ -769,431 +986,168 @@ func (rc *requestController) ApproveRequest(c *gin.Context) {
 	c.JSON(http.StatusOK, response.Status{IsSuccessful: true, Message: "Request approved successfully"})
 }
 
-func (rc *requestController) GetAllOrgRequests(c *gin.Context) {
-	departmentID, err := utils.GetDepartmentID(c)
+func (rc *requestController) AuthorizeOrgRequest(c *gin.Context) {
+	authUserID, err := utils.GetUserID(c)
 	if err != nil {
 		c.JSON(http.StatusUnauthorized, response.Status{Message: common.MessUnauthorized, Error: err.Error()})
 		return
 	}
 
-	branchID, err := utils.GetBranchID(c)
-	if err != nil {
-		c.JSON(http.StatusUnauthorized, response.Status{Message: common.MessUnauthorized, Error: err.Error()})
+	// get user id from param
+	requestID := c.Param("id")
+	if requestID == "" {
+		c.JSON(http.StatusBadRequest, response.Status{Message: common.MessUnauthorized, Error: "request ID is required"})
 		return
 	}
 
-	var orgRequests interface{}
-
-	if !branchID.IsZero() {
-		orgRequests, err = rc.requestUsecase.GetAllOrgRequests(c, "branch_id", branchID)
-	} else if !departmentID.IsZero() {
-		orgRequests, err = rc.requestUsecase.GetAllOrgRequests(c, "department_id", departmentID)
-	} else {
-		c.JSON(http.StatusBadRequest, response.Status{Message: common.MessInvalidRequest, Error: "Invalid orgKey. Must be 'branchID' or 'departmentID'"})
+	requestObjID, err := primitive.ObjectIDFromHex(requestID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.Status{Message: common.MessInvalidRequestData, Error: err.Error()})
 		return
 	}
 
+	err = rc.requestUsecase.AuthorizeOrgRequest(c, authUserID, requestObjID)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, response.Status{Message: common.MessInternalServerError, Error: err.Error()})
-		return
-	}
+		var (
+			status  int
+			message string
+		)
 
-	c.JSON(http.StatusOK, response.Status{IsSuccessful: true, Message: "All Org requests fetched successfully", Data: orgRequests})
+		switch {
+		case errors.Is(err, common.ErrRequestNotFound):
+			status = http.StatusNotFound
+			message = common.MessRequestNotFound
 
-}
+		case errors.Is(err, common.ErrRequestIsLocked):
+			status = http.StatusConflict
+			message = common.MessRequestLocked
 
-func (rc *requestController) GetAuthorizedRequests(c *gin.Context) {
-	_, err := utils.GetDepartmentID(c)
-	if err != nil {
-		c.JSON(http.StatusUnauthorized, response.Status{Message: common.MessUnauthorized, Error: err.Error()})
-		return
-	}
+		case errors.Is(err, common.ErrRequestConflict):
+			status = http.StatusConflict
+			message = common.MessRequestConflict
 
-	_, err = utils.GetBranchID(c)
-	if err != nil {
-		c.JSON(http.StatusUnauthorized, response.Status{Message: common.MessUnauthorized, Error: err.Error()})
-		return
-	}
+		case errors.Is(err, common.ErrUnauthorized):
+			status = http.StatusForbidden
+			message = common.MessForbidden
 
-	requests, err := rc.requestUsecase.GetAuthorizedRequests(c)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, response.Status{Message: common.MessInternalServerError, Error: err.Error()})
-		return
-	}
+		case errors.Is(err, common.ErrInvalidStatusTransition):
+			status = http.StatusConflict
+			message = common.MessInvalidTransition
 
-	c.JSON(http.StatusOK, response.Status{IsSuccessful: true, Message: "Authorized requests fetched successfully", Data: requests})
-}
+		default:
+			status = http.StatusInternalServerError
+			message = common.MessInternalServerError
+		}
 
-func (rc *requestController) GetNewOrgRequests(c *gin.Context) {
-	departmentID, err := utils.GetDepartmentID(c)
-	if err != nil {
-		c.JSON(http.StatusUnauthorized, response.Status{Message: common.MessUnauthorized, Error: err.Error()})
+		c.JSON(status, response.Status{Message: message, Error: err.Error()})
 		return
 	}
 
-	branchID, err := utils.GetBranchID(c)
+	c.JSON(http.StatusOK, response.Status{IsSuccessful: true, Message: "Request authorized successfully"})
+}
+
+func (rc *requestController) RejectRequest(c *gin.Context) {
+	authUserID, err := utils.GetUserID(c)
 	if err != nil {
 		c.JSON(http.StatusUnauthorized, response.Status{Message: common.MessUnauthorized, Error: err.Error()})
 		return
 	}
 
-	var (
-		newOrgRequests interface{}
-		orgKey         string
-		orgID          primitive.ObjectID
-	)
-
-	if !branchID.IsZero() {
-		orgKey = "branch_id"
-		orgID = branchID
-	} else if !departmentID.IsZero() {
-		orgKey = "department_id"
-		orgID = departmentID
-	} else {
-		c.JSON(http.StatusBadRequest, response.Status{Message: common.MessInvalidRequest, Error: "Invalid organization context"})
+	// get user id from param
+	requestIDStr := c.Param("id")
+	if requestIDStr == "" {
+		c.JSON(http.StatusBadRequest, response.Status{Message: common.MessUnauthorized, Error: "request ID is required"})
 		return
 	}
 
-	newOrgRequests, err = rc.requestUsecase.GetNewOrgRequests(c, orgKey, orgID)
+	requestID, err := primitive.ObjectIDFromHex(requestIDStr)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, response.Status{Message: common.MessInternalServerError, Error: err.Error()})
+		c.JSON(http.StatusBadRequest, response.Status{Message: common.MessInvalidRequestData, Error: err.Error()})
 		return
 	}
 
-	c.JSON(http.StatusOK, response.Status{IsSuccessful: true, Message: "New Org requests fetched successfully", Data: newOrgRequests})
-}
-
-func (rc *requestController) GetRejectedOrgRequests(c *gin.Context) {
-	departmentID, err := utils.GetDepartmentID(c)
+	err = c.ShouldBind(&payload)
 	if err != nil {
-		c.JSON(http.StatusUnauthorized, response.Status{Message: common.MessUnauthorized, Error: err.Error()})
-		return
-	}
+		if validationErrors, ok := err.(validator.ValidationErrors); ok {
+			e := validationErrors[0]
+			message := fmt.Sprintf("%s failed on %s validation", e.Field(), e.Tag())
 
-	branchID, err := utils.GetBranchID(c)
-	if err != nil {
-		c.JSON(http.StatusUnauthorized, response.Status{Message: common.MessUnauthorized, Error: err.Error()})
-		return
-	}
+			c.JSON(http.StatusBadRequest, response.Status{
+				Message: message,
+				Error:   err.Error(),
+			})
 
-	var (
-		newOrgRequests interface{}
-		orgKey         string
-		orgID          primitive.ObjectID
-	)
+			return
+		}
 
-	if !branchID.IsZero() {
-		orgKey = "branch_id"
-		orgID = branchID
-	} else if !departmentID.IsZero() {
-		orgKey = "department_id"
-		orgID = departmentID
-	} else {
-		c.JSON(http.StatusBadRequest, response.Status{Message: common.MessInvalidRequest, Error: "Invalid organization context"})
+		c.JSON(http.StatusBadRequest, response.Status{
+			Message: common.MessInvalidRequest,
+			Error:   err.Error(),
+		})
 		return
 	}
 
-	newOrgRequests, err = rc.requestUsecase.GetRejectedOrgRequests(c, orgKey, orgID)
+	err = rc.requestUsecase.RejectRequest(c, authUserID, requestID, payload.RejectionReason)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, response.Status{Message: common.MessInternalServerError, Error: err.Error()})
-		return
-	}
-
-	c.JSON(http.StatusOK, response.Status{IsSuccessful: true, Message: "Rejected requests fetched successfully", Data: newOrgRequests})
-}
+		var (
+			status  int
+			message string
+		)
 
-func (rc *requestController) GetRejectedRequests(c *gin.Context) {
-	rejectedRequests, err := rc.requestUsecase.GetRejectedRequests(c)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, response.Status{Message: common.MessInternalServerError, Error: err.Error()})
-		return
-	}
+		switch {
+		case errors.Is(err, common.ErrUnauthorized):
+			status = http.StatusForbidden
+			message = common.MessForbidden
 
-	c.JSON(http.StatusOK, response.Status{IsSuccessful: true, Message: "Rejected Org requests fetched successfully", Data: rejectedRequests})
-}
+		case errors.Is(err, common.ErrRequestNotFound):
+			status = http.StatusNotFound
+			message = common.MessRequestNotFound
 
-func (rc *requestController) GetApprovedOrgRequests(c *gin.Context) {
-	departmentID, err := utils.GetDepartmentID(c)
-	if err != nil {
-		c.JSON(http.StatusUnauthorized, response.Status{Message: common.MessUnauthorized, Error: err.Error()})
-		return
-	}
+		case errors.Is(err, common.ErrRequestConflict):
+			status = http.StatusConflict
+			message = common.MessRequestConflict
 
-	branchID, err := utils.GetBranchID(c)
-	if err != nil {
-		c.JSON(http.StatusUnauthorized, response.Status{Message: common.MessUnauthorized, Error: err.Error()})
-		return
-	}
+		case errors.Is(err, common.ErrInvalidStatusTransition):
+			status = http.StatusConflict
+			message = common.MessInvalidTransition
 
-	var (
-		approvedOrgRequests interface{}
-		orgKey              string
-		orgID               primitive.ObjectID
-	)
+		case errors.Is(err, common.ErrRequestIsLocked):
+			status = http.StatusConflict
+			message = common.MessRequestLocked
 
-	if !branchID.IsZero() {
-		orgKey = "branch_id"
-		orgID = branchID
-	} else if !departmentID.IsZero() {
-		orgKey = "department_id"
-		orgID = departmentID
-	} else {
-		c.JSON(http.StatusBadRequest, response.Status{Message: common.MessInvalidRequest, Error: "Invalid organization context"})
-		return
-	}
+		default:
+			status = http.StatusInternalServerError
+			message = common.MessInternalServerError
+		}
 
-	approvedOrgRequests, err = rc.requestUsecase.GetApprovedOrgRequests(c, orgKey, orgID)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, response.Status{Message: common.MessInternalServerError, Error: err.Error()})
+		c.JSON(status, response.Status{Message: message, Error: err.Error()})
 		return
 	}
 
-	c.JSON(http.StatusOK, response.Status{IsSuccessful: true, Message: "Approved Org requests fetched successfully", Data: approvedOrgRequests})
+	c.JSON(http.StatusOK, response.Status{IsSuccessful: true, Message: "Request rejected successfully"})
 }
 
-func (rc *requestController) GetAcceptedOrgRequests(c *gin.Context) {
-	departmentID, err := utils.GetDepartmentID(c)
+func (rc *requestController) DeleteRequest(c *gin.Context) {
+	authUserID, err := utils.GetUserID(c)
 	if err != nil {
 		c.JSON(http.StatusUnauthorized, response.Status{Message: common.MessUnauthorized, Error: err.Error()})
 		return
 	}
 
-	branchID, err := utils.GetBranchID(c)
-	if err != nil {
-		c.JSON(http.StatusUnauthorized, response.Status{Message: common.MessUnauthorized, Error: err.Error()})
+	// get user id from param
+	requestIDStr := c.Param("id")
+	if requestIDStr == "" {
+		c.JSON(http.StatusBadRequest, response.Status{Message: common.MessInvalidRequest, Error: "request ID is required"})
 		return
 	}
 
-	var (
-		orgRequests interface{}
-		orgKey      string
-		orgID       primitive.ObjectID
-	)
-
-	if !branchID.IsZero() {
-		orgKey = "branch_id"
-		orgID = branchID
-	} else if !departmentID.IsZero() {
-		orgKey = "department_id"
-		orgID = departmentID
-	} else {
-		c.JSON(http.StatusBadRequest, response.Status{Message: common.MessInvalidRequest, Error: "Invalid organization context"})
+	requestID, err := primitive.ObjectIDFromHex(requestIDStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.Status{Message: common.MessInvalidRequestData, Error: err.Error()})
 		return
 	}
 
-	orgRequests, err = rc.requestUsecase.GetAcceptedOrgRequests(c, orgKey, orgID)
-	if err != nil {
-
-		c.JSON(http.StatusInternalServerError, response.Status{Message: common.MessInternalServerError, Error: err.Error()})
-		return
-	}
-
-	c.JSON(http.StatusOK, response.Status{IsSuccessful: true, Message: "Accepted Org requests fetched successfully", Data: orgRequests})
-}
-
-func (rc *requestController) GetDeclinedOrgRequests(c *gin.Context) {
-	departmentID, err := utils.GetDepartmentID(c)
-	if err != nil {
-		c.JSON(http.StatusUnauthorized, response.Status{Message: common.MessUnauthorized, Error: err.Error()})
-		return
-	}
-
-	branchID, err := utils.GetBranchID(c)
-	if err != nil {
-		c.JSON(http.StatusUnauthorized, response.Status{Message: common.MessUnauthorized, Error: err.Error()})
-		return
-	}
-
-	var (
-		orgRequests interface{}
-		orgKey      string
-		orgID       primitive.ObjectID
-	)
-
-	if !branchID.IsZero() {
-		orgKey = "branch_id"
-		orgID = branchID
-	} else if !departmentID.IsZero() {
-		orgKey = "department_id"
-		orgID = departmentID
-	} else {
-		c.JSON(http.StatusBadRequest, response.Status{Message: common.MessInvalidRequest, Error: "Invalid organization context"})
-		return
-	}
-
-	orgRequests, err = rc.requestUsecase.GetDeclinedOrgRequests(c, orgKey, orgID)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, response.Status{Message: common.MessInternalServerError, Error: err.Error()})
-		return
-	}
-
-	c.JSON(http.StatusOK, response.Status{IsSuccessful: true, Message: "Declined Org requests fetched successfully", Data: orgRequests})
-}
-
-func (rc *requestController) GetAuthorizedOrgRequests(c *gin.Context) {
-	departmentID, err := utils.GetDepartmentID(c)
-	if err != nil {
-		c.JSON(http.StatusUnauthorized, response.Status{Message: common.MessUnauthorized, Error: err.Error()})
-		return
-	}
-
-	branchID, err := utils.GetBranchID(c)
-	if err != nil {
-		c.JSON(http.StatusUnauthorized, response.Status{Message: common.MessUnauthorized, Error: err.Error()})
-		return
-	}
-
-	var (
-		orgRequests interface{}
-		orgKey      string
-		orgID       primitive.ObjectID
-	)
-
-	if !branchID.IsZero() {
-		orgKey = "branch_id"
-		orgID = branchID
-	} else if !departmentID.IsZero() {
-		orgKey = "department_id"
-		orgID = departmentID
-	} else {
-		c.JSON(http.StatusBadRequest, response.Status{Message: common.MessInvalidRequest, Error: "Invalid organization context"})
-		return
-	}
-
-	orgRequests, err = rc.requestUsecase.GetAuthorizedOrgRequests(c, orgKey, orgID)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, response.Status{Message: common.MessInternalServerError, Error: err.Error()})
-		return
-	}
-
-	c.JSON(http.StatusOK, response.Status{IsSuccessful: true, Message: "Authorized Org requests", Data: orgRequests})
-}
-
-func (rc *requestController) GetDraftedOrgRequests(c *gin.Context) {
-	departmentID, err := utils.GetDepartmentID(c)
-	if err != nil {
-		c.JSON(http.StatusUnauthorized, response.Status{Message: common.MessUnauthorized, Error: err.Error()})
-		return
-	}
-
-	branchID, err := utils.GetBranchID(c)
-	if err != nil {
-		c.JSON(http.StatusUnauthorized, response.Status{Message: common.MessUnauthorized, Error: err.Error()})
-		return
-	}
-
-	var (
-		orgRequests interface{}
-		orgKey      string
-		orgID       primitive.ObjectID
-	)
-
-	if !branchID.IsZero() {
-		orgKey = "branch_id"
-		orgID = branchID
-	} else if !departmentID.IsZero() {
-		orgKey = "department_id"
-		orgID = departmentID
-	} else {
-		c.JSON(http.StatusBadRequest, response.Status{Message: common.MessInvalidRequest, Error: "Invalid organization context"})
-		return
-	}
-
-	orgRequests, err = rc.requestUsecase.GetDraftedOrgRequests(c, orgKey, orgID)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, response.Status{Message: common.MessInternalServerError, Error: err.Error()})
-		return
-	}
-
-	c.JSON(http.StatusOK, response.Status{IsSuccessful: true, Message: "Drafted requests fetched successfully", Data: orgRequests})
-}
-
-func (rc *requestController) AuthorizeOrgRequest(c *gin.Context) {
-	authUserID, err := utils.GetUserID(c)
-	if err != nil {
-		c.JSON(http.StatusUnauthorized, response.Status{Message: common.MessUnauthorized, Error: err.Error()})
-		return
-	}
-
-	// get user id from param
-	requestID := c.Param("id")
-	if requestID == "" {
-		c.JSON(http.StatusBadRequest, response.Status{Message: common.MessUnauthorized, Error: "request ID is required"})
-		return
-	}
-
-	requestObjID, err := primitive.ObjectIDFromHex(requestID)
-	if err != nil {
-		c.JSON(http.StatusBadRequest, response.Status{Message: common.MessInvalidRequestData, Error: err.Error()})
-		return
-	}
-
-	err = rc.requestUsecase.AuthorizeOrgRequest(c, authUserID, requestObjID)
-	if err != nil {
-		var (
-			status  int
-			message string
-		)
-
-		switch {
-		case errors.Is(err, common.ErrRequestNotFound):
-			status = http.StatusNotFound
-			message = common.MessRequestNotFound
-
-		default:
-			status = http.StatusInternalServerError
-			message = common.MessInternalServerError
-		}
-
-		c.JSON(status, response.Status{Message: message, Error: err.Error()})
-		return
-	}
-
-	c.JSON(http.StatusOK, response.Status{IsSuccessful: true, Message: "Request authorized successfully"})
-}
-
-func (rc *requestController) RejectRequest(c *gin.Context) {
-	authUserID, err := utils.GetUserID(c)
-	if err != nil {
-		c.JSON(http.StatusUnauthorized, response.Status{Message: common.MessUnauthorized, Error: err.Error()})
-		return
-	}
-
-	// get user id from param
-	requestIDStr := c.Param("id")
-	if requestIDStr == "" {
-		c.JSON(http.StatusBadRequest, response.Status{Message: common.MessUnauthorized, Error: "request ID is required"})
-		return
-	}
-
-	requestID, err := primitive.ObjectIDFromHex(requestIDStr)
-	if err != nil {
-		c.JSON(http.StatusBadRequest, response.Status{Message: common.MessInvalidRequestData, Error: err.Error()})
-		return
-	}
-
-	err = c.ShouldBind(&payload)
-	if err != nil {
-		if validationErrors, ok := err.(validator.ValidationErrors); ok {
-			e := validationErrors[0]
-			message := fmt.Sprintf("%s failed on %s validation", e.Field(), e.Tag())
-
-			c.JSON(http.StatusBadRequest, response.Status{
-				Message: message,
-				Error:   err.Error(),
-			})
-
-			return
-		}
-
-		c.JSON(http.StatusBadRequest, response.Status{
-			Message: common.MessInvalidRequest,
-			Error:   err.Error(),
-		})
-		return
-	}
-
-	err = rc.requestUsecase.RejectRequest(c, authUserID, requestID, payload.RejectionReason)
+	err = rc.requestUsecase.DeleteRequest(c, authUserID, requestID)
 	if err != nil {
 		var (
 			status  int
@@ -1201,10 +1155,6 @@ func (rc *requestController) RejectRequest(c *gin.Context) {
 		)
 
 		switch {
-		case errors.Is(err, common.ErrUnauthorized):
-			status = http.StatusUnauthorized
-			message = common.MessUnauthorized
-
 		case errors.Is(err, common.ErrRequestNotFound):
 			status = http.StatusNotFound
 			message = common.MessRequestNotFound
@@ -1213,49 +1163,17 @@ func (rc *requestController) RejectRequest(c *gin.Context) {
 			status = http.StatusConflict
 			message = common.MessRequestLocked
 
-		default:
-			status = http.StatusInternalServerError
-			message = common.MessInternalServerError
-		}
-
-		c.JSON(status, response.Status{Message: message, Error: err.Error()})
-		return
-	}
-
-	c.JSON(http.StatusOK, response.Status{IsSuccessful: true, Message: "Request rejected successfully"})
-}
-
-func (rc *requestController) DeleteRequest(c *gin.Context) {
-	authUserID, err := utils.GetUserID(c)
-	if err != nil {
-		c.JSON(http.StatusUnauthorized, response.Status{Message: common.MessUnauthorized, Error: err.Error()})
-		return
-	}
-
-	// get user id from param
-	requestIDStr := c.Param("id")
-	if requestIDStr == "" {
-		c.JSON(http.StatusBadRequest, response.Status{Message: common.MessInvalidRequest, Error: "request ID is required"})
-		return
-	}
-
-	requestID, err := primitive.ObjectIDFromHex(requestIDStr)
-	if err != nil {
-		c.JSON(http.StatusBadRequest, response.Status{Message: common.MessInvalidRequestData, Error: err.Error()})
-		return
-	}
+		case errors.Is(err, common.ErrRequestConflict):
+			status = http.StatusConflict
+			message = common.MessRequestConflict
 
-	err = rc.requestUsecase.DeleteRequest(c, authUserID, requestID)
-	if err != nil {
-		var (
-			status  int
-			message string
-		)
+		case errors.Is(err, common.ErrUnauthorized):
+			status = http.StatusForbidden
+			message = common.MessForbidden
 
-		switch {
-		case errors.Is(err, common.ErrRequestNotFound):
-			status = http.StatusNotFound
-			message = common.MessRequestNotFound
+		case errors.Is(err, common.ErrInvalidStatusTransition):
+			status = http.StatusConflict
+			message = common.MessInvalidTransition
 
 		case errors.Is(err, common.ErrRequestCannotBeDeleted):
 			status = http.StatusForbidden
@@ -1385,6 +1303,12 @@ func (rc *requestController) AcceptRequest(c *gin.Context) {
 
 	err = rc.requestUsecase.AcceptRequest(c, authUserID, requestID, &request)
 	if err != nil {
+		var limitErr *model.AllocationLimitError
+		if errors.As(err, &limitErr) {
+			c.JSON(http.StatusUnprocessableEntity, response.Status{Message: common.MessAllocationExceeded, Error: err.Error(), Data: limitErr.Violations})
+			return
+		}
+
 		var (
 			status  int
 			message string
@@ -1395,6 +1319,22 @@ func (rc *requestController) AcceptRequest(c *gin.Context) {
 			status = http.StatusNotFound
 			message = common.MessRequestNotFound
 
+		case errors.Is(err, common.ErrRequestIsLocked):
+			status = http.StatusConflict
+			message = common.MessRequestLocked
+
+		case errors.Is(err, common.ErrRequestConflict):
+			status = http.StatusConflict
+			message = common.MessRequestConflict
+
+		case errors.Is(err, common.ErrUnauthorized):
+			status = http.StatusForbidden
+			message = common.MessForbidden
+
+		case errors.Is(err, common.ErrInvalidStatusTransition):
+			status = http.StatusConflict
+			message = common.MessInvalidTransition
+
 		case errors.Is(err, common.ErrInvalidAcceptedCurrency):
 			status = http.StatusBadRequest
 			message = "Accepted currency must be the same as approved currency"
@@ -1403,6 +1343,22 @@ func (rc *requestController) AcceptRequest(c *gin.Context) {
 			status = http.StatusBadRequest
 			message = "Accepted amount cannot be greater than approved amount"
 
+		case errors.Is(err, common.ErrAllocationLinesMismatch):
+			status = http.StatusBadRequest
+			message = common.MessAllocationLines
+
+		case errors.Is(err, common.ErrExchangeRateNotFound):
+			status = http.StatusUnprocessableEntity
+			message = common.MessExchangeRateMissing
+
+		case errors.Is(err, common.ErrLimitOverrideNotAllowed):
+			status = http.StatusForbidden
+			message = "You are not allowed to override allocation limits"
+
+		case errors.Is(err, common.ErrOverrideJustificationRequired):
+			status = http.StatusBadRequest
+			message = "A justification is required to override allocation limits"
+
 		default:
 			status = http.StatusInternalServerError
 			message = common.MessInternalServerError
@@ -1437,6 +1393,12 @@ func (rc *requestController) SendRequest(c *gin.Context) {
 
 	err = rc.requestUsecase.SendRequest(c, authUserID, requestID)
 	if err != nil {
+		var duplicateErr *model.DuplicateRequestError
+		if errors.As(err, &duplicateErr) {
+			c.JSON(http.StatusConflict, response.Status{Message: common.MessDuplicateRequest, Error: err.Error(), Data: duplicateErr.Check})
+			return
+		}
+
 		var (
 			status  int
 			message string
@@ -1447,6 +1409,22 @@ func (rc *requestController) SendRequest(c *gin.Context) {
 			status = http.StatusNotFound
 			message = common.MessRequestNotFound
 
+		case errors.Is(err, common.ErrRequestIsLocked):
+			status = http.StatusConflict
+			message = common.MessRequestLocked
+
+		case errors.Is(err, common.ErrRequestConflict):
+			status = http.StatusConflict
+			message = common.MessRequestConflict
+
+		case errors.Is(err, common.ErrUnauthorized):
+			status = http.StatusForbidden
+			message = common.MessForbidden
+
+		case errors.Is(err, common.ErrInvalidStatusTransition):
+			status = http.StatusConflict
+			message = common.MessInvalidTransition
+
 		default:
 			status = http.StatusInternalServerError
 			message = common.MessInternalServerError
@@ -1491,6 +1469,22 @@ func (rc *requestController) DeclineOrgRequest(c *gin.Context) {
 			status = http.StatusNotFound
 			message = common.MessRequestNotFound
 
+		case errors.Is(err, common.ErrRequestIsLocked):
+			status = http.StatusConflict
+			message = common.MessRequestLocked
+
+		case errors.Is(err, common.ErrRequestConflict):
+			status = http.StatusConflict
+			message = common.MessRequestConflict
+
+		case errors.Is(err, common.ErrUnauthorized):
+			status = http.StatusForbidden
+			message = common.MessForbidden
+
+		case errors.Is(err, common.ErrInvalidStatusTransition):
+			status = http.StatusConflict
+			message = common.MessInvalidTransition
+
 		default:
 			status = http.StatusInternalServerError
 			message = common.MessInternalServerError
@@ -1503,47 +1497,59 @@ func (rc *requestController) DeclineOrgRequest(c *gin.Context) {
 	c.JSON(http.StatusOK, response.Status{IsSuccessful: true, Message: "Request declined successfully"})
 }
 
-func (rc *requestController) GetValidatedRequests(c *gin.Context) {
-	requests, err := rc.requestUsecase.GetValidatedRequests(c)
+func (rc *requestController) LockRequest(c *gin.Context) {
+	authUserID, err := utils.GetUserID(c)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, response.Status{Message: common.MessInternalServerError, Error: err.Error()})
+		c.JSON(http.StatusUnauthorized, response.Status{Message: common.MessUnauthorized, Error: err.Error()})
 		return
 	}
 
-	c.JSON(http.StatusOK, response.Status{IsSuccessful: true, Message: "Validated requests fetched successfully", Data: requests})
-}
-
-func (rc *requestController) GetApprovedRequests(c *gin.Context) {
-	requests, err := rc.requestUsecase.GetApprovedRequests(c)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, response.Status{Message: common.MessInternalServerError, Error: err.Error()})
+	// get request id from param
+	requestIDStr := c.Param("id")
+	if requestIDStr == "" {
+		c.JSON(http.StatusBadRequest, response.Status{Message: common.MessInvalidRequest, Error: "request ID is required"})
 		return
 	}
 
-	c.JSON(http.StatusOK, response.Status{IsSuccessful: true, Message: "Approved requests fetched successfully", Data: requests})
-}
-
-func (rc *requestController) GetAcceptedRequests(c *gin.Context) {
-	requests, err := rc.requestUsecase.GetAcceptedRequests(c)
+	requestID, err := primitive.ObjectIDFromHex(requestIDStr)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, response.Status{Message: common.MessInternalServerError, Error: err.Error()})
+		c.JSON(http.StatusBadRequest, response.Status{Message: common.MessInvalidRequestData, Error: err.Error()})
 		return
 	}
 
-	c.JSON(http.StatusOK, response.Status{IsSuccessful: true, Message: "Accepted requests fetched successfully", Data: requests})
-}
-
-func (rc *requestController) GetDeclinedRequests(c *gin.Context) {
-	requests, err := rc.requestUsecase.GetDeclinedRequests(c)
+	expiresAt, err := rc.requestUsecase.LockRequest(c, authUserID, requestID)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, response.Status{Error: err.Error()})
+		var (
+			status  int
+			message string
+		)
+
+		switch {
+		case errors.Is(err, common.ErrRequestNotFound):
+			status = http.StatusNotFound
+			message = common.MessRequestNotFound
+
+		case errors.Is(err, common.ErrRequestConflict):
+			status = http.StatusConflict
+			message = common.MessRequestConflict
+
+		case errors.Is(err, common.ErrRequestIsLocked):
+			status = http.StatusConflict
+			message = common.MessRequestLocked
+
+		default:
+			status = http.StatusInternalServerError
+			message = common.MessInternalServerError
+		}
+
+		c.JSON(status, response.Status{Message: message, Error: err.Error()})
 		return
 	}
 
-	c.JSON(http.StatusOK, response.Status{IsSuccessful: true, Message: "Declined requests fetched successfully", Data: requests})
+	c.JSON(http.StatusOK, response.Status{IsSuccessful: true, Message: "Request locked successfully", Data: model.RequestLockResponseDTO{LockExpiresAt: *expiresAt}})
 }
 
-func (rc *requestController) LockRequest(c *gin.Context) {
+func (rc *requestController) RenewRequestLock(c *gin.Context) {
 	authUserID, err := utils.GetUserID(c)
 	if err != nil {
 		c.JSON(http.StatusUnauthorized, response.Status{Message: common.MessUnauthorized, Error: err.Error()})
@@ -1563,7 +1569,7 @@ func (rc *requestController) LockRequest(c *gin.Context) {
 		return
 	}
 
-	err = rc.requestUsecase.LockRequest(c, authUserID, requestID)
+	expiresAt, err := rc.requestUsecase.RenewRequestLock(c, authUserID, requestID)
 	if err != nil {
 		var (
 			status  int
@@ -1579,6 +1585,10 @@ func (rc *requestController) LockRequest(c *gin.Context) {
 			status = http.StatusConflict
 			message = common.MessRequestLocked
 
+		case errors.Is(err, common.ErrRequestLockNotHeld):
+			status = http.StatusConflict
+			message = common.MessRequestLockNotHeld
+
 		default:
 			status = http.StatusInternalServerError
 			message = common.MessInternalServerError
@@ -1588,7 +1598,7 @@ func (rc *requestController) LockRequest(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusOK, response.Status{IsSuccessful: true, Message: "Request locked successfully"})
+	c.JSON(http.StatusOK, response.Status{IsSuccessful: true, Message: "Request lock renewed successfully", Data: model.RequestLockResponseDTO{LockExpiresAt: *expiresAt}})
 }
 
 func (rc *requestController) UnLockRequest(c *gin.Context) {
@@ -1624,6 +1634,10 @@ func (rc *requestController) UnLockRequest(c *gin.Context) {
 			status = http.StatusNotFound
 			message = common.MessRequestNotFound
 
+		case errors.Is(err, common.ErrRequestConflict):
+			status = http.StatusConflict
+			message = common.MessRequestConflict
+
 		case errors.Is(err, common.ErrRequestIsLocked):
 			status = http.StatusConflict
 			message = common.MessRequestLocked
@@ -1639,3 +1653,150 @@ func (rc *requestController) UnLockRequest(c *gin.Context) {
 
 	c.JSON(http.StatusOK, response.Status{IsSuccessful: true, Message: "Request unlock successful"})
 }
+
+func (rc *requestController) ForceUnlockRequest(c *gin.Context) {
+	authUserID, err := utils.GetUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, response.Status{Message: common.MessUnauthorized, Error: err.Error()})
+		return
+	}
+
+	// get request id from param
+	requestIDStr := c.Param("id")
+	if requestIDStr == "" {
+		c.JSON(http.StatusBadRequest, response.Status{Message: common.MessInvalidRequest, Error: "request ID is required"})
+		return
+	}
+
+	requestID, err := primitive.ObjectIDFromHex(requestIDStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.Status{Message: common.MessInvalidRequestData, Error: err.Error()})
+		return
+	}
+
+	err = rc.requestUsecase.ForceUnlockRequest(c, authUserID, requestID)
+	if err != nil {
+		var (
+			status  int
+			message string
+		)
+
+		switch {
+		case errors.Is(err, common.ErrRequestNotFound):
+			status = http.StatusNotFound
+			message = common.MessRequestNotFound
+
+		default:
+			status = http.StatusInternalServerError
+			message = common.MessInternalServerError
+		}
+
+		c.JSON(status, response.Status{Message: message, Error: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, response.Status{IsSuccessful: true, Message: "Request force unlocked successfully"})
+}
+
+func (rc *requestController) GetRequest(c *gin.Context) {
+	authUserID, err := utils.GetUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, response.Status{Message: common.MessUnauthorized, Error: err.Error()})
+		return
+	}
+
+	requestID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.Status{Message: common.MessInvalidRequestData, Error: err.Error()})
+		return
+	}
+
+	request, err := rc.requestUsecase.GetRequest(c, authUserID, requestID)
+	writeRequestDetail(c, request, err)
+}
+
+func (rc *requestController) GetRequestByCode(c *gin.Context) {
+	authUserID, err := utils.GetUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, response.Status{Message: common.MessUnauthorized, Error: err.Error()})
+		return
+	}
+
+	requestCode := strings.ToUpper(strings.TrimSpace(c.Param("code")))
+	if requestCode == "" || len(requestCode) > 50 {
+		c.JSON(http.StatusBadRequest, response.Status{Message: common.MessInvalidRequestData, Error: "invalid request code"})
+		return
+	}
+
+	request, err := rc.requestUsecase.GetRequestByCode(c, authUserID, requestCode)
+	writeRequestDetail(c, request, err)
+}
+
+func writeRequestDetail(c *gin.Context, request *model.Request, err error) {
+	if err != nil {
+		var (
+			status  int
+			message string
+		)
+
+		switch {
+		case errors.Is(err, common.ErrRequestNotFound):
+			status = http.StatusNotFound
+			message = common.MessRequestNotFound
+
+		case errors.Is(err, common.ErrUnauthorized):
+			status = http.StatusForbidden
+			message = common.MessForbidden
+
+		default:
+			status = http.StatusInternalServerError
+			message = common.MessInternalServerError
+		}
+
+		c.JSON(status, response.Status{Message: message, Error: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, response.Status{IsSuccessful: true, Message: "Request fetched successfully", Data: request})
+}
+
+func (rc *requestController) GetRequestHistory(c *gin.Context) {
+	authUserID, err := utils.GetUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, response.Status{Message: common.MessUnauthorized, Error: err.Error()})
+		return
+	}
+
+	requestID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.Status{Message: common.MessInvalidRequestData, Error: err.Error()})
+		return
+	}
+
+	events, err := rc.requestUsecase.GetRequestHistory(c, authUserID, requestID)
+	if err != nil {
+		var (
+			status  int
+			message string
+		)
+
+		switch {
+		case errors.Is(err, common.ErrRequestNotFound):
+			status = http.StatusNotFound
+			message = common.MessRequestNotFound
+
+		case errors.Is(err, common.ErrUnauthorized):
+			status = http.StatusForbidden
+			message = common.MessForbidden
+
+		default:
+			status = http.StatusInternalServerError
+			message = common.MessInternalServerError
+		}
+
+		c.JSON(status, response.Status{Message: message, Error: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, response.Status{IsSuccessful: true, Message: "Request history fetched successfully", Data: events})
+}
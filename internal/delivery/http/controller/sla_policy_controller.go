@@ -0,0 +1,178 @@
+package controller
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"github.com/latiiLA/coop-forex-server/internal/common"
+	"github.com/latiiLA/coop-forex-server/internal/delivery/http/response"
+	"github.com/latiiLA/coop-forex-server/internal/domain/model"
+	"github.com/latiiLA/coop-forex-server/internal/infrastructure/utils"
+	"github.com/latiiLA/coop-forex-server/internal/usecase"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type SLAPolicyController interface {
+	AddSLAPolicy(c *gin.Context)
+	GetAllSLAPolicies(c *gin.Context)
+	GetSLAPolicyByID(c *gin.Context)
+	UpdateSLAPolicy(c *gin.Context)
+	DeleteSLAPolicy(c *gin.Context)
+}
+
+type slaPolicyController struct {
+	slaPolicyUsecase usecase.SLAPolicyUsecase
+}
+
+func NewSLAPolicyController(slaPolicyUsecase usecase.SLAPolicyUsecase) SLAPolicyController {
+	return &slaPolicyController{
+		slaPolicyUsecase: slaPolicyUsecase,
+	}
+}
+
+func (sc *slaPolicyController) AddSLAPolicy(c *gin.Context) {
+	userID, err := utils.GetUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, response.Status{Message: common.MessUnauthorized, Error: err.Error()})
+		return
+	}
+
+	var policy model.CreateSLAPolicyDTO
+	if !bindSLAPolicy(c, &policy) {
+		return
+	}
+
+	created, err := sc.slaPolicyUsecase.AddSLAPolicy(c, userID, &policy)
+	if err != nil {
+		writeSLAPolicyError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response.Status{IsSuccessful: true, Message: "SLA policy created successfully", Data: created})
+}
+
+func (sc *slaPolicyController) GetAllSLAPolicies(c *gin.Context) {
+	policies, err := sc.slaPolicyUsecase.GetAllSLAPolicies(c)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, response.Status{Message: common.MessInternalServerError, Error: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, response.Status{IsSuccessful: true, Message: "SLA policies fetched successfully", Data: policies})
+}
+
+func (sc *slaPolicyController) GetSLAPolicyByID(c *gin.Context) {
+	policyID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.Status{Message: common.MessInvalidRequestData, Error: err.Error()})
+		return
+	}
+
+	policy, err := sc.slaPolicyUsecase.GetSLAPolicyByID(c, policyID)
+	if err != nil {
+		writeSLAPolicyError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response.Status{IsSuccessful: true, Message: "SLA policy fetched successfully", Data: policy})
+}
+
+func (sc *slaPolicyController) UpdateSLAPolicy(c *gin.Context) {
+	authUserID, err := utils.GetUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, response.Status{Message: common.MessUnauthorized, Error: err.Error()})
+		return
+	}
+
+	policyID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.Status{Message: common.MessInvalidRequestData, Error: err.Error()})
+		return
+	}
+
+	var policy model.UpdateSLAPolicyDTO
+	if !bindSLAPolicy(c, &policy) {
+		return
+	}
+
+	err = sc.slaPolicyUsecase.UpdateSLAPolicy(c, authUserID, policyID, &policy)
+	if err != nil {
+		writeSLAPolicyError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response.Status{IsSuccessful: true, Message: "SLA policy updated successfully"})
+}
+
+func (sc *slaPolicyController) DeleteSLAPolicy(c *gin.Context) {
+	authUserID, err := utils.GetUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, response.Status{Message: common.MessUnauthorized, Error: err.Error()})
+		return
+	}
+
+	policyID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.Status{Message: common.MessInvalidRequestData, Error: err.Error()})
+		return
+	}
+
+	err = sc.slaPolicyUsecase.DeleteSLAPolicy(c, authUserID, policyID)
+	if err != nil {
+		writeSLAPolicyError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response.Status{IsSuccessful: true, Message: "SLA policy deleted successfully"})
+}
+
+func bindSLAPolicy(c *gin.Context, policy *model.CreateSLAPolicyDTO) bool {
+	err := c.ShouldBindJSON(policy)
+	if err == nil {
+		return true
+	}
+
+	if validationErrors, ok := err.(validator.ValidationErrors); ok {
+		e := validationErrors[0]
+		message := fmt.Sprintf("%s failed on %s validation", e.Field(), e.Tag())
+
+		c.JSON(http.StatusBadRequest, response.Status{
+			Message: message,
+			Error:   err.Error(),
+		})
+
+		return false
+	}
+
+	c.JSON(http.StatusBadRequest, response.Status{
+		Message: common.MessInvalidRequest,
+		Error:   err.Error(),
+	})
+	return false
+}
+
+func writeSLAPolicyError(c *gin.Context, err error) {
+	var (
+		status  int
+		message string
+	)
+
+	switch {
+	case errors.Is(err, common.ErrSLAPolicyNotFound):
+		status = http.StatusNotFound
+		message = "SLA policy not found"
+
+	case errors.Is(err, common.ErrInvalidSLAPolicy):
+		status = http.StatusBadRequest
+		message = common.MessInvalidRequestData
+
+	default:
+		status = http.StatusInternalServerError
+		message = common.MessInternalServerError
+	}
+
+	c.JSON(status, response.Status{Message: message, Error: err.Error()})
+}
@@ -0,0 +1,101 @@
+package controller_test
+
+import (
+	"bufio"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/latiiLA/coop-forex-server/internal/delivery/http/controller"
+	"github.com/latiiLA/coop-forex-server/internal/domain/model"
+	"github.com/latiiLA/coop-forex-server/internal/usecase"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// inbox is an InboxUsecase that redeems any ticket for one session and
+// reports it revoked once revoked is set.
+type inbox struct {
+	usecase.InboxUsecase
+	userID    primitive.ObjectID
+	sessionID primitive.ObjectID
+	items     chan model.InboxItem
+	revoked   atomic.Bool
+}
+
+func (i *inbox) RedeemStreamTicket(ctx context.Context, ticket string) (primitive.ObjectID, primitive.ObjectID, error) {
+	return i.userID, i.sessionID, nil
+}
+
+func (i *inbox) Subscribe(authUserID primitive.ObjectID) (<-chan model.InboxItem, func()) {
+	return i.items, func() {}
+}
+
+func (i *inbox) CountUnread(ctx context.Context, authUserID primitive.ObjectID) (int64, error) {
+	return 0, nil
+}
+
+func (i *inbox) IsSessionRevoked(sessionID primitive.ObjectID) bool {
+	return sessionID == i.sessionID && i.revoked.Load()
+}
+
+func TestStreamInboxEndsWhenTheSessionIsRevoked(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	fake := &inbox{
+		userID:    primitive.NewObjectID(),
+		sessionID: primitive.NewObjectID(),
+		items:     make(chan model.InboxItem),
+	}
+	engine := gin.New()
+	engine.GET("/inbox/stream", controller.NewInboxController(fake, 10*time.Millisecond).StreamInbox)
+
+	server := httptest.NewServer(engine)
+	defer server.Close()
+
+	res, err := http.Get(server.URL + "/inbox/stream?ticket=t")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer res.Body.Close()
+
+	lines := make(chan string)
+	go func() {
+		defer close(lines)
+		scanner := bufio.NewScanner(res.Body)
+		for scanner.Scan() {
+			lines <- scanner.Text()
+		}
+	}()
+
+	// Wait for a ping so the stream is known to be running before revoking.
+	deadline := time.After(2 * time.Second)
+	for pinged := false; !pinged; {
+		select {
+		case line, ok := <-lines:
+			if !ok {
+				t.Fatal("stream ended before the session was revoked")
+			}
+			pinged = strings.HasPrefix(line, "event:ping")
+		case <-deadline:
+			t.Fatal("no ping received")
+		}
+	}
+
+	fake.revoked.Store(true)
+
+	for {
+		select {
+		case _, ok := <-lines:
+			if !ok {
+				return
+			}
+		case <-deadline:
+			t.Fatal("stream still open after the session was revoked")
+		}
+	}
+}
@@ -22,6 +22,7 @@ type UserController interface {
 	Login(c *gin.Context)
 	GetAllUsers(c *gin.Context)
 	UpdateUser(c *gin.Context)
+	UpdateUserStatus(c *gin.Context)
 	IP(c *gin.Context)
 	RefreshToken(c *gin.Context)
 }
@@ -94,13 +95,11 @@ func (uc *userController) Login(c *gin.Context) {
 
 	logEntry.WithField("username", userReq.Username).Debug("Login attempt")
 
-	clientIP := c.ClientIP()
-
 	// Sanitize
 	userReq.Username = html.EscapeString(userReq.Username)
 	userReq.Password = html.EscapeString(userReq.Password)
 
-	user, err := uc.userUsecase.Login(c, userReq, clientIP)
+	user, err := uc.userUsecase.Login(c, userReq, utils.GetClientInfo(c))
 	if err != nil {
 		logEntry.WithField("error", err.Error()).Warn("Login failed")
 
@@ -204,6 +203,42 @@ func (uc *userController) UpdateUser(c *gin.Context) {
 	c.JSON(http.StatusOK, response.Status{IsSuccessful: true, Message: "User updated successfully", Data: user})
 }
 
+func (uc *userController) UpdateUserStatus(c *gin.Context) {
+	logEntry := utils.GetLogger(c)
+	authUserID, err := utils.GetUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, response.Status{Message: common.MessUnauthorized, Error: err.Error()})
+		return
+	}
+
+	userObjID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.Status{Message: common.MessInvalidRequestData, Error: err.Error()})
+		return
+	}
+
+	var statusReq model.UpdateUserStatusDTO
+	if err := c.ShouldBindJSON(&statusReq); err != nil {
+		c.JSON(http.StatusBadRequest, response.Status{Message: common.MessInvalidRequest, Error: err.Error()})
+		return
+	}
+
+	err = uc.userUsecase.UpdateUserStatus(c, userObjID, authUserID, statusReq.Status)
+	switch {
+	case err == nil:
+	case errors.Is(err, common.ErrUserNotFound):
+		c.JSON(http.StatusNotFound, response.Status{Message: "User not found", Error: err.Error()})
+		return
+	default:
+		logEntry.WithField("error", err.Error()).Warn("user status update failed")
+		c.JSON(http.StatusInternalServerError, response.Status{Message: common.MessInternalServerError, Error: err.Error()})
+		return
+	}
+
+	logEntry.WithField("status", statusReq.Status).Info("user status updated")
+	c.JSON(http.StatusOK, response.Status{IsSuccessful: true, Message: "User status updated successfully"})
+}
+
 func (uc *userController) IP(c *gin.Context) {
 	clientIP := c.ClientIP()
 
@@ -233,9 +268,15 @@ func (ac *userController) RefreshToken(c *gin.Context) {
 		return
 	}
 
-	clientIP := c.ClientIP() // get client IP
-
-	access_token, refresh_token, err := ac.userUsecase.RefreshToken(c, refreshInput, clientIP)
+	access_token, refresh_token, err := ac.userUsecase.RefreshToken(c, refreshInput, utils.GetClientInfo(c))
+	if errors.Is(err, common.ErrRefreshTokenReused) {
+		c.JSON(http.StatusUnauthorized, response.Status{Message: common.MessUnauthorized, Error: "Refresh token was already used, log in again"})
+		return
+	}
+	if errors.Is(err, common.ErrIPBindingMismatch) {
+		c.JSON(http.StatusUnauthorized, response.Status{Message: common.MessUnauthorized, Error: "your network has been changed. please relogin"})
+		return
+	}
 	if err != nil {
 		logrus.Error("invalid token", err)
 		c.JSON(http.StatusUnauthorized, response.Status{Message: common.MessUnauthorized, Error: "Invalid or expired token"})
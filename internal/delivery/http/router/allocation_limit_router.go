@@ -0,0 +1,24 @@
+package router
+
+import (
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/latiiLA/coop-forex-server/internal/delivery/http/controller"
+	"github.com/latiiLA/coop-forex-server/internal/infrastructure/middleware"
+	"github.com/latiiLA/coop-forex-server/internal/repository"
+	"github.com/latiiLA/coop-forex-server/internal/usecase"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+func NewAllocationLimitRouter(db *mongo.Database, timeout time.Duration, group *gin.RouterGroup) {
+	allocationLimitRepository := repository.NewAllocationLimitRepository(db)
+	allocationLimitUsecase := usecase.NewAllocationLimitUsecase(allocationLimitRepository, timeout)
+	allocationLimitController := controller.NewAllocationLimitController(allocationLimitUsecase)
+
+	group.POST("/allocationlimit", middleware.JwtAuthMiddleware(), middleware.AuthorizeRolesOrPermissions([]string{""}, []string{"allocationlimit:add"}), allocationLimitController.AddAllocationLimit)
+	group.GET("/allocationlimits", middleware.JwtAuthMiddleware(), middleware.AuthorizeRolesOrPermissions([]string{""}, []string{"allocationlimit:view"}), allocationLimitController.GetAllAllocationLimits)
+	group.GET("/allocationlimit/:id", middleware.JwtAuthMiddleware(), middleware.AuthorizeRolesOrPermissions([]string{""}, []string{"allocationlimit:view"}), allocationLimitController.GetAllocationLimitByID)
+	group.PUT("/allocationlimit/:id", middleware.JwtAuthMiddleware(), middleware.AuthorizeRolesOrPermissions([]string{""}, []string{"allocationlimit:update"}), allocationLimitController.UpdateAllocationLimit)
+	group.PATCH("/allocationlimit/:id", middleware.JwtAuthMiddleware(), middleware.AuthorizeRolesOrPermissions([]string{""}, []string{"allocationlimit:delete"}), allocationLimitController.DeleteAllocationLimit)
+}
@@ -4,7 +4,6 @@ import (
 	"time"
 
 	"github.com/gin-gonic/gin"
-	"github.com/latiiLA/coop-forex-server/configs"
 	"github.com/latiiLA/coop-forex-server/internal/delivery/http/controller"
 	"github.com/latiiLA/coop-forex-server/internal/infrastructure/middleware"
 	"github.com/latiiLA/coop-forex-server/internal/repository"
@@ -17,6 +16,6 @@ func NewBranchRouter(db *mongo.Database, timeout time.Duration, group *gin.Route
 	branchUsecase := usecase.NewBranchUsecase(branchRepo, timeout)
 	branchController := controller.NewBranchController(branchUsecase)
 
-	group.GET("/branches/:id", middleware.JwtAuthMiddleware(configs.JwtSecret), branchController.GetBranchesByDistrictID)
-	group.GET("/branches", middleware.JwtAuthMiddleware(configs.JwtSecret), branchController.GetAllBranches)
+	group.GET("/branches/:id", middleware.JwtAuthMiddleware(), branchController.GetBranchesByDistrictID)
+	group.GET("/branches", middleware.JwtAuthMiddleware(), branchController.GetAllBranches)
 }
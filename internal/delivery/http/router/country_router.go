@@ -4,7 +4,6 @@ import (
 	"time"
 
 	"github.com/gin-gonic/gin"
-	"github.com/latiiLA/coop-forex-server/configs"
 	"github.com/latiiLA/coop-forex-server/internal/delivery/http/controller"
 	"github.com/latiiLA/coop-forex-server/internal/infrastructure/middleware"
 	"github.com/latiiLA/coop-forex-server/internal/repository"
@@ -17,5 +16,5 @@ func NewCountryRouter(db *mongo.Database, timeout time.Duration, group *gin.Rout
 	countryUsecase := usecase.NewCountryUsecase(countryRepository, timeout)
 	countryController := controller.NewCountryController(countryUsecase)
 
-	group.GET("/countries", middleware.JwtAuthMiddleware(configs.JwtSecret), countryController.GetAllCountry)
+	group.GET("/countries", middleware.JwtAuthMiddleware(), countryController.GetAllCountry)
 }
@@ -4,7 +4,6 @@ import (
 	"time"
 
 	"github.com/gin-gonic/gin"
-	"github.com/latiiLA/coop-forex-server/configs"
 	"github.com/latiiLA/coop-forex-server/internal/delivery/http/controller"
 	"github.com/latiiLA/coop-forex-server/internal/infrastructure/middleware"
 	"github.com/latiiLA/coop-forex-server/internal/repository"
@@ -17,5 +16,5 @@ func NewCurrencyRouter(db *mongo.Database, timeout time.Duration, group *gin.Rou
 	currencyUsecase := usecase.NewCurrencyUsecase(currencyRepository, timeout)
 	currencyController := controller.NewCurrencyController(currencyUsecase)
 
-	group.GET("/currencies", middleware.JwtAuthMiddleware(configs.JwtSecret), currencyController.GetAllCurrency)
+	group.GET("/currencies", middleware.JwtAuthMiddleware(), currencyController.GetAllCurrency)
 }
@@ -4,7 +4,6 @@ import (
 	"time"
 
 	"github.com/gin-gonic/gin"
-	"github.com/latiiLA/coop-forex-server/configs"
 	"github.com/latiiLA/coop-forex-server/internal/delivery/http/controller"
 	"github.com/latiiLA/coop-forex-server/internal/infrastructure/middleware"
 	"github.com/latiiLA/coop-forex-server/internal/repository"
@@ -18,6 +17,6 @@ func NewDepartmentRouter(db *mongo.Database, timeout time.Duration, group *gin.R
 	departmentController := controller.NewDepartmentController(departmentUsecase)
 
 	// group.POST("/department", departmentController.AddDepartment)
-	group.GET("/departments/:id", middleware.JwtAuthMiddleware(configs.JwtSecret), departmentController.GetDepartmentsByProcessID)
-	group.GET("/departments", middleware.JwtAuthMiddleware(configs.JwtSecret), departmentController.GetAllDepartments)
+	group.GET("/departments/:id", middleware.JwtAuthMiddleware(), departmentController.GetDepartmentsByProcessID)
+	group.GET("/departments", middleware.JwtAuthMiddleware(), departmentController.GetAllDepartments)
 }
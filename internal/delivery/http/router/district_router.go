@@ -4,7 +4,6 @@ import (
 	"time"
 
 	"github.com/gin-gonic/gin"
-	"github.com/latiiLA/coop-forex-server/configs"
 	"github.com/latiiLA/coop-forex-server/internal/delivery/http/controller"
 	"github.com/latiiLA/coop-forex-server/internal/infrastructure/middleware"
 	"github.com/latiiLA/coop-forex-server/internal/repository"
@@ -17,5 +16,5 @@ func NewDistrictRouter(db *mongo.Database, timeout time.Duration, group *gin.Rou
 	districtUsecase := usecase.NewDistrictUsecase(districtRepo, timeout)
 	districtController := controller.NewDistrictController(districtUsecase)
 
-	group.GET("/districts", middleware.JwtAuthMiddleware(configs.JwtSecret), districtController.GetAllDistricts)
+	group.GET("/districts", middleware.JwtAuthMiddleware(), districtController.GetAllDistricts)
 }
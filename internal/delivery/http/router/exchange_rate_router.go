@@ -0,0 +1,27 @@
+package router
+
+import (
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/latiiLA/coop-forex-server/internal/delivery/http/controller"
+	"github.com/latiiLA/coop-forex-server/internal/infrastructure/middleware"
+	"github.com/latiiLA/coop-forex-server/internal/repository"
+	"github.com/latiiLA/coop-forex-server/internal/usecase"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+func NewExchangeRateRouter(db *mongo.Database, timeout time.Duration, group *gin.RouterGroup) {
+	exchangeRateRepository := repository.NewExchangeRateRepository(db)
+	currencyRepository := repository.NewCurrencyRepository(db)
+	exchangeRateUsecase := usecase.NewExchangeRateUsecase(exchangeRateRepository, currencyRepository, timeout, db.Client())
+	exchangeRateController := controller.NewExchangeRateController(exchangeRateUsecase)
+
+	group.GET("/exchangerates", middleware.JwtAuthMiddleware(), exchangeRateController.GetCurrentExchangeRates)
+	group.GET("/exchangerates/history", middleware.JwtAuthMiddleware(), middleware.AuthorizeRolesOrPermissions([]string{""}, []string{"exchangerate:view"}), exchangeRateController.GetExchangeRateHistory)
+	group.POST("/exchangerates/upload", middleware.JwtAuthMiddleware(), middleware.AuthorizeRolesOrPermissions([]string{""}, []string{"exchangerate:upload"}), exchangeRateController.UploadExchangeRates)
+	group.POST("/exchangerate", middleware.JwtAuthMiddleware(), middleware.AuthorizeRolesOrPermissions([]string{""}, []string{"exchangerate:add"}), exchangeRateController.AddExchangeRate)
+	group.GET("/exchangerate/:id", middleware.JwtAuthMiddleware(), middleware.AuthorizeRolesOrPermissions([]string{""}, []string{"exchangerate:view"}), exchangeRateController.GetExchangeRateByID)
+	group.PUT("/exchangerate/:id", middleware.JwtAuthMiddleware(), middleware.AuthorizeRolesOrPermissions([]string{""}, []string{"exchangerate:update"}), exchangeRateController.UpdateExchangeRate)
+	group.PATCH("/exchangerate/:id", middleware.JwtAuthMiddleware(), middleware.AuthorizeRolesOrPermissions([]string{""}, []string{"exchangerate:delete"}), exchangeRateController.DeleteExchangeRate)
+}
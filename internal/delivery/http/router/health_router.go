@@ -0,0 +1,16 @@
+package router
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/latiiLA/coop-forex-server/internal/delivery/http/controller"
+	"github.com/latiiLA/coop-forex-server/internal/domain/model"
+)
+
+// NewHealthRouter registers the load balancer probes. They are meant for the
+// root of the engine, ahead of the rate limiter and request logging.
+func NewHealthRouter(group *gin.RouterGroup, readinessChecker model.ReadinessChecker) {
+	healthController := controller.NewHealthController(readinessChecker)
+
+	group.GET("/healthz", healthController.Liveness)
+	group.GET("/readyz", healthController.Readiness)
+}
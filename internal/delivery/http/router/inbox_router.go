@@ -0,0 +1,31 @@
+package router
+
+import (
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/latiiLA/coop-forex-server/internal/delivery/http/controller"
+	"github.com/latiiLA/coop-forex-server/internal/domain/model"
+	"github.com/latiiLA/coop-forex-server/internal/infrastructure/middleware"
+	"github.com/latiiLA/coop-forex-server/internal/repository"
+	"github.com/latiiLA/coop-forex-server/internal/usecase"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// inboxHeartbeat keeps idle streams from being closed by proxies.
+const inboxHeartbeat = 25 * time.Second
+
+func NewInboxRouter(db *mongo.Database, timeout time.Duration, group *gin.RouterGroup, inboxBroker model.InboxBroker, revocations model.SessionRevocations) {
+	inboxRepository := repository.NewInboxRepository(db)
+	streamTicketRepository := repository.NewStreamTicketRepository(db)
+	inboxUsecase := usecase.NewInboxUsecase(inboxRepository, streamTicketRepository, inboxBroker, revocations, timeout)
+	inboxController := controller.NewInboxController(inboxUsecase, inboxHeartbeat)
+
+	group.GET("/inbox", middleware.JwtAuthMiddleware(), inboxController.GetInbox)
+	group.GET("/inbox/unread", middleware.JwtAuthMiddleware(), inboxController.CountUnread)
+	group.POST("/inbox/read", middleware.JwtAuthMiddleware(), inboxController.MarkRead)
+	group.POST("/inbox/read-all", middleware.JwtAuthMiddleware(), inboxController.MarkAllRead)
+	group.POST("/inbox/stream/ticket", middleware.JwtAuthMiddleware(), inboxController.IssueStreamTicket)
+	// Authenticated by the single-use ticket, as EventSource cannot send headers
+	group.GET("/inbox/stream", inboxController.StreamInbox)
+}
@@ -0,0 +1,26 @@
+package router
+
+import (
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/latiiLA/coop-forex-server/internal/delivery/http/controller"
+	"github.com/latiiLA/coop-forex-server/internal/domain/model"
+	"github.com/latiiLA/coop-forex-server/internal/infrastructure/middleware"
+	"github.com/latiiLA/coop-forex-server/internal/repository"
+	"github.com/latiiLA/coop-forex-server/internal/usecase"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+func NewJobRouter(db *mongo.Database, timeout time.Duration, group *gin.RouterGroup, jobRunner model.JobRunner) {
+	jobRepository := repository.NewJobRepository(db)
+	jobRunRepository := repository.NewJobRunRepository(db)
+	jobUsecase := usecase.NewJobUsecase(jobRepository, jobRunRepository, jobRunner, timeout)
+	jobController := controller.NewJobController(jobUsecase)
+
+	group.GET("/jobs", middleware.JwtAuthMiddleware(), middleware.AuthorizeRolesOrPermissions([]string{""}, []string{"job:view"}), jobController.GetJobs)
+	group.GET("/job/:name/runs", middleware.JwtAuthMiddleware(), middleware.AuthorizeRolesOrPermissions([]string{""}, []string{"job:view"}), jobController.GetJobRuns)
+	group.POST("/job/:name/trigger", middleware.JwtAuthMiddleware(), middleware.AuthorizeRolesOrPermissions([]string{""}, []string{"job:run"}), jobController.TriggerJob)
+	group.POST("/job/:name/pause", middleware.JwtAuthMiddleware(), middleware.AuthorizeRolesOrPermissions([]string{""}, []string{"job:pause"}), jobController.PauseJob)
+	group.POST("/job/:name/resume", middleware.JwtAuthMiddleware(), middleware.AuthorizeRolesOrPermissions([]string{""}, []string{"job:pause"}), jobController.ResumeJob)
+}
@@ -0,0 +1,15 @@
+package router
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/latiiLA/coop-forex-server/internal/delivery/http/controller"
+	"github.com/latiiLA/coop-forex-server/internal/domain/model"
+)
+
+// NewJWKSRouter publishes the access token verification keys at the
+// well-known location, for other services to verify tokens offline.
+func NewJWKSRouter(group *gin.RouterGroup, publicKeys model.PublicKeySet) {
+	jwksController := controller.NewJWKSController(publicKeys)
+
+	group.GET("/.well-known/jwks.json", jwksController.GetJWKS)
+}
@@ -0,0 +1,13 @@
+package router
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/latiiLA/coop-forex-server/internal/infrastructure/metrics"
+	"github.com/latiiLA/coop-forex-server/internal/infrastructure/middleware"
+)
+
+// NewMetricsRouter registers the Prometheus scrape endpoint. Like the health
+// probes it is meant for the root of the engine, ahead of the rate limiter.
+func NewMetricsRouter(group *gin.RouterGroup, token string) {
+	group.GET("/metrics", middleware.MetricsAuth(token), gin.WrapH(metrics.Handler()))
+}
@@ -0,0 +1,26 @@
+package router
+
+import (
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/latiiLA/coop-forex-server/internal/delivery/http/controller"
+	"github.com/latiiLA/coop-forex-server/internal/domain/model"
+	"github.com/latiiLA/coop-forex-server/internal/infrastructure/middleware"
+	"github.com/latiiLA/coop-forex-server/internal/repository"
+	"github.com/latiiLA/coop-forex-server/internal/usecase"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+func NewNotificationRouter(db *mongo.Database, timeout time.Duration, group *gin.RouterGroup, notificationRenderer model.NotificationRenderer) {
+	notificationRepository := repository.NewNotificationRepository(db)
+	requestRepository := repository.NewRequestRepository(db)
+	currencyRepository := repository.NewCurrencyRepository(db)
+	userRepository := repository.NewUserRepository(db)
+	notificationUsecase := usecase.NewNotificationUsecase(notificationRepository, requestRepository, currencyRepository, userRepository, notificationRenderer, timeout)
+	notificationController := controller.NewNotificationController(notificationUsecase)
+
+	group.GET("/request/:id/notifications", middleware.JwtAuthMiddleware(), middleware.AuthorizeRolesOrPermissions([]string{""}, []string{"notification:view"}), notificationController.GetRequestNotifications)
+	group.POST("/notification/:id/retry", middleware.JwtAuthMiddleware(), middleware.AuthorizeRolesOrPermissions([]string{""}, []string{"notification:retry"}), notificationController.RetryNotification)
+	group.GET("/notification/preview/:event", middleware.JwtAuthMiddleware(), middleware.AuthorizeRolesOrPermissions([]string{""}, []string{"notification:view"}), notificationController.PreviewNotification)
+}
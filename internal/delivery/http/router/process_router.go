@@ -4,7 +4,6 @@ import (
 	"time"
 
 	"github.com/gin-gonic/gin"
-	"github.com/latiiLA/coop-forex-server/configs"
 	"github.com/latiiLA/coop-forex-server/internal/delivery/http/controller"
 	"github.com/latiiLA/coop-forex-server/internal/infrastructure/middleware"
 	"github.com/latiiLA/coop-forex-server/internal/repository"
@@ -18,5 +17,5 @@ func NewProcessRouter(db *mongo.Database, timeout time.Duration, group *gin.Rout
 	processController := controller.NewProcessController(processUsecase)
 
 	// group.POST("/process", processController.AddProcess)
-	group.GET("/processes", middleware.JwtAuthMiddleware(configs.JwtSecret), processController.GetAllProcesses)
+	group.GET("/processes", middleware.JwtAuthMiddleware(), processController.GetAllProcesses)
 }
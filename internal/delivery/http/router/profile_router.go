@@ -4,25 +4,25 @@ import (
 	"time"
 
 	"github.com/gin-gonic/gin"
-	"github.com/latiiLA/coop-forex-server/configs"
 	"github.com/latiiLA/coop-forex-server/internal/delivery/http/controller"
+	"github.com/latiiLA/coop-forex-server/internal/domain/model"
 	"github.com/latiiLA/coop-forex-server/internal/infrastructure/middleware"
 	"github.com/latiiLA/coop-forex-server/internal/repository"
 	"github.com/latiiLA/coop-forex-server/internal/usecase"
 	"go.mongodb.org/mongo-driver/mongo"
 )
 
-func NewProfileRouter(db *mongo.Database, timeout time.Duration, group *gin.RouterGroup) {
+func NewProfileRouter(db *mongo.Database, timeout time.Duration, group *gin.RouterGroup, revocations model.SessionRevocations, permissionVersions model.PermissionVersions, ipBindingGuard model.IPBindingGuard) {
 	profileRepo := repository.NewProfileRepository(db)
 	userRepo := repository.NewUserRepository(db)
 
 	profileUsecase := usecase.NewProfileUsecase(profileRepo, userRepo, timeout)
 
 	roleRepo := repository.NewRoleRepository(db)
-	tokenBlacklistRepo := repository.NewTokenBlacklistRepository(db)
-	userUsecase := usecase.NewUserUsecase(userRepo, roleRepo, profileRepo, tokenBlacklistRepo, timeout, db.Client())
+	sessionRepo := repository.NewSessionRepository(db)
+	userUsecase := usecase.NewUserUsecase(userRepo, roleRepo, profileRepo, sessionRepo, repository.NewSecurityEventRepository(db), revocations, permissionVersions, ipBindingGuard, timeout, db.Client())
 	profileController := controller.NewProfileController(profileUsecase, userUsecase)
 
-	group.GET("/profile/:id", middleware.JwtAuthMiddleware(configs.JwtSecret), profileController.GetProfileByID)
-	group.PUT("/profile/:id", middleware.JwtAuthMiddleware(configs.JwtSecret), profileController.UpdateProfileByID)
+	group.GET("/profile/:id", middleware.JwtAuthMiddleware(), profileController.GetProfileByID)
+	group.PUT("/profile/:id", middleware.JwtAuthMiddleware(), profileController.UpdateProfileByID)
 }
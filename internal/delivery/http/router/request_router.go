@@ -6,49 +6,110 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/latiiLA/coop-forex-server/configs"
 	"github.com/latiiLA/coop-forex-server/internal/delivery/http/controller"
+	"github.com/latiiLA/coop-forex-server/internal/domain/model"
 	"github.com/latiiLA/coop-forex-server/internal/infrastructure/middleware"
 	"github.com/latiiLA/coop-forex-server/internal/repository"
 	"github.com/latiiLA/coop-forex-server/internal/usecase"
 	"go.mongodb.org/mongo-driver/mongo"
 )
 
-func NewRequestRouter(db *mongo.Database, timeout time.Duration, group *gin.RouterGroup) {
-	requestRepo := repository.NewRequestRepository(db)
-	userRepo := repository.NewUserRepository(db)
-	requestUsecase := usecase.NewRequestUsecase(requestRepo, userRepo, timeout)
+func NewRequestRouter(db *mongo.Database, timeout time.Duration, group *gin.RouterGroup, notificationRenderer model.NotificationRenderer) {
+	requestUsecase := BuildRequestUsecase(db, timeout, notificationRenderer)
 	fileRepo := repository.NewFileRepository(db)
 	fileUsecase := usecase.NewFileUsecase(fileRepo, timeout)
 	requestController := controller.NewRequestController(requestUsecase, fileUsecase)
 
-	group.POST("/request", middleware.JwtAuthMiddleware(configs.JwtSecret), middleware.AuthorizeRolesOrPermissions([]string{}, []string{"request:add"}), requestController.AddRequest)
-	group.GET("/requests", middleware.JwtAuthMiddleware(configs.JwtSecret), middleware.AuthorizeRolesOrPermissions([]string{}, []string{"request:view"}), requestController.GetAllRequests)
-	group.GET("/orgrequests", middleware.JwtAuthMiddleware(configs.JwtSecret), middleware.AuthorizeRolesOrPermissions([]string{}, []string{"request:status"}), requestController.GetAllOrgRequests)
-	group.POST("/validaterequest/:id", middleware.JwtAuthMiddleware(configs.JwtSecret), middleware.AuthorizeRolesOrPermissions([]string{""}, []string{"request:validate"}), requestController.ValidateRequest)
-	group.POST("/approverequest/:id", middleware.JwtAuthMiddleware(configs.JwtSecret), middleware.AuthorizeRolesOrPermissions([]string{""}, []string{"request:approve"}), requestController.ApproveRequest)
-	group.POST("/orgauthorizerequest/:id", middleware.JwtAuthMiddleware(configs.JwtSecret), middleware.AuthorizeRolesOrPermissions([]string{""}, []string{"request:authorize"}), requestController.AuthorizeOrgRequest)
-
-	group.POST("/rejectrequest/:id", middleware.JwtAuthMiddleware(configs.JwtSecret), middleware.AuthorizeRolesOrPermissions([]string{""}, []string{"request:reject"}), requestController.RejectRequest)
-	group.PUT("/updaterequest/:id", middleware.JwtAuthMiddleware(configs.JwtSecret), middleware.AuthorizeRolesOrPermissions([]string{""}, []string{"request:update"}), requestController.UpdateRequest)
-	group.PATCH("/deleterequest/:id", middleware.JwtAuthMiddleware(configs.JwtSecret), middleware.AuthorizeRolesOrPermissions([]string{""}, []string{"request:delete"}), requestController.DeleteRequest)
-	group.POST("/acceptrequest/:id", middleware.JwtAuthMiddleware(configs.JwtSecret), middleware.AuthorizeRolesOrPermissions([]string{""}, []string{"request:process"}), requestController.AcceptRequest)
-	group.POST("/orgsendrequest/:id", middleware.JwtAuthMiddleware(configs.JwtSecret), middleware.AuthorizeRolesOrPermissions([]string{""}, []string{"request:send"}), requestController.SendRequest)
-	group.POST("/orgdeclinerequest/:id", middleware.JwtAuthMiddleware(configs.JwtSecret), middleware.AuthorizeRolesOrPermissions([]string{""}, []string{"request:decline"}), requestController.DeclineOrgRequest)
-
-	group.GET("/authorizedrequests", middleware.JwtAuthMiddleware(configs.JwtSecret), middleware.AuthorizeRolesOrPermissions([]string{""}, []string{"request:view-authorized"}), requestController.GetAuthorizedRequests)
-	group.GET("/newrequests", middleware.JwtAuthMiddleware(configs.JwtSecret), middleware.AuthorizeRolesOrPermissions([]string{""}, []string{"request:view-new"}), requestController.GetNewOrgRequests)
-	group.GET("/rejectedrequests", middleware.JwtAuthMiddleware(configs.JwtSecret), middleware.AuthorizeRolesOrPermissions([]string{}, []string{"request:view-rejected"}), requestController.GetRejectedRequests)
-	group.GET("/validatedrequests", middleware.JwtAuthMiddleware(configs.JwtSecret), middleware.AuthorizeRolesOrPermissions([]string{}, []string{"request:view-validated"}), requestController.GetValidatedRequests)
-	group.GET("/approvedrequests", middleware.JwtAuthMiddleware(configs.JwtSecret), middleware.AuthorizeRolesOrPermissions([]string{}, []string{"request:view-approved"}), requestController.GetApprovedRequests)
-	group.GET("/acceptedrequests", middleware.JwtAuthMiddleware(configs.JwtSecret), middleware.AuthorizeRolesOrPermissions([]string{}, []string{"request:view-accepted"}), requestController.GetAcceptedRequests)
-	group.GET("/declinedrequests", middleware.JwtAuthMiddleware(configs.JwtSecret), middleware.AuthorizeRolesOrPermissions([]string{}, []string{"request:view-declined"}), requestController.GetDeclinedRequests)
-	group.POST("/lockrequest/:id", middleware.JwtAuthMiddleware(configs.JwtSecret), middleware.AuthorizeRolesOrPermissions([]string{}, []string{"request:lock"}), requestController.LockRequest)
-	group.POST("/unlockrequest/:id", middleware.JwtAuthMiddleware(configs.JwtSecret), middleware.AuthorizeRolesOrPermissions([]string{}, []string{"request:unlock"}), requestController.UnLockRequest)
-
-	group.GET("/orgdraftedrequests", middleware.JwtAuthMiddleware(configs.JwtSecret), middleware.AuthorizeRolesOrPermissions([]string{}, []string{"request:view-orgdrafted"}), requestController.GetDraftedOrgRequests)
-	group.GET("/orgauthorizedrequests", middleware.JwtAuthMiddleware(configs.JwtSecret), middleware.AuthorizeRolesOrPermissions([]string{}, []string{"request:view-orgauthorized"}), requestController.GetAuthorizedOrgRequests)
-	group.GET("/orgapprovedrequests", middleware.JwtAuthMiddleware(configs.JwtSecret), middleware.AuthorizeRolesOrPermissions([]string{}, []string{"request:view-orgapproved"}), requestController.GetApprovedOrgRequests)
-	group.GET("/orgacceptedrequests", middleware.JwtAuthMiddleware(configs.JwtSecret), middleware.AuthorizeRolesOrPermissions([]string{}, []string{"request:view-orgaccepted"}), requestController.GetAcceptedOrgRequests)
-	group.GET("/orgdeclinedrequests", middleware.JwtAuthMiddleware(configs.JwtSecret), middleware.AuthorizeRolesOrPermissions([]string{}, []string{"request:view-orgdeclined"}), requestController.GetDeclinedOrgRequests)
-	group.GET("/orgrejectedrequests", middleware.JwtAuthMiddleware(configs.JwtSecret), middleware.AuthorizeRolesOrPermissions([]string{}, []string{"request:view-orgrejected"}), requestController.GetRejectedOrgRequests)
+	group.POST("/request", middleware.JwtAuthMiddleware(), middleware.AuthorizeRolesOrPermissions([]string{}, []string{"request:add"}), requestController.AddRequest)
+	group.GET("/requests", middleware.JwtAuthMiddleware(), middleware.AuthorizeRolesOrPermissions([]string{}, requestListPermissions()), requestController.SearchRequests)
+	group.GET("/request/code/:code", middleware.JwtAuthMiddleware(), middleware.AuthorizeRolesOrPermissions([]string{}, requestListPermissions()), requestController.GetRequestByCode)
+	group.GET("/request/:id", middleware.JwtAuthMiddleware(), middleware.AuthorizeRolesOrPermissions([]string{}, requestListPermissions()), requestController.GetRequest)
+	group.GET("/request/:id/history", middleware.JwtAuthMiddleware(), middleware.AuthorizeRolesOrPermissions([]string{}, requestListPermissions()), requestController.GetRequestHistory)
+	group.POST("/validaterequest/:id", middleware.JwtAuthMiddleware(), middleware.AuthorizeRolesOrPermissions([]string{""}, []string{"request:validate"}), requestController.ValidateRequest)
+	group.POST("/approverequest/:id", middleware.JwtAuthMiddleware(), middleware.AuthorizeRolesOrPermissions([]string{""}, []string{"request:approve"}), requestController.ApproveRequest)
+	group.POST("/orgauthorizerequest/:id", middleware.JwtAuthMiddleware(), middleware.AuthorizeRolesOrPermissions([]string{""}, []string{"request:authorize"}), requestController.AuthorizeOrgRequest)
+
+	group.POST("/rejectrequest/:id", middleware.JwtAuthMiddleware(), middleware.AuthorizeRolesOrPermissions([]string{""}, []string{"request:reject"}), requestController.RejectRequest)
+	group.PUT("/updaterequest/:id", middleware.JwtAuthMiddleware(), middleware.AuthorizeRolesOrPermissions([]string{""}, []string{"request:update"}), requestController.UpdateRequest)
+	group.PATCH("/deleterequest/:id", middleware.JwtAuthMiddleware(), middleware.AuthorizeRolesOrPermissions([]string{""}, []string{"request:delete"}), requestController.DeleteRequest)
+	group.POST("/acceptrequest/:id", middleware.JwtAuthMiddleware(), middleware.AuthorizeRolesOrPermissions([]string{""}, []string{"request:process"}), requestController.AcceptRequest)
+	group.POST("/orgsendrequest/:id", middleware.JwtAuthMiddleware(), middleware.AuthorizeRolesOrPermissions([]string{""}, []string{"request:send"}), requestController.SendRequest)
+	group.POST("/orgdeclinerequest/:id", middleware.JwtAuthMiddleware(), middleware.AuthorizeRolesOrPermissions([]string{""}, []string{"request:decline"}), requestController.DeclineOrgRequest)
+
+	group.POST("/lockrequest/:id", middleware.JwtAuthMiddleware(), middleware.AuthorizeRolesOrPermissions([]string{}, []string{"request:lock"}), requestController.LockRequest)
+	group.POST("/unlockrequest/:id", middleware.JwtAuthMiddleware(), middleware.AuthorizeRolesOrPermissions([]string{}, []string{"request:unlock"}), requestController.UnLockRequest)
+	group.POST("/renewlockrequest/:id", middleware.JwtAuthMiddleware(), middleware.AuthorizeRolesOrPermissions([]string{}, []string{"request:lock"}), requestController.RenewRequestLock)
+	group.POST("/forceunlockrequest/:id", middleware.JwtAuthMiddleware(), middleware.AuthorizeRolesOrPermissions([]string{}, []string{"request:force-unlock"}), requestController.ForceUnlockRequest)
+
+}
+
+// BuildRequestUsecase wires the request usecase to its repositories and the
+// configured duplicate policy and notification routes. The server uses it for
+// the background SLA checks as well as for the request routes.
+func BuildRequestUsecase(db *mongo.Database, timeout time.Duration, notificationRenderer model.NotificationRenderer) usecase.RequestUsecase {
+	requestRepo := repository.NewRequestRepository(db)
+	requestEventRepo := repository.NewRequestEventRepository(db)
+	exchangeRateRepo := repository.NewExchangeRateRepository(db)
+	allocationLimitRepo := repository.NewAllocationLimitRepository(db)
+	counterRepo := repository.NewCounterRepository(db)
+	branchRepo := repository.NewBranchRepository(db)
+	userRepo := repository.NewUserRepository(db)
+	notificationRepo := repository.NewNotificationRepository(db)
+	inboxRepo := repository.NewInboxRepository(db)
+	slaPolicyRepo := repository.NewSLAPolicyRepository(db)
+	currencyRepo := repository.NewCurrencyRepository(db)
+	duplicatePolicy := model.DuplicatePolicy{
+		Mode:          model.DuplicatePolicyMode(configs.DuplicateRequestPolicy),
+		Lookback:      configs.DuplicateRequestLookback,
+		NameThreshold: configs.DuplicateNameThreshold,
+		VelocityMax:   configs.DuplicateVelocityMax,
+	}
+
+	return usecase.NewRequestUsecase(requestRepo, requestEventRepo, exchangeRateRepo, allocationLimitRepo, counterRepo, branchRepo, userRepo, notificationRepo, inboxRepo, slaPolicyRepo, currencyRepo, notificationRenderer, requestNotificationRoutes(), timeout, configs.RequestLockTTL, duplicatePolicy, db.Client())
+}
+
+// requestListPermissions are the permissions that grant access to at least part
+// of the request listing; the usecase narrows the results to the caller's scope.
+func requestListPermissions() []string {
+	permissions := []string{"request:view", "request:status"}
+	for _, permission := range model.RequestStatusViewPermissions {
+		permissions = append(permissions, permission)
+	}
+	for _, permission := range model.RequestOrgStatusViewPermissions {
+		permissions = append(permissions, permission)
+	}
+
+	return permissions
+}
+
+// requestNotificationRoutes builds the audience of every request notification
+// from the MAIL_REQUEST_* settings.
+func requestNotificationRoutes() model.NotificationRoutes {
+	return model.NotificationRoutes{
+		model.NotifyRequestSent:       notificationRoute(model.NotifyRequestSent, configs.MailRequestSentTo, configs.MailRequestSentCc, configs.MailRequestSentBcc, configs.MailRequestSentNotify),
+		model.NotifyRequestAuthorized: notificationRoute(model.NotifyRequestAuthorized, configs.MailRequestAuthorizedTo, configs.MailRequestAuthorizedCc, configs.MailRequestAuthorizedBcc, configs.MailRequestAuthorizedNotify),
+		model.NotifyRequestValidated:  notificationRoute(model.NotifyRequestValidated, configs.MailRequestValidatedTo, configs.MailRequestValidatedCc, configs.MailRequestValidatedBcc, configs.MailRequestValidatedNotify),
+		model.NotifyRequestApproved:   notificationRoute(model.NotifyRequestApproved, configs.MailRequestApprovedTo, configs.MailRequestApprovedCc, configs.MailRequestApprovedBcc, configs.MailRequestApprovedNotify),
+		model.NotifyRequestRejected:   notificationRoute(model.NotifyRequestRejected, configs.MailRequestRejectedTo, configs.MailRequestRejectedCc, configs.MailRequestRejectedBcc, configs.MailRequestRejectedNotify),
+		model.NotifyRequestAccepted:   notificationRoute(model.NotifyRequestAccepted, configs.MailRequestAcceptedTo, configs.MailRequestAcceptedCc, configs.MailRequestAcceptedBcc, configs.MailRequestAcceptedNotify),
+		model.NotifyRequestDeclined:   notificationRoute(model.NotifyRequestDeclined, configs.MailRequestDeclinedTo, configs.MailRequestDeclinedCc, configs.MailRequestDeclinedBcc, configs.MailRequestDeclinedNotify),
+		model.NotifyRequestDueSoon:    notificationRoute(model.NotifyRequestDueSoon, configs.MailRequestDueSoonTo, configs.MailRequestDueSoonCc, configs.MailRequestDueSoonBcc, configs.MailRequestDueSoonNotify),
+		model.NotifyRequestOverdue:    notificationRoute(model.NotifyRequestOverdue, configs.MailRequestOverdueTo, configs.MailRequestOverdueCc, configs.MailRequestOverdueBcc, configs.MailRequestOverdueNotify),
+		model.NotifyRequestExpired:    notificationRoute(model.NotifyRequestExpired, configs.MailRequestExpiredTo, configs.MailRequestExpiredCc, configs.MailRequestExpiredBcc, configs.MailRequestExpiredNotify),
+	}
+}
+
+// notificationRoute falls back to the default dynamic recipients of event
+// when none are configured.
+func notificationRoute(event model.NotificationEvent, to, cc, bcc, notify []string) model.NotificationRoute {
+	route := model.NotificationRoute{To: to, Cc: cc, Bcc: bcc}
+	if notify == nil {
+		route.Dynamic = model.DefaultNotificationRecipients[event]
+		return route
+	}
+
+	for _, recipient := range notify {
+		route.Dynamic = append(route.Dynamic, model.NotificationRecipient(recipient))
+	}
 
+	return route
 }
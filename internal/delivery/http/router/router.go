@@ -4,19 +4,23 @@ import (
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/latiiLA/coop-forex-server/internal/domain/model"
 	"go.mongodb.org/mongo-driver/mongo"
 )
 
-func RouterSetup(router *gin.RouterGroup, timeout time.Duration, db *mongo.Database) {
+func RouterSetup(router *gin.RouterGroup, timeout time.Duration, db *mongo.Database, notificationRenderer model.NotificationRenderer, inboxBroker model.InboxBroker, jobRunner model.JobRunner, sessionRevocations model.SessionRevocations, permissionVersions model.PermissionVersions, ipBindingGuard model.IPBindingGuard) {
 	publicRouter := router.Group("")
 	// All public APIS
-	NewPublicRouter(db, timeout, publicRouter)
+	NewPublicRouter(db, timeout, publicRouter, sessionRevocations, permissionVersions, ipBindingGuard)
+
+	sessionRouter := router.Group("")
+	NewSessionRouter(db, timeout, sessionRouter, sessionRevocations)
 
 	roleRouter := router.Group("")
-	NewRoleRouter(db, timeout, roleRouter)
+	NewRoleRouter(db, timeout, roleRouter, permissionVersions)
 
 	profileRouter := router.Group("")
-	NewProfileRouter(db, timeout, profileRouter)
+	NewProfileRouter(db, timeout, profileRouter, sessionRevocations, permissionVersions, ipBindingGuard)
 
 	countryRouter := router.Group("")
 	NewCountryRouter(db, timeout, countryRouter)
@@ -36,8 +40,26 @@ func RouterSetup(router *gin.RouterGroup, timeout time.Duration, db *mongo.Datab
 	currencyRouter := router.Group("")
 	NewCurrencyRouter(db, timeout, currencyRouter)
 
+	exchangeRateRouter := router.Group("")
+	NewExchangeRateRouter(db, timeout, exchangeRateRouter)
+
+	allocationLimitRouter := router.Group("")
+	NewAllocationLimitRouter(db, timeout, allocationLimitRouter)
+
+	slaPolicyRouter := router.Group("")
+	NewSLAPolicyRouter(db, timeout, slaPolicyRouter)
+
 	requestRouter := router.Group("")
-	NewRequestRouter(db, timeout, requestRouter)
+	NewRequestRouter(db, timeout, requestRouter, notificationRenderer)
+
+	notificationRouter := router.Group("")
+	NewNotificationRouter(db, timeout, notificationRouter, notificationRenderer)
+
+	inboxRouter := router.Group("")
+	NewInboxRouter(db, timeout, inboxRouter, inboxBroker, sessionRevocations)
+
+	jobRouter := router.Group("")
+	NewJobRouter(db, timeout, jobRouter, jobRunner)
 
 	districtRouter := router.Group("")
 	NewDistrictRouter(db, timeout, districtRouter)
@@ -0,0 +1,24 @@
+package router
+
+import (
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/latiiLA/coop-forex-server/internal/delivery/http/controller"
+	"github.com/latiiLA/coop-forex-server/internal/domain/model"
+	"github.com/latiiLA/coop-forex-server/internal/infrastructure/middleware"
+	"github.com/latiiLA/coop-forex-server/internal/repository"
+	"github.com/latiiLA/coop-forex-server/internal/usecase"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+func NewSessionRouter(db *mongo.Database, timeout time.Duration, group *gin.RouterGroup, revocations model.SessionRevocations) {
+	sessionRepository := repository.NewSessionRepository(db)
+	sessionUsecase := usecase.NewSessionUsecase(sessionRepository, revocations, timeout)
+	sessionController := controller.NewSessionController(sessionUsecase)
+
+	group.GET("/sessions", middleware.JwtAuthMiddleware(), sessionController.ListSessions)
+	group.POST("/logout", middleware.JwtAuthMiddleware(), sessionController.Logout)
+	group.POST("/logout/all", middleware.JwtAuthMiddleware(), sessionController.LogoutAll)
+	group.DELETE("/users/:id/sessions", middleware.JwtAuthMiddleware(), middleware.AuthorizeRolesOrPermissions([]string{""}, []string{"session:revoke"}), sessionController.KillUserSessions)
+}
@@ -0,0 +1,24 @@
+package router
+
+import (
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/latiiLA/coop-forex-server/internal/delivery/http/controller"
+	"github.com/latiiLA/coop-forex-server/internal/infrastructure/middleware"
+	"github.com/latiiLA/coop-forex-server/internal/repository"
+	"github.com/latiiLA/coop-forex-server/internal/usecase"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+func NewSLAPolicyRouter(db *mongo.Database, timeout time.Duration, group *gin.RouterGroup) {
+	slaPolicyRepository := repository.NewSLAPolicyRepository(db)
+	slaPolicyUsecase := usecase.NewSLAPolicyUsecase(slaPolicyRepository, timeout)
+	slaPolicyController := controller.NewSLAPolicyController(slaPolicyUsecase)
+
+	group.POST("/slapolicy", middleware.JwtAuthMiddleware(), middleware.AuthorizeRolesOrPermissions([]string{""}, []string{"sla:add"}), slaPolicyController.AddSLAPolicy)
+	group.GET("/slapolicies", middleware.JwtAuthMiddleware(), middleware.AuthorizeRolesOrPermissions([]string{""}, []string{"sla:view"}), slaPolicyController.GetAllSLAPolicies)
+	group.GET("/slapolicy/:id", middleware.JwtAuthMiddleware(), middleware.AuthorizeRolesOrPermissions([]string{""}, []string{"sla:view"}), slaPolicyController.GetSLAPolicyByID)
+	group.PUT("/slapolicy/:id", middleware.JwtAuthMiddleware(), middleware.AuthorizeRolesOrPermissions([]string{""}, []string{"sla:update"}), slaPolicyController.UpdateSLAPolicy)
+	group.PATCH("/slapolicy/:id", middleware.JwtAuthMiddleware(), middleware.AuthorizeRolesOrPermissions([]string{""}, []string{"sla:delete"}), slaPolicyController.DeleteSLAPolicy)
+}
@@ -4,7 +4,6 @@ import (
 	"time"
 
 	"github.com/gin-gonic/gin"
-	"github.com/latiiLA/coop-forex-server/configs"
 	"github.com/latiiLA/coop-forex-server/internal/delivery/http/controller"
 	"github.com/latiiLA/coop-forex-server/internal/infrastructure/middleware"
 	"github.com/latiiLA/coop-forex-server/internal/repository"
@@ -18,6 +17,6 @@ func NewSubprocessRouter(db *mongo.Database, timeout time.Duration, group *gin.R
 	subprocessController := controller.NewSubprocessController(subprocessUsecase)
 
 	// group.POST("/subprocess", processController.AddProcess)
-	group.GET("/subprocesses/:id", middleware.JwtAuthMiddleware(configs.JwtSecret), subprocessController.GetSubprocessByProcessID)
-	group.GET("/subprocesses", middleware.JwtAuthMiddleware(configs.JwtSecret), subprocessController.GetAllSubprocesses)
+	group.GET("/subprocesses/:id", middleware.JwtAuthMiddleware(), subprocessController.GetSubprocessByProcessID)
+	group.GET("/subprocesses", middleware.JwtAuthMiddleware(), subprocessController.GetAllSubprocesses)
 }
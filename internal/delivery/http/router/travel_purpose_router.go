@@ -4,7 +4,6 @@ import (
 	"time"
 
 	"github.com/gin-gonic/gin"
-	"github.com/latiiLA/coop-forex-server/configs"
 	"github.com/latiiLA/coop-forex-server/internal/delivery/http/controller"
 	"github.com/latiiLA/coop-forex-server/internal/infrastructure/middleware"
 	"github.com/latiiLA/coop-forex-server/internal/repository"
@@ -17,5 +16,5 @@ func NewTravelPurposeRouter(db *mongo.Database, timeout time.Duration, group *gi
 	travelPurposeUsecase := usecase.NewTravelPurposeUsecase(travelPurposeRepository, timeout)
 	travelController := controller.NewTravelPurposeController(travelPurposeUsecase)
 
-	group.GET("/travelpurpose", middleware.JwtAuthMiddleware(configs.JwtSecret), travelController.GetAllTravelPurposes)
+	group.GET("/travelpurpose", middleware.JwtAuthMiddleware(), travelController.GetAllTravelPurposes)
 }
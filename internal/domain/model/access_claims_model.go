@@ -0,0 +1,38 @@
+package model
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// AccessClaims is what an access token says about its user. Login and
+// refresh build it the same way, from the user's current status, role and
+// permissions.
+type AccessClaims struct {
+	UserID       primitive.ObjectID
+	Role         string
+	BranchID     primitive.ObjectID
+	DepartmentID primitive.ObjectID
+	// Permissions merges those of the role with those granted to the user.
+	Permissions []string
+	// PermissionsVersion is the user's version when the token was issued.
+	PermissionsVersion int64
+}
+
+// PermissionsVersion is how far a user's access has changed; access tokens
+// carrying an older version are refused.
+type PermissionsVersion struct {
+	UserID    primitive.ObjectID `bson:"_id"`
+	Version   int64              `bson:"permissions_version"`
+	ChangedAt time.Time          `bson:"permissions_changed_at"`
+}
+
+// PermissionVersions answers whether an access token was issued before its
+// user's access changed, without a database round trip.
+type PermissionVersions interface {
+	IsStale(userID primitive.ObjectID, version int64) bool
+	// Add records versions this instance bumped so they apply at once;
+	// other instances learn of them when they next poll.
+	Add(versions ...PermissionsVersion)
+}
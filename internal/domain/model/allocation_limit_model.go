@@ -0,0 +1,215 @@
+package model
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/latiiLA/coop-forex-server/internal/common"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type AllocationLimitScope string
+
+const (
+	// LimitPerRequest caps the amount of a single approval or acceptance.
+	LimitPerRequest AllocationLimitScope = "per_request"
+	// LimitApplicant30Days caps what one applicant account may be allocated
+	// over the last 30 days.
+	LimitApplicant30Days AllocationLimitScope = "applicant_30d"
+	// LimitApplicant365Days caps what one applicant account may be allocated
+	// over the last 365 days.
+	LimitApplicant365Days AllocationLimitScope = "applicant_365d"
+	// LimitDailyBudget caps what the whole bank may allocate in a currency
+	// during one calendar day.
+	LimitDailyBudget AllocationLimitScope = "daily_budget"
+)
+
+var AllocationLimitScopes = []AllocationLimitScope{LimitPerRequest, LimitApplicant30Days, LimitApplicant365Days, LimitDailyBudget}
+
+// AllocationLimit is a ceiling on foreign currency allocation. Every criterion
+// left empty matches all requests. A limit with a currency is measured in that
+// currency, otherwise in the birr equivalent of all currencies.
+type AllocationLimit struct {
+	ID              primitive.ObjectID   `json:"_id" bson:"_id,omitempty"`
+	Name            string               `json:"name" bson:"name"`
+	Scope           AllocationLimitScope `json:"scope" bson:"scope"`
+	TravelPurposeID *primitive.ObjectID  `json:"travel_purpose_id,omitempty" bson:"travel_purpose_id,omitempty"`
+	CustomerTypeID  *primitive.ObjectID  `json:"customer_type_id,omitempty" bson:"customer_type_id,omitempty"`
+	RequestingAs    string               `json:"requesting_as,omitempty" bson:"requesting_as,omitempty"`
+	CountryID       *primitive.ObjectID  `json:"country_id,omitempty" bson:"country_id,omitempty"`
+	CurrencyID      *primitive.ObjectID  `json:"currency_id,omitempty" bson:"currency_id,omitempty"`
+	MaxAmount       float64              `json:"max_amount" bson:"max_amount"`
+	IsActive        bool                 `json:"is_active" bson:"is_active"`
+	CreatedAt       time.Time            `json:"created_at" bson:"created_at"`
+	UpdatedAt       time.Time            `json:"updated_at" bson:"updated_at"`
+	CreatedBy       primitive.ObjectID   `json:"created_by" bson:"created_by"`
+	UpdatedBy       *primitive.ObjectID  `json:"updated_by,omitempty" bson:"updated_by,omitempty"`
+	DeletedBy       *primitive.ObjectID  `json:"deleted_by,omitempty" bson:"deleted_by,omitempty"`
+	DeletedAt       *time.Time           `json:"deleted_at,omitempty" bson:"deleted_at,omitempty"`
+	IsDeleted       bool                 `json:"is_deleted" bson:"is_deleted"`
+}
+
+// Matches reports whether the limit applies to the request.
+func (l *AllocationLimit) Matches(request *Request) bool {
+	if l.TravelPurposeID != nil && *l.TravelPurposeID != request.TravelPurposeID {
+		return false
+	}
+	if l.CustomerTypeID != nil && (request.CustomerTypeID == nil || *l.CustomerTypeID != *request.CustomerTypeID) {
+		return false
+	}
+	if l.RequestingAs != "" && l.RequestingAs != request.RequestingAs {
+		return false
+	}
+	if l.CountryID != nil && *l.CountryID != request.TravelCountryID {
+		return false
+	}
+
+	return true
+}
+
+// Measure totals the allocation lines the limit counts. It reports false when
+// none of the lines is in the limit's currency.
+func (l *AllocationLimit) Measure(lines []RequestRateSnapshot) (float64, bool) {
+	total, counted := 0.0, false
+
+	for _, line := range lines {
+		if l.CurrencyID == nil {
+			total += line.EtbEquivalent
+			counted = true
+		} else if line.CurrencyID == *l.CurrencyID {
+			total += line.Amount
+			counted = true
+		}
+	}
+
+	return total, counted
+}
+
+// MeasureUsage totals previously allocated amounts the same way Measure does.
+func (l *AllocationLimit) MeasureUsage(usage []AllocationUsage) float64 {
+	total := 0.0
+
+	for _, u := range usage {
+		if l.CurrencyID == nil {
+			total += u.EtbEquivalent
+		} else if u.CurrencyID == *l.CurrencyID {
+			total += u.Amount
+		}
+	}
+
+	return total
+}
+
+// WindowStart returns the beginning of the period whose earlier allocations
+// count toward the limit, or nil for per-request limits.
+func (l *AllocationLimit) WindowStart(at time.Time) *time.Time {
+	var start time.Time
+
+	switch l.Scope {
+	case LimitApplicant30Days:
+		start = at.AddDate(0, 0, -30)
+	case LimitApplicant365Days:
+		start = at.AddDate(0, 0, -365)
+	case LimitDailyBudget:
+		start = time.Date(at.Year(), at.Month(), at.Day(), 0, 0, 0, 0, at.Location())
+	default:
+		return nil
+	}
+
+	return &start
+}
+
+type CreateAllocationLimitDTO struct {
+	Name            string  `json:"name" binding:"required,min=3,max=100,excludesall=<>"`
+	Scope           string  `json:"scope" binding:"required,oneof=per_request applicant_30d applicant_365d daily_budget"`
+	TravelPurposeID string  `json:"travel_purpose_id" binding:"omitempty,alphanum,len=24"`
+	CustomerTypeID  string  `json:"customer_type_id" binding:"omitempty,alphanum,len=24"`
+	RequestingAs    string  `json:"requesting_as" binding:"omitempty,alphanum"`
+	CountryID       string  `json:"country_id" binding:"omitempty,alphanum,len=24"`
+	CurrencyID      string  `json:"currency_id" binding:"omitempty,alphanum,len=24"`
+	MaxAmount       float64 `json:"max_amount" binding:"required,gt=0"`
+	IsActive        *bool   `json:"is_active"`
+}
+
+type UpdateAllocationLimitDTO = CreateAllocationLimitDTO
+
+// AllocationUsage is the amount already allocated in one currency.
+type AllocationUsage struct {
+	CurrencyID    primitive.ObjectID `json:"currency_id" bson:"_id"`
+	Amount        float64            `json:"amount" bson:"amount"`
+	EtbEquivalent float64            `json:"etb_equivalent" bson:"etb_equivalent"`
+}
+
+// AllocationUsageQuery selects the approved and accepted requests whose
+// allocations count toward a limit.
+type AllocationUsageQuery struct {
+	Since                  time.Time
+	ExcludeRequestID       primitive.ObjectID
+	ApplicantAccountNumber string
+	TravelPurposeID        *primitive.ObjectID
+	CustomerTypeID         *primitive.ObjectID
+	RequestingAs           string
+	CountryID              *primitive.ObjectID
+}
+
+// AllocationViolation explains by how much a decision exceeds a limit. The
+// amounts are in the limit's currency, or in birr when it has none.
+type AllocationViolation struct {
+	LimitID    primitive.ObjectID   `json:"limit_id" bson:"limit_id"`
+	LimitName  string               `json:"limit_name" bson:"limit_name"`
+	Scope      AllocationLimitScope `json:"scope" bson:"scope"`
+	CurrencyID *primitive.ObjectID  `json:"currency_id,omitempty" bson:"currency_id,omitempty"`
+	MaxAmount  float64              `json:"max_amount" bson:"max_amount"`
+	Used       float64              `json:"used" bson:"used"`
+	Requested  float64              `json:"requested" bson:"requested"`
+}
+
+func (v AllocationViolation) String() string {
+	return fmt.Sprintf("%s: %.2f used + %.2f requested exceeds %.2f", v.LimitName, v.Used, v.Requested, v.MaxAmount)
+}
+
+// AllocationLimitError is returned when a decision exceeds one or more
+// allocation limits and was not overridden.
+type AllocationLimitError struct {
+	Violations []AllocationViolation
+}
+
+func (e *AllocationLimitError) Error() string {
+	parts := make([]string, len(e.Violations))
+	for i, v := range e.Violations {
+		parts[i] = v.String()
+	}
+	return "allocation limits exceeded: " + strings.Join(parts, "; ")
+}
+
+func (e *AllocationLimitError) Unwrap() error {
+	return common.ErrAllocationLimitExceeded
+}
+
+// AllocationOverride records a decision that was taken despite limit
+// violations.
+type AllocationOverride struct {
+	Action        RequestAction         `json:"action" bson:"action"`
+	Violations    []AllocationViolation `json:"violations" bson:"violations"`
+	Justification string                `json:"justification" bson:"justification"`
+	OverriddenBy  primitive.ObjectID    `json:"overridden_by" bson:"overridden_by"`
+	OverriddenAt  time.Time             `json:"overridden_at" bson:"overridden_at"`
+}
+
+// AllocationOverrideDTO lets an authorized user take a decision that exceeds
+// allocation limits.
+type AllocationOverrideDTO struct {
+	OverrideLimits        bool   `json:"override_limits"`
+	OverrideJustification string `json:"override_justification" binding:"omitempty,max=500,excludesall=<>"`
+}
+
+type AllocationLimitRepository interface {
+	Create(ctx context.Context, limit *AllocationLimit) error
+	FindByID(ctx context.Context, limitID primitive.ObjectID) (*AllocationLimit, error)
+	FindAll(ctx context.Context) ([]AllocationLimit, error)
+	FindActive(ctx context.Context) ([]AllocationLimit, error)
+	Update(ctx context.Context, limitID primitive.ObjectID, limit *AllocationLimit) error
+	Delete(ctx context.Context, limitID primitive.ObjectID, limit *AllocationLimit) error
+}
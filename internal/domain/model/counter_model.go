@@ -0,0 +1,15 @@
+package model
+
+import "context"
+
+// Counter is a named, monotonically increasing sequence.
+type Counter struct {
+	ID  string `json:"_id" bson:"_id"`
+	Seq int64  `json:"seq" bson:"seq"`
+}
+
+type CounterRepository interface {
+	// Next increments the named counter, creating it on first use, and
+	// returns the new value.
+	Next(ctx context.Context, name string) (int64, error)
+}
@@ -27,6 +27,7 @@ type Currency struct {
 type CurrencyRepository interface {
 	Create(ctx context.Context, currency *Currency) error
 	FindByID(ctx context.Context, currency primitive.ObjectID) (*Currency, error)
+	FindByShortCode(ctx context.Context, shortCode string) (*Currency, error)
 	FindAll(ctx context.Context) ([]Currency, error)
 	Update(ctx context.Context, currency_id primitive.ObjectID, currency *Currency) (*Currency, error)
 	Delete(ctx context.Context, currency_id primitive.ObjectID) error
@@ -0,0 +1,138 @@
+package model
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	ExchangeRateSourceManual = "manual"
+	ExchangeRateSourceUpload = "upload"
+)
+
+// ExchangeRate is the price of one unit of a foreign currency in birr for the
+// period between ValidFrom and ValidTo. A nil ValidTo keeps the rate in force
+// until a newer one is published for the same currency.
+type ExchangeRate struct {
+	ID          primitive.ObjectID  `json:"_id" bson:"_id,omitempty"`
+	CurrencyID  primitive.ObjectID  `json:"currency_id" bson:"currency_id"`
+	Currency    *Currency           `json:"currency,omitempty" bson:"currency,omitempty"`
+	BuyingRate  float64             `json:"buying_rate" bson:"buying_rate"`
+	SellingRate float64             `json:"selling_rate" bson:"selling_rate"`
+	MidRate     float64             `json:"mid_rate" bson:"mid_rate"`
+	ValidFrom   time.Time           `json:"valid_from" bson:"valid_from"`
+	ValidTo     *time.Time          `json:"valid_to,omitempty" bson:"valid_to,omitempty"`
+	Source      string              `json:"source" bson:"source"`
+	PublishedAt time.Time           `json:"published_at" bson:"published_at"`
+	PublishedBy primitive.ObjectID  `json:"published_by" bson:"published_by"`
+	Publisher   *User               `json:"publisher,omitempty" bson:"publisher,omitempty"`
+	CreatedAt   time.Time           `json:"created_at" bson:"created_at"`
+	UpdatedAt   time.Time           `json:"updated_at" bson:"updated_at"`
+	CreatedBy   primitive.ObjectID  `json:"created_by" bson:"created_by"`
+	UpdatedBy   *primitive.ObjectID `json:"updated_by,omitempty" bson:"updated_by,omitempty"`
+	DeletedBy   *primitive.ObjectID `json:"deleted_by,omitempty" bson:"deleted_by,omitempty"`
+	DeletedAt   *time.Time          `json:"deleted_at,omitempty" bson:"deleted_at,omitempty"`
+	IsDeleted   bool                `json:"is_deleted" bson:"is_deleted"`
+}
+
+// IsEffectiveAt reports whether the rate is in force at the given time.
+func (r *ExchangeRate) IsEffectiveAt(at time.Time) bool {
+	if at.Before(r.ValidFrom) {
+		return false
+	}
+	return r.ValidTo == nil || at.Before(*r.ValidTo)
+}
+
+type CreateExchangeRateDTO struct {
+	CurrencyID  string     `json:"currency_id" binding:"required,alphanum,len=24"`
+	BuyingRate  float64    `json:"buying_rate" binding:"required,gt=0"`
+	SellingRate float64    `json:"selling_rate" binding:"required,gt=0"`
+	MidRate     float64    `json:"mid_rate" binding:"omitempty,gt=0"`
+	ValidFrom   *time.Time `json:"valid_from" binding:"omitempty"`
+	ValidTo     *time.Time `json:"valid_to" binding:"omitempty"`
+}
+
+type UpdateExchangeRateDTO struct {
+	BuyingRate  float64    `json:"buying_rate" binding:"required,gt=0"`
+	SellingRate float64    `json:"selling_rate" binding:"required,gt=0"`
+	MidRate     float64    `json:"mid_rate" binding:"omitempty,gt=0"`
+	ValidFrom   *time.Time `json:"valid_from" binding:"omitempty"`
+	ValidTo     *time.Time `json:"valid_to" binding:"omitempty"`
+}
+
+// ExchangeRateRowDTO is one line of a bulk rate upload. The currency is
+// identified by its short code (USD, EUR, ...) so files can be prepared
+// without knowing database IDs.
+type ExchangeRateRowDTO struct {
+	Currency    string     `json:"currency"`
+	BuyingRate  float64    `json:"buying_rate"`
+	SellingRate float64    `json:"selling_rate"`
+	MidRate     float64    `json:"mid_rate"`
+	ValidFrom   *time.Time `json:"valid_from"`
+	ValidTo     *time.Time `json:"valid_to"`
+}
+
+// ExchangeRateRowError describes why a row of a bulk upload was rejected.
+// Row is 1-based and counts data rows only.
+type ExchangeRateRowError struct {
+	Row     int    `json:"row"`
+	Message string `json:"message"`
+}
+
+type ExchangeRateUploadResultDTO struct {
+	Published int                    `json:"published"`
+	Errors    []ExchangeRateRowError `json:"errors,omitempty"`
+}
+
+// ExchangeRateHistoryQueryDTO filters the rate history of a currency.
+type ExchangeRateHistoryQueryDTO struct {
+	CurrencyID string `form:"currency_id" binding:"required,alphanum,len=24"`
+	From       string `form:"from" binding:"omitempty,max=35"`
+	To         string `form:"to" binding:"omitempty,max=35"`
+}
+
+// RequestRateSnapshot freezes the rate used for one currency of a request at
+// the moment it was approved or accepted, so later rate changes do not alter
+// the birr value of a decision that was already taken.
+type RequestRateSnapshot struct {
+	CurrencyID    primitive.ObjectID `json:"currency_id" bson:"currency_id"`
+	RateID        primitive.ObjectID `json:"rate_id" bson:"rate_id"`
+	BuyingRate    float64            `json:"buying_rate" bson:"buying_rate"`
+	SellingRate   float64            `json:"selling_rate" bson:"selling_rate"`
+	MidRate       float64            `json:"mid_rate" bson:"mid_rate"`
+	ValidFrom     time.Time          `json:"valid_from" bson:"valid_from"`
+	Amount        float64            `json:"amount" bson:"amount"`
+	EtbEquivalent float64            `json:"etb_equivalent" bson:"etb_equivalent"`
+	CapturedAt    time.Time          `json:"captured_at" bson:"captured_at"`
+}
+
+// NewRequestRateSnapshot prices an amount of foreign currency at the rate's
+// selling rate, which is what the bank charges the applicant.
+func NewRequestRateSnapshot(rate *ExchangeRate, amount float64, at time.Time) RequestRateSnapshot {
+	return RequestRateSnapshot{
+		CurrencyID:    rate.CurrencyID,
+		RateID:        rate.ID,
+		BuyingRate:    rate.BuyingRate,
+		SellingRate:   rate.SellingRate,
+		MidRate:       rate.MidRate,
+		ValidFrom:     rate.ValidFrom,
+		Amount:        amount,
+		EtbEquivalent: amount * rate.SellingRate,
+		CapturedAt:    at,
+	}
+}
+
+type ExchangeRateRepository interface {
+	Create(ctx context.Context, rate *ExchangeRate) error
+	CreateMany(ctx context.Context, rates []ExchangeRate) error
+	FindByID(ctx context.Context, rateID primitive.ObjectID) (*ExchangeRate, error)
+	FindEffective(ctx context.Context, currencyID primitive.ObjectID, at time.Time) (*ExchangeRate, error)
+	FindCurrent(ctx context.Context, at time.Time) ([]ExchangeRate, error)
+	FindHistory(ctx context.Context, currencyID primitive.ObjectID, from *time.Time, to *time.Time) ([]ExchangeRate, error)
+	CloseOpenRates(ctx context.Context, currencyID primitive.ObjectID, at time.Time) error
+	MovePredecessorEnd(ctx context.Context, currencyID primitive.ObjectID, rateID primitive.ObjectID, from time.Time, to *time.Time) error
+	Update(ctx context.Context, rateID primitive.ObjectID, rate *ExchangeRate) error
+	Delete(ctx context.Context, rateID primitive.ObjectID, rate *ExchangeRate) error
+}
@@ -18,7 +18,32 @@ type File struct {
 	UpdatedAt time.Time          `json:"updated_at" bson:"updated_at"`
 }
 
+// RequestAttachmentFields are the request fields that reference uploaded
+// files. A file none of them references belongs to no request.
+var RequestAttachmentFields = []string{
+	"passport_attachment",
+	"ticket_attachment",
+	"visa_attachment",
+	"business_license_attachment",
+	"education_loa_attachment",
+	"health_letter_attachment",
+	"business_supporting_attachment",
+}
+
+// FileSweep reports what one sweep of orphaned uploads removed: records no
+// request references and files on disk that have no record.
+type FileSweep struct {
+	Records int `json:"records"`
+	Files   int `json:"files"`
+}
+
 type FileRepository interface {
 	Create(ctx context.Context, file *File) (*primitive.ObjectID, error)
 	FindByID(ctx context.Context, file_id primitive.ObjectID) (*File, error)
+	// FindUnreferenced lists up to limit files created before createdBefore
+	// that no request references, now or in its history.
+	FindUnreferenced(ctx context.Context, createdBefore time.Time, limit int64) ([]File, error)
+	// FindNames returns which of names have a file record.
+	FindNames(ctx context.Context, names []string) (map[string]bool, error)
+	Delete(ctx context.Context, fileID primitive.ObjectID) error
 }
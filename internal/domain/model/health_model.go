@@ -0,0 +1,44 @@
+package model
+
+import (
+	"context"
+	"time"
+)
+
+type ReadinessStatus string
+
+const (
+	// ReadinessOK means every dependency answered.
+	ReadinessOK ReadinessStatus = "ok"
+	// ReadinessDegraded means an optional dependency failed. The server still
+	// takes traffic; the features needing it fail or wait until it is back.
+	ReadinessDegraded ReadinessStatus = "degraded"
+	// ReadinessUnavailable means a required dependency failed or the server
+	// is shutting down, so it should be taken out of rotation.
+	ReadinessUnavailable ReadinessStatus = "unavailable"
+)
+
+// HealthCheckResult is the outcome of checking one dependency.
+type HealthCheckResult struct {
+	Name     string  `json:"name"`
+	Required bool    `json:"required"`
+	Healthy  bool    `json:"healthy"`
+	Error    string  `json:"error,omitempty"`
+	Duration float64 `json:"duration_ms"`
+}
+
+type ReadinessReport struct {
+	Status    ReadinessStatus     `json:"status"`
+	Draining  bool                `json:"draining,omitempty"`
+	Checks    []HealthCheckResult `json:"checks"`
+	CheckedAt time.Time           `json:"checked_at"`
+}
+
+// Ready reports whether the server should receive traffic.
+func (r *ReadinessReport) Ready() bool {
+	return r.Status != ReadinessUnavailable
+}
+
+type ReadinessChecker interface {
+	Check(ctx context.Context) *ReadinessReport
+}
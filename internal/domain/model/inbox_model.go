@@ -0,0 +1,106 @@
+package model
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// InboxItem is one entry of a user's in-app notification inbox. It is written
+// together with the request change it announces and pushed live to the
+// user's open streams. ActorID is nil for items raised by the system.
+type InboxItem struct {
+	ID          primitive.ObjectID  `json:"_id" bson:"_id,omitempty"`
+	UserID      primitive.ObjectID  `json:"user_id" bson:"user_id"`
+	RequestID   primitive.ObjectID  `json:"request_id" bson:"request_id"`
+	RequestCode string              `json:"request_code" bson:"request_code"`
+	Event       NotificationEvent   `json:"event" bson:"event"`
+	Title       string              `json:"title" bson:"title"`
+	Status      RequestStatus       `json:"status" bson:"status"`
+	ActorID     *primitive.ObjectID `json:"actor_id,omitempty" bson:"actor_id,omitempty"`
+	ActorName   string              `json:"actor_name,omitempty" bson:"actor_name,omitempty"`
+	ReadAt      *time.Time          `json:"read_at,omitempty" bson:"read_at,omitempty"`
+	CreatedAt   time.Time           `json:"created_at" bson:"created_at"`
+}
+
+// InboxQuery pages through a user's inbox from the newest item backwards.
+type InboxQuery struct {
+	UnreadOnly bool                `form:"unread"`
+	Before     *primitive.ObjectID `form:"-"`
+	Limit      int64               `form:"limit"`
+}
+
+const (
+	DefaultInboxLimit int64 = 20
+	MaxInboxLimit     int64 = 100
+)
+
+// Normalize applies the default page size and caps it.
+func (q *InboxQuery) Normalize() {
+	if q.Limit <= 0 {
+		q.Limit = DefaultInboxLimit
+	}
+	if q.Limit > MaxInboxLimit {
+		q.Limit = MaxInboxLimit
+	}
+}
+
+// InboxPage is one page of a user's inbox. NextBefore is passed as before to
+// fetch the following page and is empty on the last one.
+type InboxPage struct {
+	Items       []InboxItem         `json:"items"`
+	UnreadCount int64               `json:"unread_count"`
+	NextBefore  *primitive.ObjectID `json:"next_before,omitempty"`
+}
+
+type InboxReadDTO struct {
+	IDs []primitive.ObjectID `json:"ids" binding:"required,min=1"`
+}
+
+type InboxRepository interface {
+	CreateMany(ctx context.Context, items []*InboxItem) error
+	FindByUser(ctx context.Context, userID primitive.ObjectID, query *InboxQuery) ([]InboxItem, error)
+	CountUnread(ctx context.Context, userID primitive.ObjectID) (int64, error)
+	// MarkRead marks the given items of the user read and returns how many
+	// were unread.
+	MarkRead(ctx context.Context, userID primitive.ObjectID, itemIDs []primitive.ObjectID, at time.Time) (int64, error)
+	MarkAllRead(ctx context.Context, userID primitive.ObjectID, at time.Time) (int64, error)
+	// Watch calls deliver with every item added to any inbox until ctx is
+	// done or the watch fails.
+	Watch(ctx context.Context, deliver func(item *InboxItem)) error
+}
+
+// InboxBroker fans new inbox items out to the streams users have open.
+type InboxBroker interface {
+	// Subscribe returns the items added to the user's inbox from now on and
+	// a function that ends the subscription.
+	Subscribe(userID primitive.ObjectID) (<-chan InboxItem, func())
+}
+
+// StreamTicketTTL is how long a stream ticket may wait to be redeemed.
+const StreamTicketTTL = 30 * time.Second
+
+// StreamTicket lets a client that cannot set headers, such as a browser
+// EventSource, open its inbox stream without putting its access token in the
+// URL. Tickets are issued to authenticated users, expire within seconds and
+// are redeemed once, so one showing up in a log is of no use. Only a hash of
+// the ticket is stored.
+type StreamTicket struct {
+	Hash      string             `bson:"_id"`
+	UserID    primitive.ObjectID `bson:"user_id"`
+	SessionID primitive.ObjectID `bson:"session_id"`
+	ExpiresAt time.Time          `bson:"expires_at"`
+}
+
+type StreamTicketDTO struct {
+	Ticket    string    `json:"ticket"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+type StreamTicketRepository interface {
+	Create(ctx context.Context, ticket *StreamTicket) error
+	// Redeem removes the ticket with the given hash and returns it, or nil
+	// when there is none or it expired at at.
+	Redeem(ctx context.Context, hash string, at time.Time) (*StreamTicket, error)
+}
@@ -0,0 +1,107 @@
+package model
+
+import (
+	"context"
+	"net/netip"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// IPBindingMode decides how closely the client using a token must match the
+// client it was issued to.
+type IPBindingMode string
+
+const (
+	// IPBindingStrict requires the very same IP address.
+	IPBindingStrict IPBindingMode = "strict"
+	// IPBindingSubnet requires the same /24 network, or /64 for IPv6, so a
+	// client moving between addresses of one network stays logged in.
+	IPBindingSubnet IPBindingMode = "subnet"
+	// IPBindingDevice requires the same device fingerprint from any network,
+	// for mobile users changing networks. The fingerprint is built from
+	// headers the client sends, so whoever holds a stolen token can copy
+	// them: this mode only stops accidental reuse and should not be used for
+	// privileged roles, which belong on strict or subnet.
+	IPBindingDevice IPBindingMode = "device"
+	IPBindingOff    IPBindingMode = "off"
+)
+
+var IPBindingModes = []IPBindingMode{IPBindingStrict, IPBindingSubnet, IPBindingDevice, IPBindingOff}
+
+// ClientInfo describes the client that sent a request. Tokens record the IP
+// and fingerprint of the client they were issued to.
+type ClientInfo struct {
+	IP        string
+	UserAgent string
+	// Fingerprint identifies the device from its request headers.
+	Fingerprint string
+}
+
+// IPBindingPolicy picks the binding mode of a token from its user's role.
+type IPBindingPolicy struct {
+	Default IPBindingMode
+	// Roles overrides Default for the roles named, in upper case.
+	Roles map[string]IPBindingMode
+}
+
+func (p IPBindingPolicy) ModeFor(role string) IPBindingMode {
+	if mode, ok := p.Roles[strings.ToUpper(role)]; ok {
+		return mode
+	}
+	return p.Default
+}
+
+// Matches reports whether a token issued to the client at boundIP with
+// boundFingerprint may be used by client. An unknown mode is as strict as
+// IPBindingStrict.
+func (mode IPBindingMode) Matches(boundIP string, boundFingerprint string, client ClientInfo) bool {
+	switch mode {
+	case IPBindingOff:
+		return true
+	case IPBindingDevice:
+		return boundFingerprint != "" && boundFingerprint == client.Fingerprint
+	case IPBindingSubnet:
+		return sameSubnet(boundIP, client.IP)
+	default:
+		return sameIP(boundIP, client.IP)
+	}
+}
+
+func sameIP(a string, b string) bool {
+	addrA, errA := netip.ParseAddr(a)
+	addrB, errB := netip.ParseAddr(b)
+	if errA != nil || errB != nil {
+		return false
+	}
+
+	return addrA.Unmap() == addrB.Unmap()
+}
+
+func sameSubnet(a string, b string) bool {
+	addrA, errA := netip.ParseAddr(a)
+	addrB, errB := netip.ParseAddr(b)
+	if errA != nil || errB != nil {
+		return false
+	}
+	addrA, addrB = addrA.Unmap(), addrB.Unmap()
+	if addrA.Is4() != addrB.Is4() {
+		return false
+	}
+
+	bits := 64
+	if addrA.Is4() {
+		bits = 24
+	}
+	prefixA, _ := addrA.Prefix(bits)
+	prefixB, _ := addrB.Prefix(bits)
+
+	return prefixA == prefixB
+}
+
+// IPBindingGuard enforces the IP binding policy on every use of a token.
+type IPBindingGuard interface {
+	// Verify refuses, and records a security event, when client may not use
+	// a token of userID's session issued to boundIP and boundFingerprint.
+	Verify(ctx context.Context, role string, userID primitive.ObjectID, sessionID primitive.ObjectID, boundIP string, boundFingerprint string, client ClientInfo) error
+}
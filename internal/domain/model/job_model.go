@@ -0,0 +1,115 @@
+package model
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type JobStatus string
+
+const (
+	JobRunning   JobStatus = "running"
+	JobSucceeded JobStatus = "succeeded"
+	JobFailed    JobStatus = "failed"
+	// JobCancelled was stopped by the server shutting down or by losing its
+	// lease.
+	JobCancelled JobStatus = "cancelled"
+)
+
+type JobTrigger string
+
+const (
+	JobTriggerSchedule JobTrigger = "schedule"
+	JobTriggerManual   JobTrigger = "manual"
+)
+
+// Job is the state of a background job shared by every server instance. The
+// instance running it holds a lease on the document that it keeps renewing;
+// when that instance dies the lease runs out and another one takes over.
+type Job struct {
+	Name        string              `json:"name" bson:"_id"`
+	Schedule    string              `json:"schedule" bson:"schedule"`
+	Paused      bool                `json:"paused" bson:"paused"`
+	PausedBy    *primitive.ObjectID `json:"paused_by,omitempty" bson:"paused_by,omitempty"`
+	PausedAt    *time.Time          `json:"paused_at,omitempty" bson:"paused_at,omitempty"`
+	NextRunAt   time.Time           `json:"next_run_at" bson:"next_run_at"`
+	LastRunAt   *time.Time          `json:"last_run_at,omitempty" bson:"last_run_at,omitempty"`
+	LastStatus  JobStatus           `json:"last_status,omitempty" bson:"last_status,omitempty"`
+	LeaseHolder string              `json:"lease_holder,omitempty" bson:"lease_holder,omitempty"`
+	LeaseUntil  *time.Time          `json:"lease_until,omitempty" bson:"lease_until,omitempty"`
+	UpdatedAt   time.Time           `json:"updated_at" bson:"updated_at"`
+}
+
+// IsRunning reports whether an instance holds an unexpired lease on the job.
+func (j *Job) IsRunning(now time.Time) bool {
+	return j.LeaseUntil != nil && j.LeaseUntil.After(now)
+}
+
+// JobRun is one run of a job, kept as its history.
+type JobRun struct {
+	ID          primitive.ObjectID  `json:"_id" bson:"_id,omitempty"`
+	Job         string              `json:"job" bson:"job"`
+	Trigger     JobTrigger          `json:"trigger" bson:"trigger"`
+	TriggeredBy *primitive.ObjectID `json:"triggered_by,omitempty" bson:"triggered_by,omitempty"`
+	Instance    string              `json:"instance" bson:"instance"`
+	Status      JobStatus           `json:"status" bson:"status"`
+	Summary     string              `json:"summary,omitempty" bson:"summary,omitempty"`
+	Error       string              `json:"error,omitempty" bson:"error,omitempty"`
+	StartedAt   time.Time           `json:"started_at" bson:"started_at"`
+	FinishedAt  *time.Time          `json:"finished_at,omitempty" bson:"finished_at,omitempty"`
+}
+
+// JobRunQuery pages through the runs of a job from the newest backwards.
+type JobRunQuery struct {
+	Limit int64 `form:"limit"`
+}
+
+const (
+	DefaultJobRunLimit int64 = 20
+	MaxJobRunLimit     int64 = 100
+)
+
+// Normalize applies the default page size and caps it.
+func (q *JobRunQuery) Normalize() {
+	if q.Limit <= 0 {
+		q.Limit = DefaultJobRunLimit
+	}
+	if q.Limit > MaxJobRunLimit {
+		q.Limit = MaxJobRunLimit
+	}
+}
+
+type JobRepository interface {
+	// Register records a job the first time an instance runs it and moves
+	// its next run to next whenever its schedule changed.
+	Register(ctx context.Context, name string, schedule string, next time.Time) error
+	FindAll(ctx context.Context) ([]Job, error)
+	FindByName(ctx context.Context, name string) (*Job, error)
+	// ClaimDue leases the job to holder until until when it is due at now,
+	// not paused and not running, and moves its next run to next. It reports
+	// whether the lease was taken; only one instance takes it.
+	ClaimDue(ctx context.Context, name string, holder string, now time.Time, until time.Time, next time.Time) (bool, error)
+	// Claim leases the job to holder unless it is running.
+	Claim(ctx context.Context, name string, holder string, now time.Time, until time.Time) (bool, error)
+	// RenewLease extends the lease of holder and reports whether it still
+	// held it.
+	RenewLease(ctx context.Context, name string, holder string, until time.Time) (bool, error)
+	// ReleaseLease ends the lease of holder and records how the run ended.
+	ReleaseLease(ctx context.Context, name string, holder string, finishedAt time.Time, status JobStatus) error
+	SetPaused(ctx context.Context, name string, paused bool, userID primitive.ObjectID, at time.Time) error
+}
+
+type JobRunRepository interface {
+	Create(ctx context.Context, run *JobRun) error
+	Finish(ctx context.Context, run *JobRun) error
+	FindByJob(ctx context.Context, name string, limit int64) ([]JobRun, error)
+}
+
+// JobRunner starts jobs outside their schedule.
+type JobRunner interface {
+	// Trigger runs the job now on this instance unless it is already
+	// running on any of them.
+	Trigger(ctx context.Context, name string, triggeredBy primitive.ObjectID) (*JobRun, error)
+}
@@ -0,0 +1,161 @@
+package model
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type NotificationChannel string
+
+const (
+	NotificationChannelEmail NotificationChannel = "email"
+)
+
+type NotificationStatus string
+
+const (
+	// NotificationPending is waiting for its first delivery attempt.
+	NotificationPending NotificationStatus = "pending"
+	// NotificationSending has been claimed by a dispatcher. A claim whose
+	// lease ran out is picked up again, so a crash mid-send is retried.
+	NotificationSending NotificationStatus = "sending"
+	NotificationSent    NotificationStatus = "sent"
+	// NotificationFailed failed at least once and is waiting for a retry.
+	NotificationFailed NotificationStatus = "failed"
+	// NotificationDead ran out of attempts and is only retried by hand.
+	NotificationDead NotificationStatus = "dead"
+)
+
+type NotificationEvent string
+
+const (
+	NotifyRequestSent       NotificationEvent = "request_sent"
+	NotifyRequestAuthorized NotificationEvent = "request_authorized"
+	NotifyRequestValidated  NotificationEvent = "request_validated"
+	NotifyRequestApproved   NotificationEvent = "request_approved"
+	NotifyRequestRejected   NotificationEvent = "request_rejected"
+	NotifyRequestAccepted   NotificationEvent = "request_accepted"
+	NotifyRequestDeclined   NotificationEvent = "request_declined"
+	// NotifyRequestDueSoon reminds whoever must act that a request is
+	// about to breach its SLA.
+	NotifyRequestDueSoon NotificationEvent = "request_due_soon"
+	// NotifyRequestOverdue escalates a request that breached its SLA.
+	NotifyRequestOverdue NotificationEvent = "request_overdue"
+	// NotifyRequestExpired announces an approval declined by the system
+	// because it was not accepted in time.
+	NotifyRequestExpired NotificationEvent = "request_expired"
+)
+
+// NotificationEvents lists every event that has templates of its own.
+var NotificationEvents = []NotificationEvent{
+	NotifyRequestSent,
+	NotifyRequestAuthorized,
+	NotifyRequestValidated,
+	NotifyRequestApproved,
+	NotifyRequestRejected,
+	NotifyRequestAccepted,
+	NotifyRequestDeclined,
+	NotifyRequestDueSoon,
+	NotifyRequestOverdue,
+	NotifyRequestExpired,
+}
+
+// NotificationEventStatus is the status a request is in once event happened.
+// Reminders and escalations leave the status as it is; their sample requests
+// wait for validation.
+var NotificationEventStatus = map[NotificationEvent]RequestStatus{
+	NotifyRequestSent:       ReqStatusNew,
+	NotifyRequestAuthorized: ReqStatusAuthorized,
+	NotifyRequestValidated:  ReqStatusValidated,
+	NotifyRequestApproved:   ReqStatusApproved,
+	NotifyRequestRejected:   ReqStatusRejected,
+	NotifyRequestAccepted:   ReqStatusAccepted,
+	NotifyRequestDeclined:   ReqStatusDeclined,
+	NotifyRequestDueSoon:    ReqStatusAuthorized,
+	NotifyRequestOverdue:    ReqStatusAuthorized,
+	NotifyRequestExpired:    ReqStatusDeclined,
+}
+
+// Notification is one message in the outbox. It is stored together with the
+// change it announces and delivered later by the dispatcher, so a mail server
+// outage delays notifications instead of losing them.
+type Notification struct {
+	ID          primitive.ObjectID  `json:"_id" bson:"_id,omitempty"`
+	RequestID   primitive.ObjectID  `json:"request_id" bson:"request_id"`
+	RequestCode string              `json:"request_code" bson:"request_code"`
+	Event       NotificationEvent   `json:"event" bson:"event"`
+	Channel     NotificationChannel `json:"channel" bson:"channel"`
+	To          []string            `json:"to" bson:"to"`
+	Cc          []string            `json:"cc,omitempty" bson:"cc,omitempty"`
+	Bcc         []string            `json:"bcc,omitempty" bson:"bcc,omitempty"`
+	Subject     string              `json:"subject" bson:"subject"`
+	Body        string              `json:"-" bson:"body"`
+	TextBody    string              `json:"-" bson:"text_body,omitempty"`
+
+	Status        NotificationStatus `json:"status" bson:"status"`
+	Attempts      int                `json:"attempts" bson:"attempts"`
+	NextAttemptAt time.Time          `json:"next_attempt_at" bson:"next_attempt_at"`
+	LeaseUntil    *time.Time         `json:"-" bson:"lease_until,omitempty"`
+	LastError     string             `json:"last_error,omitempty" bson:"last_error,omitempty"`
+	SentAt        *time.Time         `json:"sent_at,omitempty" bson:"sent_at,omitempty"`
+	DeadAt        *time.Time         `json:"dead_at,omitempty" bson:"dead_at,omitempty"`
+
+	CreatedBy *primitive.ObjectID `json:"created_by,omitempty" bson:"created_by,omitempty"`
+	TraceID   string              `json:"trace_id,omitempty" bson:"trace_id,omitempty"`
+	CreatedAt time.Time           `json:"created_at" bson:"created_at"`
+	UpdatedAt time.Time           `json:"updated_at" bson:"updated_at"`
+}
+
+// Recipients lists every address the notification goes to.
+func (n *Notification) Recipients() []string {
+	return append(append(append([]string{}, n.To...), n.Cc...), n.Bcc...)
+}
+
+// NotificationRetryPolicy spaces out delivery attempts with exponential
+// backoff and decides when a notification is given up on.
+type NotificationRetryPolicy struct {
+	MaxAttempts int
+	BaseDelay   time.Duration
+	MaxDelay    time.Duration
+}
+
+// Backoff is the delay before the attempt following attempt number attempts:
+// BaseDelay after the first failure, doubling after each one up to MaxDelay.
+func (p NotificationRetryPolicy) Backoff(attempts int) time.Duration {
+	delay := p.BaseDelay
+	for i := 1; i < attempts; i++ {
+		delay *= 2
+		if delay >= p.MaxDelay {
+			return p.MaxDelay
+		}
+	}
+
+	return min(delay, p.MaxDelay)
+}
+
+// Exhausted reports whether a notification that has failed attempts times
+// should be dead-lettered.
+func (p NotificationRetryPolicy) Exhausted(attempts int) bool {
+	return attempts >= p.MaxAttempts
+}
+
+// Notifier delivers notifications over one channel.
+type Notifier interface {
+	Channel() NotificationChannel
+	Notify(ctx context.Context, notification *Notification) error
+}
+
+type NotificationRepository interface {
+	Create(ctx context.Context, notification *Notification) error
+	FindByID(ctx context.Context, notificationID primitive.ObjectID) (*Notification, error)
+	FindByRequestID(ctx context.Context, requestID primitive.ObjectID) ([]Notification, error)
+	// ClaimDue marks the oldest notification that is due for delivery as
+	// being sent until leaseUntil and returns it, or nil when none is due.
+	ClaimDue(ctx context.Context, now time.Time, leaseUntil time.Time) (*Notification, error)
+	// SaveDelivery stores the outcome of a delivery attempt.
+	SaveDelivery(ctx context.Context, notification *Notification) error
+	// Requeue schedules a dead notification for another round of attempts.
+	Requeue(ctx context.Context, notificationID primitive.ObjectID, at time.Time) error
+}
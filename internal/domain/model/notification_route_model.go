@@ -0,0 +1,79 @@
+package model
+
+// NotificationRecipient names a group of people worked out from the request
+// itself rather than configured addresses.
+type NotificationRecipient string
+
+const (
+	// RecipientBranch is the mailbox of the branch that raised the request.
+	RecipientBranch NotificationRecipient = "branch"
+	// RecipientRequester is the user who sent the request.
+	RecipientRequester NotificationRecipient = "requester"
+	// RecipientNextStep is every user who may take the request further.
+	RecipientNextStep NotificationRecipient = "next_step"
+	// RecipientEscalation is every supervisor of the step the request is
+	// waiting for, that is the holders of RequestEscalationPermission.
+	RecipientEscalation NotificationRecipient = "escalation"
+)
+
+// RequestEscalationPermission marks the supervisors told about overdue
+// requests.
+const RequestEscalationPermission = "request:escalation"
+
+// NotificationRoute is who hears about one event: the configured addresses
+// plus the dynamic recipients resolved for each request.
+type NotificationRoute struct {
+	To      []string
+	Cc      []string
+	Bcc     []string
+	Dynamic []NotificationRecipient
+}
+
+type NotificationRoutes map[NotificationEvent]NotificationRoute
+
+// DefaultNotificationRecipients are the dynamic recipients of each event
+// unless configured otherwise. The branch and requester hear about every
+// outcome; the next step only about requests waiting for it.
+var DefaultNotificationRecipients = map[NotificationEvent][]NotificationRecipient{
+	NotifyRequestSent:       {RecipientBranch, RecipientRequester, RecipientNextStep},
+	NotifyRequestAuthorized: {RecipientBranch, RecipientRequester, RecipientNextStep},
+	NotifyRequestValidated:  {RecipientNextStep},
+	NotifyRequestApproved:   {RecipientBranch, RecipientRequester, RecipientNextStep},
+	NotifyRequestRejected:   {RecipientBranch, RecipientRequester},
+	NotifyRequestAccepted:   {RecipientBranch, RecipientRequester},
+	NotifyRequestDeclined:   {RecipientBranch, RecipientRequester},
+	NotifyRequestDueSoon:    {RecipientNextStep},
+	NotifyRequestOverdue:    {RecipientNextStep, RecipientEscalation},
+	NotifyRequestExpired:    {RecipientBranch, RecipientRequester},
+}
+
+// InboxRecipients are who finds each event in their in-app inbox. The actor
+// never gets their own action.
+var InboxRecipients = map[NotificationEvent][]NotificationRecipient{
+	NotifyRequestSent:       {RecipientRequester, RecipientNextStep},
+	NotifyRequestAuthorized: {RecipientRequester, RecipientNextStep},
+	NotifyRequestValidated:  {RecipientRequester, RecipientNextStep},
+	NotifyRequestApproved:   {RecipientRequester, RecipientNextStep},
+	NotifyRequestRejected:   {RecipientRequester},
+	NotifyRequestAccepted:   {RecipientRequester},
+	NotifyRequestDeclined:   {RecipientRequester},
+	NotifyRequestDueSoon:    {RecipientNextStep},
+	NotifyRequestOverdue:    {RecipientNextStep, RecipientEscalation},
+	NotifyRequestExpired:    {RecipientRequester},
+}
+
+// NotificationNextStep is the permission that takes a request further from
+// a status. Steps taken within the branch or department only go to users of
+// the request's own branch or department.
+type NotificationNextStep struct {
+	Permission string
+	WithinOrg  bool
+}
+
+// RequestNextSteps are the steps requests wait for in each status.
+var RequestNextSteps = map[RequestStatus]NotificationNextStep{
+	ReqStatusNew:        {Permission: RequestTransitions[ReqActionAuthorize].Permission, WithinOrg: true},
+	ReqStatusAuthorized: {Permission: RequestTransitions[ReqActionValidate].Permission},
+	ReqStatusValidated:  {Permission: RequestTransitions[ReqActionApprove].Permission},
+	ReqStatusApproved:   {Permission: RequestTransitions[ReqActionAccept].Permission, WithinOrg: true},
+}
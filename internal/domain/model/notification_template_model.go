@@ -0,0 +1,142 @@
+package model
+
+import (
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// NotificationAmount is one currency line of an approval or acceptance.
+type NotificationAmount struct {
+	Currency string  `json:"currency"`
+	Amount   float64 `json:"amount"`
+	InCash   float64 `json:"in_cash"`
+	InCard   float64 `json:"in_card"`
+}
+
+// NotificationData is what the templates of a request notification are
+// rendered with.
+type NotificationData struct {
+	Event             NotificationEvent    `json:"event"`
+	RequestCode       string               `json:"request_code"`
+	ApplicantName     string               `json:"applicant_name"`
+	BranchName        string               `json:"branch_name"`
+	DepartmentName    string               `json:"department_name"`
+	Status            RequestStatus        `json:"status"`
+	RequestedCurrency string               `json:"requested_currency"`
+	RequestedAmount   float64              `json:"requested_amount"`
+	Approved          []NotificationAmount `json:"approved,omitempty"`
+	Accepted          []NotificationAmount `json:"accepted,omitempty"`
+	RejectionReason   string               `json:"rejection_reason,omitempty"`
+	DueDate           *time.Time           `json:"due_date,omitempty"`
+	ActorName         string               `json:"actor_name"`
+	At                time.Time            `json:"at"`
+}
+
+// RenderedNotification is the content of a notification produced from its
+// event's templates.
+type RenderedNotification struct {
+	Subject string `json:"subject"`
+	HTML    string `json:"html"`
+	Text    string `json:"text"`
+}
+
+// NotificationRenderer turns notification data into message content.
+type NotificationRenderer interface {
+	Render(event NotificationEvent, data *NotificationData) (*RenderedNotification, error)
+}
+
+// NewNotificationData collects what the templates of event need from request.
+// currencyCodes maps currency IDs to their short codes. actor is nil for
+// notifications raised by the system itself.
+func NewNotificationData(event NotificationEvent, request *Request, actor *User, currencyCodes map[primitive.ObjectID]string, at time.Time) *NotificationData {
+	data := &NotificationData{
+		Event:             event,
+		RequestCode:       request.RequestCode,
+		ApplicantName:     request.ApplicantName,
+		BranchName:        "N/A",
+		DepartmentName:    "N/A",
+		Status:            request.RequestStatus,
+		RequestedCurrency: currencyCodes[request.FcyRequestedID],
+		RequestedAmount:   request.FcyRequestedAmount,
+		Approved:          notificationAmounts(request.ApprovedCurrencyIDs, request.ApprovedAmounts, request.ApprovedAmountInCash, request.ApprovedAmountInCard, currencyCodes),
+		Accepted:          notificationAmounts(request.AcceptedCurrencyIDs, request.AcceptedAmounts, request.AcceptedAmountInCash, request.AcceptedAmountInCard, currencyCodes),
+		RejectionReason:   request.RejectionReason,
+		DueDate:           request.DueDate,
+		ActorName:         UserDisplayName(actor),
+		At:                at,
+	}
+
+	if request.Branch != nil {
+		data.BranchName = request.Branch.Name
+	}
+	if request.Department != nil {
+		data.DepartmentName = request.Department.Name
+	}
+
+	return data
+}
+
+func notificationAmounts(currencyIDs []primitive.ObjectID, amounts, inCash, inCard []float64, currencyCodes map[primitive.ObjectID]string) []NotificationAmount {
+	at := func(values []float64, i int) float64 {
+		if i < len(values) {
+			return values[i]
+		}
+		return 0
+	}
+
+	lines := make([]NotificationAmount, 0, len(currencyIDs))
+	for i, currencyID := range currencyIDs {
+		lines = append(lines, NotificationAmount{
+			Currency: currencyCodes[currencyID],
+			Amount:   at(amounts, i),
+			InCash:   at(inCash, i),
+			InCard:   at(inCard, i),
+		})
+	}
+
+	return lines
+}
+
+// UserDisplayName is how user is named to other people, falling back from
+// the profile's display name to the full name and then the username.
+func UserDisplayName(user *User) string {
+	if user == nil {
+		return ""
+	}
+
+	if user.Profile != nil {
+		if user.Profile.DisplayName != "" {
+			return user.Profile.DisplayName
+		}
+		if name := strings.TrimSpace(user.Profile.FirstName + " " + user.Profile.LastName); name != "" {
+			return name
+		}
+	}
+
+	return user.Username
+}
+
+// SampleNotificationData is placeholder data used to check templates when
+// they are loaded and to preview them without a real request.
+func SampleNotificationData(event NotificationEvent) *NotificationData {
+	dueDate := time.Date(2026, 1, 3, 9, 30, 0, 0, time.UTC)
+
+	return &NotificationData{
+		Event:             event,
+		RequestCode:       "REQ-HO-2026-000001",
+		ApplicantName:     "Abebe Kebede",
+		BranchName:        "Sample Branch",
+		DepartmentName:    "N/A",
+		Status:            NotificationEventStatus[event],
+		RequestedCurrency: "USD",
+		RequestedAmount:   5000,
+		Approved:          []NotificationAmount{{Currency: "USD", Amount: 4000, InCash: 1000, InCard: 3000}},
+		Accepted:          []NotificationAmount{{Currency: "USD", Amount: 4000, InCash: 1000, InCard: 3000}},
+		RejectionReason:   "Supporting documents are missing",
+		DueDate:           &dueDate,
+		ActorName:         "Sample User",
+		At:                time.Date(2026, 1, 2, 9, 30, 0, 0, time.UTC),
+	}
+}
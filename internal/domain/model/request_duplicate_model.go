@@ -0,0 +1,104 @@
+package model
+
+import (
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/latiiLA/coop-forex-server/internal/common"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type DuplicatePolicyMode string
+
+const (
+	DuplicatePolicyOff   DuplicatePolicyMode = "off"
+	DuplicatePolicyFlag  DuplicatePolicyMode = "flag"
+	DuplicatePolicyBlock DuplicatePolicyMode = "block"
+)
+
+// DuplicatePolicy controls how requests for an applicant that already has
+// open or recent requests are treated when they are added and sent.
+type DuplicatePolicy struct {
+	Mode DuplicatePolicyMode
+	// Lookback is how far back finished requests still count as recent.
+	Lookback time.Duration
+	// NameThreshold is the similarity, between 0 and 1, from which two
+	// applicant names are considered the same person.
+	NameThreshold float64
+	// VelocityMax is the number of requests one account may file within the
+	// lookback before it is treated as a duplicate on its own.
+	VelocityMax int
+}
+
+type RequestDuplicateReason string
+
+const (
+	DuplicateSameAccount RequestDuplicateReason = "same_account"
+	DuplicateSimilarName RequestDuplicateReason = "similar_name"
+)
+
+// RequestDuplicateLink points at another request that appears to be for the
+// same applicant.
+type RequestDuplicateLink struct {
+	RequestID              primitive.ObjectID     `json:"request_id" bson:"request_id"`
+	RequestCode            string                 `json:"request_code" bson:"request_code"`
+	ApplicantName          string                 `json:"applicant_name" bson:"applicant_name"`
+	ApplicantAccountNumber string                 `json:"applicant_account_number" bson:"applicant_account_number"`
+	RequestStatus          RequestStatus          `json:"request_status" bson:"request_status"`
+	BranchID               *primitive.ObjectID    `json:"branch_id,omitempty" bson:"branch_id,omitempty"`
+	DepartmentID           *primitive.ObjectID    `json:"department_id,omitempty" bson:"department_id,omitempty"`
+	CreatedAt              time.Time              `json:"created_at" bson:"created_at"`
+	Reason                 RequestDuplicateReason `json:"reason" bson:"reason"`
+	Score                  float64                `json:"score" bson:"score"`
+}
+
+// IsOpen reports whether the linked request is still in progress.
+func (l RequestDuplicateLink) IsOpen() bool {
+	return slices.Contains(RequestOpenStatuses, l.RequestStatus)
+}
+
+// RequestDuplicateCheck is the outcome of the duplicate check of a request.
+// Velocity counts the requests of the same account within the lookback,
+// including the checked one.
+type RequestDuplicateCheck struct {
+	Links     []RequestDuplicateLink `json:"links" bson:"links"`
+	Velocity  int                    `json:"velocity" bson:"velocity"`
+	Flagged   bool                   `json:"flagged" bson:"flagged"`
+	Action    RequestAction          `json:"action" bson:"action"`
+	CheckedAt time.Time              `json:"checked_at" bson:"checked_at"`
+}
+
+// Blocks reports whether the block policy must stop the request: another
+// request for the applicant is still open, or the account files requests
+// faster than allowed. Matches on finished requests only flag.
+func (c *RequestDuplicateCheck) Blocks(policy DuplicatePolicy) bool {
+	if policy.Mode != DuplicatePolicyBlock {
+		return false
+	}
+
+	if policy.VelocityMax > 0 && c.Velocity > policy.VelocityMax {
+		return true
+	}
+
+	for _, link := range c.Links {
+		if link.IsOpen() {
+			return true
+		}
+	}
+
+	return false
+}
+
+// DuplicateRequestError is returned when the duplicate policy blocks a request.
+type DuplicateRequestError struct {
+	Check *RequestDuplicateCheck
+}
+
+func (e *DuplicateRequestError) Error() string {
+	return fmt.Sprintf("applicant has %d open or recent requests (%d for the account)", len(e.Check.Links), e.Check.Velocity)
+}
+
+func (e *DuplicateRequestError) Unwrap() error {
+	return common.ErrDuplicateRequest
+}
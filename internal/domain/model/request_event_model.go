@@ -0,0 +1,121 @@
+package model
+
+import (
+	"context"
+	"reflect"
+	"slices"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type RequestEventType string
+
+const (
+	ReqEventCreated           RequestEventType = "created"
+	ReqEventEdited            RequestEventType = "edited"
+	ReqEventTransitioned      RequestEventType = "transitioned"
+	ReqEventAttachmentChanged RequestEventType = "attachment_changed"
+	ReqEventLocked            RequestEventType = "locked"
+	ReqEventUnlocked          RequestEventType = "unlocked"
+	ReqEventForceUnlocked     RequestEventType = "force_unlocked"
+	ReqEventEmailSent         RequestEventType = "email_sent"
+	ReqEventEmailFailed       RequestEventType = "email_failed"
+	ReqEventDuplicateFlagged  RequestEventType = "duplicate_flagged"
+	ReqEventSLAReminded       RequestEventType = "sla_reminded"
+	ReqEventSLAEscalated      RequestEventType = "sla_escalated"
+)
+
+type RequestFieldChange struct {
+	Field  string      `json:"field" bson:"field"`
+	Before interface{} `json:"before" bson:"before"`
+	After  interface{} `json:"after" bson:"after"`
+}
+
+// RequestEvent is one append-only entry of a request's history. ActorID is
+// nil for changes made by the system itself.
+type RequestEvent struct {
+	ID         primitive.ObjectID   `json:"_id" bson:"_id,omitempty"`
+	RequestID  primitive.ObjectID   `json:"request_id" bson:"request_id"`
+	Type       RequestEventType     `json:"type" bson:"type"`
+	Action     RequestAction        `json:"action,omitempty" bson:"action,omitempty"`
+	FromStatus RequestStatus        `json:"from_status,omitempty" bson:"from_status,omitempty"`
+	ToStatus   RequestStatus        `json:"to_status,omitempty" bson:"to_status,omitempty"`
+	Changes    []RequestFieldChange `json:"changes,omitempty" bson:"changes,omitempty"`
+	Reason     string               `json:"reason,omitempty" bson:"reason,omitempty"`
+	Recipients []string             `json:"recipients,omitempty" bson:"recipients,omitempty"`
+	Error      string               `json:"error,omitempty" bson:"error,omitempty"`
+
+	ActorID   *primitive.ObjectID `json:"actor_id,omitempty" bson:"actor_id,omitempty"`
+	Actor     *User               `json:"actor,omitempty" bson:"actor,omitempty"`
+	ActorIP   string              `json:"actor_ip,omitempty" bson:"actor_ip,omitempty"`
+	TraceID   string              `json:"trace_id,omitempty" bson:"trace_id,omitempty"`
+	CreatedAt time.Time           `json:"created_at" bson:"created_at"`
+}
+
+type RequestEventRepository interface {
+	Create(ctx context.Context, event *RequestEvent) error
+	FindByRequestID(ctx context.Context, requestID primitive.ObjectID) ([]RequestEvent, error)
+}
+
+// requestDiffIgnoredFields change on every write and would only add noise to
+// the history. Duplicate checks get an event of their own.
+var requestDiffIgnoredFields = []string{"_id", "updated_at", "updated_by", "duplicate_check"}
+
+// DiffRequestUpdates lists the fields whose values differ between before and
+// after, keyed by their stored (bson) name.
+func DiffRequestUpdates(before, after *RequestUpdate) []RequestFieldChange {
+	var changes []RequestFieldChange
+
+	bv := reflect.ValueOf(before).Elem()
+	av := reflect.ValueOf(after).Elem()
+	fields := bv.Type()
+
+	for i := 0; i < fields.NumField(); i++ {
+		name := strings.Split(fields.Field(i).Tag.Get("bson"), ",")[0]
+		if name == "" || name == "-" || slices.Contains(requestDiffIgnoredFields, name) {
+			continue
+		}
+
+		oldValue := fieldValue(bv.Field(i))
+		newValue := fieldValue(av.Field(i))
+		if valuesEqual(oldValue, newValue) {
+			continue
+		}
+
+		changes = append(changes, RequestFieldChange{Field: name, Before: oldValue, After: newValue})
+	}
+
+	return changes
+}
+
+// IsAttachmentField reports whether the stored field name refers to one of
+// the request attachments.
+func IsAttachmentField(field string) bool {
+	return strings.HasSuffix(field, "_attachment")
+}
+
+func fieldValue(v reflect.Value) interface{} {
+	if v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			return nil
+		}
+		v = v.Elem()
+	}
+	if v.Kind() == reflect.Slice && v.Len() == 0 {
+		return nil
+	}
+
+	return v.Interface()
+}
+
+func valuesEqual(a, b interface{}) bool {
+	at, aok := a.(time.Time)
+	bt, bok := b.(time.Time)
+	if aok && bok {
+		return at.Equal(bt)
+	}
+
+	return reflect.DeepEqual(a, b)
+}
@@ -39,6 +39,7 @@ type Request struct {
 	TravelCountryID        primitive.ObjectID  `json:"travel_country_id" bson:"travel_country_id"`
 	TravelCountry          *Country            `json:"travel_country,omitempty" bson:"travel_country,omitempty"`
 	RequestingAs           string              `json:"requesting_as" bson:"requesting_as"`
+	CustomerTypeID         *primitive.ObjectID `json:"customer_type_id,omitempty" bson:"customer_type_id,omitempty"`
 	AccountCurrencyID      primitive.ObjectID  `json:"account_currency_id" bson:"account_currency_id"`
 	AccountCurrency        *Currency           `json:"account_currency,omitempty" bson:"account_currency,omitempty"`
 	FcyRequestedID         primitive.ObjectID  `json:"fcy_requested_id" bson:"fcy_requested_id"`
@@ -73,18 +74,24 @@ type Request struct {
 	ValidatedCurrentBalance    *float64            `json:"validated_current_balance,omitempty" bson:"validated_current_balance,omitempty"`
 
 	// Approved Fields
-	ApprovedCurrencyIDs  []primitive.ObjectID `json:"approved_currency_ids,omitempty" bson:"approved_currency_ids,omitempty"`
-	ApprovedCurrencies   []Currency           `json:"approved_currencies,omitempty" bson:"approved_currencies,omitempty"`
-	ApprovedAmounts      []float64            `json:"approved_amounts,omitempty" bson:"approved_amounts,omitempty"`
-	ApprovedAmountInCash []float64            `json:"approved_amount_in_cash,omitempty" bson:"approved_amount_in_cash,omitempty"`
-	ApprovedAmountInCard []float64            `json:"approved_amount_in_card,omitempty" bson:"approved_amount_in_card,omitempty"`
+	ApprovedCurrencyIDs  []primitive.ObjectID  `json:"approved_currency_ids,omitempty" bson:"approved_currency_ids,omitempty"`
+	ApprovedCurrencies   []Currency            `json:"approved_currencies,omitempty" bson:"approved_currencies,omitempty"`
+	ApprovedAmounts      []float64             `json:"approved_amounts,omitempty" bson:"approved_amounts,omitempty"`
+	ApprovedAmountInCash []float64             `json:"approved_amount_in_cash,omitempty" bson:"approved_amount_in_cash,omitempty"`
+	ApprovedAmountInCard []float64             `json:"approved_amount_in_card,omitempty" bson:"approved_amount_in_card,omitempty"`
+	ApprovedRates        []RequestRateSnapshot `json:"approved_rates,omitempty" bson:"approved_rates,omitempty"`
 
 	// Accepted Fields
-	AcceptedCurrencyIDs  []primitive.ObjectID `json:"accepted_currency_ids,omitempty" bson:"accepted_currency_ids,omitempty"`
-	AcceptedCurrencies   []Currency           `json:"accepted_currencies,omitempty" bson:"accepted_currencies,omitempty"`
-	AcceptedAmounts      []float64            `json:"accepted_amounts,omitempty" bson:"accepted_amounts,omitempty"`
-	AcceptedAmountInCash []float64            `json:"accepted_amount_in_cash,omitempty" bson:"accepted_amount_in_cash,omitempty"`
-	AcceptedAmountInCard []float64            `json:"accepted_amount_in_card,omitempty" bson:"accepted_amount_in_card,omitempty"`
+	AcceptedCurrencyIDs  []primitive.ObjectID  `json:"accepted_currency_ids,omitempty" bson:"accepted_currency_ids,omitempty"`
+	AcceptedCurrencies   []Currency            `json:"accepted_currencies,omitempty" bson:"accepted_currencies,omitempty"`
+	AcceptedAmounts      []float64             `json:"accepted_amounts,omitempty" bson:"accepted_amounts,omitempty"`
+	AcceptedAmountInCash []float64             `json:"accepted_amount_in_cash,omitempty" bson:"accepted_amount_in_cash,omitempty"`
+	AcceptedAmountInCard []float64             `json:"accepted_amount_in_card,omitempty" bson:"accepted_amount_in_card,omitempty"`
+	AcceptedRates        []RequestRateSnapshot `json:"accepted_rates,omitempty" bson:"accepted_rates,omitempty"`
+	LimitOverrides       []AllocationOverride  `json:"limit_overrides,omitempty" bson:"limit_overrides,omitempty"`
+
+	// Duplicate detection
+	DuplicateCheck *RequestDuplicateCheck `json:"duplicate_check,omitempty" bson:"duplicate_check,omitempty"`
 
 	// Status & remarks
 	RequestStatus   RequestStatus `json:"request_status" bson:"request_status"`
@@ -92,6 +99,7 @@ type Request struct {
 	RejectionReason string        `json:"rejection_reason,omitempty" bson:"rejection_reason,omitempty"`
 	ProcessedAmount *float64      `json:"processed_amount,omitempty" bson:"processed_amount,omitempty"`
 	DueDate         *time.Time    `json:"due_date,omitempty" bson:"due_date,omitempty"`
+	SLA             *RequestSLA   `json:"sla,omitempty" bson:"sla,omitempty"`
 
 	// Audit
 	CreatedAt    time.Time  `json:"created_at" bson:"created_at"`
@@ -103,6 +111,9 @@ type Request struct {
 	ApprovedAt   *time.Time `json:"approved_at,omitempty" bson:"approved_at,omitempty"`
 	AcceptedAt   *time.Time `json:"accepted_at,omitempty" bson:"accepted_at,omitempty"`
 	DeclinedAt   *time.Time `json:"declined_at,omitempty" bson:"declined_at,omitempty"`
+	// SystemDeclinedAt is set when the request was declined because it was
+	// not accepted within its SLA.
+	SystemDeclinedAt *time.Time `json:"system_declined_at,omitempty" bson:"system_declined_at,omitempty"`
 
 	CreatedBy    primitive.ObjectID  `json:"created_by" bson:"created_by"`
 	Creator      *User               `json:"creator,omitempty" bson:"creator,omitempty"`
@@ -128,6 +139,9 @@ type Request struct {
 	LockedBy      *primitive.ObjectID `json:"locked_by,omitempty" bson:"locked_by,omitempty"`
 	LockedAt      *time.Time          `json:"locked_at,omitempty" bson:"locked_at,omitempty"`
 	LockExpiresAt *time.Time          `json:"lock_expires_at,omitempty" bson:"lock_expires_at,omitempty"`
+
+	// Version is incremented by every write so concurrent updates can be detected
+	Version int64 `json:"version" bson:"version"`
 }
 
 type RequestUpdate struct {
@@ -143,6 +157,7 @@ type RequestUpdate struct {
 	TravelPurposeID        primitive.ObjectID  `json:"travel_purpose_id" bson:"travel_purpose_id"`
 	TravelCountryID        primitive.ObjectID  `json:"travel_country_id" bson:"travel_country_id"`
 	RequestingAs           string              `json:"requesting_as" bson:"requesting_as"`
+	CustomerTypeID         *primitive.ObjectID `json:"customer_type_id,omitempty" bson:"customer_type_id,omitempty"`
 	AccountCurrencyID      primitive.ObjectID  `json:"account_currency_id" bson:"account_currency_id"`
 	FcyRequestedID         primitive.ObjectID  `json:"fcy_requested_id" bson:"fcy_requested_id"`
 	FcyRequestedAmount     float64             `json:"fcy_requested_amount" bson:"fcy_requested_amount"`
@@ -166,22 +181,29 @@ type RequestUpdate struct {
 	ValidatedCurrentBalance    *float64            `json:"validated_current_balance,omitempty" bson:"validated_current_balance,omitempty"`
 
 	// Approved Fields
-	ApprovedCurrencyIDs  []primitive.ObjectID `json:"approved_currency_ids,omitempty" bson:"approved_currency_ids,omitempty"`
-	ApprovedAmounts      []float64            `json:"approved_amounts,omitempty" bson:"approved_amounts,omitempty"`
-	ApprovedAmountInCash []float64            `json:"approved_amount_in_cash,omitempty" bson:"approved_amount_in_cash,omitempty"`
-	ApprovedAmountInCard []float64            `json:"approved_amount_in_card,omitempty" bson:"approved_amount_in_card,omitempty"`
-
-	AcceptedCurrencyIDs  []primitive.ObjectID `json:"accepted_currency_ids,omitempty" bson:"accepted_currency_ids,omitempty"`
-	AcceptedAmounts      []float64            `json:"accepted_amounts,omitempty" bson:"accepted_amounts,omitempty"`
-	AcceptedAmountInCash []float64            `json:"accepted_amount_in_cash,omitempty" bson:"accepted_amount_in_cash,omitempty"`
-	AcceptedAmountInCard []float64            `json:"accepted_amount_in_card,omitempty" bson:"accepted_amount_in_card,omitempty"`
+	ApprovedCurrencyIDs  []primitive.ObjectID  `json:"approved_currency_ids,omitempty" bson:"approved_currency_ids,omitempty"`
+	ApprovedAmounts      []float64             `json:"approved_amounts,omitempty" bson:"approved_amounts,omitempty"`
+	ApprovedAmountInCash []float64             `json:"approved_amount_in_cash,omitempty" bson:"approved_amount_in_cash,omitempty"`
+	ApprovedAmountInCard []float64             `json:"approved_amount_in_card,omitempty" bson:"approved_amount_in_card,omitempty"`
+	ApprovedRates        []RequestRateSnapshot `json:"approved_rates,omitempty" bson:"approved_rates,omitempty"`
+
+	AcceptedCurrencyIDs  []primitive.ObjectID  `json:"accepted_currency_ids,omitempty" bson:"accepted_currency_ids,omitempty"`
+	AcceptedAmounts      []float64             `json:"accepted_amounts,omitempty" bson:"accepted_amounts,omitempty"`
+	AcceptedAmountInCash []float64             `json:"accepted_amount_in_cash,omitempty" bson:"accepted_amount_in_cash,omitempty"`
+	AcceptedAmountInCard []float64             `json:"accepted_amount_in_card,omitempty" bson:"accepted_amount_in_card,omitempty"`
+	AcceptedRates        []RequestRateSnapshot `json:"accepted_rates,omitempty" bson:"accepted_rates,omitempty"`
+	LimitOverrides       []AllocationOverride  `json:"limit_overrides,omitempty" bson:"limit_overrides,omitempty"`
+
+	// Duplicate detection
+	DuplicateCheck *RequestDuplicateCheck `json:"duplicate_check,omitempty" bson:"duplicate_check,omitempty"`
 
 	// Status & remarks
-	RequestStatus   string     `json:"request_status" bson:"request_status"`
-	Remark          string     `json:"remark,omitempty" bson:"remark,omitempty"`
-	RejectionReason string     `json:"rejection_reason,omitempty" bson:"rejection_reason,omitempty"`
-	ProcessedAmount *float64   `json:"processed_amount,omitempty" bson:"processed_amount,omitempty"`
-	DueDate         *time.Time `json:"due_date,omitempty" bson:"due_date,omitempty"`
+	RequestStatus   string      `json:"request_status" bson:"request_status"`
+	Remark          string      `json:"remark,omitempty" bson:"remark,omitempty"`
+	RejectionReason string      `json:"rejection_reason,omitempty" bson:"rejection_reason,omitempty"`
+	ProcessedAmount *float64    `json:"processed_amount,omitempty" bson:"processed_amount,omitempty"`
+	DueDate         *time.Time  `json:"due_date,omitempty" bson:"due_date,omitempty"`
+	SLA             *RequestSLA `json:"sla,omitempty" bson:"sla,omitempty"`
 
 	// Audit
 	CreatedAt        time.Time  `json:"created_at" bson:"created_at"`
@@ -207,10 +229,6 @@ type RequestUpdate struct {
 	DeletedBy    *primitive.ObjectID `json:"deleted_by,omitempty" bson:"deleted_by,omitempty"`
 	DeletedAt    *time.Time          `json:"deleted_at,omitempty" bson:"deleted_at,omitempty"`
 	IsDeleted    bool                `json:"is_deleted" bson:"is_deleted"`
-
-	LockedBy      *primitive.ObjectID `json:"locked_by,omitempty" bson:"locked_by,omitempty"`
-	LockedAt      *time.Time          `json:"locked_at,omitempty" bson:"locked_at,omitempty"`
-	LockExpiresAt *time.Time          `json:"lock_expires_at,omitempty" bson:"lock_expires_at,omitempty"`
 }
 
 type RequestDTO struct {
@@ -222,6 +240,7 @@ type RequestDTO struct {
 	TravelPurposeID        string   `form:"travel_purpose_id" binding:"required,alphanum,len=24"`
 	TravelCountryID        string   `form:"travel_country_id" binding:"required,alphanum,len=24"`
 	RequestingAs           string   `form:"requesting_as" binding:"required,alphanum"`
+	CustomerTypeID         string   `form:"customer_type_id" binding:"omitempty,alphanum,len=24"`
 	AccountCurrencyID      string   `form:"account_currency_id" binding:"required,alphanum,len=24"`
 	FcyRequestedID         string   `form:"fcy_requested_id" binding:"required,alphanum,len=24"`
 	FcyRequestedAmount     string   `form:"fcy_requested_amount" binding:"required,alphanum"`
@@ -249,6 +268,7 @@ type UpdateRequestDTO struct {
 	TravelPurposeID        string   `form:"travel_purpose_id" binding:"required,alphanum,len=24"`
 	TravelCountryID        string   `form:"travel_country_id" binding:"required,alphanum,len=24"`
 	RequestingAs           string   `form:"requesting_as" binding:"required,alphanum"`
+	CustomerTypeID         string   `form:"customer_type_id" binding:"omitempty,alphanum,len=24"`
 	AccountCurrencyID      string   `form:"account_currency_id" binding:"required,alphanum,len=24"`
 	FcyRequestedID         string   `form:"fcy_requested_id" binding:"required,alphanum,len=24"`
 	FcyRequestedAmount     string   `form:"fcy_requested_amount" binding:"required,alphanum,gt=0"`
@@ -278,6 +298,7 @@ type RequestApprovalDTO struct {
 	ApprovedAmounts      []float64            `json:"approved_amounts" binding:"required,dive,gte=0"`
 	ApprovedAmountInCash []float64            `json:"approved_amount_in_cash" binding:"required,dive,gte=0"`
 	ApprovedAmountInCard []float64            `json:"approved_amount_in_card" binding:"required,dive,gte=0"`
+	AllocationOverrideDTO
 }
 
 type RequestAcceptanceDTO struct {
@@ -285,6 +306,11 @@ type RequestAcceptanceDTO struct {
 	AcceptedAmounts      []float64            `json:"accepted_amounts" binding:"required,dive,gte=0"`
 	AcceptedAmountInCash []float64            `json:"accepted_amount_in_cash" binding:"required,dive,gte=0"`
 	AcceptedAmountInCard []float64            `json:"accepted_amount_in_card" binding:"required,dive,gte=0"`
+	AllocationOverrideDTO
+}
+
+type RequestLockResponseDTO struct {
+	LockExpiresAt time.Time `json:"lock_expires_at"`
 }
 
 // Internal model with ObjectIDs
@@ -293,13 +319,40 @@ type ParsedApproval struct {
 	ApprovedAmounts     []float64
 }
 
+// IsLockedByOther reports whether another user holds an unexpired lock on the request.
+func (r *Request) IsLockedByOther(userID primitive.ObjectID, now time.Time) bool {
+	if r.LockedBy == nil || *r.LockedBy == userID {
+		return false
+	}
+
+	return r.LockExpiresAt != nil && r.LockExpiresAt.After(now)
+}
+
 type RequestRepository interface {
 	Create(ctx context.Context, request *Request) error
 	FindByID(ctx context.Context, request_id primitive.ObjectID, populate bool) (*Request, error)
-	FindAll(ctx context.Context, populate bool) ([]Request, error)
-	Validate(ctx context.Context, request_id primitive.ObjectID, request *Request) error
-	FindAllByOrgID(ctx context.Context, orgKey string, orgID primitive.ObjectID, populate bool) ([]Request, error)
-	FindOrgByRequestStatus(ctx context.Context, orgID primitive.ObjectID, orgKey, request_status string, populate bool) ([]Request, error)
-	Update(ctx context.Context, requestID primitive.ObjectID, request *RequestUpdate) error
-	FindByRequestStatus(ctx context.Context, request_status string, populate bool) ([]Request, error)
+	FindByCode(ctx context.Context, requestCode string, populate bool) (*Request, error)
+	Search(ctx context.Context, search *RequestSearch) (*RequestPage, error)
+	SumAllocations(ctx context.Context, query *AllocationUsageQuery) ([]AllocationUsage, error)
+	// CountByStatus counts the requests that are not deleted in each status
+	// that has any.
+	CountByStatus(ctx context.Context) (map[RequestStatus]int64, error)
+	FindDuplicateCandidates(ctx context.Context, excludeID primitive.ObjectID, since time.Time) ([]Request, error)
+	UpdateIfUnchanged(ctx context.Context, requestID primitive.ObjectID, actorID primitive.ObjectID, expectedStatus RequestStatus, expectedVersion int64, request *RequestUpdate) error
+	AcquireLock(ctx context.Context, requestID primitive.ObjectID, userID primitive.ObjectID, ttl time.Duration) (*time.Time, error)
+	RenewLock(ctx context.Context, requestID primitive.ObjectID, userID primitive.ObjectID, ttl time.Duration) (*time.Time, error)
+	ReleaseLock(ctx context.Context, requestID primitive.ObjectID, userID primitive.ObjectID) error
+	ForceReleaseLock(ctx context.Context, requestID primitive.ObjectID) error
+	// ReleaseExpiredLocks clears every lock that expired before now and
+	// returns how many it cleared.
+	ReleaseExpiredLocks(ctx context.Context, now time.Time) (int64, error)
+	// ClaimSLAReminder marks one request whose reminder is due as reminded and
+	// returns it, or nil when none is due.
+	ClaimSLAReminder(ctx context.Context, now time.Time) (*Request, error)
+	// ClaimSLAEscalation marks one overdue request as escalated and returns
+	// it, or nil when none is left.
+	ClaimSLAEscalation(ctx context.Context, now time.Time) (*Request, error)
+	// FindExpiredApprovals lists approved requests whose SLA declines them
+	// once overdue and that are overdue at now.
+	FindExpiredApprovals(ctx context.Context, now time.Time, limit int64) ([]Request, error)
 }
@@ -0,0 +1,200 @@
+package model
+
+import (
+	"slices"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	RequestDefaultPageSize = 20
+	RequestDefaultSort     = "-created_at"
+)
+
+// RequestSortFields are the fields a request listing may be sorted by. They
+// are all present on every request, which keyset cursors rely on.
+var RequestSortFields = []string{"created_at", "updated_at", "fcy_requested_amount", "request_code", "applicant_name"}
+
+type RequestQueryDTO struct {
+	Page         int      `form:"page" binding:"omitempty,min=1"`
+	PageSize     int      `form:"page_size" binding:"omitempty,min=1,max=100"`
+	Cursor       string   `form:"cursor" binding:"omitempty,max=512"`
+	Sort         string   `form:"sort" binding:"omitempty,max=30"`
+	Status       []string `form:"status" binding:"omitempty,dive,max=100"`
+	From         string   `form:"from" binding:"omitempty,max=35"`
+	To           string   `form:"to" binding:"omitempty,max=35"`
+	BranchID     string   `form:"branch_id" binding:"omitempty,alphanum,len=24"`
+	DepartmentID string   `form:"department_id" binding:"omitempty,alphanum,len=24"`
+	CurrencyID   string   `form:"currency_id" binding:"omitempty,alphanum,len=24"`
+	MinAmount    *float64 `form:"min_amount" binding:"omitempty,gte=0"`
+	MaxAmount    *float64 `form:"max_amount" binding:"omitempty,gte=0"`
+	Applicant    string   `form:"applicant" binding:"omitempty,max=100,excludesall=<>"`
+	RequestCode  string   `form:"request_code" binding:"omitempty,max=50,excludesall=<>"`
+}
+
+// ParseRequestSort splits a sort expression such as "-created_at" into the
+// field and direction, rejecting fields that cannot be sorted by.
+func ParseRequestSort(sort string) (field string, desc bool, ok bool) {
+	if sort == "" {
+		sort = RequestDefaultSort
+	}
+
+	field = strings.TrimPrefix(sort, "-")
+	desc = strings.HasPrefix(sort, "-")
+
+	return field, desc, slices.Contains(RequestSortFields, field)
+}
+
+// ParseRequestStatuses accepts repeated and comma separated status values and
+// rejects unknown ones.
+func ParseRequestStatuses(values []string) ([]RequestStatus, bool) {
+	var statuses []RequestStatus
+	for _, value := range values {
+		for _, part := range strings.Split(value, ",") {
+			status := RequestStatus(strings.TrimSpace(part))
+			if status == "" {
+				continue
+			}
+			if !slices.Contains(RequestOrgStatuses, status) {
+				return nil, false
+			}
+			statuses = appendStatus(statuses, status)
+		}
+	}
+
+	return statuses, true
+}
+
+// RequestFilter narrows a request listing. Zero values mean "no condition".
+type RequestFilter struct {
+	Statuses     []RequestStatus
+	CreatedFrom  *time.Time
+	CreatedTo    *time.Time
+	BranchID     *primitive.ObjectID
+	DepartmentID *primitive.ObjectID
+	CurrencyID   *primitive.ObjectID
+	MinAmount    *float64
+	MaxAmount    *float64
+	Applicant    string
+	RequestCode  string
+}
+
+// RequestSearch is a single page of a request listing. When Cursor is set
+// it takes precedence over Page.
+type RequestSearch struct {
+	Filter    RequestFilter
+	Scope     RequestScope
+	SortField string
+	SortDesc  bool
+	Page      int
+	PageSize  int
+	Cursor    string
+}
+
+type RequestPage struct {
+	Items      []Request `json:"items"`
+	Total      int64     `json:"total"`
+	Page       int       `json:"page,omitempty"`
+	PageSize   int       `json:"page_size"`
+	NextCursor string    `json:"next_cursor,omitempty"`
+}
+
+// RequestScope describes the requests a user may read: statuses visible
+// bank-wide and statuses visible only within the user's branch or department.
+type RequestScope struct {
+	AllStatuses []RequestStatus
+	OrgStatuses []RequestStatus
+	OrgKey      string
+	OrgID       primitive.ObjectID
+}
+
+// NewRequestScope derives the read scope from the user's effective
+// permissions and the organization of their profile.
+func NewRequestScope(permissions []string, profile *Profile) RequestScope {
+	scope := RequestScope{}
+
+	if slices.Contains(permissions, "request:view") {
+		scope.AllStatuses = append(scope.AllStatuses, RequestViewAllStatuses...)
+	}
+	for status, permission := range RequestStatusViewPermissions {
+		if slices.Contains(permissions, permission) {
+			scope.AllStatuses = appendStatus(scope.AllStatuses, status)
+		}
+	}
+
+	if profile != nil && profile.BranchID != nil {
+		scope.OrgKey, scope.OrgID = "branch_id", *profile.BranchID
+	} else if profile != nil && profile.DepartmentID != nil {
+		scope.OrgKey, scope.OrgID = "department_id", *profile.DepartmentID
+	} else {
+		return scope
+	}
+
+	if slices.Contains(permissions, "request:status") {
+		scope.OrgStatuses = append(scope.OrgStatuses, RequestOrgStatuses...)
+	}
+	for status, permission := range RequestOrgStatusViewPermissions {
+		if slices.Contains(permissions, permission) {
+			scope.OrgStatuses = appendStatus(scope.OrgStatuses, status)
+		}
+	}
+
+	return scope
+}
+
+// Allows reports whether the request falls inside the scope.
+func (s RequestScope) Allows(request *Request) bool {
+	if slices.Contains(s.AllStatuses, request.RequestStatus) {
+		return true
+	}
+
+	if !slices.Contains(s.OrgStatuses, request.RequestStatus) {
+		return false
+	}
+
+	switch s.OrgKey {
+	case "branch_id":
+		return request.BranchID != nil && *request.BranchID == s.OrgID
+	case "department_id":
+		return request.DepartmentID != nil && *request.DepartmentID == s.OrgID
+	}
+
+	return false
+}
+
+// Restrict keeps only the given statuses in the scope; an empty list leaves
+// the scope unchanged.
+func (s RequestScope) Restrict(statuses []RequestStatus) RequestScope {
+	if len(statuses) == 0 {
+		return s
+	}
+
+	keep := func(in []RequestStatus) []RequestStatus {
+		var out []RequestStatus
+		for _, status := range in {
+			if slices.Contains(statuses, status) {
+				out = append(out, status)
+			}
+		}
+		return out
+	}
+
+	s.AllStatuses = keep(s.AllStatuses)
+	s.OrgStatuses = keep(s.OrgStatuses)
+
+	return s
+}
+
+// IsEmpty reports whether the scope grants access to nothing.
+func (s RequestScope) IsEmpty() bool {
+	return len(s.AllStatuses) == 0 && len(s.OrgStatuses) == 0
+}
+
+func appendStatus(statuses []RequestStatus, status RequestStatus) []RequestStatus {
+	if slices.Contains(statuses, status) {
+		return statuses
+	}
+	return append(statuses, status)
+}
@@ -0,0 +1,189 @@
+package model
+
+import (
+	"slices"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type RequestAction string
+
+const (
+	// ReqActionCreate is not a transition; it tags checks that run when a
+	// request is first added.
+	ReqActionCreate    RequestAction = "create"
+	ReqActionSend      RequestAction = "send"
+	ReqActionAuthorize RequestAction = "authorize"
+	ReqActionValidate  RequestAction = "validate"
+	ReqActionApprove   RequestAction = "approve"
+	ReqActionAccept    RequestAction = "accept"
+	ReqActionDecline   RequestAction = "decline"
+	ReqActionReject    RequestAction = "reject"
+	ReqActionDelete    RequestAction = "delete"
+	// ReqActionExpire is taken by the system, not a user: it declines an
+	// approved request that was not accepted within its SLA.
+	ReqActionExpire RequestAction = "expire"
+)
+
+// RequestTransition declares one legal move of the request workflow: the
+// statuses it may start from, the status it lands on, the permission the
+// actor must hold and the audit fields it stamps on the request.
+type RequestTransition struct {
+	Action     RequestAction
+	From       []RequestStatus
+	To         RequestStatus
+	Permission string
+	Stamp      func(request *RequestUpdate, actorID primitive.ObjectID, at time.Time)
+}
+
+// RequestEditableStatuses are the statuses in which the request content may
+// still be changed by the originating branch or department.
+var RequestEditableStatuses = []RequestStatus{ReqStatusDrafted, ReqStatusRejected}
+
+// RequestOpenStatuses are the statuses of requests that have not reached a
+// final decision yet.
+var RequestOpenStatuses = []RequestStatus{
+	ReqStatusDrafted, ReqStatusNew, ReqStatusAuthorized, ReqStatusValidated, ReqStatusRejected, ReqStatusApproved,
+}
+
+// RequestViewAllStatuses are the statuses visible bank-wide to holders of
+// request:view; requests still owned by the branch are left out.
+var RequestViewAllStatuses = []RequestStatus{
+	ReqStatusAuthorized, ReqStatusValidated, ReqStatusApproved, ReqStatusAccepted, ReqStatusDeclined,
+}
+
+// RequestOrgStatuses are the statuses visible within their own branch or
+// department to holders of request:status.
+var RequestOrgStatuses = []RequestStatus{
+	ReqStatusDrafted, ReqStatusNew, ReqStatusAuthorized, ReqStatusValidated, ReqStatusRejected,
+	ReqStatusApproved, ReqStatusAccepted, ReqStatusDeclined,
+}
+
+// RequestOrgStatusViewPermissions grant read access to requests in a given
+// status, limited to the user's own branch or department.
+var RequestOrgStatusViewPermissions = map[RequestStatus]string{
+	ReqStatusDrafted:    "request:view-orgdrafted",
+	ReqStatusNew:        "request:view-new",
+	ReqStatusAuthorized: "request:view-orgauthorized",
+	ReqStatusRejected:   "request:view-orgrejected",
+	ReqStatusApproved:   "request:view-orgapproved",
+	ReqStatusAccepted:   "request:view-orgaccepted",
+	ReqStatusDeclined:   "request:view-orgdeclined",
+}
+
+// RequestStatusViewPermissions grant bank-wide read access to requests that
+// are in a given status, regardless of the branch or department they belong to.
+var RequestStatusViewPermissions = map[RequestStatus]string{
+	ReqStatusAuthorized: "request:view-authorized",
+	ReqStatusValidated:  "request:view-validated",
+	ReqStatusApproved:   "request:view-approved",
+	ReqStatusAccepted:   "request:view-accepted",
+	ReqStatusDeclined:   "request:view-declined",
+	ReqStatusRejected:   "request:view-rejected",
+}
+
+var RequestTransitions = map[RequestAction]RequestTransition{
+	ReqActionSend: {
+		Action:     ReqActionSend,
+		From:       []RequestStatus{ReqStatusDrafted, ReqStatusRejected},
+		To:         ReqStatusNew,
+		Permission: "request:send",
+		Stamp: func(r *RequestUpdate, actorID primitive.ObjectID, at time.Time) {
+			r.RequestedAt = &at
+			r.RequestedBy = &actorID
+		},
+	},
+	ReqActionAuthorize: {
+		Action:     ReqActionAuthorize,
+		From:       []RequestStatus{ReqStatusNew},
+		To:         ReqStatusAuthorized,
+		Permission: "request:authorize",
+		Stamp: func(r *RequestUpdate, actorID primitive.ObjectID, at time.Time) {
+			r.AuthorizedAt = &at
+			r.AuthorizedBy = &actorID
+		},
+	},
+	ReqActionValidate: {
+		Action:     ReqActionValidate,
+		From:       []RequestStatus{ReqStatusAuthorized},
+		To:         ReqStatusValidated,
+		Permission: "request:validate",
+		Stamp: func(r *RequestUpdate, actorID primitive.ObjectID, at time.Time) {
+			r.ValidatedAt = &at
+			r.ValidatedBy = &actorID
+		},
+	},
+	ReqActionApprove: {
+		Action:     ReqActionApprove,
+		From:       []RequestStatus{ReqStatusValidated},
+		To:         ReqStatusApproved,
+		Permission: "request:approve",
+		Stamp: func(r *RequestUpdate, actorID primitive.ObjectID, at time.Time) {
+			r.ApprovedAt = &at
+			r.ApprovedBy = &actorID
+		},
+	},
+	ReqActionAccept: {
+		Action:     ReqActionAccept,
+		From:       []RequestStatus{ReqStatusApproved},
+		To:         ReqStatusAccepted,
+		Permission: "request:process",
+		Stamp: func(r *RequestUpdate, actorID primitive.ObjectID, at time.Time) {
+			r.AcceptedAt = &at
+			r.AcceptedBy = &actorID
+		},
+	},
+	ReqActionDecline: {
+		Action:     ReqActionDecline,
+		From:       []RequestStatus{ReqStatusApproved},
+		To:         ReqStatusDeclined,
+		Permission: "request:decline",
+		Stamp: func(r *RequestUpdate, actorID primitive.ObjectID, at time.Time) {
+			r.DeclinedAt = &at
+			r.DeclinedBy = &actorID
+		},
+	},
+	ReqActionReject: {
+		Action:     ReqActionReject,
+		From:       []RequestStatus{ReqStatusNew, ReqStatusAuthorized, ReqStatusValidated},
+		To:         ReqStatusRejected,
+		Permission: "request:reject",
+		Stamp: func(r *RequestUpdate, actorID primitive.ObjectID, at time.Time) {
+			r.RejectedAt = &at
+			r.RejectedBy = &actorID
+		},
+	},
+	ReqActionDelete: {
+		Action:     ReqActionDelete,
+		From:       []RequestStatus{ReqStatusDrafted},
+		To:         ReqStatusDeleted,
+		Permission: "request:delete",
+		Stamp: func(r *RequestUpdate, actorID primitive.ObjectID, at time.Time) {
+			r.DeletedAt = &at
+			r.DeletedBy = &actorID
+			r.IsDeleted = true
+		},
+	},
+}
+
+// Allows reports whether the transition may start from the given status.
+func (t RequestTransition) Allows(from RequestStatus) bool {
+	return slices.Contains(t.From, from)
+}
+
+// Apply stamps the audit fields of the transition and moves the request to
+// the target status.
+func (t RequestTransition) Apply(request *RequestUpdate, actorID primitive.ObjectID, at time.Time) {
+	if t.Stamp != nil {
+		t.Stamp(request, actorID, at)
+	}
+	request.RequestStatus = string(t.To)
+	request.UpdatedAt = at
+	request.UpdatedBy = &actorID
+}
+
+// IsEditable reports whether the request content may still be updated.
+func (s RequestStatus) IsEditable() bool {
+	return slices.Contains(RequestEditableStatuses, s)
+}
@@ -0,0 +1,37 @@
+package model
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type SecurityEventType string
+
+const (
+	// SecurityRefreshTokenReused is raised when a refresh token that was
+	// already exchanged is presented again. Either the legitimate client or
+	// whoever stole the token replayed it; the session is revoked for both.
+	SecurityRefreshTokenReused SecurityEventType = "refresh_token_reused"
+	// SecurityIPBindingMismatch is raised when a token is used by a client
+	// its IP binding mode does not allow.
+	SecurityIPBindingMismatch SecurityEventType = "ip_binding_mismatch"
+)
+
+// SecurityEvent records something suspicious about a user's credentials for
+// administrators to follow up on.
+type SecurityEvent struct {
+	ID        primitive.ObjectID  `json:"_id" bson:"_id,omitempty"`
+	Type      SecurityEventType   `json:"type" bson:"type"`
+	UserID    primitive.ObjectID  `json:"user_id" bson:"user_id"`
+	SessionID *primitive.ObjectID `json:"session_id,omitempty" bson:"session_id,omitempty"`
+	IP        string              `json:"ip" bson:"ip"`
+	UserAgent string              `json:"user_agent,omitempty" bson:"user_agent,omitempty"`
+	Detail    string              `json:"detail,omitempty" bson:"detail,omitempty"`
+	CreatedAt time.Time           `json:"created_at" bson:"created_at"`
+}
+
+type SecurityEventRepository interface {
+	Create(ctx context.Context, event *SecurityEvent) error
+}
@@ -0,0 +1,104 @@
+package model
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type SessionRevokeReason string
+
+const (
+	SessionLogout    SessionRevokeReason = "logout"
+	SessionLogoutAll SessionRevokeReason = "logout_all"
+	// SessionKilled was ended by an administrator.
+	SessionKilled SessionRevokeReason = "killed"
+	// SessionUserDisabled ended because the user may no longer log in.
+	SessionUserDisabled SessionRevokeReason = "user_disabled"
+	// SessionRefreshReused ended because a refresh token that had already
+	// been exchanged was presented again, so it may have been stolen.
+	SessionRefreshReused SessionRevokeReason = "refresh_reused"
+)
+
+// Session is one login of a user and the family of refresh tokens issued for
+// it. Every token carries its ID as the sid claim, so revoking the session
+// revokes them all. Only the latest refresh token, named by RefreshJTI, can
+// be exchanged; presenting an earlier one revokes the session, except for the
+// one it replaced within RefreshReuseGrace.
+type Session struct {
+	ID         primitive.ObjectID `json:"_id" bson:"_id,omitempty"`
+	UserID     primitive.ObjectID `json:"user_id" bson:"user_id"`
+	RefreshJTI string             `json:"-" bson:"refresh_jti"`
+	// PreviousJTI is the refresh token RefreshJTI replaced at RotatedAt.
+	PreviousJTI string     `json:"-" bson:"previous_refresh_jti,omitempty"`
+	RotatedAt   *time.Time `json:"-" bson:"rotated_at,omitempty"`
+	// Rotations counts the refresh tokens exchanged so far.
+	Rotations int `json:"rotations" bson:"rotations"`
+
+	// Device the session was started from, for users to tell their
+	// sessions apart.
+	IP        string `json:"ip" bson:"ip"`
+	LastIP    string `json:"last_ip" bson:"last_ip"`
+	UserAgent string `json:"user_agent" bson:"user_agent"`
+	// Current marks the session the listing was requested with.
+	Current bool `json:"current" bson:"-"`
+
+	CreatedAt    time.Time           `json:"created_at" bson:"created_at"`
+	LastUsedAt   time.Time           `json:"last_used_at" bson:"last_used_at"`
+	ExpiresAt    time.Time           `json:"expires_at" bson:"expires_at"`
+	RevokedAt    *time.Time          `json:"revoked_at,omitempty" bson:"revoked_at,omitempty"`
+	RevokedBy    *primitive.ObjectID `json:"revoked_by,omitempty" bson:"revoked_by,omitempty"`
+	RevokeReason SessionRevokeReason `json:"revoke_reason,omitempty" bson:"revoke_reason,omitempty"`
+}
+
+// IsActive reports whether tokens of the session are still accepted at now.
+func (s *Session) IsActive(now time.Time) bool {
+	return s.RevokedAt == nil && s.ExpiresAt.After(now)
+}
+
+// RefreshReuseGrace is how long after a rotation the refresh token it
+// replaced is still accepted, so concurrent refreshes from several tabs and
+// retries of a refresh whose response was lost are not taken for replays.
+const RefreshReuseGrace = 10 * time.Second
+
+// IsRecentlyRotated reports whether jti is the refresh token the session
+// replaced no longer than RefreshReuseGrace before now.
+func (s *Session) IsRecentlyRotated(jti string, now time.Time) bool {
+	return jti != "" && s.PreviousJTI == jti && s.RotatedAt != nil && now.Sub(*s.RotatedAt) <= RefreshReuseGrace
+}
+
+// RevokedSession is what the revocation cache keeps of a revoked session:
+// it is forgotten once every token of the session has expired.
+type RevokedSession struct {
+	ID        primitive.ObjectID `bson:"_id"`
+	ExpiresAt time.Time          `bson:"expires_at"`
+}
+
+type SessionRepository interface {
+	Create(ctx context.Context, session *Session) error
+	FindByID(ctx context.Context, sessionID primitive.ObjectID) (*Session, error)
+	// FindActiveByUserID lists the sessions of userID still active at now,
+	// most recently used first.
+	FindActiveByUserID(ctx context.Context, userID primitive.ObjectID, now time.Time) ([]Session, error)
+	// Rotate replaces the refresh token of an active session, only when
+	// oldJTI is still its latest, and reports whether it did.
+	Rotate(ctx context.Context, sessionID primitive.ObjectID, oldJTI string, newJTI string, ip string, now time.Time, expiresAt time.Time) (bool, error)
+	// Revoke ends one active session of userID and returns it, or nil when
+	// there is none. revokedBy is zero when the system revoked it.
+	Revoke(ctx context.Context, sessionID primitive.ObjectID, userID primitive.ObjectID, reason SessionRevokeReason, revokedBy primitive.ObjectID, at time.Time) (*RevokedSession, error)
+	// RevokeAll ends every active session of userID and returns them.
+	RevokeAll(ctx context.Context, userID primitive.ObjectID, reason SessionRevokeReason, revokedBy primitive.ObjectID, at time.Time) ([]RevokedSession, error)
+	// FindRevokedSince lists the sessions revoked at or after since whose
+	// tokens have not all expired at now.
+	FindRevokedSince(ctx context.Context, since time.Time, now time.Time) ([]RevokedSession, error)
+}
+
+// SessionRevocations answers whether a session was revoked without a
+// database round trip, for checking every authenticated request.
+type SessionRevocations interface {
+	IsRevoked(sessionID primitive.ObjectID) bool
+	// Add records sessions this instance revoked so they are refused at
+	// once; other instances learn of them when they next poll.
+	Add(sessions ...RevokedSession)
+}
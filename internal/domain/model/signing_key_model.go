@@ -0,0 +1,24 @@
+package model
+
+// JSONWebKey is the public half of a token signing key as published in a
+// JWKS (RFC 7517). RSA keys fill N and E, Ed25519 keys Crv and X.
+type JSONWebKey struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty"`
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+}
+
+type JSONWebKeySet struct {
+	Keys []JSONWebKey `json:"keys"`
+}
+
+// PublicKeySet publishes the keys access tokens are verified with, so other
+// services can verify them without sharing a secret.
+type PublicKeySet interface {
+	JWKS() JSONWebKeySet
+}
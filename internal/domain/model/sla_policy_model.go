@@ -0,0 +1,123 @@
+package model
+
+import (
+	"context"
+	"slices"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// SLAStages are the statuses in which a request waits on someone and can
+// therefore fall behind. Requests in any other status have no due date.
+var SLAStages = []RequestStatus{ReqStatusNew, ReqStatusAuthorized, ReqStatusValidated, ReqStatusApproved}
+
+// SLAPolicy is how long a request may wait in one stage of the workflow. A
+// policy without a travel purpose applies to every purpose that has no policy
+// of its own for the stage.
+type SLAPolicy struct {
+	ID                primitive.ObjectID  `json:"_id" bson:"_id,omitempty"`
+	Name              string              `json:"name" bson:"name"`
+	Stage             RequestStatus       `json:"stage" bson:"stage"`
+	TravelPurposeID   *primitive.ObjectID `json:"travel_purpose_id,omitempty" bson:"travel_purpose_id,omitempty"`
+	DueAfterHours     int                 `json:"due_after_hours" bson:"due_after_hours"`
+	RemindBeforeHours int                 `json:"remind_before_hours" bson:"remind_before_hours"`
+	// AutoDecline declines approved requests that are not accepted in time.
+	// Only policies of the approved stage may set it.
+	AutoDecline bool                `json:"auto_decline" bson:"auto_decline"`
+	IsActive    bool                `json:"is_active" bson:"is_active"`
+	CreatedAt   time.Time           `json:"created_at" bson:"created_at"`
+	UpdatedAt   time.Time           `json:"updated_at" bson:"updated_at"`
+	CreatedBy   primitive.ObjectID  `json:"created_by" bson:"created_by"`
+	UpdatedBy   *primitive.ObjectID `json:"updated_by,omitempty" bson:"updated_by,omitempty"`
+	DeletedBy   *primitive.ObjectID `json:"deleted_by,omitempty" bson:"deleted_by,omitempty"`
+	DeletedAt   *time.Time          `json:"deleted_at,omitempty" bson:"deleted_at,omitempty"`
+	IsDeleted   bool                `json:"is_deleted" bson:"is_deleted"`
+}
+
+// Schedule starts the clock of the policy for a request entering its stage at
+// the given time.
+func (p *SLAPolicy) Schedule(at time.Time) (time.Time, *RequestSLA) {
+	due := at.Add(time.Duration(p.DueAfterHours) * time.Hour)
+
+	sla := &RequestSLA{
+		PolicyID:    p.ID,
+		Stage:       p.Stage,
+		StartedAt:   at,
+		AutoDecline: p.AutoDecline,
+	}
+	if p.RemindBeforeHours > 0 {
+		remindAt := due.Add(-time.Duration(p.RemindBeforeHours) * time.Hour)
+		sla.RemindAt = &remindAt
+	}
+
+	return due, sla
+}
+
+// SelectSLAPolicy picks the policy for a request entering stage: the one for
+// its travel purpose if there is one, otherwise the one for all purposes. It
+// returns nil when neither exists.
+func SelectSLAPolicy(policies []SLAPolicy, stage RequestStatus, travelPurposeID primitive.ObjectID) *SLAPolicy {
+	var general *SLAPolicy
+	for i := range policies {
+		p := &policies[i]
+		if p.Stage != stage {
+			continue
+		}
+
+		if p.TravelPurposeID == nil {
+			if general == nil {
+				general = p
+			}
+		} else if *p.TravelPurposeID == travelPurposeID {
+			return p
+		}
+	}
+
+	return general
+}
+
+// IsSLAStage reports whether requests in status can fall behind.
+func IsSLAStage(status RequestStatus) bool {
+	return slices.Contains(SLAStages, status)
+}
+
+// RequestSLA tracks the clock of the stage a request is waiting in. It is
+// replaced on every transition, together with the request's due date.
+type RequestSLA struct {
+	PolicyID    primitive.ObjectID `json:"policy_id" bson:"policy_id"`
+	Stage       RequestStatus      `json:"stage" bson:"stage"`
+	StartedAt   time.Time          `json:"started_at" bson:"started_at"`
+	RemindAt    *time.Time         `json:"remind_at,omitempty" bson:"remind_at,omitempty"`
+	RemindedAt  *time.Time         `json:"reminded_at,omitempty" bson:"reminded_at,omitempty"`
+	EscalatedAt *time.Time         `json:"escalated_at,omitempty" bson:"escalated_at,omitempty"`
+	AutoDecline bool               `json:"auto_decline,omitempty" bson:"auto_decline,omitempty"`
+}
+
+// SLARun reports what one pass over the request deadlines did.
+type SLARun struct {
+	Reminded  int `json:"reminded"`
+	Escalated int `json:"escalated"`
+	Declined  int `json:"declined"`
+}
+
+type CreateSLAPolicyDTO struct {
+	Name              string `json:"name" binding:"required,min=3,max=100,excludesall=<>"`
+	Stage             string `json:"stage" binding:"required,oneof=New Authorized Validated Approved"`
+	TravelPurposeID   string `json:"travel_purpose_id" binding:"omitempty,alphanum,len=24"`
+	DueAfterHours     int    `json:"due_after_hours" binding:"required,gt=0"`
+	RemindBeforeHours int    `json:"remind_before_hours" binding:"gte=0,ltfield=DueAfterHours"`
+	AutoDecline       bool   `json:"auto_decline"`
+	IsActive          *bool  `json:"is_active"`
+}
+
+type UpdateSLAPolicyDTO = CreateSLAPolicyDTO
+
+type SLAPolicyRepository interface {
+	Create(ctx context.Context, policy *SLAPolicy) error
+	FindByID(ctx context.Context, policyID primitive.ObjectID) (*SLAPolicy, error)
+	FindAll(ctx context.Context) ([]SLAPolicy, error)
+	FindActive(ctx context.Context) ([]SLAPolicy, error)
+	Update(ctx context.Context, policyID primitive.ObjectID, policy *SLAPolicy) error
+	Delete(ctx context.Context, policyID primitive.ObjectID, policy *SLAPolicy) error
+}
@@ -0,0 +1,103 @@
+package model_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/latiiLA/coop-forex-server/internal/common"
+	"github.com/latiiLA/coop-forex-server/internal/domain/model"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestAllocationLimitMatches(t *testing.T) {
+	purposeID := primitive.NewObjectID()
+	countryID := primitive.NewObjectID()
+	customerTypeID := primitive.NewObjectID()
+
+	request := &model.Request{
+		TravelPurposeID: purposeID,
+		TravelCountryID: countryID,
+		RequestingAs:    "self",
+	}
+
+	cases := []struct {
+		name  string
+		limit model.AllocationLimit
+		want  bool
+	}{
+		{"no criteria", model.AllocationLimit{}, true},
+		{"same purpose and country", model.AllocationLimit{TravelPurposeID: &purposeID, CountryID: &countryID}, true},
+		{"other purpose", model.AllocationLimit{TravelPurposeID: &countryID}, false},
+		{"other requesting as", model.AllocationLimit{RequestingAs: "agent"}, false},
+		{"customer type on request without one", model.AllocationLimit{CustomerTypeID: &customerTypeID}, false},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := tc.limit.Matches(request); got != tc.want {
+				t.Errorf("Matches() = %v; expected %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestAllocationLimitMeasure(t *testing.T) {
+	usd := primitive.NewObjectID()
+	eur := primitive.NewObjectID()
+	gbp := primitive.NewObjectID()
+
+	lines := []model.RequestRateSnapshot{
+		{CurrencyID: usd, Amount: 100, EtbEquivalent: 15000},
+		{CurrencyID: eur, Amount: 50, EtbEquivalent: 8000},
+	}
+
+	total, counted := (&model.AllocationLimit{}).Measure(lines)
+	if !counted || total != 23000 {
+		t.Errorf("birr limit measured %v, %v; expected 23000, true", total, counted)
+	}
+
+	total, counted = (&model.AllocationLimit{CurrencyID: &eur}).Measure(lines)
+	if !counted || total != 50 {
+		t.Errorf("EUR limit measured %v, %v; expected 50, true", total, counted)
+	}
+
+	if _, counted = (&model.AllocationLimit{CurrencyID: &gbp}).Measure(lines); counted {
+		t.Errorf("GBP limit counted a request without GBP")
+	}
+
+	usage := []model.AllocationUsage{{CurrencyID: usd, Amount: 300, EtbEquivalent: 45000}}
+	if used := (&model.AllocationLimit{CurrencyID: &usd}).MeasureUsage(usage); used != 300 {
+		t.Errorf("USD usage = %v; expected 300", used)
+	}
+}
+
+func TestAllocationLimitWindowStart(t *testing.T) {
+	at := time.Date(2026, 10, 17, 15, 30, 0, 0, time.UTC)
+
+	cases := map[model.AllocationLimitScope]*time.Time{
+		model.LimitPerRequest:       nil,
+		model.LimitApplicant30Days:  ptrTime(time.Date(2026, 9, 17, 15, 30, 0, 0, time.UTC)),
+		model.LimitApplicant365Days: ptrTime(time.Date(2025, 10, 17, 15, 30, 0, 0, time.UTC)),
+		model.LimitDailyBudget:      ptrTime(time.Date(2026, 10, 17, 0, 0, 0, 0, time.UTC)),
+	}
+
+	for scope, want := range cases {
+		got := (&model.AllocationLimit{Scope: scope}).WindowStart(at)
+		if (got == nil) != (want == nil) || (got != nil && !got.Equal(*want)) {
+			t.Errorf("WindowStart(%s) = %v; expected %v", scope, got, want)
+		}
+	}
+}
+
+func TestAllocationLimitError(t *testing.T) {
+	err := error(&model.AllocationLimitError{Violations: []model.AllocationViolation{{LimitName: "Daily USD", MaxAmount: 10}}})
+
+	if !errors.Is(err, common.ErrAllocationLimitExceeded) {
+		t.Errorf("AllocationLimitError does not match ErrAllocationLimitExceeded")
+	}
+}
+
+func ptrTime(t time.Time) *time.Time {
+	return &t
+}
@@ -0,0 +1,66 @@
+package model_test
+
+import (
+	"testing"
+
+	"github.com/latiiLA/coop-forex-server/internal/domain/model"
+)
+
+func TestIPBindingModeMatches(t *testing.T) {
+	const fingerprint = "device-a"
+
+	cases := []struct {
+		name   string
+		mode   model.IPBindingMode
+		client model.ClientInfo
+		want   bool
+	}{
+		{"strict same ip", model.IPBindingStrict, model.ClientInfo{IP: "10.1.2.3"}, true},
+		{"strict mapped ipv4", model.IPBindingStrict, model.ClientInfo{IP: "::ffff:10.1.2.3"}, true},
+		{"strict other ip", model.IPBindingStrict, model.ClientInfo{IP: "10.1.2.4"}, false},
+		{"subnet same /24", model.IPBindingSubnet, model.ClientInfo{IP: "10.1.2.200"}, true},
+		{"subnet other /24", model.IPBindingSubnet, model.ClientInfo{IP: "10.1.3.3"}, false},
+		{"subnet invalid ip", model.IPBindingSubnet, model.ClientInfo{IP: "unknown"}, false},
+		{"device same fingerprint", model.IPBindingDevice, model.ClientInfo{IP: "192.168.0.9", Fingerprint: fingerprint}, true},
+		{"device other fingerprint", model.IPBindingDevice, model.ClientInfo{IP: "10.1.2.3", Fingerprint: "device-b"}, false},
+		{"off", model.IPBindingOff, model.ClientInfo{IP: "192.168.0.9"}, true},
+		{"unknown mode is strict", model.IPBindingMode("loose"), model.ClientInfo{IP: "10.1.2.4"}, false},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if got := c.mode.Matches("10.1.2.3", fingerprint, c.client); got != c.want {
+				t.Errorf("Matches() = %v; expected %v", got, c.want)
+			}
+		})
+	}
+}
+
+func TestIPBindingSubnetOfIPv6(t *testing.T) {
+	if !model.IPBindingSubnet.Matches("2001:db8:0:1::5", "", model.ClientInfo{IP: "2001:db8:0:1:ffff::9"}) {
+		t.Error("addresses of one /64 do not match")
+	}
+	if model.IPBindingSubnet.Matches("2001:db8:0:1::5", "", model.ClientInfo{IP: "2001:db8:0:2::5"}) {
+		t.Error("addresses of different /64s match")
+	}
+}
+
+func TestIPBindingDeviceNeedsFingerprint(t *testing.T) {
+	if model.IPBindingDevice.Matches("10.1.2.3", "", model.ClientInfo{IP: "10.1.2.3"}) {
+		t.Error("token without a fingerprint matches in device mode")
+	}
+}
+
+func TestIPBindingPolicyModeFor(t *testing.T) {
+	policy := model.IPBindingPolicy{
+		Default: model.IPBindingStrict,
+		Roles:   map[string]model.IPBindingMode{"SUPERADMIN": model.IPBindingDevice},
+	}
+
+	if mode := policy.ModeFor("superAdmin"); mode != model.IPBindingDevice {
+		t.Errorf("ModeFor(superAdmin) = %q; expected device", mode)
+	}
+	if mode := policy.ModeFor("branch_user"); mode != model.IPBindingStrict {
+		t.Errorf("ModeFor(branch_user) = %q; expected the default", mode)
+	}
+}
@@ -0,0 +1,74 @@
+package model_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/latiiLA/coop-forex-server/internal/domain/model"
+)
+
+func TestNotificationRetryPolicyBackoff(t *testing.T) {
+	policy := model.NotificationRetryPolicy{MaxAttempts: 5, BaseDelay: 30 * time.Second, MaxDelay: 5 * time.Minute}
+
+	cases := map[int]time.Duration{
+		1:  30 * time.Second,
+		2:  time.Minute,
+		3:  2 * time.Minute,
+		4:  4 * time.Minute,
+		5:  5 * time.Minute,
+		40: 5 * time.Minute,
+	}
+
+	for attempts, expected := range cases {
+		if actual := policy.Backoff(attempts); actual != expected {
+			t.Errorf("Backoff(%d) = %v; expected %v", attempts, actual, expected)
+		}
+	}
+}
+
+func TestNotificationRetryPolicyExhausted(t *testing.T) {
+	policy := model.NotificationRetryPolicy{MaxAttempts: 3}
+
+	if policy.Exhausted(2) {
+		t.Errorf("Exhausted(2) = true; expected false")
+	}
+	if !policy.Exhausted(3) {
+		t.Errorf("Exhausted(3) = false; expected true")
+	}
+}
+
+func TestDefaultNotificationRecipientsCoverEveryEvent(t *testing.T) {
+	for _, event := range model.NotificationEvents {
+		if len(model.DefaultNotificationRecipients[event]) == 0 {
+			t.Errorf("%s has no default recipients", event)
+		}
+	}
+}
+
+func TestRequestNextStepsFollowTheWorkflow(t *testing.T) {
+	expected := map[model.RequestStatus]string{
+		model.ReqStatusNew:        "request:authorize",
+		model.ReqStatusAuthorized: "request:validate",
+		model.ReqStatusValidated:  "request:approve",
+		model.ReqStatusApproved:   "request:process",
+	}
+
+	for status, permission := range expected {
+		if actual := model.RequestNextSteps[status].Permission; actual != permission {
+			t.Errorf("next step from %s = %q; expected %q", status, actual, permission)
+		}
+	}
+
+	for _, status := range []model.RequestStatus{model.ReqStatusRejected, model.ReqStatusDeclined, model.ReqStatusAccepted} {
+		if _, ok := model.RequestNextSteps[status]; ok {
+			t.Errorf("%s should end the workflow", status)
+		}
+	}
+
+	// Reminders go to whoever the request waits for.
+	for _, stage := range model.SLAStages {
+		if _, ok := model.RequestNextSteps[stage]; !ok {
+			t.Errorf("SLA stage %s has no next step", stage)
+		}
+	}
+}
@@ -0,0 +1,47 @@
+package model_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/latiiLA/coop-forex-server/internal/common"
+	"github.com/latiiLA/coop-forex-server/internal/domain/model"
+)
+
+func TestRequestDuplicateCheckBlocks(t *testing.T) {
+	open := model.RequestDuplicateLink{RequestStatus: model.ReqStatusValidated}
+	finished := model.RequestDuplicateLink{RequestStatus: model.ReqStatusAccepted}
+
+	block := model.DuplicatePolicy{Mode: model.DuplicatePolicyBlock, VelocityMax: 3}
+	flag := model.DuplicatePolicy{Mode: model.DuplicatePolicyFlag, VelocityMax: 3}
+
+	cases := []struct {
+		name   string
+		check  model.RequestDuplicateCheck
+		policy model.DuplicatePolicy
+		want   bool
+	}{
+		{"no links", model.RequestDuplicateCheck{Velocity: 1}, block, false},
+		{"open link", model.RequestDuplicateCheck{Links: []model.RequestDuplicateLink{open}, Velocity: 2}, block, true},
+		{"finished link only", model.RequestDuplicateCheck{Links: []model.RequestDuplicateLink{finished}, Velocity: 2}, block, false},
+		{"velocity at maximum", model.RequestDuplicateCheck{Velocity: 3}, block, false},
+		{"velocity above maximum", model.RequestDuplicateCheck{Velocity: 4}, block, true},
+		{"flag policy never blocks", model.RequestDuplicateCheck{Links: []model.RequestDuplicateLink{open}, Velocity: 4}, flag, false},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if got := c.check.Blocks(c.policy); got != c.want {
+				t.Errorf("Blocks() = %v; expected %v", got, c.want)
+			}
+		})
+	}
+}
+
+func TestDuplicateRequestErrorIs(t *testing.T) {
+	err := error(&model.DuplicateRequestError{Check: &model.RequestDuplicateCheck{Velocity: 2}})
+
+	if !errors.Is(err, common.ErrDuplicateRequest) {
+		t.Errorf("DuplicateRequestError does not unwrap to ErrDuplicateRequest")
+	}
+}
@@ -0,0 +1,52 @@
+package model_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/latiiLA/coop-forex-server/internal/domain/model"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestDiffRequestUpdates(t *testing.T) {
+	actorID := primitive.NewObjectID()
+	visaID := primitive.NewObjectID()
+	now := time.Now()
+
+	before := model.RequestUpdate{
+		ApplicantName:    "Abebe",
+		RequestStatus:    string(model.ReqStatusDrafted),
+		AccountsToDeduct: []string{},
+		UpdatedAt:        now.Add(-time.Hour),
+	}
+	after := before
+	after.ApplicantName = "Abebe Kebede"
+	after.VisaAttachment = &visaID
+	after.AccountsToDeduct = nil
+	after.UpdatedAt = now
+	after.UpdatedBy = &actorID
+
+	changes := model.DiffRequestUpdates(&before, &after)
+
+	got := map[string]model.RequestFieldChange{}
+	for _, change := range changes {
+		got[change.Field] = change
+	}
+
+	if len(got) != 2 {
+		t.Fatalf("expected 2 changes, got %d: %+v", len(got), changes)
+	}
+
+	name, ok := got["applicant_name"]
+	if !ok || name.Before != "Abebe" || name.After != "Abebe Kebede" {
+		t.Errorf("applicant_name change = %+v", name)
+	}
+
+	visa, ok := got["visa_attachment"]
+	if !ok || visa.Before != nil || visa.After != visaID {
+		t.Errorf("visa_attachment change = %+v", visa)
+	}
+	if !model.IsAttachmentField(visa.Field) {
+		t.Errorf("IsAttachmentField(%q) = false; expected true", visa.Field)
+	}
+}
@@ -0,0 +1,81 @@
+package model_test
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/latiiLA/coop-forex-server/internal/domain/model"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestNewRequestScope(t *testing.T) {
+	branchID := primitive.NewObjectID()
+	otherBranchID := primitive.NewObjectID()
+	profile := &model.Profile{BranchID: &branchID}
+
+	own := &model.Request{BranchID: &branchID, RequestStatus: model.ReqStatusDrafted}
+	other := &model.Request{BranchID: &otherBranchID, RequestStatus: model.ReqStatusDrafted}
+	approved := &model.Request{BranchID: &otherBranchID, RequestStatus: model.ReqStatusApproved}
+
+	cases := []struct {
+		name        string
+		permissions []string
+		request     *model.Request
+		want        bool
+	}{
+		{"org status sees own branch", []string{"request:status"}, own, true},
+		{"org status does not see other branch", []string{"request:status"}, other, false},
+		{"org drafted sees own drafts", []string{"request:view-orgdrafted"}, own, true},
+		{"org approved does not see drafts", []string{"request:view-orgapproved"}, own, false},
+		{"view sees approved bank-wide", []string{"request:view"}, approved, true},
+		{"view does not see drafts", []string{"request:view"}, other, false},
+		{"status view sees approved bank-wide", []string{"request:view-approved"}, approved, true},
+		{"no permission sees nothing", nil, own, false},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			scope := model.NewRequestScope(tc.permissions, profile)
+			if got := scope.Allows(tc.request); got != tc.want {
+				t.Errorf("Allows() = %v; expected %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestRequestScopeRestrict(t *testing.T) {
+	branchID := primitive.NewObjectID()
+	scope := model.NewRequestScope([]string{"request:view", "request:status"}, &model.Profile{BranchID: &branchID})
+
+	restricted := scope.Restrict([]model.RequestStatus{model.ReqStatusNew})
+	if len(restricted.AllStatuses) != 0 {
+		t.Errorf("AllStatuses = %v; expected none", restricted.AllStatuses)
+	}
+	if !slices.Equal(restricted.OrgStatuses, []model.RequestStatus{model.ReqStatusNew}) {
+		t.Errorf("OrgStatuses = %v; expected [New]", restricted.OrgStatuses)
+	}
+
+	if !scope.Restrict([]model.RequestStatus{model.ReqStatusDeleted}).IsEmpty() {
+		t.Errorf("restricting to Deleted should leave an empty scope")
+	}
+}
+
+func TestParseRequestSortAndStatuses(t *testing.T) {
+	field, desc, ok := model.ParseRequestSort("")
+	if !ok || field != "created_at" || !desc {
+		t.Errorf("default sort = (%q, %v, %v)", field, desc, ok)
+	}
+
+	if _, _, ok := model.ParseRequestSort("password"); ok {
+		t.Errorf("sorting by an unknown field should be rejected")
+	}
+
+	statuses, ok := model.ParseRequestStatuses([]string{"New,Approved", "New"})
+	if !ok || !slices.Equal(statuses, []model.RequestStatus{model.ReqStatusNew, model.ReqStatusApproved}) {
+		t.Errorf("ParseRequestStatuses = (%v, %v)", statuses, ok)
+	}
+
+	if _, ok := model.ParseRequestStatuses([]string{"Deleted"}); ok {
+		t.Errorf("Deleted should not be accepted as a listing filter")
+	}
+}
@@ -0,0 +1,63 @@
+package model_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/latiiLA/coop-forex-server/internal/domain/model"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestRequestTransitionsAllows(t *testing.T) {
+	cases := []struct {
+		action model.RequestAction
+		from   model.RequestStatus
+		want   bool
+	}{
+		{model.ReqActionSend, model.ReqStatusDrafted, true},
+		{model.ReqActionSend, model.ReqStatusRejected, true},
+		{model.ReqActionSend, model.ReqStatusApproved, false},
+		{model.ReqActionAuthorize, model.ReqStatusNew, true},
+		{model.ReqActionAuthorize, model.ReqStatusAccepted, false},
+		{model.ReqActionValidate, model.ReqStatusAuthorized, true},
+		{model.ReqActionApprove, model.ReqStatusValidated, true},
+		{model.ReqActionApprove, model.ReqStatusDrafted, false},
+		{model.ReqActionAccept, model.ReqStatusApproved, true},
+		{model.ReqActionAccept, model.ReqStatusAccepted, false},
+		{model.ReqActionDecline, model.ReqStatusApproved, true},
+		{model.ReqActionReject, model.ReqStatusValidated, true},
+		{model.ReqActionReject, model.ReqStatusApproved, false},
+		{model.ReqActionDelete, model.ReqStatusDrafted, true},
+		{model.ReqActionDelete, model.ReqStatusNew, false},
+	}
+
+	for _, tc := range cases {
+		t.Run(string(tc.action)+"/"+string(tc.from), func(t *testing.T) {
+			transition, ok := model.RequestTransitions[tc.action]
+			if !ok {
+				t.Fatalf("transition %q is not declared", tc.action)
+			}
+			if got := transition.Allows(tc.from); got != tc.want {
+				t.Errorf("Allows(%q) = %v; expected %v", tc.from, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestRequestTransitionApply(t *testing.T) {
+	actorID := primitive.NewObjectID()
+	now := time.Now()
+
+	request := model.RequestUpdate{RequestStatus: string(model.ReqStatusValidated)}
+	model.RequestTransitions[model.ReqActionApprove].Apply(&request, actorID, now)
+
+	if request.RequestStatus != string(model.ReqStatusApproved) {
+		t.Errorf("RequestStatus = %q; expected %q", request.RequestStatus, model.ReqStatusApproved)
+	}
+	if request.ApprovedBy == nil || *request.ApprovedBy != actorID {
+		t.Errorf("ApprovedBy was not stamped with the actor")
+	}
+	if request.ApprovedAt == nil || !request.ApprovedAt.Equal(now) {
+		t.Errorf("ApprovedAt was not stamped with the transition time")
+	}
+}
@@ -0,0 +1,39 @@
+package model_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/latiiLA/coop-forex-server/internal/domain/model"
+)
+
+func TestSessionIsRecentlyRotated(t *testing.T) {
+	rotatedAt := time.Date(2026, 10, 17, 9, 0, 0, 0, time.UTC)
+	session := model.Session{RefreshJTI: "current", PreviousJTI: "previous", RotatedAt: &rotatedAt}
+
+	cases := []struct {
+		name string
+		jti  string
+		now  time.Time
+		want bool
+	}{
+		{"previous within grace", "previous", rotatedAt.Add(2 * time.Second), true},
+		{"previous at end of grace", "previous", rotatedAt.Add(model.RefreshReuseGrace), true},
+		{"previous after grace", "previous", rotatedAt.Add(model.RefreshReuseGrace + time.Second), false},
+		{"older token within grace", "older", rotatedAt.Add(time.Second), false},
+		{"empty jti", "", rotatedAt.Add(time.Second), false},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if got := session.IsRecentlyRotated(c.jti, c.now); got != c.want {
+				t.Errorf("IsRecentlyRotated() = %v; expected %v", got, c.want)
+			}
+		})
+	}
+
+	neverRotated := model.Session{RefreshJTI: "current"}
+	if neverRotated.IsRecentlyRotated("", rotatedAt) {
+		t.Error("a session that was never rotated accepts no previous token")
+	}
+}
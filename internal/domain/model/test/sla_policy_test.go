@@ -0,0 +1,74 @@
+package model_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/latiiLA/coop-forex-server/internal/domain/model"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestSelectSLAPolicyPrefersTheTravelPurpose(t *testing.T) {
+	medical := primitive.NewObjectID()
+	study := primitive.NewObjectID()
+
+	policies := []model.SLAPolicy{
+		{Name: "approve any", Stage: model.ReqStatusValidated},
+		{Name: "authorize any", Stage: model.ReqStatusNew},
+		{Name: "authorize medical", Stage: model.ReqStatusNew, TravelPurposeID: &medical},
+	}
+
+	cases := []struct {
+		name    string
+		stage   model.RequestStatus
+		purpose primitive.ObjectID
+		want    string
+	}{
+		{"purpose policy", model.ReqStatusNew, medical, "authorize medical"},
+		{"general policy", model.ReqStatusNew, study, "authorize any"},
+		{"general policy of another stage", model.ReqStatusValidated, medical, "approve any"},
+		{"no policy", model.ReqStatusApproved, medical, ""},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := ""
+			if policy := model.SelectSLAPolicy(policies, tc.stage, tc.purpose); policy != nil {
+				got = policy.Name
+			}
+			if got != tc.want {
+				t.Errorf("SelectSLAPolicy() = %q; expected %q", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestSLAPolicySchedule(t *testing.T) {
+	at := time.Date(2026, 10, 17, 9, 0, 0, 0, time.UTC)
+
+	policy := model.SLAPolicy{Stage: model.ReqStatusApproved, DueAfterHours: 48, RemindBeforeHours: 6, AutoDecline: true}
+	due, sla := policy.Schedule(at)
+
+	if want := at.Add(48 * time.Hour); !due.Equal(want) {
+		t.Errorf("due = %v; expected %v", due, want)
+	}
+	if want := at.Add(42 * time.Hour); sla.RemindAt == nil || !sla.RemindAt.Equal(want) {
+		t.Errorf("remind at = %v; expected %v", sla.RemindAt, want)
+	}
+	if !sla.StartedAt.Equal(at) || sla.Stage != model.ReqStatusApproved || !sla.AutoDecline {
+		t.Errorf("sla = %+v; expected the approved stage started at %v with auto decline", sla, at)
+	}
+
+	policy.RemindBeforeHours = 0
+	if _, sla := policy.Schedule(at); sla.RemindAt != nil {
+		t.Errorf("remind at = %v; expected no reminder", sla.RemindAt)
+	}
+}
+
+func TestOnlySLAStagesFallBehind(t *testing.T) {
+	for _, status := range []model.RequestStatus{model.ReqStatusAccepted, model.ReqStatusDeclined, model.ReqStatusRejected} {
+		if model.IsSLAStage(status) {
+			t.Errorf("%s is an SLA stage; requests in it wait on nobody", status)
+		}
+	}
+}
@@ -27,4 +27,7 @@ type TokenResponseDTO struct {
 type TokenBlacklistRepository interface {
 	BlacklistToken(ctx context.Context, token string, expiresAt time.Time, userID primitive.ObjectID, ip string) error
 	IsBlacklisted(ctx context.Context, token string) (bool, error)
+	// DeleteExpired removes the tokens that expired before now and returns
+	// how many it removed.
+	DeleteExpired(ctx context.Context, now time.Time) (int64, error)
 }
@@ -19,26 +19,29 @@ const (
 )
 
 type User struct {
-	ID          primitive.ObjectID  `json:"_id" bson:"_id,omitempty"`
-	RoleID      primitive.ObjectID  `json:"role_id" bson:"role_id"`
-	Role        *Role               `json:"role,omitempty" bson:"role,omitempty"`
-	Permissions []string            `json:"permissions,omitempty" bson:"permissions,omitempty"`
-	ProfileID   primitive.ObjectID  `json:"profile_id" bson:"profile_id"`
-	Profile     *Profile            `json:"profile,omitempty" bson:"profile,omitempty"`
-	Username    string              `json:"username" bson:"username"`
-	Password    string              `json:"-" bson:"password,omitempty"`
-	Status      UserStatus          `json:"status" bson:"status"`
-	LastLogin   *time.Time          `json:"last_login,omitempty" bson:"last_login,omitempty"`
-	Signature   *string             `json:"signature,omitempty" bson:"signature,omitempty"`
-	CreatedAt   time.Time           `json:"created_at" bson:"created_at"`
-	UpdatedAt   time.Time           `json:"updated_at" bson:"updated_at"`
-	CreatedBy   primitive.ObjectID  `json:"created_by" bson:"created_by"`
-	Creator     *User               `json:"creator,omitempty" bson:"omitempty"`
-	Updater     *User               `json:"updater,omitempty" bson:"updater,omitempty"`
-	UpdatedBy   *primitive.ObjectID `json:"updated_by,omitempty" bson:"updated_by,omitempty"`
-	DeletedBy   *primitive.ObjectID `json:"deleted_by,omitempty" bson:"deleted_by,omitempty"`
-	DeletedAt   *time.Time          `json:"deleted_at,omitempty" bson:"deleted_at,omitempty"`
-	IsDeleted   bool                `json:"is_deleted" bson:"is_deleted"`
+	ID          primitive.ObjectID `json:"_id" bson:"_id,omitempty"`
+	RoleID      primitive.ObjectID `json:"role_id" bson:"role_id"`
+	Role        *Role              `json:"role,omitempty" bson:"role,omitempty"`
+	Permissions []string           `json:"permissions,omitempty" bson:"permissions,omitempty"`
+	// PermissionsVersion goes up whenever the user's status, role or
+	// permissions change, making access tokens issued before stale.
+	PermissionsVersion int64               `json:"-" bson:"permissions_version"`
+	ProfileID          primitive.ObjectID  `json:"profile_id" bson:"profile_id"`
+	Profile            *Profile            `json:"profile,omitempty" bson:"profile,omitempty"`
+	Username           string              `json:"username" bson:"username"`
+	Password           string              `json:"-" bson:"password,omitempty"`
+	Status             UserStatus          `json:"status" bson:"status"`
+	LastLogin          *time.Time          `json:"last_login,omitempty" bson:"last_login,omitempty"`
+	Signature          *string             `json:"signature,omitempty" bson:"signature,omitempty"`
+	CreatedAt          time.Time           `json:"created_at" bson:"created_at"`
+	UpdatedAt          time.Time           `json:"updated_at" bson:"updated_at"`
+	CreatedBy          primitive.ObjectID  `json:"created_by" bson:"created_by"`
+	Creator            *User               `json:"creator,omitempty" bson:"omitempty"`
+	Updater            *User               `json:"updater,omitempty" bson:"updater,omitempty"`
+	UpdatedBy          *primitive.ObjectID `json:"updated_by,omitempty" bson:"updated_by,omitempty"`
+	DeletedBy          *primitive.ObjectID `json:"deleted_by,omitempty" bson:"deleted_by,omitempty"`
+	DeletedAt          *time.Time          `json:"deleted_at,omitempty" bson:"deleted_at,omitempty"`
+	IsDeleted          bool                `json:"is_deleted" bson:"is_deleted"`
 }
 
 type RegisterRequestDTO struct {
@@ -78,6 +81,12 @@ type UpdateUserRequestDTO struct {
 	BranchID     *primitive.ObjectID `json:"branch_id" binding:"omitempty,len=24,hexadecimal"`
 }
 
+// UpdateUserStatusDTO changes whether a user may log in. Every status but
+// active ends the user's sessions.
+type UpdateUserStatusDTO struct {
+	Status UserStatus `json:"status" binding:"required,oneof=active inactive suspended deactivated"`
+}
+
 type LoginRequestDTO struct {
 	Username string `json:"username" binding:"required,min=3,max=50,alphanum"`
 	Password string `json:"password" binding:"required,min=6,max=50"`
@@ -124,6 +133,19 @@ type UserRepository interface {
 	FindByUsername(c context.Context, username string) (*User, error)
 	FindAll(c context.Context) (*[]UserResponseDTO, error)
 	FindByID(c context.Context, user_id primitive.ObjectID) (*User, error)
+	// FindByPermission lists the users who may log in and hold permission
+	// through their role or directly, optionally only those of one branch
+	// or department.
+	FindByPermission(c context.Context, permission string, branchID *primitive.ObjectID, departmentID *primitive.ObjectID) ([]User, error)
 	Update(c context.Context, user_id primitive.ObjectID, user *User) (*User, error)
+	UpdateStatus(c context.Context, user_id primitive.ObjectID, status UserStatus, updatedBy primitive.ObjectID, at time.Time) error
+	// BumpPermissionsVersion makes the access tokens of a user stale and
+	// returns the user's new version.
+	BumpPermissionsVersion(c context.Context, user_id primitive.ObjectID, at time.Time) (*PermissionsVersion, error)
+	// BumpRolePermissionsVersion does the same for every user of a role.
+	BumpRolePermissionsVersion(c context.Context, roleID primitive.ObjectID, at time.Time) ([]PermissionsVersion, error)
+	// FindPermissionsChangedSince lists the users whose version went up at
+	// or after since.
+	FindPermissionsChangedSince(c context.Context, since time.Time) ([]PermissionsVersion, error)
 	Delete(c context.Context, user_id primitive.ObjectID, user *User) error
 }
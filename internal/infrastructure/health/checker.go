@@ -0,0 +1,140 @@
+// Package health checks the dependencies the server needs to serve requests,
+// for the load balancer's readiness probe.
+package health
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/latiiLA/coop-forex-server/internal/domain/model"
+)
+
+// CheckFunc reports whether a dependency is usable. It must give up when ctx
+// is done.
+type CheckFunc func(ctx context.Context) error
+
+type check struct {
+	name     string
+	required bool
+	run      CheckFunc
+}
+
+// Checker runs its checks concurrently and reports the server ready while
+// every required one passes. Once draining it reports unavailable without
+// checking anything, so the load balancer stops sending traffic while the
+// requests in flight finish.
+type Checker struct {
+	checks []check
+
+	// Timeout bounds each check.
+	Timeout time.Duration
+	// CacheTTL is how long a report is reused, so frequent probes from
+	// several load balancers do not hammer the dependencies.
+	CacheTTL time.Duration
+
+	draining atomic.Bool
+
+	mu       sync.Mutex
+	last     *model.ReadinessReport
+	lastTime time.Time
+}
+
+func NewChecker() *Checker {
+	return &Checker{
+		Timeout:  5 * time.Second,
+		CacheTTL: 2 * time.Second,
+	}
+}
+
+// Require adds a check that must pass for the server to be ready.
+func (c *Checker) Require(name string, run CheckFunc) {
+	c.checks = append(c.checks, check{name: name, required: true, run: run})
+}
+
+// Optional adds a check whose failure only degrades the report.
+func (c *Checker) Optional(name string, run CheckFunc) {
+	c.checks = append(c.checks, check{name: name, run: run})
+}
+
+// Drain makes every later report unavailable.
+func (c *Checker) Drain() {
+	c.draining.Store(true)
+}
+
+func (c *Checker) Check(ctx context.Context) *model.ReadinessReport {
+	if c.draining.Load() {
+		return &model.ReadinessReport{
+			Status:    model.ReadinessUnavailable,
+			Draining:  true,
+			Checks:    []model.HealthCheckResult{},
+			CheckedAt: time.Now(),
+		}
+	}
+
+	// Holding the lock while checking makes concurrent probes share one run.
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.last != nil && time.Since(c.lastTime) < c.CacheTTL {
+		return c.last
+	}
+
+	report := c.run(ctx)
+	c.last = report
+	c.lastTime = report.CheckedAt
+
+	return report
+}
+
+func (c *Checker) run(ctx context.Context) *model.ReadinessReport {
+	results := make([]model.HealthCheckResult, len(c.checks))
+
+	var wg sync.WaitGroup
+	for i, ch := range c.checks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i] = c.runCheck(ctx, ch)
+		}()
+	}
+	wg.Wait()
+
+	report := &model.ReadinessReport{
+		Status:    model.ReadinessOK,
+		Checks:    results,
+		CheckedAt: time.Now(),
+	}
+	for _, result := range results {
+		switch {
+		case result.Healthy:
+		case result.Required:
+			report.Status = model.ReadinessUnavailable
+		case report.Status == model.ReadinessOK:
+			report.Status = model.ReadinessDegraded
+		}
+	}
+
+	return report
+}
+
+func (c *Checker) runCheck(ctx context.Context, ch check) model.HealthCheckResult {
+	ctx, cancel := context.WithTimeout(ctx, c.Timeout)
+	defer cancel()
+
+	started := time.Now()
+	err := ch.run(ctx)
+
+	result := model.HealthCheckResult{
+		Name:     ch.name,
+		Required: ch.required,
+		Healthy:  err == nil,
+		Duration: float64(time.Since(started).Microseconds()) / 1000,
+	}
+	if err != nil {
+		result.Error = err.Error()
+	}
+
+	return result
+}
@@ -0,0 +1,85 @@
+package health
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/textproto"
+	"os"
+
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/readpref"
+)
+
+// Mongo pings the primary, which every write goes to.
+func Mongo(client *mongo.Client) CheckFunc {
+	return func(ctx context.Context) error {
+		return client.Ping(ctx, readpref.Primary())
+	}
+}
+
+// Dial checks that a TCP connection to addr can be opened.
+func Dial(addr string) CheckFunc {
+	return func(ctx context.Context) error {
+		var dialer net.Dialer
+		conn, err := dialer.DialContext(ctx, "tcp", addr)
+		if err != nil {
+			return err
+		}
+
+		return conn.Close()
+	}
+}
+
+// SMTP checks that the server at addr greets with 220, the way it does when
+// it accepts mail, and says goodbye without sending anything.
+func SMTP(addr string) CheckFunc {
+	return func(ctx context.Context) error {
+		var dialer net.Dialer
+		conn, err := dialer.DialContext(ctx, "tcp", addr)
+		if err != nil {
+			return err
+		}
+		defer conn.Close()
+
+		if deadline, ok := ctx.Deadline(); ok {
+			if err := conn.SetDeadline(deadline); err != nil {
+				return err
+			}
+		}
+
+		text := textproto.NewConn(conn)
+		if _, _, err := text.ReadResponse(220); err != nil {
+			return fmt.Errorf("unexpected greeting: %w", err)
+		}
+		// The server is up whatever it answers to QUIT.
+		if id, err := text.Cmd("QUIT"); err == nil {
+			text.StartResponse(id)
+			text.ReadResponse(221)
+			text.EndResponse(id)
+		}
+
+		return nil
+	}
+}
+
+// WritableDir checks that files can be created in dir.
+func WritableDir(dir string) CheckFunc {
+	return func(ctx context.Context) error {
+		file, err := os.CreateTemp(dir, ".readyz-*")
+		if err != nil {
+			return err
+		}
+		name := file.Name()
+
+		_, err = file.WriteString("ok")
+		if closeErr := file.Close(); err == nil {
+			err = closeErr
+		}
+		if removeErr := os.Remove(name); err == nil {
+			err = removeErr
+		}
+
+		return err
+	}
+}
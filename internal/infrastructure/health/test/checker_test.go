@@ -0,0 +1,143 @@
+package health_test
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"net"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/latiiLA/coop-forex-server/internal/domain/model"
+	"github.com/latiiLA/coop-forex-server/internal/infrastructure/health"
+)
+
+func pass(ctx context.Context) error { return nil }
+
+func fail(ctx context.Context) error { return errors.New("down") }
+
+func TestCheckerStatus(t *testing.T) {
+	cases := []struct {
+		name     string
+		required health.CheckFunc
+		optional health.CheckFunc
+		want     model.ReadinessStatus
+	}{
+		{"all pass", pass, pass, model.ReadinessOK},
+		{"optional fails", pass, fail, model.ReadinessDegraded},
+		{"required fails", fail, pass, model.ReadinessUnavailable},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			checker := health.NewChecker()
+			checker.Require("db", tc.required)
+			checker.Optional("mail", tc.optional)
+
+			report := checker.Check(context.Background())
+			if report.Status != tc.want {
+				t.Fatalf("status = %s, want %s", report.Status, tc.want)
+			}
+			if len(report.Checks) != 2 || report.Checks[0].Name != "db" || !report.Checks[0].Required {
+				t.Fatalf("unexpected checks %+v", report.Checks)
+			}
+			if report.Ready() != (tc.want != model.ReadinessUnavailable) {
+				t.Fatalf("Ready() = %v for %s", report.Ready(), report.Status)
+			}
+		})
+	}
+}
+
+func TestCheckerTimesOutChecks(t *testing.T) {
+	checker := health.NewChecker()
+	checker.Timeout = 20 * time.Millisecond
+	checker.Require("slow", func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	})
+
+	report := checker.Check(context.Background())
+	if report.Status != model.ReadinessUnavailable || report.Checks[0].Error == "" {
+		t.Fatalf("slow check was not failed: %+v", report)
+	}
+}
+
+func TestCheckerCachesReports(t *testing.T) {
+	var calls atomic.Int32
+	checker := health.NewChecker()
+	checker.CacheTTL = time.Minute
+	checker.Require("db", func(ctx context.Context) error {
+		calls.Add(1)
+		return nil
+	})
+
+	checker.Check(context.Background())
+	checker.Check(context.Background())
+	if calls.Load() != 1 {
+		t.Fatalf("check ran %d times, want 1", calls.Load())
+	}
+}
+
+func TestCheckerDraining(t *testing.T) {
+	checker := health.NewChecker()
+	checker.Require("db", pass)
+	checker.Check(context.Background())
+
+	checker.Drain()
+
+	report := checker.Check(context.Background())
+	if report.Ready() || !report.Draining {
+		t.Fatalf("draining server reported %+v", report)
+	}
+}
+
+func TestWritableDir(t *testing.T) {
+	dir := t.TempDir()
+	if err := health.WritableDir(dir)(context.Background()); err != nil {
+		t.Fatalf("writable dir failed: %v", err)
+	}
+	if entries, _ := os.ReadDir(dir); len(entries) != 0 {
+		t.Fatalf("check left %d files behind", len(entries))
+	}
+
+	if err := health.WritableDir(filepath.Join(dir, "missing"))(context.Background()); err == nil {
+		t.Fatal("missing dir passed")
+	}
+}
+
+func TestSMTP(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+
+	greetings := []string{"220 mail ready\r\n", "554 go away\r\n"}
+	go func() {
+		for _, greeting := range greetings {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			conn.Write([]byte(greeting))
+			if line, err := bufio.NewReader(conn).ReadString('\n'); err == nil && line == "QUIT\r\n" {
+				conn.Write([]byte("221 bye\r\n"))
+			}
+			conn.Close()
+		}
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	check := health.SMTP(listener.Addr().String())
+	if err := check(ctx); err != nil {
+		t.Fatalf("ready server failed: %v", err)
+	}
+	if err := check(ctx); err == nil {
+		t.Fatal("server refusing mail passed")
+	}
+}
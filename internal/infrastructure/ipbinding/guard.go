@@ -0,0 +1,65 @@
+package ipbinding
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/latiiLA/coop-forex-server/internal/common"
+	"github.com/latiiLA/coop-forex-server/internal/domain/model"
+	"github.com/latiiLA/coop-forex-server/internal/infrastructure/metrics"
+	"github.com/sirupsen/logrus"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Guard checks each use of a token against the binding mode of its user's
+// role and audits every mismatch as a security event.
+type Guard struct {
+	policy                  model.IPBindingPolicy
+	securityEventRepository model.SecurityEventRepository
+
+	// StoreTimeout bounds recording a mismatch, which outlives the request
+	// that was refused.
+	StoreTimeout time.Duration
+}
+
+func NewGuard(policy model.IPBindingPolicy, securityEventRepository model.SecurityEventRepository) *Guard {
+	return &Guard{
+		policy:                  policy,
+		securityEventRepository: securityEventRepository,
+		StoreTimeout:            5 * time.Second,
+	}
+}
+
+func (g *Guard) Verify(ctx context.Context, role string, userID primitive.ObjectID, sessionID primitive.ObjectID, boundIP string, boundFingerprint string, client model.ClientInfo) error {
+	mode := g.policy.ModeFor(role)
+	if mode.Matches(boundIP, boundFingerprint, client) {
+		return nil
+	}
+
+	event := &model.SecurityEvent{
+		Type:      model.SecurityIPBindingMismatch,
+		UserID:    userID,
+		SessionID: &sessionID,
+		IP:        client.IP,
+		UserAgent: client.UserAgent,
+		Detail:    fmt.Sprintf("%s binding: token issued to %s, used from %s", mode, boundIP, client.IP),
+		CreatedAt: time.Now(),
+	}
+
+	storeCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), g.StoreTimeout)
+	defer cancel()
+	if err := g.securityEventRepository.Create(storeCtx, event); err != nil {
+		logrus.WithError(err).Error("Failed to record security event")
+	}
+	metrics.RecordSecurityEvent(event.Type)
+	logrus.WithFields(logrus.Fields{
+		"user_id":    userID.Hex(),
+		"session_id": sessionID.Hex(),
+		"mode":       mode,
+		"bound_ip":   boundIP,
+		"ip":         client.IP,
+	}).Warn("Token used from a client it is not bound to")
+
+	return common.ErrIPBindingMismatch
+}
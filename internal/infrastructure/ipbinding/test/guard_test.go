@@ -0,0 +1,65 @@
+package ipbinding_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/latiiLA/coop-forex-server/internal/common"
+	"github.com/latiiLA/coop-forex-server/internal/domain/model"
+	"github.com/latiiLA/coop-forex-server/internal/infrastructure/ipbinding"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// recordedEvents is a SecurityEventRepository that keeps what it is given.
+type recordedEvents struct {
+	events []*model.SecurityEvent
+}
+
+func (r *recordedEvents) Create(ctx context.Context, event *model.SecurityEvent) error {
+	r.events = append(r.events, event)
+	return nil
+}
+
+func TestGuardAllowsMatchingClients(t *testing.T) {
+	events := &recordedEvents{}
+	guard := ipbinding.NewGuard(model.IPBindingPolicy{Default: model.IPBindingSubnet}, events)
+
+	err := guard.Verify(context.Background(), "USER", primitive.NewObjectID(), primitive.NewObjectID(), "10.1.2.3", "", model.ClientInfo{IP: "10.1.2.77"})
+	if err != nil {
+		t.Fatalf("Verify: %v", err)
+	}
+	if len(events.events) != 0 {
+		t.Errorf("recorded %d events for a matching client", len(events.events))
+	}
+}
+
+func TestGuardAuditsMismatches(t *testing.T) {
+	events := &recordedEvents{}
+	guard := ipbinding.NewGuard(model.IPBindingPolicy{
+		Default: model.IPBindingOff,
+		Roles:   map[string]model.IPBindingMode{"ADMIN": model.IPBindingStrict},
+	}, events)
+	userID, sessionID := primitive.NewObjectID(), primitive.NewObjectID()
+	client := model.ClientInfo{IP: "10.9.9.9", UserAgent: "curl/8"}
+
+	err := guard.Verify(context.Background(), "admin", userID, sessionID, "10.1.2.3", "", client)
+	if !errors.Is(err, common.ErrIPBindingMismatch) {
+		t.Fatalf("Verify = %v; expected ErrIPBindingMismatch", err)
+	}
+	if len(events.events) != 1 {
+		t.Fatalf("recorded %d events; expected 1", len(events.events))
+	}
+
+	event := events.events[0]
+	if event.Type != model.SecurityIPBindingMismatch || event.UserID != userID || *event.SessionID != sessionID {
+		t.Errorf("recorded %+v", event)
+	}
+	if event.IP != client.IP || event.UserAgent != client.UserAgent {
+		t.Errorf("event client = %s %s; expected %s %s", event.IP, event.UserAgent, client.IP, client.UserAgent)
+	}
+
+	if err := guard.Verify(context.Background(), "user", userID, sessionID, "10.1.2.3", "", client); err != nil {
+		t.Errorf("role without binding refused: %v", err)
+	}
+}
@@ -5,78 +5,84 @@ import (
 	"fmt"
 	"time"
 
-	"github.com/dgrijalva/jwt-go"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
 	"github.com/latiiLA/coop-forex-server/configs"
+	"github.com/latiiLA/coop-forex-server/internal/domain/model"
+	"github.com/latiiLA/coop-forex-server/internal/infrastructure/signing"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
-func GenerateToken(userID primitive.ObjectID, role string, branchID primitive.ObjectID, departmentID primitive.ObjectID, permissions []string, ip string) (string, error) {
+var accessTokenKeys *signing.KeySet
+
+// UseAccessTokenKeys sets the keys access tokens are signed and verified
+// with. It is set once at startup, before any token is issued.
+func UseAccessTokenKeys(keys *signing.KeySet) {
+	accessTokenKeys = keys
+}
+
+// GenerateToken issues an access token of a session carrying accessClaims,
+// bound to client.
+func GenerateToken(accessClaims *model.AccessClaims, client model.ClientInfo, sessionID primitive.ObjectID) (string, error) {
 	claims := jwt.MapClaims{
-		"userID":       userID.Hex(),
-		"role":         role,
-		"branchID":     branchID,
-		"departmentID": departmentID,
-		"permissions":  permissions,
-		"ip":           ip,
+		"userID":       accessClaims.UserID.Hex(),
+		"sid":          sessionID.Hex(),     // session, for revocation
+		"jti":          uuid.New().String(), // token ID
+		"role":         accessClaims.Role,
+		"branchID":     accessClaims.BranchID,
+		"departmentID": accessClaims.DepartmentID,
+		"permissions":  accessClaims.Permissions,
+		"pv":           accessClaims.PermissionsVersion,                  // refused once the user's access changes
+		"ip":           client.IP,                                        // bound client, see model.IPBindingMode
+		"dfp":          client.Fingerprint,                               // bound device
 		"exp":          time.Now().Add(configs.AccessTokenExpiry).Unix(), // expiration
 		"iat":          time.Now().Unix(),                                // issued at
 		"iss":          "coop-forex",                                     // issuer
-		"sub":          userID.Hex(),                                     // subject
+		"sub":          accessClaims.UserID.Hex(),                        // subject
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString([]byte(configs.JwtSecret))
+	return accessTokenKeys.Sign(claims)
 }
 
-func ValidateToken(tokenString string, clientIP string) (jwt.MapClaims, error) {
-	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, errors.New("unexpected signing method")
-		}
-		return []byte(configs.JwtSecret), nil
-	})
-
-	if err != nil || !token.Valid {
+// ValidateToken verifies an access token. Whether the client may use it is
+// left to the IP binding policy.
+func ValidateToken(tokenString string) (jwt.MapClaims, error) {
+	claims, err := accessTokenKeys.Parse(tokenString)
+	if err != nil {
 		return nil, errors.New("invalid token")
 	}
 
-	claims, ok := token.Claims.(jwt.MapClaims)
-	if !ok {
-		return nil, errors.New("user ID missing in token")
-	}
-
 	// Check if user ID exists
 	tokenUserID, exists := claims["userID"].(string)
 	if !exists || tokenUserID == "" {
 		return nil, errors.New("user ID missing in token")
 	}
 
-	// --- IP validation ---
-	tokenIP, ok := claims["ip"].(string)
-	if ok {
-		// Allow localhost variations
-		if clientIP != tokenIP && clientIP != "::1" && clientIP != "127.0.0.1" {
-			return nil, errors.New("IP does not match the token")
-		}
+	// Tokens issued before sessions were tracked cannot be revoked
+	if _, err := SessionID(claims); err != nil {
+		return nil, err
 	}
 
 	return claims, nil
 }
 
-func GenerateRefreshToken(userID primitive.ObjectID, ip string) (string, error) {
+// GenerateRefreshToken issues the refresh token jti of a session. Only the
+// session's latest refresh token is accepted, and only from a client the IP
+// binding policy allows.
+func GenerateRefreshToken(userID primitive.ObjectID, client model.ClientInfo, sessionID primitive.ObjectID, jti string, expirationTime time.Time) (string, error) {
 	// Get JWT secret from environment variable
 	secret := configs.RefreshJwtSecret
 	if secret == "" {
 		return "", fmt.Errorf("REFRESH_JWT_SECRET environment variable is not set")
 	}
 
-	// Set expiration time (e.g., 7 days)
-	expirationTime := time.Now().Add(7 * 24 * time.Hour)
-
 	// Use MapClaims to match your access token style
 	claims := jwt.MapClaims{
 		"userID": userID.Hex(),
-		"ip":     ip, // optional
+		"sid":    sessionID.Hex(),
+		"jti":    jti,
+		"ip":     client.IP,
+		"dfp":    client.Fingerprint,
 		"exp":    expirationTime.Unix(),
 		"iat":    time.Now().Unix(),
 		"nbf":    time.Now().Unix(),
@@ -93,18 +99,15 @@ func GenerateRefreshToken(userID primitive.ObjectID, ip string) (string, error)
 	return tokenString, nil
 }
 
-func ValidateRefreshToken(tokenString string, clientIP string) (jwt.MapClaims, error) {
+func ValidateRefreshToken(tokenString string) (jwt.MapClaims, error) {
 	secret := configs.RefreshJwtSecret
 	if secret == "" {
 		return nil, fmt.Errorf("REFRESH_JWT_SECRET environment variable is not set")
 	}
 
 	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, fmt.Errorf("unexpected signing method")
-		}
 		return []byte(secret), nil
-	})
+	}, jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}))
 
 	if err != nil || !token.Valid {
 		return nil, fmt.Errorf("invalid token: %w", err)
@@ -121,13 +124,11 @@ func ValidateRefreshToken(tokenString string, clientIP string) (jwt.MapClaims, e
 		return nil, fmt.Errorf("userID missing in token")
 	}
 
-	// --- Optional IP validation ---
-	tokenIP, ok := claims["ip"].(string)
-	if ok {
-		// Allow localhost variations
-		if clientIP != tokenIP && clientIP != "::1" && clientIP != "127.0.0.1" {
-			return nil, fmt.Errorf("IP does not match token")
-		}
+	if _, err := SessionID(claims); err != nil {
+		return nil, err
+	}
+	if jti, _ := claims["jti"].(string); jti == "" {
+		return nil, fmt.Errorf("jti missing in token")
 	}
 
 	// Check expiration
@@ -139,3 +140,28 @@ func ValidateRefreshToken(tokenString string, clientIP string) (jwt.MapClaims, e
 
 	return claims, nil
 }
+
+// SessionID reads the session a token was issued for.
+func SessionID(claims jwt.MapClaims) (primitive.ObjectID, error) {
+	sid, _ := claims["sid"].(string)
+	sessionID, err := primitive.ObjectIDFromHex(sid)
+	if err != nil {
+		return primitive.NilObjectID, errors.New("session missing in token")
+	}
+
+	return sessionID, nil
+}
+
+// BoundClient reads the IP and device fingerprint a token was issued to.
+func BoundClient(claims jwt.MapClaims) (string, string) {
+	ip, _ := claims["ip"].(string)
+	fingerprint, _ := claims["dfp"].(string)
+	return ip, fingerprint
+}
+
+// PermissionsVersion reads the permissions version an access token was issued
+// with; tokens issued before versions were tracked carry none and count as 0.
+func PermissionsVersion(claims jwt.MapClaims) int64 {
+	pv, _ := claims["pv"].(float64)
+	return int64(pv)
+}
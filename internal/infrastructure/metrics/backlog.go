@@ -0,0 +1,99 @@
+package metrics
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/latiiLA/coop-forex-server/internal/domain/model"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/sirupsen/logrus"
+)
+
+// backlogStatuses are reported even when no request is in them, so a
+// drained stage shows as zero rather than disappearing.
+var backlogStatuses = []model.RequestStatus{
+	model.ReqStatusDrafted,
+	model.ReqStatusNew,
+	model.ReqStatusAuthorized,
+	model.ReqStatusValidated,
+	model.ReqStatusRejected,
+	model.ReqStatusApproved,
+	model.ReqStatusAccepted,
+	model.ReqStatusDeclined,
+}
+
+// BacklogCollector reports how many requests are in each status, counted in
+// the database when scraped.
+type BacklogCollector struct {
+	requestRepository model.RequestRepository
+	backlog           *prometheus.Desc
+
+	// Timeout bounds the count.
+	Timeout time.Duration
+	// CacheTTL is how long a count is reused, so every scrape from every
+	// Prometheus does not run the aggregation.
+	CacheTTL time.Duration
+
+	mu        sync.Mutex
+	counts    map[model.RequestStatus]int64
+	countedAt time.Time
+}
+
+func NewBacklogCollector(requestRepository model.RequestRepository) *BacklogCollector {
+	return &BacklogCollector{
+		requestRepository: requestRepository,
+		backlog: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "", "request_backlog"),
+			"Forex requests that are not deleted, by status.",
+			[]string{"status"}, nil,
+		),
+		Timeout:  5 * time.Second,
+		CacheTTL: 30 * time.Second,
+	}
+}
+
+// RegisterBacklog adds a backlog collector with the default settings to the
+// served metrics.
+func RegisterBacklog(requestRepository model.RequestRepository) {
+	prometheus.MustRegister(NewBacklogCollector(requestRepository))
+}
+
+func (bc *BacklogCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- bc.backlog
+}
+
+// Collect reports the last count when counting fails, and nothing when there
+// is none, so a database outage does not read as an empty backlog.
+func (bc *BacklogCollector) Collect(ch chan<- prometheus.Metric) {
+	counts := bc.count()
+	if counts == nil {
+		return
+	}
+
+	for _, status := range backlogStatuses {
+		ch <- prometheus.MustNewConstMetric(bc.backlog, prometheus.GaugeValue, float64(counts[status]), string(status))
+	}
+}
+
+func (bc *BacklogCollector) count() map[model.RequestStatus]int64 {
+	bc.mu.Lock()
+	defer bc.mu.Unlock()
+
+	if bc.counts != nil && time.Since(bc.countedAt) < bc.CacheTTL {
+		return bc.counts
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), bc.Timeout)
+	defer cancel()
+
+	counts, err := bc.requestRepository.CountByStatus(ctx)
+	if err != nil {
+		logrus.WithError(err).Warn("Failed to count the request backlog")
+		return bc.counts
+	}
+	bc.counts = counts
+	bc.countedAt = time.Now()
+
+	return counts
+}
@@ -0,0 +1,116 @@
+// Package metrics exposes the server's Prometheus metrics. Every instance
+// serves its own; counters are summed and backlog gauges, which every
+// instance reads from the shared database, are taken with max().
+package metrics
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/latiiLA/coop-forex-server/internal/domain/model"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "forex"
+
+var (
+	httpRequestDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "http_request_duration_seconds",
+		Help:      "Time taken to serve HTTP requests, by route and status.",
+		Buckets:   []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10, 30},
+	}, []string{"method", "route", "status"})
+
+	httpRateLimited = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "http_rate_limited_total",
+		Help:      "HTTP requests rejected by the rate limiter.",
+	})
+
+	requestTransitions = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "request_transitions_total",
+		Help:      "Forex request workflow transitions, by action, statuses and branch.",
+	}, []string{"action", "from", "to", "branch"})
+
+	fcyVolume = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "fcy_volume_total",
+		Help:      "Foreign currency amounts approved and accepted, by currency.",
+	}, []string{"stage", "currency"})
+
+	ldapAuthentications = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "ldap_authentications_total",
+		Help:      "LDAP logins, by result.",
+	}, []string{"result"})
+
+	notificationDeliveries = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "notification_deliveries_total",
+		Help:      "Notification delivery attempts, by channel and outcome.",
+	}, []string{"channel", "outcome"})
+
+	securityEvents = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "security_events_total",
+		Help:      "Suspicious credential use, by type.",
+	}, []string{"type"})
+)
+
+// Unmatched is the route label of requests no route matched, so scanners
+// cannot blow up the number of series.
+const Unmatched = "unmatched"
+
+// Results of an LDAP login.
+const (
+	LDAPSuccess = "success"
+	// LDAPRejected means the directory refused the user or the password.
+	LDAPRejected = "rejected"
+	// LDAPError means the directory could not be reached or searched.
+	LDAPError = "error"
+)
+
+// Handler serves the metrics in the Prometheus text format.
+func Handler() http.Handler {
+	return promhttp.Handler()
+}
+
+func ObserveHTTPRequest(method string, route string, status int, duration time.Duration) {
+	if route == "" {
+		route = Unmatched
+	}
+	httpRequestDuration.WithLabelValues(method, route, strconv.Itoa(status)).Observe(duration.Seconds())
+}
+
+func RateLimited() {
+	httpRateLimited.Inc()
+}
+
+func RecordTransition(action model.RequestAction, from model.RequestStatus, to model.RequestStatus, branch string) {
+	requestTransitions.WithLabelValues(string(action), string(from), string(to), branch).Inc()
+}
+
+// AddFCYVolume adds an amount approved or accepted, stage being the status
+// the request moved to.
+func AddFCYVolume(stage model.RequestStatus, currency string, amount float64) {
+	if amount <= 0 {
+		return
+	}
+	fcyVolume.WithLabelValues(string(stage), currency).Add(amount)
+}
+
+func RecordLDAPAuthentication(result string) {
+	ldapAuthentications.WithLabelValues(result).Inc()
+}
+
+func RecordNotificationDelivery(channel model.NotificationChannel, outcome model.NotificationStatus) {
+	notificationDeliveries.WithLabelValues(string(channel), string(outcome)).Inc()
+}
+
+func RecordSecurityEvent(eventType model.SecurityEventType) {
+	securityEvents.WithLabelValues(string(eventType)).Inc()
+}
@@ -0,0 +1,52 @@
+package metrics
+
+import (
+	"context"
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"go.mongodb.org/mongo-driver/event"
+)
+
+var mongoCommandDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+	Namespace: namespace,
+	Name:      "mongo_command_duration_seconds",
+	Help:      "Time taken by MongoDB commands, by command, collection and outcome.",
+	Buckets:   []float64{.001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5},
+}, []string{"command", "collection", "outcome"})
+
+// MongoMonitor times every command the driver sends. It is set on the client
+// options before connecting.
+func MongoMonitor() *event.CommandMonitor {
+	// The collection is only named in the command itself, so it is kept
+	// until the command finishes.
+	var collections sync.Map
+
+	finished := func(requestID int64, command string, outcome string, seconds float64) {
+		collection := ""
+		if name, ok := collections.LoadAndDelete(requestID); ok {
+			collection = name.(string)
+		}
+		mongoCommandDuration.WithLabelValues(command, collection, outcome).Observe(seconds)
+	}
+
+	return &event.CommandMonitor{
+		Started: func(_ context.Context, evt *event.CommandStartedEvent) {
+			// Commands such as find and insert name their collection in
+			// their first element; admin commands name none, and redacted
+			// ones such as authentication have no elements at all.
+			collection := ""
+			if first, err := evt.Command.IndexErr(0); err == nil {
+				collection, _ = first.Value().StringValueOK()
+			}
+			collections.Store(evt.RequestID, collection)
+		},
+		Succeeded: func(_ context.Context, evt *event.CommandSucceededEvent) {
+			finished(evt.RequestID, evt.CommandName, "success", evt.Duration.Seconds())
+		},
+		Failed: func(_ context.Context, evt *event.CommandFailedEvent) {
+			finished(evt.RequestID, evt.CommandName, "failure", evt.Duration.Seconds())
+		},
+	}
+}
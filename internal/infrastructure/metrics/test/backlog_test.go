@@ -0,0 +1,71 @@
+package metrics_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/latiiLA/coop-forex-server/internal/domain/model"
+	"github.com/latiiLA/coop-forex-server/internal/infrastructure/metrics"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+// counts is a RequestRepository that only counts.
+type counts struct {
+	model.RequestRepository
+	byStatus map[model.RequestStatus]int64
+	err      error
+	calls    int
+}
+
+func (c *counts) CountByStatus(ctx context.Context) (map[model.RequestStatus]int64, error) {
+	c.calls++
+	return c.byStatus, c.err
+}
+
+func TestBacklogCollectorReportsEveryStatus(t *testing.T) {
+	repo := &counts{byStatus: map[model.RequestStatus]int64{model.ReqStatusNew: 4, model.ReqStatusApproved: 2}}
+	collector := metrics.NewBacklogCollector(repo)
+
+	expected := `
+# HELP forex_request_backlog Forex requests that are not deleted, by status.
+# TYPE forex_request_backlog gauge
+forex_request_backlog{status="Accepted"} 0
+forex_request_backlog{status="Approved"} 2
+forex_request_backlog{status="Authorized"} 0
+forex_request_backlog{status="Declined"} 0
+forex_request_backlog{status="Drafted"} 0
+forex_request_backlog{status="New"} 4
+forex_request_backlog{status="Rejected"} 0
+forex_request_backlog{status="Validated"} 0
+`
+	if err := testutil.CollectAndCompare(collector, strings.NewReader(expected)); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestBacklogCollectorCachesAndKeepsLastCount(t *testing.T) {
+	repo := &counts{byStatus: map[model.RequestStatus]int64{model.ReqStatusNew: 1}}
+	collector := metrics.NewBacklogCollector(repo)
+
+	testutil.CollectAndCount(collector)
+	testutil.CollectAndCount(collector)
+	if repo.calls != 1 {
+		t.Fatalf("counted %d times within the cache TTL, want 1", repo.calls)
+	}
+
+	collector.CacheTTL = 0
+	repo.byStatus, repo.err = nil, errors.New("down")
+	if n := testutil.CollectAndCount(collector); n != 8 {
+		t.Fatalf("reported %d series after a failed count, want the last 8", n)
+	}
+}
+
+func TestBacklogCollectorReportsNothingBeforeFirstCount(t *testing.T) {
+	collector := metrics.NewBacklogCollector(&counts{err: errors.New("down")})
+
+	if n := testutil.CollectAndCount(collector); n != 0 {
+		t.Fatalf("reported %d series without a count, want 0", n)
+	}
+}
@@ -7,11 +7,39 @@ import (
 
 	"github.com/gin-gonic/gin"
 	"github.com/latiiLA/coop-forex-server/internal/delivery/http/response"
+	"github.com/latiiLA/coop-forex-server/internal/domain/model"
 	"github.com/latiiLA/coop-forex-server/internal/infrastructure"
 	"github.com/latiiLA/coop-forex-server/internal/infrastructure/utils"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
-func JwtAuthMiddleware(secretKey string) gin.HandlerFunc {
+var sessionRevocations model.SessionRevocations
+
+// UseSessionRevocations makes JwtAuthMiddleware refuse the tokens of revoked
+// sessions. It is set once at startup, before any request is served.
+func UseSessionRevocations(revocations model.SessionRevocations) {
+	sessionRevocations = revocations
+}
+
+var permissionVersions model.PermissionVersions
+
+// UsePermissionVersions makes JwtAuthMiddleware refuse access tokens issued
+// before their user's status, role or permissions changed, so the client
+// refreshes them. It is set once at startup, before any request is served.
+func UsePermissionVersions(versions model.PermissionVersions) {
+	permissionVersions = versions
+}
+
+var ipBindingGuard model.IPBindingGuard
+
+// UseIPBindingGuard makes JwtAuthMiddleware refuse access tokens used from a
+// client their IP binding mode does not allow. It is set once at startup,
+// before any request is served.
+func UseIPBindingGuard(guard model.IPBindingGuard) {
+	ipBindingGuard = guard
+}
+
+func JwtAuthMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		// Check Authorization header
 		authHeader := c.GetHeader("Authorization")
@@ -20,19 +48,41 @@ func JwtAuthMiddleware(secretKey string) gin.HandlerFunc {
 			return
 		}
 		token := strings.TrimPrefix(authHeader, "Bearer ")
-		clientIP, err := utils.GetIPAddress(c)
+
+		claims, err := infrastructure.ValidateToken(token)
 		if err != nil {
-			c.AbortWithStatusJSON(http.StatusUnauthorized, response.Status{Message: "Unauthorized or token expired", Error: "unauthorized"})
+			c.AbortWithStatusJSON(http.StatusUnauthorized, response.Status{Message: "Unauthorized or token expired", Error: "Invalid token"})
+			return
+		}
+
+		sessionID, err := infrastructure.SessionID(claims)
+		if err != nil || (sessionRevocations != nil && sessionRevocations.IsRevoked(sessionID)) {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, response.Status{Message: "Unauthorized or token expired", Error: "Session has ended"})
 			return
 		}
 
-		claims, err := infrastructure.ValidateToken(token, clientIP)
+		userID, err := primitive.ObjectIDFromHex(claims["userID"].(string))
 		if err != nil {
 			c.AbortWithStatusJSON(http.StatusUnauthorized, response.Status{Message: "Unauthorized or token expired", Error: "Invalid token"})
 			return
 		}
 
+		if permissionVersions != nil && permissionVersions.IsStale(userID, infrastructure.PermissionsVersion(claims)) {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, response.Status{Message: "Unauthorized or token expired", Error: "Permissions have changed"})
+			return
+		}
+
+		if ipBindingGuard != nil {
+			role, _ := claims["role"].(string)
+			boundIP, boundFingerprint := infrastructure.BoundClient(claims)
+			if err := ipBindingGuard.Verify(c.Request.Context(), role, userID, sessionID, boundIP, boundFingerprint, utils.GetClientInfo(c)); err != nil {
+				c.AbortWithStatusJSON(http.StatusUnauthorized, response.Status{Message: "Unauthorized or token expired", Error: "your network has been changed. please relogin"})
+				return
+			}
+		}
+
 		c.Set("userID", claims["userID"])
+		c.Set(utils.SessionIDKey, sessionID)
 		c.Set("role", claims["role"])
 		c.Set("branchID", claims["branchID"])
 		c.Set("departmentID", claims["departmentID"])
@@ -52,27 +102,6 @@ func AuthorizeRolesOrPermissions(allowedRoles []string, requiredPermission []str
 			return
 		}
 
-		// security measures by adding ip to the token
-		clientIP, err := utils.GetIPAddress(c)
-		if err != nil {
-			logEntry.WithField("error", err.Error()).Warn("invalid ip address")
-			c.JSON(http.StatusBadRequest, response.Status{Message: "Unauthorized or token expired", Error: "cannot determine client IP"})
-			return
-		}
-
-		tokenClaimIP, err := utils.GetClaimIpAddress(c)
-		if err != nil {
-			logEntry.WithField("error", err.Error()).Warn("invalid token ip address", tokenClaimIP)
-			c.JSON(http.StatusBadRequest, response.Status{Message: "Unauthorized or token expired", Error: "cannot determine token IP"})
-			return
-		}
-
-		if tokenClaimIP != clientIP {
-			logEntry.Warn("trying from different ip")
-			c.JSON(http.StatusBadRequest, response.Status{Message: "Unauthorized or token expired", Error: "your network has been changed. please relogin"})
-			return
-		}
-
 		role, ok := roleValue.(string)
 		if !ok {
 			c.AbortWithStatusJSON(http.StatusInternalServerError, response.Status{Message: "Unauthorized or token expired", Error: "Invalid role format"})
@@ -5,6 +5,7 @@ import (
 
 	"github.com/gin-gonic/gin"
 	"github.com/latiiLA/coop-forex-server/internal/delivery/http/response"
+	"github.com/latiiLA/coop-forex-server/internal/infrastructure/metrics"
 	"golang.org/x/time/rate"
 )
 
@@ -13,6 +14,7 @@ var limiter = rate.NewLimiter(5, 10) // 5 req/sec, burst of 10
 func RateLimitMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		if !limiter.Allow() {
+			metrics.RateLimited()
 			c.AbortWithStatusJSON(http.StatusTooManyRequests, response.Status{
 				Message: "Too many requests. Please try again later.",
 				Error:   "Too many requests",
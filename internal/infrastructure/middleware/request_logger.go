@@ -10,7 +10,7 @@ import (
 	log "github.com/sirupsen/logrus"
 )
 
-const TraceIDKey = "TraceID"
+const TraceIDKey = utils.TraceIDKey
 
 func RequestLogger() gin.HandlerFunc {
 	return func(c *gin.Context) {
@@ -28,6 +28,13 @@ func RequestLogger() gin.HandlerFunc {
 		// Store TraceID in context for downstream handlers
 		c.Set(TraceIDKey, traceID)
 
+		// Store the client IP so audit records written further down can use it
+		clientIP, err := utils.GetIPAddress(c)
+		if err != nil {
+			clientIP = c.ClientIP()
+		}
+		c.Set(utils.ClientIPKey, clientIP)
+
 		// Set it in response header so clients can see it
 		c.Writer.Header().Set("X-Trace-ID", traceID)
 
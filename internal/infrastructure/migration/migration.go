@@ -0,0 +1,156 @@
+package migration
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// fileNamePattern matches <version>_<name>.<up|down>.json, where the version
+// is the timestamp the migration was created at.
+var fileNamePattern = regexp.MustCompile(`^(\d+)_([A-Za-z0-9_-]+)\.(up|down)\.json$`)
+
+// Migration is one versioned pair of command files. Each file holds a JSON
+// array of database commands in MongoDB Extended JSON, run in order.
+type Migration struct {
+	Version  int64
+	Name     string
+	UpFile   string
+	DownFile string
+}
+
+// Load reads the migrations directory and returns its migrations ordered by
+// version. Every migration must have both an up and a down file.
+func Load(dir string) ([]Migration, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	byVersion := map[int64]*Migration{}
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+
+		match := fileNamePattern.FindStringSubmatch(entry.Name())
+		if match == nil {
+			continue
+		}
+
+		version, err := strconv.ParseInt(match[1], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid migration version in %s: %w", entry.Name(), err)
+		}
+
+		m, ok := byVersion[version]
+		if !ok {
+			m = &Migration{Version: version, Name: match[2]}
+			byVersion[version] = m
+		} else if m.Name != match[2] {
+			return nil, fmt.Errorf("migration %d has two names: %s and %s", version, m.Name, match[2])
+		}
+
+		path := filepath.Join(dir, entry.Name())
+		if match[3] == "up" {
+			m.UpFile = path
+		} else {
+			m.DownFile = path
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, m := range byVersion {
+		if m.UpFile == "" || m.DownFile == "" {
+			return nil, fmt.Errorf("migration %d_%s must have both an up and a down file", m.Version, m.Name)
+		}
+		migrations = append(migrations, *m)
+	}
+
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+
+	return migrations, nil
+}
+
+// ReadCommands parses a migration file. It also returns the checksum of the
+// file content so changes to an applied migration can be detected.
+func ReadCommands(path string) ([]bson.D, string, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, "", err
+	}
+
+	// Extended JSON only decodes documents, so the command array is wrapped
+	// in one. bson.D keeps the key order the command name depends on.
+	wrapped := append(append([]byte(`{"commands":`), content...), '}')
+
+	var file struct {
+		Commands []bson.D `bson:"commands"`
+	}
+	if err := bson.UnmarshalExtJSON(wrapped, false, &file); err != nil {
+		return nil, "", fmt.Errorf("%s: %w", filepath.Base(path), err)
+	}
+
+	return file.Commands, Checksum(content), nil
+}
+
+// Checksum returns the hex encoded SHA-256 of a migration file.
+func Checksum(content []byte) string {
+	sum := sha256.Sum256(content)
+	return hex.EncodeToString(sum[:])
+}
+
+// versionLayout formats the creation time that versions new migrations.
+const versionLayout = "20060102150405"
+
+var nameCleaner = regexp.MustCompile(`[^a-z0-9]+`)
+
+// Create scaffolds an empty up and down file for a new migration named after
+// the given description, versioned with the current UTC time.
+func Create(dir string, name string, at time.Time) (*Migration, error) {
+	name = strings.Trim(nameCleaner.ReplaceAllString(strings.ToLower(name), "_"), "_")
+	if name == "" {
+		return nil, fmt.Errorf("migration name must contain letters or digits")
+	}
+
+	version, _ := strconv.ParseInt(at.UTC().Format(versionLayout), 10, 64)
+	migration := &Migration{
+		Version:  version,
+		Name:     name,
+		UpFile:   filepath.Join(dir, fmt.Sprintf("%d_%s.up.json", version, name)),
+		DownFile: filepath.Join(dir, fmt.Sprintf("%d_%s.down.json", version, name)),
+	}
+
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+
+	for _, path := range []string{migration.UpFile, migration.DownFile} {
+		file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o644)
+		if err != nil {
+			return nil, err
+		}
+		_, err = file.WriteString("[\n]\n")
+		if closeErr := file.Close(); err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return migration, nil
+}
+
+func (m Migration) String() string {
+	return fmt.Sprintf("%d_%s", m.Version, m.Name)
+}
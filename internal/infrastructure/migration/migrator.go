@@ -0,0 +1,665 @@
+package migration
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const (
+	recordCollection = "schema_migrations"
+	lockCollection   = "schema_migrations_lock"
+	lockID           = "migration"
+	lockPollInterval = 2 * time.Second
+)
+
+var (
+	ErrLocked         = errors.New("another instance is running migrations")
+	ErrDirty          = errors.New("a migration failed part way and must be fixed by hand")
+	ErrUnknownVersion = errors.New("no migration file has this version")
+)
+
+type Direction string
+
+const (
+	Up   Direction = "up"
+	Down Direction = "down"
+)
+
+// Record is the schema_migrations entry of an applied migration. Dirty is set
+// while the migration runs and stays set if one of its commands fails.
+type Record struct {
+	Version   int64     `bson:"_id"`
+	Name      string    `bson:"name"`
+	Checksum  string    `bson:"checksum"`
+	Dirty     bool      `bson:"dirty"`
+	AppliedAt time.Time `bson:"applied_at"`
+}
+
+// Migrator applies the migrations of a directory to a database and keeps
+// track of them in the schema_migrations collection.
+type Migrator struct {
+	db      *mongo.Database
+	dir     string
+	records *mongo.Collection
+	locks   *mongo.Collection
+	owner   string
+
+	// LockTTL is how long a lock is honoured if its holder dies without
+	// releasing it.
+	LockTTL time.Duration
+	// LockWait is how long to wait for another instance to finish.
+	LockWait time.Duration
+	// DryRun prints the commands of the migrations that would run to Out
+	// instead of running them, and leaves schema_migrations untouched.
+	DryRun bool
+	Out    io.Writer
+}
+
+func New(db *mongo.Database, dir string) *Migrator {
+	hostname, _ := os.Hostname()
+
+	return &Migrator{
+		db:       db,
+		dir:      dir,
+		records:  db.Collection(recordCollection),
+		locks:    db.Collection(lockCollection),
+		owner:    fmt.Sprintf("%s:%d:%s", hostname, os.Getpid(), primitive.NewObjectID().Hex()),
+		LockTTL:  15 * time.Minute,
+		LockWait: 5 * time.Minute,
+		Out:      os.Stdout,
+	}
+}
+
+// state is what the migrations directory and schema_migrations hold when an
+// operation starts.
+type state struct {
+	migrations []Migration
+	records    map[int64]Record
+}
+
+// begin loads the migrations and the applied records while holding the lock.
+// The returned function releases the lock.
+func (m *Migrator) begin(ctx context.Context) (*state, func(), error) {
+	migrations, err := Load(m.dir)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	release := func() {}
+	if !m.DryRun {
+		if release, err = m.lock(ctx); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	records, err := m.appliedRecords(ctx)
+	if err == nil {
+		err = m.adoptLegacyVersion(ctx, migrations, records)
+	}
+	if err != nil {
+		release()
+		return nil, nil, err
+	}
+
+	return &state{migrations: migrations, records: records}, release, nil
+}
+
+func (s *state) checkClean() error {
+	for _, record := range s.records {
+		if record.Dirty {
+			return fmt.Errorf("%w: %d_%s", ErrDirty, record.Version, record.Name)
+		}
+	}
+	return nil
+}
+
+// pending returns the migrations not applied yet, oldest first, up to and
+// including version when it is positive.
+func (s *state) pending(version int64) []Migration {
+	var pending []Migration
+	for _, migration := range s.migrations {
+		if version > 0 && migration.Version > version {
+			break
+		}
+		if _, ok := s.records[migration.Version]; !ok {
+			pending = append(pending, migration)
+		}
+	}
+	return pending
+}
+
+// applied returns the applied migrations newer than version, newest first.
+func (s *state) applied(version int64) ([]Migration, error) {
+	byVersion := make(map[int64]Migration, len(s.migrations))
+	for _, migration := range s.migrations {
+		byVersion[migration.Version] = migration
+	}
+
+	var applied []Migration
+	for _, record := range s.records {
+		if record.Version <= version {
+			continue
+		}
+		migration, ok := byVersion[record.Version]
+		if !ok {
+			return nil, fmt.Errorf("migration %d_%s is applied but its files are missing", record.Version, record.Name)
+		}
+		applied = append(applied, migration)
+	}
+
+	sort.Slice(applied, func(i, j int) bool { return applied[i].Version > applied[j].Version })
+
+	return applied, nil
+}
+
+func (s *state) hasVersion(version int64) bool {
+	if version == 0 {
+		return true
+	}
+	for _, migration := range s.migrations {
+		if migration.Version == version {
+			return true
+		}
+	}
+	return false
+}
+
+// Up applies pending migrations in version order, at most limit of them when
+// limit is positive. It returns the migrations it applied.
+func (m *Migrator) Up(ctx context.Context, limit int) ([]Migration, error) {
+	s, release, err := m.begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	if err := s.checkClean(); err != nil {
+		return nil, err
+	}
+
+	for _, migration := range s.migrations {
+		if record, ok := s.records[migration.Version]; ok {
+			m.warnIfModified(migration, record)
+		}
+	}
+
+	pending := s.pending(0)
+	if limit > 0 && len(pending) > limit {
+		pending = pending[:limit]
+	}
+
+	return m.runAll(ctx, pending, Up)
+}
+
+// Down rolls back applied migrations, newest first, at most limit of them
+// when limit is positive. It returns the migrations it rolled back.
+func (m *Migrator) Down(ctx context.Context, limit int) ([]Migration, error) {
+	s, release, err := m.begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	if err := s.checkClean(); err != nil {
+		return nil, err
+	}
+
+	applied, err := s.applied(0)
+	if err != nil {
+		return nil, err
+	}
+	if limit > 0 && len(applied) > limit {
+		applied = applied[:limit]
+	}
+
+	return m.runAll(ctx, applied, Down)
+}
+
+// Goto brings the database to the given version: newer applied migrations are
+// rolled back, then pending ones up to the version are applied. Version 0
+// rolls everything back.
+func (m *Migrator) Goto(ctx context.Context, version int64) (rolledBack []Migration, applied []Migration, err error) {
+	s, release, err := m.begin(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer release()
+
+	if !s.hasVersion(version) {
+		return nil, nil, fmt.Errorf("%w: %d", ErrUnknownVersion, version)
+	}
+	if err := s.checkClean(); err != nil {
+		return nil, nil, err
+	}
+
+	newer, err := s.applied(version)
+	if err != nil {
+		return nil, nil, err
+	}
+	if rolledBack, err = m.runAll(ctx, newer, Down); err != nil {
+		return rolledBack, nil, err
+	}
+
+	if version == 0 {
+		return rolledBack, nil, nil
+	}
+
+	applied, err = m.runAll(ctx, s.pending(version), Up)
+
+	return rolledBack, applied, err
+}
+
+// Force records every migration up to version as cleanly applied and every
+// later one as not applied, without running anything. It is the way out of a
+// dirty state once the database has been repaired by hand.
+func (m *Migrator) Force(ctx context.Context, version int64) error {
+	s, release, err := m.begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer release()
+
+	if !s.hasVersion(version) {
+		return fmt.Errorf("%w: %d", ErrUnknownVersion, version)
+	}
+
+	for _, migration := range s.migrations {
+		if migration.Version > version {
+			break
+		}
+
+		_, checksum, err := ReadCommands(migration.UpFile)
+		if err != nil {
+			return err
+		}
+
+		record, ok := s.records[migration.Version]
+		if ok && !record.Dirty && record.Checksum == checksum {
+			continue
+		}
+		if !ok {
+			record.AppliedAt = time.Now().UTC()
+		}
+		record.Version, record.Name, record.Checksum, record.Dirty = migration.Version, migration.Name, checksum, false
+
+		if m.DryRun {
+			fmt.Fprintf(m.Out, "-- would mark %s as applied\n", migration)
+			continue
+		}
+		if err := m.saveRecord(ctx, &record); err != nil {
+			return err
+		}
+	}
+
+	for _, record := range s.records {
+		if record.Version <= version {
+			continue
+		}
+		if m.DryRun {
+			fmt.Fprintf(m.Out, "-- would mark %d_%s as not applied\n", record.Version, record.Name)
+			continue
+		}
+		if _, err := m.records.DeleteOne(ctx, bson.M{"_id": record.Version}); err != nil {
+			return err
+		}
+	}
+
+	if !m.DryRun {
+		logrus.WithField("version", version).Warn("migration version forced")
+	}
+
+	return nil
+}
+
+// Status describes one migration, or an applied record whose files are gone.
+type Status struct {
+	Version   int64
+	Name      string
+	Applied   bool
+	Dirty     bool
+	AppliedAt *time.Time
+	// Modified is set when the up file changed after it was applied.
+	Modified bool
+	// Missing is set when an applied migration has no files any more.
+	Missing bool
+}
+
+func (s Status) State() string {
+	switch {
+	case s.Missing:
+		return "missing"
+	case s.Dirty:
+		return "dirty"
+	case s.Modified:
+		return "modified"
+	case s.Applied:
+		return "applied"
+	default:
+		return "pending"
+	}
+}
+
+// Status lists every migration with its state, ordered by version.
+func (m *Migrator) Status(ctx context.Context) ([]Status, error) {
+	migrations, err := Load(m.dir)
+	if err != nil {
+		return nil, err
+	}
+
+	records, err := m.appliedRecords(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := m.legacyRecords(ctx, migrations, records); err != nil {
+		return nil, err
+	}
+
+	statuses := make([]Status, 0, len(migrations))
+	known := make(map[int64]bool, len(migrations))
+
+	for _, migration := range migrations {
+		known[migration.Version] = true
+		status := Status{Version: migration.Version, Name: migration.Name}
+
+		if record, ok := records[migration.Version]; ok {
+			appliedAt := record.AppliedAt
+			status.Applied = true
+			status.Dirty = record.Dirty
+			status.AppliedAt = &appliedAt
+
+			_, checksum, err := ReadCommands(migration.UpFile)
+			if err != nil {
+				return nil, err
+			}
+			status.Modified = record.Checksum != "" && checksum != record.Checksum
+		}
+
+		statuses = append(statuses, status)
+	}
+
+	for _, record := range records {
+		if known[record.Version] {
+			continue
+		}
+		appliedAt := record.AppliedAt
+		statuses = append(statuses, Status{Version: record.Version, Name: record.Name, Applied: true, Dirty: record.Dirty, AppliedAt: &appliedAt, Missing: true})
+	}
+
+	sort.Slice(statuses, func(i, j int) bool { return statuses[i].Version < statuses[j].Version })
+
+	return statuses, nil
+}
+
+// Validate returns the migrations that need attention: applied files that
+// were edited or deleted, and migrations left dirty.
+func (m *Migrator) Validate(ctx context.Context) ([]Status, error) {
+	statuses, err := m.Status(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var problems []Status
+	for _, status := range statuses {
+		if status.Dirty || status.Modified || status.Missing {
+			problems = append(problems, status)
+		}
+	}
+
+	return problems, nil
+}
+
+func (m *Migrator) runAll(ctx context.Context, migrations []Migration, direction Direction) ([]Migration, error) {
+	var done []Migration
+	for _, migration := range migrations {
+		if err := m.run(ctx, migration, direction); err != nil {
+			return done, err
+		}
+		done = append(done, migration)
+	}
+	return done, nil
+}
+
+// run executes one migration in the given direction, marking it dirty until
+// all of its commands have succeeded.
+func (m *Migrator) run(ctx context.Context, migration Migration, direction Direction) error {
+	path := migration.UpFile
+	if direction == Down {
+		path = migration.DownFile
+	}
+
+	commands, checksum, err := ReadCommands(path)
+	if err != nil {
+		return err
+	}
+
+	if m.DryRun {
+		return m.print(migration, direction, commands)
+	}
+
+	record := Record{Version: migration.Version, Name: migration.Name, Checksum: checksum, Dirty: true, AppliedAt: time.Now().UTC()}
+	if direction == Down {
+		_, err = m.records.UpdateOne(ctx, bson.M{"_id": migration.Version}, bson.M{"$set": bson.M{"dirty": true}})
+	} else {
+		err = m.saveRecord(ctx, &record)
+	}
+	if err != nil {
+		return err
+	}
+
+	for i, command := range commands {
+		if err := m.db.RunCommand(ctx, command).Err(); err != nil {
+			return fmt.Errorf("migration %s %s, command %d (%s): %w", migration, direction, i+1, commandName(command), err)
+		}
+	}
+
+	if direction == Down {
+		_, err = m.records.DeleteOne(ctx, bson.M{"_id": migration.Version})
+	} else {
+		record.Dirty = false
+		record.AppliedAt = time.Now().UTC()
+		err = m.saveRecord(ctx, &record)
+	}
+	if err != nil {
+		return err
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"version":   migration.Version,
+		"name":      migration.Name,
+		"direction": direction,
+	}).Info("migration applied")
+
+	return nil
+}
+
+func (m *Migrator) print(migration Migration, direction Direction, commands []bson.D) error {
+	fmt.Fprintf(m.Out, "-- %s (%s)\n", migration, direction)
+
+	for _, command := range commands {
+		out, err := bson.MarshalExtJSONIndent(command, false, false, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(m.Out, "%s\n", out)
+	}
+
+	return nil
+}
+
+// adoptLegacyVersion converts the single {version, dirty} document left by
+// golang-migrate, which applied these files before, into one record per
+// migration up to that version. A dirty legacy version stays dirty on its
+// last migration. In a dry run the records are only added to the in-memory
+// state.
+func (m *Migrator) adoptLegacyVersion(ctx context.Context, migrations []Migration, records map[int64]Record) error {
+	legacy, err := m.findLegacyVersion(ctx)
+	if err != nil || legacy == nil {
+		return err
+	}
+
+	if err := m.legacyRecords(ctx, migrations, records); err != nil {
+		return err
+	}
+	if m.DryRun {
+		return nil
+	}
+
+	for _, record := range records {
+		if record.Version > legacy.Version {
+			continue
+		}
+		if err := m.saveRecord(ctx, &record); err != nil {
+			return err
+		}
+	}
+
+	if _, err := m.records.DeleteOne(ctx, bson.M{"_id": legacy.ID}); err != nil {
+		return err
+	}
+
+	logrus.WithField("version", legacy.Version).Info("adopted golang-migrate schema version")
+
+	return nil
+}
+
+type legacyVersion struct {
+	ID      interface{} `bson:"_id"`
+	Version int64       `bson:"version"`
+	Dirty   bool        `bson:"dirty"`
+}
+
+func (m *Migrator) findLegacyVersion(ctx context.Context) (*legacyVersion, error) {
+	var legacy legacyVersion
+
+	err := m.records.FindOne(ctx, bson.M{"version": bson.M{"$exists": true}}).Decode(&legacy)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &legacy, nil
+}
+
+// legacyRecords adds a record for every migration covered by a golang-migrate
+// version document to records, without storing anything.
+func (m *Migrator) legacyRecords(ctx context.Context, migrations []Migration, records map[int64]Record) error {
+	legacy, err := m.findLegacyVersion(ctx)
+	if err != nil || legacy == nil {
+		return err
+	}
+
+	for _, migration := range migrations {
+		if migration.Version > legacy.Version {
+			break
+		}
+
+		_, checksum, err := ReadCommands(migration.UpFile)
+		if err != nil {
+			return err
+		}
+
+		records[migration.Version] = Record{Version: migration.Version, Name: migration.Name, Checksum: checksum, Dirty: legacy.Dirty && migration.Version == legacy.Version, AppliedAt: time.Now().UTC()}
+	}
+
+	return nil
+}
+
+func (m *Migrator) appliedRecords(ctx context.Context) (map[int64]Record, error) {
+	cursor, err := m.records.Find(ctx, bson.M{"version": bson.M{"$exists": false}})
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var records []Record
+	if err := cursor.All(ctx, &records); err != nil {
+		return nil, err
+	}
+
+	byVersion := make(map[int64]Record, len(records))
+	for _, record := range records {
+		byVersion[record.Version] = record
+	}
+
+	return byVersion, nil
+}
+
+func (m *Migrator) saveRecord(ctx context.Context, record *Record) error {
+	_, err := m.records.ReplaceOne(ctx, bson.M{"_id": record.Version}, record, options.Replace().SetUpsert(true))
+	return err
+}
+
+func (m *Migrator) warnIfModified(migration Migration, record Record) {
+	_, checksum, err := ReadCommands(migration.UpFile)
+	if err != nil || checksum == record.Checksum {
+		return
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"version": migration.Version,
+		"name":    migration.Name,
+	}).Warn("applied migration file was modified after it ran")
+}
+
+// lock makes sure only one instance migrates at a time. The lock is a single
+// document; inserting it fails while another owner holds an unexpired one.
+func (m *Migrator) lock(ctx context.Context) (func(), error) {
+	deadline := time.Now().Add(m.LockWait)
+
+	for {
+		now := time.Now().UTC()
+		_, err := m.locks.UpdateOne(ctx,
+			bson.M{"_id": lockID, "expires_at": bson.M{"$lt": now}},
+			bson.M{"$set": bson.M{"owner": m.owner, "locked_at": now, "expires_at": now.Add(m.LockTTL)}},
+			options.Update().SetUpsert(true),
+		)
+		if err == nil {
+			return m.unlock, nil
+		}
+		if !mongo.IsDuplicateKeyError(err) {
+			return nil, err
+		}
+		if now.After(deadline) {
+			return nil, ErrLocked
+		}
+
+		logrus.Info("waiting for another instance to finish migrating")
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(lockPollInterval):
+		}
+	}
+}
+
+// unlock releases the lock. It uses a context of its own so the lock is
+// released even when the migration was cancelled.
+func (m *Migrator) unlock() {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if _, err := m.locks.DeleteOne(ctx, bson.M{"_id": lockID, "owner": m.owner}); err != nil {
+		logrus.WithError(err).Warn("failed to release the migration lock")
+	}
+}
+
+func commandName(command bson.D) string {
+	if len(command) == 0 {
+		return "empty"
+	}
+	return command[0].Key
+}
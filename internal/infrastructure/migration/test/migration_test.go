@@ -0,0 +1,98 @@
+package migration_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/latiiLA/coop-forex-server/internal/infrastructure/migration"
+)
+
+const migrationsDir = "../../../../db/migrations"
+
+func TestLoadRepositoryMigrations(t *testing.T) {
+	migrations, err := migration.Load(migrationsDir)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if len(migrations) == 0 {
+		t.Fatal("Load() found no migrations")
+	}
+
+	for i, m := range migrations {
+		if i > 0 && migrations[i-1].Version >= m.Version {
+			t.Errorf("migrations are not ordered: %s before %s", migrations[i-1], m)
+		}
+
+		for _, path := range []string{m.UpFile, m.DownFile} {
+			commands, checksum, err := migration.ReadCommands(path)
+			if err != nil {
+				t.Errorf("ReadCommands(%s) error = %v", filepath.Base(path), err)
+				continue
+			}
+			if len(commands) == 0 {
+				t.Errorf("%s holds no commands", filepath.Base(path))
+			}
+			if len(checksum) != 64 {
+				t.Errorf("%s checksum = %q", filepath.Base(path), checksum)
+			}
+		}
+	}
+}
+
+func TestLoadRequiresBothDirections(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "20260101000000_only_up.up.json"), []byte("[]"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := migration.Load(dir); err == nil {
+		t.Error("Load() accepted a migration without a down file")
+	}
+}
+
+func TestReadCommandsKeepsCommandOrder(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "20260101000000_sample.up.json")
+	content := `[{"createIndexes": "requests", "indexes": [{"key": {"created_at": -1}, "name": "idx"}]}, {"insert": "x", "documents": [{"_id": {"$oid": "66a7b8c9e4b0f12345679000"}}]}]`
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	commands, checksum, err := migration.ReadCommands(path)
+	if err != nil {
+		t.Fatalf("ReadCommands() error = %v", err)
+	}
+	if len(commands) != 2 || commands[0][0].Key != "createIndexes" || commands[1][0].Key != "insert" {
+		t.Errorf("ReadCommands() = %v", commands)
+	}
+	if checksum != migration.Checksum([]byte(content)) {
+		t.Errorf("checksum does not match the file content")
+	}
+}
+
+func TestCreateScaffoldsLoadablePair(t *testing.T) {
+	dir := t.TempDir()
+	at := time.Date(2026, 10, 17, 15, 4, 5, 0, time.UTC)
+
+	created, err := migration.Create(dir, "Add SLA fields!", at)
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	if created.Version != 20261017150405 || created.Name != "add_sla_fields" {
+		t.Errorf("Create() = %s", created)
+	}
+
+	migrations, err := migration.Load(dir)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if len(migrations) != 1 || migrations[0].Version != created.Version {
+		t.Errorf("Load() = %v", migrations)
+	}
+
+	if _, err := migration.Create(dir, "add sla fields", at); err == nil {
+		t.Error("Create() overwrote an existing migration")
+	}
+}
@@ -0,0 +1,184 @@
+// Package notification delivers the notifications the usecases leave in the
+// outbox collection.
+package notification
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/latiiLA/coop-forex-server/internal/common"
+	"github.com/latiiLA/coop-forex-server/internal/domain/model"
+	"github.com/latiiLA/coop-forex-server/internal/infrastructure/metrics"
+	"github.com/sirupsen/logrus"
+)
+
+// Dispatcher polls the outbox and hands due notifications to the notifier of
+// their channel. Failed deliveries are retried with exponential backoff until
+// the retry policy gives up, after which the notification is dead-lettered
+// and waits for an administrator to retry it.
+type Dispatcher struct {
+	notificationRepository model.NotificationRepository
+	requestEventRepository model.RequestEventRepository
+	notifiers              map[model.NotificationChannel]model.Notifier
+	policy                 model.NotificationRetryPolicy
+
+	// PollInterval is how long the dispatcher sleeps once the outbox is
+	// drained.
+	PollInterval time.Duration
+	// SendTimeout bounds one delivery attempt; a claim is leased for twice
+	// as long so a slow send is not picked up by another instance.
+	SendTimeout time.Duration
+}
+
+func NewDispatcher(notificationRepository model.NotificationRepository, requestEventRepository model.RequestEventRepository, policy model.NotificationRetryPolicy, notifiers ...model.Notifier) *Dispatcher {
+	byChannel := make(map[model.NotificationChannel]model.Notifier, len(notifiers))
+	for _, notifier := range notifiers {
+		byChannel[notifier.Channel()] = notifier
+	}
+
+	return &Dispatcher{
+		notificationRepository: notificationRepository,
+		requestEventRepository: requestEventRepository,
+		notifiers:              byChannel,
+		policy:                 policy,
+		PollInterval:           10 * time.Second,
+		SendTimeout:            time.Minute,
+	}
+}
+
+// Run delivers notifications until ctx is cancelled. It returns once the
+// delivery in progress, if any, is finished and recorded.
+func (d *Dispatcher) Run(ctx context.Context) {
+	ticker := time.NewTicker(d.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := d.DispatchDue(ctx); err != nil && ctx.Err() == nil {
+			logrus.WithError(err).Error("Failed to dispatch notifications")
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// DispatchDue delivers every notification that is currently due and returns
+// how many were attempted.
+func (d *Dispatcher) DispatchDue(ctx context.Context) (int, error) {
+	attempted := 0
+	for ctx.Err() == nil {
+		now := time.Now()
+		notification, err := d.notificationRepository.ClaimDue(ctx, now, now.Add(2*d.SendTimeout))
+		if err != nil {
+			return attempted, err
+		}
+		if notification == nil {
+			return attempted, nil
+		}
+
+		attempted++
+		d.deliver(ctx, notification)
+	}
+
+	return attempted, ctx.Err()
+}
+
+func (d *Dispatcher) deliver(ctx context.Context, notification *model.Notification) {
+	// A send in progress is finished on shutdown rather than abandoned, so
+	// the mail is not sent again by the next attempt. SendTimeout bounds it.
+	sendErr := d.send(context.WithoutCancel(ctx), notification)
+
+	now := time.Now()
+	notification.UpdatedAt = now
+	switch {
+	case sendErr == nil:
+		notification.Status = model.NotificationSent
+		notification.SentAt = &now
+		notification.LastError = ""
+
+	case d.policy.Exhausted(notification.Attempts):
+		notification.Status = model.NotificationDead
+		notification.DeadAt = &now
+		notification.LastError = sendErr.Error()
+
+	default:
+		notification.Status = model.NotificationFailed
+		notification.NextAttemptAt = now.Add(d.policy.Backoff(notification.Attempts))
+		notification.LastError = sendErr.Error()
+	}
+
+	logger := logrus.WithFields(logrus.Fields{
+		"notificationID": notification.ID.Hex(),
+		"requestID":      notification.RequestID.Hex(),
+		"event":          notification.Event,
+		"attempts":       notification.Attempts,
+		"status":         notification.Status,
+	})
+	if sendErr != nil {
+		logger = logger.WithError(sendErr)
+	}
+
+	metrics.RecordNotificationDelivery(notification.Channel, notification.Status)
+
+	// The outcome is recorded even when shutdown cancelled ctx mid-send.
+	saveCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), d.SendTimeout)
+	defer cancel()
+
+	if err := d.notificationRepository.SaveDelivery(saveCtx, notification); err != nil {
+		if errors.Is(err, common.ErrNotificationClaimLost) {
+			logger.Warn("Notification claim expired, the outcome is left to the next attempt")
+		} else {
+			logger.WithError(err).Error("Failed to record notification delivery")
+		}
+		return
+	}
+
+	switch notification.Status {
+	case model.NotificationSent:
+		logger.Info("Notification delivered")
+		d.recordOutcome(saveCtx, notification, model.ReqEventEmailSent)
+	case model.NotificationDead:
+		logger.Error("Notification dead-lettered")
+		d.recordOutcome(saveCtx, notification, model.ReqEventEmailFailed)
+	default:
+		logger.Warn("Notification delivery failed, will retry")
+	}
+}
+
+func (d *Dispatcher) send(ctx context.Context, notification *model.Notification) error {
+	notifier, ok := d.notifiers[notification.Channel]
+	if !ok {
+		return fmt.Errorf("no notifier for channel %q", notification.Channel)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, d.SendTimeout)
+	defer cancel()
+
+	return notifier.Notify(ctx, notification)
+}
+
+// recordOutcome adds the final outcome of a notification to the history of
+// its request, attributed to whoever caused it.
+func (d *Dispatcher) recordOutcome(ctx context.Context, notification *model.Notification, eventType model.RequestEventType) {
+	event := &model.RequestEvent{
+		RequestID:  notification.RequestID,
+		Type:       eventType,
+		ActorID:    notification.CreatedBy,
+		TraceID:    notification.TraceID,
+		Recipients: notification.Recipients(),
+		Error:      notification.LastError,
+		CreatedAt:  notification.UpdatedAt,
+	}
+
+	if err := d.requestEventRepository.Create(ctx, event); err != nil {
+		logrus.WithError(err).WithFields(logrus.Fields{
+			"requestID": notification.RequestID.Hex(),
+			"type":      eventType,
+		}).Error("Failed to record request event")
+	}
+}
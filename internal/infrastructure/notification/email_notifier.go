@@ -0,0 +1,99 @@
+package notification
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"os"
+	"strconv"
+
+	"github.com/latiiLA/coop-forex-server/configs"
+	"github.com/latiiLA/coop-forex-server/internal/domain/model"
+	"gopkg.in/gomail.v2"
+)
+
+const logoFile = "assets/coop.gif"
+
+// EmailNotifier delivers notifications through the configured SMTP server
+// under the configured sender identity.
+type EmailNotifier struct {
+	host        string
+	port        int
+	username    string
+	password    string
+	fromAddress string
+	fromName    string
+	replyTo     string
+}
+
+func NewEmailNotifier() (*EmailNotifier, error) {
+	port, err := strconv.Atoi(configs.MailPort)
+	if err != nil {
+		return nil, fmt.Errorf("invalid SMTP port %q: %w", configs.MailPort, err)
+	}
+
+	return &EmailNotifier{
+		host:        configs.MailServer,
+		port:        port,
+		username:    configs.MailUsername,
+		password:    configs.MailPassword,
+		fromAddress: configs.MailFromAddress,
+		fromName:    configs.MailFromName,
+		replyTo:     configs.MailReplyTo,
+	}, nil
+}
+
+func (en *EmailNotifier) Channel() model.NotificationChannel {
+	return model.NotificationChannelEmail
+}
+
+func (en *EmailNotifier) Notify(ctx context.Context, notification *model.Notification) error {
+	if len(notification.Recipients()) == 0 {
+		return fmt.Errorf("notification %s has no recipients", notification.ID.Hex())
+	}
+
+	m := gomail.NewMessage()
+	m.SetHeader("From", m.FormatAddress(en.fromAddress, en.fromName))
+	m.SetHeader("Reply-To", en.replyTo)
+	if len(notification.To) > 0 {
+		m.SetHeader("To", notification.To...)
+	}
+	if len(notification.Cc) > 0 {
+		m.SetHeader("Cc", notification.Cc...)
+	}
+	if len(notification.Bcc) > 0 {
+		m.SetHeader("Bcc", notification.Bcc...)
+	}
+	m.SetHeader("Subject", notification.Subject)
+	// Mail clients thread every notification of a request together.
+	m.SetHeader("References", fmt.Sprintf("<%s@coop-forex>", notification.RequestCode))
+	if notification.TextBody != "" {
+		m.SetBody("text/plain", notification.TextBody)
+		m.AddAlternative("text/html", notification.Body)
+	} else {
+		m.SetBody("text/html", notification.Body)
+	}
+
+	if _, err := os.Stat(logoFile); err == nil {
+		m.Embed(logoFile, gomail.SetHeader(map[string][]string{
+			"Content-ID": {"coop_logo"},
+		}))
+	}
+
+	d := gomail.NewDialer(en.host, en.port, en.username, en.password)
+	d.TLSConfig = &tls.Config{InsecureSkipVerify: true}
+
+	// gomail cannot be interrupted, so a cancelled context only stops us
+	// waiting; the dispatcher's lease covers a send that outlives it.
+	done := make(chan error, 1)
+	go func() {
+		done <- d.DialAndSend(m)
+	}()
+
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,126 @@
+package notification
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/latiiLA/coop-forex-server/internal/domain/model"
+	"github.com/sirupsen/logrus"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// InboxHub pushes new inbox items to the streams users have open on this
+// server. It learns about the items by watching the inbox collection, so an
+// item written by any instance reaches every stream of its user.
+type InboxHub struct {
+	inboxRepository model.InboxRepository
+
+	mu          sync.Mutex
+	subscribers map[primitive.ObjectID]map[chan model.InboxItem]struct{}
+	closed      bool
+
+	// Buffer is how many items a slow stream may fall behind before further
+	// items are dropped for it. Dropped items stay in the inbox.
+	Buffer int
+	// RetryDelay is how long the hub waits before watching again after the
+	// watch failed.
+	RetryDelay time.Duration
+}
+
+func NewInboxHub(inboxRepository model.InboxRepository) *InboxHub {
+	return &InboxHub{
+		inboxRepository: inboxRepository,
+		subscribers:     make(map[primitive.ObjectID]map[chan model.InboxItem]struct{}),
+		Buffer:          16,
+		RetryDelay:      5 * time.Second,
+	}
+}
+
+// Run watches the inbox until ctx is cancelled, starting over whenever the
+// watch fails.
+func (h *InboxHub) Run(ctx context.Context) {
+	for {
+		err := h.inboxRepository.Watch(ctx, h.Publish)
+		if ctx.Err() != nil {
+			return
+		}
+		logrus.WithError(err).Error("Inbox watch stopped, retrying")
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(h.RetryDelay):
+		}
+	}
+}
+
+func (h *InboxHub) Subscribe(userID primitive.ObjectID) (<-chan model.InboxItem, func()) {
+	ch := make(chan model.InboxItem, h.Buffer)
+
+	h.mu.Lock()
+	if h.closed {
+		// The stream ends at once, the same as one open when Close ran.
+		h.mu.Unlock()
+		close(ch)
+		return ch, func() {}
+	}
+	if h.subscribers[userID] == nil {
+		h.subscribers[userID] = make(map[chan model.InboxItem]struct{})
+	}
+	h.subscribers[userID][ch] = struct{}{}
+	h.mu.Unlock()
+
+	var once sync.Once
+	unsubscribe := func() {
+		once.Do(func() {
+			h.mu.Lock()
+			defer h.mu.Unlock()
+
+			// Close already ended the stream.
+			if _, ok := h.subscribers[userID][ch]; !ok {
+				return
+			}
+			delete(h.subscribers[userID], ch)
+			if len(h.subscribers[userID]) == 0 {
+				delete(h.subscribers, userID)
+			}
+			close(ch)
+		})
+	}
+
+	return ch, unsubscribe
+}
+
+// Close ends every open stream and every stream opened after it, so the
+// server can shut down without waiting for clients to hang up.
+func (h *InboxHub) Close() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.closed = true
+	for userID, channels := range h.subscribers {
+		for ch := range channels {
+			close(ch)
+		}
+		delete(h.subscribers, userID)
+	}
+}
+
+// Publish hands item to every stream its user has open, skipping streams
+// that are too far behind rather than blocking the others.
+func (h *InboxHub) Publish(item *model.InboxItem) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for ch := range h.subscribers[item.UserID] {
+		select {
+		case ch <- *item:
+		default:
+			logrus.WithFields(logrus.Fields{
+				"userID":      item.UserID.Hex(),
+				"inboxItemID": item.ID.Hex(),
+			}).Warn("Inbox stream is behind, item dropped")
+		}
+	}
+}
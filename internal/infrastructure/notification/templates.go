@@ -0,0 +1,116 @@
+package notification
+
+import (
+	"bytes"
+	"fmt"
+	htmltemplate "html/template"
+	"path/filepath"
+	"strconv"
+	"strings"
+	texttemplate "text/template"
+	"time"
+
+	"github.com/latiiLA/coop-forex-server/internal/domain/model"
+)
+
+// Templates renders notifications from a directory holding a shared
+// layout.html and layout.txt plus, for every event, <event>.html defining
+// "content" and <event>.txt defining "subject" and "content".
+type Templates struct {
+	html map[model.NotificationEvent]*htmltemplate.Template
+	text map[model.NotificationEvent]*texttemplate.Template
+}
+
+var templateFuncs = map[string]any{
+	"amount": FormatAmount,
+	"datetime": func(t time.Time) string {
+		return t.Format("2006-01-02 15:04:05")
+	},
+}
+
+// LoadTemplates parses the templates of every event in dir and renders each
+// once with sample data, so a broken template stops the server at startup
+// rather than a notification later.
+func LoadTemplates(dir string) (*Templates, error) {
+	t := &Templates{
+		html: make(map[model.NotificationEvent]*htmltemplate.Template, len(model.NotificationEvents)),
+		text: make(map[model.NotificationEvent]*texttemplate.Template, len(model.NotificationEvents)),
+	}
+
+	for _, event := range model.NotificationEvents {
+		html, err := htmltemplate.New("layout.html").Funcs(templateFuncs).ParseFiles(
+			filepath.Join(dir, "layout.html"),
+			filepath.Join(dir, string(event)+".html"),
+		)
+		if err != nil {
+			return nil, fmt.Errorf("email templates of %s: %w", event, err)
+		}
+
+		text, err := texttemplate.New("layout.txt").Funcs(templateFuncs).ParseFiles(
+			filepath.Join(dir, "layout.txt"),
+			filepath.Join(dir, string(event)+".txt"),
+		)
+		if err != nil {
+			return nil, fmt.Errorf("email templates of %s: %w", event, err)
+		}
+		if text.Lookup("subject") == nil {
+			return nil, fmt.Errorf("email templates of %s: %s.txt does not define a subject", event, event)
+		}
+
+		t.html[event] = html
+		t.text[event] = text
+
+		if _, err := t.Render(event, model.SampleNotificationData(event)); err != nil {
+			return nil, err
+		}
+	}
+
+	return t, nil
+}
+
+func (t *Templates) Render(event model.NotificationEvent, data *model.NotificationData) (*model.RenderedNotification, error) {
+	html, ok := t.html[event]
+	if !ok {
+		return nil, fmt.Errorf("no email templates for %q", event)
+	}
+	text := t.text[event]
+
+	var subject, htmlBody, textBody bytes.Buffer
+	if err := text.ExecuteTemplate(&subject, "subject", data); err != nil {
+		return nil, fmt.Errorf("rendering subject of %s: %w", event, err)
+	}
+	if err := text.ExecuteTemplate(&textBody, "layout.txt", data); err != nil {
+		return nil, fmt.Errorf("rendering text of %s: %w", event, err)
+	}
+	if err := html.ExecuteTemplate(&htmlBody, "layout.html", data); err != nil {
+		return nil, fmt.Errorf("rendering html of %s: %w", event, err)
+	}
+
+	return &model.RenderedNotification{
+		Subject: strings.Join(strings.Fields(subject.String()), " "),
+		HTML:    htmlBody.String(),
+		Text:    textBody.String(),
+	}, nil
+}
+
+// FormatAmount writes an amount with two decimals and thousands separators,
+// as in 12,500.00.
+func FormatAmount(amount float64) string {
+	s := strconv.FormatFloat(amount, 'f', 2, 64)
+
+	sign := ""
+	if strings.HasPrefix(s, "-") {
+		sign, s = "-", s[1:]
+	}
+
+	whole, fraction, _ := strings.Cut(s, ".")
+	var grouped strings.Builder
+	for i, digit := range whole {
+		if i > 0 && (len(whole)-i)%3 == 0 {
+			grouped.WriteByte(',')
+		}
+		grouped.WriteRune(digit)
+	}
+
+	return sign + grouped.String() + "." + fraction
+}
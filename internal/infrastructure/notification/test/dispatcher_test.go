@@ -0,0 +1,158 @@
+package notification_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/latiiLA/coop-forex-server/internal/domain/model"
+	"github.com/latiiLA/coop-forex-server/internal/infrastructure/notification"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// outbox is an in-memory NotificationRepository that hands out each queued
+// notification while it is due.
+type outbox struct {
+	notifications []*model.Notification
+}
+
+func (o *outbox) Create(ctx context.Context, n *model.Notification) error {
+	o.notifications = append(o.notifications, n)
+	return nil
+}
+
+func (o *outbox) FindByID(ctx context.Context, id primitive.ObjectID) (*model.Notification, error) {
+	for _, n := range o.notifications {
+		if n.ID == id {
+			return n, nil
+		}
+	}
+	return nil, nil
+}
+
+func (o *outbox) FindByRequestID(ctx context.Context, requestID primitive.ObjectID) ([]model.Notification, error) {
+	return nil, nil
+}
+
+func (o *outbox) ClaimDue(ctx context.Context, now time.Time, leaseUntil time.Time) (*model.Notification, error) {
+	for _, n := range o.notifications {
+		due := (n.Status == model.NotificationPending || n.Status == model.NotificationFailed) && !n.NextAttemptAt.After(now)
+		if due {
+			n.Status = model.NotificationSending
+			n.Attempts++
+			claimed := *n
+			return &claimed, nil
+		}
+	}
+	return nil, nil
+}
+
+func (o *outbox) SaveDelivery(ctx context.Context, saved *model.Notification) error {
+	for _, n := range o.notifications {
+		if n.ID == saved.ID {
+			*n = *saved
+		}
+	}
+	return nil
+}
+
+func (o *outbox) Requeue(ctx context.Context, id primitive.ObjectID, at time.Time) error {
+	return nil
+}
+
+type history struct {
+	events []model.RequestEvent
+}
+
+func (h *history) Create(ctx context.Context, event *model.RequestEvent) error {
+	h.events = append(h.events, *event)
+	return nil
+}
+
+func (h *history) FindByRequestID(ctx context.Context, requestID primitive.ObjectID) ([]model.RequestEvent, error) {
+	return h.events, nil
+}
+
+type fakeNotifier struct {
+	err   error
+	calls int
+}
+
+func (f *fakeNotifier) Channel() model.NotificationChannel {
+	return model.NotificationChannelEmail
+}
+
+func (f *fakeNotifier) Notify(ctx context.Context, n *model.Notification) error {
+	f.calls++
+	return f.err
+}
+
+func newNotification() *model.Notification {
+	return &model.Notification{
+		ID:        primitive.NewObjectID(),
+		RequestID: primitive.NewObjectID(),
+		Channel:   model.NotificationChannelEmail,
+		To:        []string{"forex@example.com"},
+		Status:    model.NotificationPending,
+	}
+}
+
+func TestDispatcherDelivers(t *testing.T) {
+	repo := &outbox{notifications: []*model.Notification{newNotification()}}
+	events := &history{}
+	notifier := &fakeNotifier{}
+	policy := model.NotificationRetryPolicy{MaxAttempts: 3, BaseDelay: time.Minute, MaxDelay: time.Hour}
+
+	attempted, err := notification.NewDispatcher(repo, events, policy, notifier).DispatchDue(context.Background())
+	if err != nil {
+		t.Fatalf("DispatchDue: %v", err)
+	}
+	if attempted != 1 || notifier.calls != 1 {
+		t.Fatalf("attempted %d, notified %d; expected 1 and 1", attempted, notifier.calls)
+	}
+
+	n := repo.notifications[0]
+	if n.Status != model.NotificationSent || n.SentAt == nil {
+		t.Errorf("status %q, sent at %v; expected sent with a time", n.Status, n.SentAt)
+	}
+	if len(events.events) != 1 || events.events[0].Type != model.ReqEventEmailSent {
+		t.Errorf("history %+v; expected one email_sent event", events.events)
+	}
+}
+
+func TestDispatcherRetriesThenDeadLetters(t *testing.T) {
+	repo := &outbox{notifications: []*model.Notification{newNotification()}}
+	events := &history{}
+	notifier := &fakeNotifier{err: errors.New("connection refused")}
+	policy := model.NotificationRetryPolicy{MaxAttempts: 2, BaseDelay: time.Minute, MaxDelay: time.Hour}
+	dispatcher := notification.NewDispatcher(repo, events, policy, notifier)
+
+	if _, err := dispatcher.DispatchDue(context.Background()); err != nil {
+		t.Fatalf("DispatchDue: %v", err)
+	}
+
+	n := repo.notifications[0]
+	if n.Status != model.NotificationFailed || n.LastError != "connection refused" {
+		t.Fatalf("status %q, error %q; expected a failed notification", n.Status, n.LastError)
+	}
+	if wait := time.Until(n.NextAttemptAt); wait < 50*time.Second || wait > time.Minute {
+		t.Errorf("next attempt in %v; expected about a minute", wait)
+	}
+	if len(events.events) != 0 {
+		t.Errorf("history %+v; expected nothing before the last attempt", events.events)
+	}
+
+	// Make the retry due now.
+	n.NextAttemptAt = time.Now()
+	if _, err := dispatcher.DispatchDue(context.Background()); err != nil {
+		t.Fatalf("DispatchDue: %v", err)
+	}
+
+	if n.Status != model.NotificationDead || n.DeadAt == nil {
+		t.Errorf("status %q after %d attempts; expected dead", n.Status, n.Attempts)
+	}
+	if len(events.events) != 1 || events.events[0].Type != model.ReqEventEmailFailed {
+		t.Errorf("history %+v; expected one email_failed event", events.events)
+	}
+}
@@ -0,0 +1,131 @@
+package notification_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/latiiLA/coop-forex-server/internal/domain/model"
+	"github.com/latiiLA/coop-forex-server/internal/infrastructure/notification"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// feed is an InboxRepository whose watch delivers whatever is sent on items.
+type feed struct {
+	model.InboxRepository
+	items chan *model.InboxItem
+}
+
+func (f *feed) Watch(ctx context.Context, deliver func(item *model.InboxItem)) error {
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case item := <-f.items:
+			deliver(item)
+		}
+	}
+}
+
+func receive(t *testing.T, items <-chan model.InboxItem) model.InboxItem {
+	t.Helper()
+
+	select {
+	case item := <-items:
+		return item
+	case <-time.After(time.Second):
+		t.Fatal("no inbox item received")
+		return model.InboxItem{}
+	}
+}
+
+func TestInboxHubDeliversToTheUsersStreams(t *testing.T) {
+	f := &feed{items: make(chan *model.InboxItem)}
+	hub := notification.NewInboxHub(f)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go hub.Run(ctx)
+
+	alice, bob := primitive.NewObjectID(), primitive.NewObjectID()
+	aliceTab1, unsubscribe1 := hub.Subscribe(alice)
+	defer unsubscribe1()
+	aliceTab2, unsubscribe2 := hub.Subscribe(alice)
+	defer unsubscribe2()
+	bobTab, unsubscribeBob := hub.Subscribe(bob)
+	defer unsubscribeBob()
+
+	f.items <- &model.InboxItem{ID: primitive.NewObjectID(), UserID: alice, RequestCode: "REQ-1"}
+
+	for _, tab := range []<-chan model.InboxItem{aliceTab1, aliceTab2} {
+		if item := receive(t, tab); item.RequestCode != "REQ-1" {
+			t.Errorf("received %q; expected REQ-1", item.RequestCode)
+		}
+	}
+
+	select {
+	case item := <-bobTab:
+		t.Errorf("bob received %q meant for alice", item.RequestCode)
+	default:
+	}
+}
+
+func TestInboxHubUnsubscribeClosesTheStream(t *testing.T) {
+	hub := notification.NewInboxHub(nil)
+	user := primitive.NewObjectID()
+
+	items, unsubscribe := hub.Subscribe(user)
+	unsubscribe()
+	unsubscribe()
+
+	if _, ok := <-items; ok {
+		t.Error("stream is still open after unsubscribing")
+	}
+
+	// Publishing to a user without streams must not block or panic.
+	hub.Publish(&model.InboxItem{UserID: user})
+}
+
+func TestInboxHubDropsItemsForSlowStreams(t *testing.T) {
+	hub := notification.NewInboxHub(nil)
+	hub.Buffer = 1
+	user := primitive.NewObjectID()
+
+	items, unsubscribe := hub.Subscribe(user)
+	defer unsubscribe()
+
+	hub.Publish(&model.InboxItem{UserID: user, RequestCode: "first"})
+	hub.Publish(&model.InboxItem{UserID: user, RequestCode: "second"})
+
+	if item := receive(t, items); item.RequestCode != "first" {
+		t.Errorf("received %q; expected first", item.RequestCode)
+	}
+	select {
+	case item := <-items:
+		t.Errorf("received %q; expected it to be dropped", item.RequestCode)
+	default:
+	}
+}
+
+func TestInboxHubCloseEndsStreams(t *testing.T) {
+	hub := notification.NewInboxHub(&feed{items: make(chan *model.InboxItem)})
+
+	user := primitive.NewObjectID()
+	open, unsubscribe := hub.Subscribe(user)
+	defer unsubscribe()
+
+	hub.Close()
+
+	if _, ok := <-open; ok {
+		t.Fatal("open stream was not ended by Close")
+	}
+
+	late, unsubscribeLate := hub.Subscribe(user)
+	defer unsubscribeLate()
+	if _, ok := <-late; ok {
+		t.Fatal("stream opened after Close was not ended")
+	}
+
+	// Publishing after Close reaches no one and does not panic.
+	hub.Publish(&model.InboxItem{ID: primitive.NewObjectID(), UserID: user})
+}
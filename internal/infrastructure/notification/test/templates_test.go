@@ -0,0 +1,97 @@
+package notification_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/latiiLA/coop-forex-server/internal/domain/model"
+	"github.com/latiiLA/coop-forex-server/internal/infrastructure/notification"
+)
+
+const templatesDir = "../../../../templates/email"
+
+func TestLoadRepositoryTemplates(t *testing.T) {
+	templates, err := notification.LoadTemplates(templatesDir)
+	if err != nil {
+		t.Fatalf("LoadTemplates: %v", err)
+	}
+
+	for _, event := range model.NotificationEvents {
+		data := model.SampleNotificationData(event)
+		rendered, err := templates.Render(event, data)
+		if err != nil {
+			t.Fatalf("Render(%s): %v", event, err)
+		}
+		if !strings.Contains(rendered.Subject, data.RequestCode) || strings.Contains(rendered.Subject, "\n") {
+			t.Errorf("%s subject %q; expected one line with the request code", event, rendered.Subject)
+		}
+		if !strings.Contains(rendered.HTML, data.RequestCode) || !strings.Contains(rendered.Text, data.RequestCode) {
+			t.Errorf("%s bodies do not mention the request code", event)
+		}
+	}
+}
+
+func TestTemplatesIncludeEventDetails(t *testing.T) {
+	templates, err := notification.LoadTemplates(templatesDir)
+	if err != nil {
+		t.Fatalf("LoadTemplates: %v", err)
+	}
+
+	approved, err := templates.Render(model.NotifyRequestApproved, model.SampleNotificationData(model.NotifyRequestApproved))
+	if err != nil {
+		t.Fatalf("Render: %v", err)
+	}
+	for _, body := range []string{approved.HTML, approved.Text} {
+		if !strings.Contains(body, "USD") || !strings.Contains(body, "4,000.00") {
+			t.Errorf("approval does not list the approved amount:\n%s", body)
+		}
+	}
+
+	data := model.SampleNotificationData(model.NotifyRequestRejected)
+	data.RejectionReason = `Visa <expired>`
+	rejected, err := templates.Render(model.NotifyRequestRejected, data)
+	if err != nil {
+		t.Fatalf("Render: %v", err)
+	}
+	if !strings.Contains(rejected.HTML, "Visa &lt;expired&gt;") {
+		t.Errorf("rejection reason is missing or not escaped in HTML:\n%s", rejected.HTML)
+	}
+	if !strings.Contains(rejected.Text, "Visa <expired>") {
+		t.Errorf("rejection reason is missing from text:\n%s", rejected.Text)
+	}
+}
+
+func TestLoadTemplatesRequiresEveryEvent(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"layout.html", "layout.txt", "request_sent.html", "request_sent.txt"} {
+		content, err := os.ReadFile(filepath.Join(templatesDir, name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, name), content, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if _, err := notification.LoadTemplates(dir); err == nil {
+		t.Errorf("LoadTemplates succeeded without templates for every event")
+	}
+}
+
+func TestFormatAmount(t *testing.T) {
+	cases := map[float64]string{
+		0:          "0.00",
+		999.5:      "999.50",
+		1000:       "1,000.00",
+		1234567.89: "1,234,567.89",
+		-12500:     "-12,500.00",
+	}
+
+	for amount, expected := range cases {
+		if actual := notification.FormatAmount(amount); actual != expected {
+			t.Errorf("FormatAmount(%v) = %q; expected %q", amount, actual, expected)
+		}
+	}
+}
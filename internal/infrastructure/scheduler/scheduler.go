@@ -0,0 +1,321 @@
+// Package scheduler runs the server's background jobs on cron schedules. Every
+// server instance runs a scheduler; a lease on the job's document in Mongo
+// makes sure only one of them runs each job at a time.
+package scheduler
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/latiiLA/coop-forex-server/internal/common"
+	"github.com/latiiLA/coop-forex-server/internal/domain/model"
+	"github.com/sirupsen/logrus"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// JobFunc does the work of a job and returns a one line summary of it for
+// the run history. It must stop when ctx is cancelled.
+type JobFunc func(ctx context.Context) (string, error)
+
+type job struct {
+	name       string
+	spec       string
+	schedule   Schedule
+	run        JobFunc
+	registered bool
+}
+
+// Scheduler starts registered jobs when they are due and when they are
+// triggered by hand, and records every run.
+type Scheduler struct {
+	jobRepository    model.JobRepository
+	jobRunRepository model.JobRunRepository
+	jobs             map[string]*job
+	order            []string
+
+	// Instance names this server in leases and run history.
+	Instance string
+	// PollInterval is how often the scheduler looks for due jobs.
+	PollInterval time.Duration
+	// LeaseTTL is how long a lease outlives an instance that stopped
+	// renewing it. Running jobs renew it every third of that.
+	LeaseTTL time.Duration
+	// StoreTimeout bounds the writes that record a run.
+	StoreTimeout time.Duration
+
+	mu      sync.Mutex
+	base    context.Context
+	running sync.WaitGroup
+}
+
+func New(jobRepository model.JobRepository, jobRunRepository model.JobRunRepository) *Scheduler {
+	hostname, _ := os.Hostname()
+
+	return &Scheduler{
+		jobRepository:    jobRepository,
+		jobRunRepository: jobRunRepository,
+		jobs:             map[string]*job{},
+		Instance:         fmt.Sprintf("%s-%d-%s", hostname, os.Getpid(), primitive.NewObjectID().Hex()[18:]),
+		PollInterval:     15 * time.Second,
+		LeaseTTL:         time.Minute,
+		StoreTimeout:     10 * time.Second,
+	}
+}
+
+// Register adds a job that runs on the schedule spec. Jobs must be
+// registered before Run is called.
+func (s *Scheduler) Register(name string, spec string, run JobFunc) error {
+	schedule, err := ParseSchedule(spec)
+	if err != nil {
+		return fmt.Errorf("job %s: %w", name, err)
+	}
+	if _, ok := s.jobs[name]; ok {
+		return fmt.Errorf("job %s is registered twice", name)
+	}
+
+	s.jobs[name] = &job{name: name, spec: spec, schedule: schedule, run: run}
+	s.order = append(s.order, name)
+
+	return nil
+}
+
+// Run starts due jobs until ctx is cancelled. It then cancels the running
+// jobs and returns once they have stopped and their runs are recorded.
+func (s *Scheduler) Run(ctx context.Context) {
+	s.mu.Lock()
+	s.base = ctx
+	s.mu.Unlock()
+
+	ticker := time.NewTicker(s.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		s.startDue(ctx)
+
+		select {
+		case <-ctx.Done():
+			// No run starts once base is cleared, so none is missed here.
+			s.mu.Lock()
+			s.base = nil
+			s.mu.Unlock()
+			s.running.Wait()
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *Scheduler) startDue(ctx context.Context) {
+	for _, name := range s.order {
+		if ctx.Err() != nil {
+			return
+		}
+		j := s.jobs[name]
+		logger := logrus.WithField("job", name)
+
+		now := time.Now()
+		if !j.registered {
+			if err := s.jobRepository.Register(ctx, name, j.spec, j.schedule.Next(now)); err != nil {
+				logger.WithError(err).Error("Failed to register job")
+				continue
+			}
+			j.registered = true
+		}
+
+		claimed, err := s.jobRepository.ClaimDue(ctx, name, s.Instance, now, now.Add(s.LeaseTTL), j.schedule.Next(now))
+		if err != nil {
+			logger.WithError(err).Error("Failed to claim job")
+			continue
+		}
+		if !claimed {
+			continue
+		}
+
+		if _, err := s.start(ctx, j, model.JobTriggerSchedule, nil); err != nil {
+			logger.WithError(err).Error("Failed to start job")
+		}
+	}
+}
+
+// Trigger runs the job now unless it is running on any instance. The run
+// belongs to the scheduler, not to ctx: it goes on after the caller returns
+// and stops when the scheduler does.
+func (s *Scheduler) Trigger(ctx context.Context, name string, triggeredBy primitive.ObjectID) (*model.JobRun, error) {
+	j, ok := s.jobs[name]
+	if !ok {
+		return nil, common.ErrJobNotFound
+	}
+
+	s.mu.Lock()
+	base := s.base
+	s.mu.Unlock()
+	if base == nil {
+		return nil, common.ErrSchedulerNotRunning
+	}
+
+	now := time.Now()
+	claimed, err := s.jobRepository.Claim(ctx, name, s.Instance, now, now.Add(s.LeaseTTL))
+	if err != nil {
+		return nil, err
+	}
+	if !claimed {
+		// The job is running, or was never registered because the
+		// scheduler could not reach the database yet.
+		existing, err := s.jobRepository.FindByName(ctx, name)
+		if err != nil {
+			return nil, err
+		}
+		if existing == nil {
+			return nil, common.ErrSchedulerNotRunning
+		}
+		return nil, common.ErrJobRunning
+	}
+
+	return s.start(base, j, model.JobTriggerManual, &triggeredBy)
+}
+
+// start records a run of the job whose lease this instance just took and
+// runs it in the background.
+func (s *Scheduler) start(base context.Context, j *job, trigger model.JobTrigger, triggeredBy *primitive.ObjectID) (*model.JobRun, error) {
+	run := &model.JobRun{
+		Job:         j.name,
+		Trigger:     trigger,
+		TriggeredBy: triggeredBy,
+		Instance:    s.Instance,
+		Status:      model.JobRunning,
+		StartedAt:   time.Now(),
+	}
+
+	s.mu.Lock()
+	if s.base == nil {
+		s.mu.Unlock()
+		s.release(j.name, model.JobCancelled)
+		return nil, common.ErrSchedulerNotRunning
+	}
+	s.running.Add(1)
+	s.mu.Unlock()
+
+	storeCtx, cancel := context.WithTimeout(context.WithoutCancel(base), s.StoreTimeout)
+	defer cancel()
+
+	if err := s.jobRunRepository.Create(storeCtx, run); err != nil {
+		s.running.Done()
+		s.release(j.name, model.JobFailed)
+		return nil, err
+	}
+
+	started := *run
+	go s.execute(base, j, run)
+
+	return &started, nil
+}
+
+func (s *Scheduler) execute(base context.Context, j *job, run *model.JobRun) {
+	defer s.running.Done()
+
+	ctx, cancel := context.WithCancel(base)
+	defer cancel()
+
+	logger := logrus.WithFields(logrus.Fields{"job": j.name, "runID": run.ID.Hex(), "trigger": run.Trigger})
+	logger.Info("Job started")
+
+	renewed := make(chan struct{})
+	go func() {
+		defer close(renewed)
+		s.keepLease(ctx, j.name, cancel, logger)
+	}()
+
+	summary, err := s.call(ctx, j)
+	cancelled := ctx.Err() != nil
+	cancel()
+	<-renewed
+
+	now := time.Now()
+	run.Summary = summary
+	run.FinishedAt = &now
+	switch {
+	case cancelled:
+		run.Status = model.JobCancelled
+		if err != nil {
+			run.Error = err.Error()
+		}
+	case err != nil:
+		run.Status = model.JobFailed
+		run.Error = err.Error()
+	default:
+		run.Status = model.JobSucceeded
+	}
+
+	logger = logger.WithFields(logrus.Fields{"status": run.Status, "summary": run.Summary, "duration": now.Sub(run.StartedAt).String()})
+	if run.Error != "" {
+		logger = logger.WithField("error", run.Error)
+	}
+	switch run.Status {
+	case model.JobSucceeded:
+		logger.Info("Job finished")
+	case model.JobCancelled:
+		logger.Warn("Job cancelled")
+	default:
+		logger.Error("Job failed")
+	}
+
+	// The run is recorded even when shutdown cancelled it.
+	storeCtx, cancelStore := context.WithTimeout(context.WithoutCancel(base), s.StoreTimeout)
+	defer cancelStore()
+
+	if err := s.jobRunRepository.Finish(storeCtx, run); err != nil {
+		logger.WithError(err).Error("Failed to record job run")
+	}
+	s.release(j.name, run.Status)
+}
+
+// call runs the job, turning a panic into a failed run.
+func (s *Scheduler) call(ctx context.Context, j *job) (summary string, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("job panicked: %v", r)
+		}
+	}()
+
+	return j.run(ctx)
+}
+
+// keepLease renews the job's lease until ctx is done and cancels the job when
+// another instance took the lease over.
+func (s *Scheduler) keepLease(ctx context.Context, name string, cancel context.CancelFunc, logger *logrus.Entry) {
+	ticker := time.NewTicker(s.LeaseTTL / 3)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		held, err := s.jobRepository.RenewLease(ctx, name, s.Instance, time.Now().Add(s.LeaseTTL))
+		if err != nil {
+			if ctx.Err() == nil {
+				logger.WithError(err).Warn("Failed to renew job lease")
+			}
+			continue
+		}
+		if !held {
+			logger.Error("Job lease was taken over, cancelling the run")
+			cancel()
+			return
+		}
+	}
+}
+
+func (s *Scheduler) release(name string, status model.JobStatus) {
+	ctx, cancel := context.WithTimeout(context.Background(), s.StoreTimeout)
+	defer cancel()
+
+	if err := s.jobRepository.ReleaseLease(ctx, name, s.Instance, time.Now(), status); err != nil {
+		logrus.WithError(err).WithField("job", name).Error("Failed to release job lease")
+	}
+}
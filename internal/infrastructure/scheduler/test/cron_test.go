@@ -0,0 +1,61 @@
+package scheduler_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/latiiLA/coop-forex-server/internal/infrastructure/scheduler"
+)
+
+func TestScheduleNext(t *testing.T) {
+	// A Saturday.
+	after := time.Date(2026, 10, 17, 9, 7, 30, 0, time.UTC)
+
+	cases := []struct {
+		spec string
+		want time.Time
+	}{
+		{"*/5 * * * *", time.Date(2026, 10, 17, 9, 10, 0, 0, time.UTC)},
+		{"0 * * * *", time.Date(2026, 10, 17, 10, 0, 0, 0, time.UTC)},
+		{"30 2 * * *", time.Date(2026, 10, 18, 2, 30, 0, 0, time.UTC)},
+		{"0 9-17/4 * * 1-5", time.Date(2026, 10, 19, 9, 0, 0, 0, time.UTC)},
+		{"15,45 8 1 * *", time.Date(2026, 11, 1, 8, 15, 0, 0, time.UTC)},
+		{"0 0 * * 7", time.Date(2026, 10, 18, 0, 0, 0, 0, time.UTC)},
+		{"0 0 29 2 *", time.Date(2028, 2, 29, 0, 0, 0, 0, time.UTC)},
+		// Day of month or day of week, as in cron.
+		{"0 0 20 * 0", time.Date(2026, 10, 18, 0, 0, 0, 0, time.UTC)},
+		{"@daily", time.Date(2026, 10, 18, 0, 0, 0, 0, time.UTC)},
+		{"@every 90m", after.Add(90 * time.Minute)},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.spec, func(t *testing.T) {
+			schedule, err := scheduler.ParseSchedule(tc.spec)
+			if err != nil {
+				t.Fatalf("ParseSchedule() error = %v", err)
+			}
+			if got := schedule.Next(after); !got.Equal(tc.want) {
+				t.Errorf("Next() = %v; expected %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestParseScheduleRejectsInvalidSpecs(t *testing.T) {
+	for _, spec := range []string{
+		"",
+		"* * * *",
+		"60 * * * *",
+		"* 24 * * *",
+		"*/0 * * * *",
+		"5-1 * * * *",
+		"a * * * *",
+		"0 0 31 2 *",
+		"@every 10s",
+		"@yearly",
+	} {
+		if _, err := scheduler.ParseSchedule(spec); err == nil {
+			t.Errorf("ParseSchedule(%q) succeeded; expected an error", spec)
+		}
+	}
+}
@@ -0,0 +1,271 @@
+package scheduler_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/latiiLA/coop-forex-server/internal/common"
+	"github.com/latiiLA/coop-forex-server/internal/domain/model"
+	"github.com/latiiLA/coop-forex-server/internal/infrastructure/scheduler"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// store keeps jobs and their runs in memory.
+type store struct {
+	model.JobRepository
+	model.JobRunRepository
+
+	mu   sync.Mutex
+	jobs map[string]*model.Job
+	runs map[primitive.ObjectID]model.JobRun
+}
+
+func newStore() *store {
+	return &store{jobs: map[string]*model.Job{}, runs: map[primitive.ObjectID]model.JobRun{}}
+}
+
+func (s *store) Register(ctx context.Context, name string, schedule string, next time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.jobs[name]; !ok {
+		s.jobs[name] = &model.Job{Name: name, Schedule: schedule, NextRunAt: next}
+	}
+	return nil
+}
+
+func (s *store) FindByName(ctx context.Context, name string) (*model.Job, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	job, ok := s.jobs[name]
+	if !ok {
+		return nil, nil
+	}
+	copied := *job
+	return &copied, nil
+}
+
+func (s *store) ClaimDue(ctx context.Context, name string, holder string, now time.Time, until time.Time, next time.Time) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	job := s.jobs[name]
+	if job == nil || job.Paused || job.NextRunAt.After(now) || job.IsRunning(now) {
+		return false, nil
+	}
+	job.LeaseHolder, job.LeaseUntil, job.NextRunAt = holder, &until, next
+	return true, nil
+}
+
+func (s *store) Claim(ctx context.Context, name string, holder string, now time.Time, until time.Time) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	job := s.jobs[name]
+	if job == nil || job.IsRunning(now) {
+		return false, nil
+	}
+	job.LeaseHolder, job.LeaseUntil = holder, &until
+	return true, nil
+}
+
+func (s *store) RenewLease(ctx context.Context, name string, holder string, until time.Time) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	job := s.jobs[name]
+	if job == nil || job.LeaseHolder != holder {
+		return false, nil
+	}
+	job.LeaseUntil = &until
+	return true, nil
+}
+
+func (s *store) ReleaseLease(ctx context.Context, name string, holder string, finishedAt time.Time, status model.JobStatus) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if job := s.jobs[name]; job != nil && job.LeaseHolder == holder {
+		job.LeaseHolder, job.LeaseUntil = "", nil
+		job.LastRunAt, job.LastStatus = &finishedAt, status
+	}
+	return nil
+}
+
+func (s *store) Create(ctx context.Context, run *model.JobRun) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	run.ID = primitive.NewObjectID()
+	s.runs[run.ID] = *run
+	return nil
+}
+
+func (s *store) Finish(ctx context.Context, run *model.JobRun) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.runs[run.ID] = *run
+	return nil
+}
+
+func (s *store) run(id primitive.ObjectID) model.JobRun {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.runs[id]
+}
+
+// waitForRun waits until the run with id has finished.
+func waitForRun(t *testing.T, s *store, id primitive.ObjectID) model.JobRun {
+	t.Helper()
+
+	deadline := time.Now().Add(time.Second)
+	for time.Now().Before(deadline) {
+		if run := s.run(id); run.FinishedAt != nil {
+			return run
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	t.Fatal("job run did not finish")
+	return model.JobRun{}
+}
+
+// startScheduler runs a scheduler over s and returns a function that stops
+// it and waits for Run to return.
+func startScheduler(t *testing.T, sched *scheduler.Scheduler) func() {
+	t.Helper()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	stopped := make(chan struct{})
+	go func() {
+		sched.Run(ctx)
+		close(stopped)
+	}()
+
+	// Run registers the jobs before it starts due ones.
+	time.Sleep(20 * time.Millisecond)
+
+	return func() {
+		cancel()
+		<-stopped
+	}
+}
+
+func newScheduler(s *store) *scheduler.Scheduler {
+	sched := scheduler.New(s, s)
+	sched.PollInterval = time.Hour
+	sched.LeaseTTL = 30 * time.Millisecond
+	return sched
+}
+
+func TestTriggerRecordsTheRun(t *testing.T) {
+	s := newStore()
+	sched := newScheduler(s)
+	if err := sched.Register("cleanup", "@hourly", func(ctx context.Context) (string, error) {
+		return "removed 3", nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if err := sched.Register("broken", "@hourly", func(ctx context.Context) (string, error) {
+		panic("boom")
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	user := primitive.NewObjectID()
+	if _, err := sched.Trigger(context.Background(), "cleanup", user); !errors.Is(err, common.ErrSchedulerNotRunning) {
+		t.Errorf("Trigger() before Run error = %v; expected %v", err, common.ErrSchedulerNotRunning)
+	}
+
+	stop := startScheduler(t, sched)
+	defer stop()
+
+	if _, err := sched.Trigger(context.Background(), "missing", user); !errors.Is(err, common.ErrJobNotFound) {
+		t.Errorf("Trigger(missing) error = %v; expected %v", err, common.ErrJobNotFound)
+	}
+
+	started, err := sched.Trigger(context.Background(), "cleanup", user)
+	if err != nil {
+		t.Fatalf("Trigger() error = %v", err)
+	}
+	if started.Status != model.JobRunning || started.Trigger != model.JobTriggerManual || *started.TriggeredBy != user {
+		t.Errorf("started run = %+v; expected a running manual run by %s", started, user.Hex())
+	}
+
+	run := waitForRun(t, s, started.ID)
+	if run.Status != model.JobSucceeded || run.Summary != "removed 3" {
+		t.Errorf("run = %s %q; expected succeeded \"removed 3\"", run.Status, run.Summary)
+	}
+
+	broken, err := sched.Trigger(context.Background(), "broken", user)
+	if err != nil {
+		t.Fatalf("Trigger(broken) error = %v", err)
+	}
+	if run := waitForRun(t, s, broken.ID); run.Status != model.JobFailed || run.Error == "" {
+		t.Errorf("panicking run = %s %q; expected failed with an error", run.Status, run.Error)
+	}
+}
+
+func TestRunningJobCannotBeTriggeredAgain(t *testing.T) {
+	s := newStore()
+	sched := newScheduler(s)
+	release := make(chan struct{})
+	if err := sched.Register("slow", "@hourly", func(ctx context.Context) (string, error) {
+		<-release
+		return "", nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	stop := startScheduler(t, sched)
+	defer stop()
+
+	user := primitive.NewObjectID()
+	started, err := sched.Trigger(context.Background(), "slow", user)
+	if err != nil {
+		t.Fatalf("Trigger() error = %v", err)
+	}
+
+	// The lease is renewed while the job runs, so it outlives its TTL.
+	time.Sleep(100 * time.Millisecond)
+	if _, err := sched.Trigger(context.Background(), "slow", user); !errors.Is(err, common.ErrJobRunning) {
+		t.Errorf("second Trigger() error = %v; expected %v", err, common.ErrJobRunning)
+	}
+
+	close(release)
+	waitForRun(t, s, started.ID)
+}
+
+func TestStoppingTheSchedulerCancelsRunningJobs(t *testing.T) {
+	s := newStore()
+	sched := newScheduler(s)
+	if err := sched.Register("endless", "@hourly", func(ctx context.Context) (string, error) {
+		<-ctx.Done()
+		return "interrupted", ctx.Err()
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	stop := startScheduler(t, sched)
+
+	started, err := sched.Trigger(context.Background(), "endless", primitive.NewObjectID())
+	if err != nil {
+		t.Fatalf("Trigger() error = %v", err)
+	}
+
+	// Run only returns once the cancelled run is recorded.
+	stop()
+
+	run := s.run(started.ID)
+	if run.Status != model.JobCancelled || run.FinishedAt == nil {
+		t.Errorf("run = %s finished at %v; expected a recorded cancellation", run.Status, run.FinishedAt)
+	}
+	if job, _ := s.FindByName(context.Background(), "endless"); job.LeaseHolder != "" || job.LastStatus != model.JobCancelled {
+		t.Errorf("job = %+v; expected its lease released after the cancelled run", job)
+	}
+}
@@ -0,0 +1,125 @@
+package session
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/latiiLA/coop-forex-server/internal/domain/model"
+	"github.com/sirupsen/logrus"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// PermissionVersions mirrors the permissions versions of the users whose
+// access changed recently. Changes made by another instance are picked up
+// on the next poll.
+type PermissionVersions struct {
+	userRepository model.UserRepository
+
+	// PollInterval is how often changes made elsewhere are loaded, and so
+	// how long a revoked permission may still work on another instance.
+	PollInterval time.Duration
+	// Overlap is how far back each poll looks past the previous one, so a
+	// change written while the previous poll ran is not missed.
+	Overlap time.Duration
+	// StoreTimeout bounds each poll.
+	StoreTimeout time.Duration
+	// MaxTokenAge is how long access tokens live. A change older than that
+	// has outlived every token issued before it and is forgotten.
+	MaxTokenAge time.Duration
+
+	mu       sync.RWMutex
+	versions map[primitive.ObjectID]model.PermissionsVersion
+	polledAt time.Time
+}
+
+func NewPermissionVersions(userRepository model.UserRepository, maxTokenAge time.Duration) *PermissionVersions {
+	return &PermissionVersions{
+		userRepository: userRepository,
+		versions:       map[primitive.ObjectID]model.PermissionsVersion{},
+		PollInterval:   5 * time.Second,
+		Overlap:        time.Minute,
+		StoreTimeout:   10 * time.Second,
+		MaxTokenAge:    maxTokenAge,
+	}
+}
+
+// IsStale reports whether a token carrying version was issued before the
+// latest change to userID's access.
+func (pv *PermissionVersions) IsStale(userID primitive.ObjectID, version int64) bool {
+	pv.mu.RLock()
+	defer pv.mu.RUnlock()
+
+	current, ok := pv.versions[userID]
+	return ok && version < current.Version
+}
+
+func (pv *PermissionVersions) Add(versions ...model.PermissionsVersion) {
+	pv.mu.Lock()
+	defer pv.mu.Unlock()
+
+	pv.add(versions)
+}
+
+// Load reads every change recent enough to have tokens issued before it
+// still in circulation. It must succeed before the server takes requests.
+func (pv *PermissionVersions) Load(ctx context.Context) error {
+	return pv.poll(ctx, time.Now().Add(-pv.MaxTokenAge))
+}
+
+// Run polls for new changes until ctx is cancelled.
+func (pv *PermissionVersions) Run(ctx context.Context) {
+	ticker := time.NewTicker(pv.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		pv.mu.RLock()
+		since := pv.polledAt.Add(-pv.Overlap)
+		pv.mu.RUnlock()
+
+		if err := pv.poll(ctx, since); err != nil && ctx.Err() == nil {
+			logrus.WithError(err).Error("Failed to load permission changes")
+		}
+	}
+}
+
+func (pv *PermissionVersions) poll(ctx context.Context, since time.Time) error {
+	ctx, cancel := context.WithTimeout(ctx, pv.StoreTimeout)
+	defer cancel()
+
+	now := time.Now()
+	versions, err := pv.userRepository.FindPermissionsChangedSince(ctx, since)
+	if err != nil {
+		return err
+	}
+
+	pv.mu.Lock()
+	defer pv.mu.Unlock()
+
+	pv.add(versions)
+	forgetBefore := now.Add(-pv.MaxTokenAge)
+	for userID, version := range pv.versions {
+		if version.ChangedAt.Before(forgetBefore) {
+			delete(pv.versions, userID)
+		}
+	}
+	pv.polledAt = now
+
+	return nil
+}
+
+// add keeps the highest version of each user, so a poll that read a change
+// before a local bump does not undo it.
+func (pv *PermissionVersions) add(versions []model.PermissionsVersion) {
+	for _, version := range versions {
+		if current, ok := pv.versions[version.UserID]; !ok || version.Version > current.Version {
+			pv.versions[version.UserID] = version
+		}
+	}
+}
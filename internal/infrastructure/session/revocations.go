@@ -0,0 +1,116 @@
+// Package session keeps the revoked sessions and the users whose access
+// changed in memory, so every request can be checked against them without a
+// database round trip.
+package session
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/latiiLA/coop-forex-server/internal/domain/model"
+	"github.com/sirupsen/logrus"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Revocations mirrors the revoked sessions that still have tokens in
+// circulation. Sessions revoked by another instance are picked up on the
+// next poll.
+type Revocations struct {
+	sessionRepository model.SessionRepository
+
+	// PollInterval is how often revocations made elsewhere are loaded, and
+	// so how long a revoked token may still pass on another instance.
+	PollInterval time.Duration
+	// Overlap is how far back each poll looks past the previous one, so a
+	// revocation written while the previous poll ran is not missed.
+	Overlap time.Duration
+	// StoreTimeout bounds each poll.
+	StoreTimeout time.Duration
+
+	mu       sync.RWMutex
+	revoked  map[primitive.ObjectID]time.Time
+	polledAt time.Time
+}
+
+func NewRevocations(sessionRepository model.SessionRepository) *Revocations {
+	return &Revocations{
+		sessionRepository: sessionRepository,
+		revoked:           map[primitive.ObjectID]time.Time{},
+		PollInterval:      10 * time.Second,
+		Overlap:           time.Minute,
+		StoreTimeout:      10 * time.Second,
+	}
+}
+
+func (r *Revocations) IsRevoked(sessionID primitive.ObjectID) bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	_, ok := r.revoked[sessionID]
+	return ok
+}
+
+func (r *Revocations) Add(sessions ...model.RevokedSession) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, session := range sessions {
+		r.revoked[session.ID] = session.ExpiresAt
+	}
+}
+
+// Load reads every revoked session whose tokens have not expired. It must
+// succeed before the server takes requests.
+func (r *Revocations) Load(ctx context.Context) error {
+	return r.poll(ctx, time.Time{})
+}
+
+// Run polls for new revocations until ctx is cancelled.
+func (r *Revocations) Run(ctx context.Context) {
+	ticker := time.NewTicker(r.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		r.mu.RLock()
+		since := r.polledAt.Add(-r.Overlap)
+		r.mu.RUnlock()
+
+		if err := r.poll(ctx, since); err != nil && ctx.Err() == nil {
+			logrus.WithError(err).Error("Failed to load revoked sessions")
+		}
+	}
+}
+
+func (r *Revocations) poll(ctx context.Context, since time.Time) error {
+	ctx, cancel := context.WithTimeout(ctx, r.StoreTimeout)
+	defer cancel()
+
+	now := time.Now()
+	sessions, err := r.sessionRepository.FindRevokedSince(ctx, since, now)
+	if err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, session := range sessions {
+		r.revoked[session.ID] = session.ExpiresAt
+	}
+	// Tokens of an expired session are refused by their expiry anyway.
+	for id, expiresAt := range r.revoked {
+		if !expiresAt.After(now) {
+			delete(r.revoked, id)
+		}
+	}
+	r.polledAt = now
+
+	return nil
+}
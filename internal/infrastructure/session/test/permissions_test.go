@@ -0,0 +1,79 @@
+package session_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/latiiLA/coop-forex-server/internal/domain/model"
+	"github.com/latiiLA/coop-forex-server/internal/infrastructure/session"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// changedUsers is a UserRepository that only lists permission changes.
+type changedUsers struct {
+	model.UserRepository
+	versions []model.PermissionsVersion
+	since    time.Time
+}
+
+func (u *changedUsers) FindPermissionsChangedSince(ctx context.Context, since time.Time) ([]model.PermissionsVersion, error) {
+	u.since = since
+	return u.versions, nil
+}
+
+func TestPermissionVersionsRefuseOlderTokens(t *testing.T) {
+	userID := primitive.NewObjectID()
+	users := &changedUsers{versions: []model.PermissionsVersion{{UserID: userID, Version: 3, ChangedAt: time.Now()}}}
+	versions := session.NewPermissionVersions(users, 15*time.Minute)
+
+	if err := versions.Load(context.Background()); err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+
+	if !versions.IsStale(userID, 2) {
+		t.Error("token issued before the change is not stale")
+	}
+	if versions.IsStale(userID, 3) {
+		t.Error("token issued after the change is stale")
+	}
+	if versions.IsStale(primitive.NewObjectID(), 0) {
+		t.Error("token of an unchanged user is stale")
+	}
+	if since := time.Since(users.since); since < 15*time.Minute || since > 16*time.Minute {
+		t.Errorf("Load looked back %v, want the token lifetime", since)
+	}
+}
+
+func TestPermissionVersionsKeepTheHighestVersion(t *testing.T) {
+	versions := session.NewPermissionVersions(&changedUsers{}, 15*time.Minute)
+	userID := primitive.NewObjectID()
+
+	versions.Add(model.PermissionsVersion{UserID: userID, Version: 5, ChangedAt: time.Now()})
+	versions.Add(model.PermissionsVersion{UserID: userID, Version: 4, ChangedAt: time.Now()})
+
+	if !versions.IsStale(userID, 4) {
+		t.Error("an older version replaced a newer one")
+	}
+}
+
+func TestPermissionVersionsForgetChangesOlderThanTokens(t *testing.T) {
+	versions := session.NewPermissionVersions(&changedUsers{}, 15*time.Minute)
+	old := primitive.NewObjectID()
+	recent := primitive.NewObjectID()
+	versions.Add(
+		model.PermissionsVersion{UserID: old, Version: 1, ChangedAt: time.Now().Add(-time.Hour)},
+		model.PermissionsVersion{UserID: recent, Version: 1, ChangedAt: time.Now()},
+	)
+
+	if err := versions.Load(context.Background()); err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+
+	if versions.IsStale(old, 0) {
+		t.Error("change older than every token is still kept")
+	}
+	if !versions.IsStale(recent, 0) {
+		t.Error("recent change was forgotten")
+	}
+}
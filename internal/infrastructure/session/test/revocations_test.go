@@ -0,0 +1,125 @@
+package session_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/latiiLA/coop-forex-server/internal/domain/model"
+	"github.com/latiiLA/coop-forex-server/internal/infrastructure/session"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// revokedStore is a SessionRepository that only lists revoked sessions.
+type revokedStore struct {
+	model.SessionRepository
+
+	mu      sync.Mutex
+	revoked []model.RevokedSession
+	since   []time.Time
+}
+
+func (s *revokedStore) FindRevokedSince(ctx context.Context, since time.Time, now time.Time) ([]model.RevokedSession, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.since = append(s.since, since)
+	return append([]model.RevokedSession(nil), s.revoked...), nil
+}
+
+func (s *revokedStore) revoke(session model.RevokedSession) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.revoked = append(s.revoked, session)
+}
+
+func TestRevocationsLoadReadsEveryRevokedSession(t *testing.T) {
+	revoked := model.RevokedSession{ID: primitive.NewObjectID(), ExpiresAt: time.Now().Add(time.Hour)}
+	store := &revokedStore{revoked: []model.RevokedSession{revoked}}
+	revocations := session.NewRevocations(store)
+
+	if err := revocations.Load(context.Background()); err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+
+	if !revocations.IsRevoked(revoked.ID) {
+		t.Error("loaded session is not revoked")
+	}
+	if revocations.IsRevoked(primitive.NewObjectID()) {
+		t.Error("unknown session is revoked")
+	}
+	if !store.since[0].IsZero() {
+		t.Errorf("Load looked back to %v, want the beginning", store.since[0])
+	}
+}
+
+func TestRevocationsAddRefusesAtOnce(t *testing.T) {
+	revocations := session.NewRevocations(&revokedStore{})
+	sessionID := primitive.NewObjectID()
+
+	revocations.Add(model.RevokedSession{ID: sessionID, ExpiresAt: time.Now().Add(time.Hour)})
+
+	if !revocations.IsRevoked(sessionID) {
+		t.Error("added session is not revoked")
+	}
+}
+
+func TestRevocationsForgetExpiredSessions(t *testing.T) {
+	revocations := session.NewRevocations(&revokedStore{})
+	expired := primitive.NewObjectID()
+	current := primitive.NewObjectID()
+	revocations.Add(
+		model.RevokedSession{ID: expired, ExpiresAt: time.Now().Add(-time.Minute)},
+		model.RevokedSession{ID: current, ExpiresAt: time.Now().Add(time.Hour)},
+	)
+
+	if err := revocations.Load(context.Background()); err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+
+	if revocations.IsRevoked(expired) {
+		t.Error("expired session is still kept")
+	}
+	if !revocations.IsRevoked(current) {
+		t.Error("unexpired session was forgotten")
+	}
+}
+
+func TestRevocationsRunPicksUpRevocationsMadeElsewhere(t *testing.T) {
+	store := &revokedStore{}
+	revocations := session.NewRevocations(store)
+	revocations.PollInterval = 10 * time.Millisecond
+	if err := revocations.Load(context.Background()); err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		revocations.Run(ctx)
+		close(done)
+	}()
+	defer func() {
+		cancel()
+		<-done
+	}()
+
+	sessionID := primitive.NewObjectID()
+	store.revoke(model.RevokedSession{ID: sessionID, ExpiresAt: time.Now().Add(time.Hour)})
+
+	deadline := time.Now().Add(time.Second)
+	for !revocations.IsRevoked(sessionID) {
+		if time.Now().After(deadline) {
+			t.Fatal("revocation made elsewhere was not picked up")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	store.mu.Lock()
+	defer store.mu.Unlock()
+	if last := store.since[len(store.since)-1]; last.IsZero() {
+		t.Error("poll looked back to the beginning instead of the previous poll")
+	}
+}
@@ -0,0 +1,235 @@
+// Package signing holds the asymmetric keys access tokens are signed with.
+// Several keys can be trusted at once, told apart by the kid header, so a
+// new key can be rolled out before it signs anything and an old one kept
+// until the last token it signed has expired.
+//
+// Keys are PEM files named after their kid, made for example with
+//
+//	openssl genpkey -algorithm ed25519 -out keys/2026-10-17.pem
+package signing
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/latiiLA/coop-forex-server/internal/domain/model"
+)
+
+// minRSABits is the smallest RSA key accepted for signing.
+const minRSABits = 2048
+
+var (
+	ErrNoSigningKeys     = errors.New("no signing keys")
+	ErrUnknownSigningKey = errors.New("unknown signing key")
+)
+
+// Key is one signing key. RSA keys sign with RS256, Ed25519 keys with EdDSA.
+type Key struct {
+	ID     string
+	Method jwt.SigningMethod
+	signer crypto.Signer
+}
+
+func NewKey(id string, signer crypto.Signer) (*Key, error) {
+	switch private := signer.(type) {
+	case *rsa.PrivateKey:
+		if private.N.BitLen() < minRSABits {
+			return nil, fmt.Errorf("signing key %s: RSA keys must have at least %d bits", id, minRSABits)
+		}
+		return &Key{ID: id, Method: jwt.SigningMethodRS256, signer: private}, nil
+	case ed25519.PrivateKey:
+		return &Key{ID: id, Method: jwt.SigningMethodEdDSA, signer: private}, nil
+	default:
+		return nil, fmt.Errorf("signing key %s: unsupported key type %T, expected RSA or Ed25519", id, signer)
+	}
+}
+
+// KeySet verifies tokens signed by any of its keys and signs new ones with
+// the active key.
+type KeySet struct {
+	keys   map[string]*Key
+	active *Key
+}
+
+// NewKeySet trusts keys and signs with the one named activeID, or with the
+// one whose ID sorts last when activeID is empty, so keys named after the
+// date they were made take over in turn.
+func NewKeySet(activeID string, keys ...*Key) (*KeySet, error) {
+	if len(keys) == 0 {
+		return nil, ErrNoSigningKeys
+	}
+
+	ks := &KeySet{keys: map[string]*Key{}}
+	for _, key := range keys {
+		if _, ok := ks.keys[key.ID]; ok {
+			return nil, fmt.Errorf("signing key %s is defined twice", key.ID)
+		}
+		ks.keys[key.ID] = key
+	}
+
+	if activeID == "" {
+		ids := ks.ids()
+		activeID = ids[len(ids)-1]
+	}
+	active, ok := ks.keys[activeID]
+	if !ok {
+		return nil, fmt.Errorf("active signing key %s: %w", activeID, ErrUnknownSigningKey)
+	}
+	ks.active = active
+
+	return ks, nil
+}
+
+// LoadDir reads every PEM private key in dir. A key's ID is its file name
+// without the .pem extension.
+func LoadDir(dir string, activeID string) (*KeySet, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.pem"))
+	if err != nil {
+		return nil, err
+	}
+
+	keys := make([]*Key, 0, len(paths))
+	for _, path := range paths {
+		key, err := loadKey(path)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+	}
+
+	ks, err := NewKeySet(activeID, keys...)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", dir, err)
+	}
+
+	return ks, nil
+}
+
+func loadKey(path string) (*Key, error) {
+	id := strings.TrimSuffix(filepath.Base(path), ".pem")
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("signing key %s: no PEM block found", id)
+	}
+
+	var private any
+	switch block.Type {
+	case "PRIVATE KEY":
+		private, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	case "RSA PRIVATE KEY":
+		private, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	default:
+		return nil, fmt.Errorf("signing key %s: unsupported PEM block %q", id, block.Type)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("signing key %s: %w", id, err)
+	}
+
+	signer, ok := private.(crypto.Signer)
+	if !ok {
+		return nil, fmt.Errorf("signing key %s: unsupported key type %T", id, private)
+	}
+
+	return NewKey(id, signer)
+}
+
+// ActiveID names the key new tokens are signed with.
+func (ks *KeySet) ActiveID() string {
+	return ks.active.ID
+}
+
+// Sign signs claims with the active key, naming it in the kid header.
+func (ks *KeySet) Sign(claims jwt.Claims) (string, error) {
+	token := jwt.NewWithClaims(ks.active.Method, claims)
+	token.Header["kid"] = ks.active.ID
+
+	return token.SignedString(ks.active.signer)
+}
+
+// Parse verifies a token signed by any key of the set and returns its
+// claims. The expiry and not-before claims are checked as well.
+func (ks *KeySet) Parse(tokenString string) (jwt.MapClaims, error) {
+	claims := jwt.MapClaims{}
+	_, err := jwt.ParseWithClaims(tokenString, claims, ks.keyFunc, jwt.WithValidMethods(ks.methods()))
+	if err != nil {
+		return nil, err
+	}
+
+	return claims, nil
+}
+
+func (ks *KeySet) keyFunc(token *jwt.Token) (any, error) {
+	kid, _ := token.Header["kid"].(string)
+	key, ok := ks.keys[kid]
+	if !ok {
+		return nil, ErrUnknownSigningKey
+	}
+	// A token must not pick an algorithm its key was not made for.
+	if token.Method.Alg() != key.Method.Alg() {
+		return nil, fmt.Errorf("signing key %s does not sign with %s", kid, token.Method.Alg())
+	}
+
+	return key.signer.Public(), nil
+}
+
+func (ks *KeySet) methods() []string {
+	methods := []string{}
+	for _, key := range ks.keys {
+		if alg := key.Method.Alg(); !slices.Contains(methods, alg) {
+			methods = append(methods, alg)
+		}
+	}
+
+	return methods
+}
+
+// JWKS lists the public keys of the set, for other services to verify
+// tokens with.
+func (ks *KeySet) JWKS() model.JSONWebKeySet {
+	set := model.JSONWebKeySet{Keys: []model.JSONWebKey{}}
+	for _, id := range ks.ids() {
+		key := ks.keys[id]
+		jwk := model.JSONWebKey{Kid: key.ID, Use: "sig", Alg: key.Method.Alg()}
+
+		switch public := key.signer.Public().(type) {
+		case *rsa.PublicKey:
+			jwk.Kty = "RSA"
+			jwk.N = base64.RawURLEncoding.EncodeToString(public.N.Bytes())
+			jwk.E = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(public.E)).Bytes())
+		case ed25519.PublicKey:
+			jwk.Kty = "OKP"
+			jwk.Crv = "Ed25519"
+			jwk.X = base64.RawURLEncoding.EncodeToString(public)
+		}
+		set.Keys = append(set.Keys, jwk)
+	}
+
+	return set
+}
+
+func (ks *KeySet) ids() []string {
+	ids := make([]string, 0, len(ks.keys))
+	for id := range ks.keys {
+		ids = append(ids, id)
+	}
+	slices.Sort(ids)
+
+	return ids
+}
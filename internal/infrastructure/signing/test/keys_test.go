@@ -0,0 +1,173 @@
+package signing_test
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/latiiLA/coop-forex-server/internal/infrastructure/signing"
+)
+
+func rsaKey(t *testing.T, id string) *signing.Key {
+	t.Helper()
+	private, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("GenerateKey: %v", err)
+	}
+	key, err := signing.NewKey(id, private)
+	if err != nil {
+		t.Fatalf("NewKey: %v", err)
+	}
+	return key
+}
+
+func ed25519Key(t *testing.T, id string) *signing.Key {
+	t.Helper()
+	_, private, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("GenerateKey: %v", err)
+	}
+	key, err := signing.NewKey(id, private)
+	if err != nil {
+		t.Fatalf("NewKey: %v", err)
+	}
+	return key
+}
+
+func claims() jwt.MapClaims {
+	return jwt.MapClaims{"sub": "user", "exp": time.Now().Add(time.Minute).Unix()}
+}
+
+func TestKeySetSignsWithEitherAlgorithm(t *testing.T) {
+	for _, key := range []*signing.Key{rsaKey(t, "rsa"), ed25519Key(t, "ed")} {
+		keys, err := signing.NewKeySet("", key)
+		if err != nil {
+			t.Fatalf("NewKeySet: %v", err)
+		}
+
+		token, err := keys.Sign(claims())
+		if err != nil {
+			t.Fatalf("Sign with %s: %v", key.ID, err)
+		}
+		parsed, err := keys.Parse(token)
+		if err != nil {
+			t.Fatalf("Parse with %s: %v", key.ID, err)
+		}
+		if parsed["sub"] != "user" {
+			t.Errorf("sub = %v, want user", parsed["sub"])
+		}
+	}
+}
+
+func TestKeySetKeepsVerifyingAfterRotation(t *testing.T) {
+	old := ed25519Key(t, "2026-01-01")
+	current := rsaKey(t, "2026-06-01")
+
+	before, err := signing.NewKeySet("", old)
+	if err != nil {
+		t.Fatalf("NewKeySet: %v", err)
+	}
+	token, err := before.Sign(claims())
+	if err != nil {
+		t.Fatalf("Sign: %v", err)
+	}
+
+	after, err := signing.NewKeySet("", old, current)
+	if err != nil {
+		t.Fatalf("NewKeySet: %v", err)
+	}
+	if after.ActiveID() != "2026-06-01" {
+		t.Errorf("active key = %s, want the newest", after.ActiveID())
+	}
+	if _, err := after.Parse(token); err != nil {
+		t.Errorf("token signed before the rotation is refused: %v", err)
+	}
+}
+
+func TestKeySetRefusesUnknownAndMismatchedKeys(t *testing.T) {
+	keys, err := signing.NewKeySet("", rsaKey(t, "trusted"))
+	if err != nil {
+		t.Fatalf("NewKeySet: %v", err)
+	}
+
+	other, _ := signing.NewKeySet("", rsaKey(t, "other"))
+	foreign, _ := other.Sign(claims())
+	if _, err := keys.Parse(foreign); !errors.Is(err, signing.ErrUnknownSigningKey) {
+		t.Errorf("token of an unknown key: err = %v, want ErrUnknownSigningKey", err)
+	}
+
+	hmac := jwt.NewWithClaims(jwt.SigningMethodHS256, claims())
+	hmac.Header["kid"] = "trusted"
+	forged, _ := hmac.SignedString([]byte("secret"))
+	if _, err := keys.Parse(forged); err == nil {
+		t.Error("HMAC token naming an RSA key was accepted")
+	}
+
+	expired, _ := keys.Sign(jwt.MapClaims{"exp": time.Now().Add(-time.Minute).Unix()})
+	if _, err := keys.Parse(expired); !errors.Is(err, jwt.ErrTokenExpired) {
+		t.Errorf("expired token: err = %v, want ErrTokenExpired", err)
+	}
+}
+
+func TestNewKeySetRefusesUnknownActiveKey(t *testing.T) {
+	if _, err := signing.NewKeySet("missing", rsaKey(t, "present")); !errors.Is(err, signing.ErrUnknownSigningKey) {
+		t.Errorf("err = %v, want ErrUnknownSigningKey", err)
+	}
+	if _, err := signing.NewKeySet(""); !errors.Is(err, signing.ErrNoSigningKeys) {
+		t.Errorf("err = %v, want ErrNoSigningKeys", err)
+	}
+}
+
+func TestLoadDirReadsPEMKeys(t *testing.T) {
+	dir := t.TempDir()
+
+	_, edPrivate, _ := ed25519.GenerateKey(rand.Reader)
+	pkcs8, err := x509.MarshalPKCS8PrivateKey(edPrivate)
+	if err != nil {
+		t.Fatalf("MarshalPKCS8PrivateKey: %v", err)
+	}
+	writePEM(t, filepath.Join(dir, "2026-01-01.pem"), "PRIVATE KEY", pkcs8)
+
+	rsaPrivate, _ := rsa.GenerateKey(rand.Reader, 2048)
+	writePEM(t, filepath.Join(dir, "2026-06-01.pem"), "RSA PRIVATE KEY", x509.MarshalPKCS1PrivateKey(rsaPrivate))
+
+	if err := os.WriteFile(filepath.Join(dir, "README"), []byte("not a key"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	keys, err := signing.LoadDir(dir, "2026-01-01")
+	if err != nil {
+		t.Fatalf("LoadDir: %v", err)
+	}
+	if keys.ActiveID() != "2026-01-01" {
+		t.Errorf("active key = %s, want 2026-01-01", keys.ActiveID())
+	}
+
+	jwks := keys.JWKS()
+	if len(jwks.Keys) != 2 {
+		t.Fatalf("JWKS has %d keys, want 2", len(jwks.Keys))
+	}
+	ed, rs := jwks.Keys[0], jwks.Keys[1]
+	if ed.Kid != "2026-01-01" || ed.Kty != "OKP" || ed.Crv != "Ed25519" || ed.Alg != "EdDSA" || ed.X == "" {
+		t.Errorf("Ed25519 key = %+v", ed)
+	}
+	if rs.Kid != "2026-06-01" || rs.Kty != "RSA" || rs.Alg != "RS256" || rs.N == "" || rs.E != "AQAB" {
+		t.Errorf("RSA key = %+v", rs)
+	}
+}
+
+func writePEM(t *testing.T, path string, blockType string, der []byte) {
+	t.Helper()
+	data := pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: der})
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -88,7 +88,7 @@ func BuildCommonRequestPipelineStages(populate bool) mongo.Pipeline {
 		pipeline = append(pipeline, LookupAndUnwind("files", "ticket_attachment", "ticket")...)
 		pipeline = append(pipeline, LookupAndUnwind("files", "visa_attachment", "visa")...)
 		pipeline = append(pipeline, LookupAndUnwind("files", "education_loa_attachment", "education_loa")...)
-		pipeline = append(pipeline, LookupAndUnwind("files", "business_letter_attachment", "business_letter")...)
+		pipeline = append(pipeline, LookupAndUnwind("files", "business_license_attachment", "business_license")...)
 		pipeline = append(pipeline, LookupAndUnwind("files", "business_supporting_attachment", "business_supporting")...)
 		pipeline = append(pipeline, LookupAndUnwind("files", "health_letter_attachment", "health_letter")...)
 	}
@@ -100,6 +100,7 @@ func BuildCommonRequestPipelineStages(populate bool) mongo.Pipeline {
 		{Key: "applicant_name", Value: 1},
 		{Key: "applicant_account_number", Value: 1},
 		{Key: "requesting_as", Value: 1},
+		{Key: "customer_type_id", Value: 1},
 		{Key: "account_currency_id", Value: 1},
 		{Key: "fcy_requested_amount", Value: 1},
 		{Key: "total_fcy_requested", Value: 1},
@@ -115,8 +116,13 @@ func BuildCommonRequestPipelineStages(populate bool) mongo.Pipeline {
 		{Key: "travel_purpose_id", Value: 1},
 		{Key: "fcy_requested_id", Value: 1},
 		{Key: "approved_currency_ids", Value: 1},
+		{Key: "accepted_currency_ids", Value: 1},
+		{Key: "card_associated_account", Value: 1},
 		{Key: "branch_recommendation", Value: 1},
 		{Key: "rejection_reason", Value: 1},
+		{Key: "remark", Value: 1},
+		{Key: "processed_amount", Value: 1},
+		{Key: "due_date", Value: 1},
 
 		{Key: "validated_average_deposit", Value: 1},
 		{Key: "validated_current_balance", Value: 1},
@@ -125,10 +131,14 @@ func BuildCommonRequestPipelineStages(populate bool) mongo.Pipeline {
 		{Key: "approved_amounts", Value: 1},
 		{Key: "approved_amount_in_cash", Value: 1},
 		{Key: "approved_amount_in_card", Value: 1},
+		{Key: "approved_rates", Value: 1},
 
 		{Key: "accepted_amounts", Value: 1},
 		{Key: "accepted_amount_in_cash", Value: 1},
 		{Key: "accepted_amount_in_card", Value: 1},
+		{Key: "accepted_rates", Value: 1},
+		{Key: "limit_overrides", Value: 1},
+		{Key: "duplicate_check", Value: 1},
 
 		{Key: "created_by", Value: 1},
 		{Key: "requested_by", Value: 1},
@@ -141,6 +151,12 @@ func BuildCommonRequestPipelineStages(populate bool) mongo.Pipeline {
 
 		{Key: "created_at", Value: 1},
 		{Key: "updated_at", Value: 1},
+
+		// Concurrency control
+		{Key: "version", Value: 1},
+		{Key: "locked_by", Value: 1},
+		{Key: "locked_at", Value: 1},
+		{Key: "lock_expires_at", Value: 1},
 	}
 
 	if populate {
@@ -193,7 +209,7 @@ func BuildCommonRequestPipelineStages(populate bool) mongo.Pipeline {
 		project = append(project, bson.E{Key: "ticket", Value: 1})
 		project = append(project, bson.E{Key: "visa", Value: 1})
 		project = append(project, bson.E{Key: "education_loa", Value: 1})
-		project = append(project, bson.E{Key: "business_letter", Value: 1})
+		project = append(project, bson.E{Key: "business_license", Value: 1})
 		project = append(project, bson.E{Key: "business_supporting", Value: 1})
 		project = append(project, bson.E{Key: "health_letter", Value: 1})
 
@@ -201,6 +217,7 @@ func BuildCommonRequestPipelineStages(populate bool) mongo.Pipeline {
 		project = append(project, bson.E{Key: "ticket_attachment", Value: 1})
 		project = append(project, bson.E{Key: "visa_attachment", Value: 1})
 		project = append(project, bson.E{Key: "education_loa_attachment", Value: 1})
+		project = append(project, bson.E{Key: "business_license_attachment", Value: 1})
 		project = append(project, bson.E{Key: "business_supporting_attachment", Value: 1})
 		project = append(project, bson.E{Key: "health_letter_attachment", Value: 1})
 	}
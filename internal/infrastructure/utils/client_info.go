@@ -0,0 +1,34 @@
+package utils
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+
+	"github.com/gin-gonic/gin"
+	"github.com/latiiLA/coop-forex-server/internal/domain/model"
+)
+
+// DeviceIDHeader carries an ID a client generates once per installation, so
+// its fingerprint survives browser or app updates.
+const DeviceIDHeader = "X-Device-Id"
+
+// GetClientInfo describes the client that sent the request.
+func GetClientInfo(c *gin.Context) model.ClientInfo {
+	userAgent := c.Request.UserAgent()
+	return model.ClientInfo{
+		IP:          c.ClientIP(),
+		UserAgent:   userAgent,
+		Fingerprint: DeviceFingerprint(userAgent, c.GetHeader(DeviceIDHeader)),
+	}
+}
+
+// DeviceFingerprint hashes what identifies a device, so tokens do not carry
+// the headers themselves. It is no secret: both inputs come from the client
+// and can be replayed with a stolen token.
+func DeviceFingerprint(userAgent string, deviceID string) string {
+	if userAgent == "" && deviceID == "" {
+		return ""
+	}
+	sum := sha256.Sum256([]byte(userAgent + "\n" + deviceID))
+	return base64.RawURLEncoding.EncodeToString(sum[:16])
+}
@@ -1,14 +1,19 @@
 package utils
 
 import (
+	"context"
 	"errors"
 
-	"strings"
-
 	"github.com/gin-gonic/gin"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
+const (
+	TraceIDKey   = "TraceID"
+	ClientIPKey  = "clientIP"
+	SessionIDKey = "sessionID"
+)
+
 func GetUserID(c *gin.Context) (primitive.ObjectID, error) {
 	val, exists := c.Get("userID")
 	if !exists {
@@ -28,6 +33,17 @@ func GetUserID(c *gin.Context) (primitive.ObjectID, error) {
 	return userID, nil
 }
 
+// GetSessionID returns the session of the access token the request was
+// authenticated with.
+func GetSessionID(c *gin.Context) (primitive.ObjectID, error) {
+	sessionID, ok := c.Value(SessionIDKey).(primitive.ObjectID)
+	if !ok {
+		return primitive.NilObjectID, errors.New("session ID not found in context")
+	}
+
+	return sessionID, nil
+}
+
 func GetDepartmentID(c *gin.Context) (primitive.ObjectID, error) {
 	val, exists := c.Get("departmentID")
 	if !exists {
@@ -66,25 +82,26 @@ func GetBranchID(c *gin.Context) (primitive.ObjectID, error) {
 	return branchID, nil
 }
 
-func GetClaimIpAddress(c *gin.Context) (any, error) {
-	val, exists := c.Get("ip")
-	if !exists {
-		return "", errors.New("ip address not found in context")
-	}
-
-	return val, nil
-}
-
+// GetIPAddress returns the client IP. Forwarding headers are only honored
+// when the request came through one of the router's trusted proxies.
 func GetIPAddress(c *gin.Context) (string, error) {
-	ip := c.Request.Header.Get("X-Forwarded-For")
-	if ip != "" {
-		// X-Forwarded-For can contain multiple IPs: "client, proxy1, proxy2"
-		ips := strings.Split(ip, ",")
-		return strings.TrimSpace(ips[0]), nil
-	}
-	ip = c.ClientIP() // fallback
+	ip := c.ClientIP()
 	if ip == "" {
 		return "", errors.New("IP not found")
 	}
 	return ip, nil
 }
+
+// GetTraceID returns the trace ID stored by the request logger, or an empty
+// string when ctx does not originate from an HTTP request.
+func GetTraceID(ctx context.Context) string {
+	traceID, _ := ctx.Value(TraceIDKey).(string)
+	return traceID
+}
+
+// GetContextClientIP returns the client IP stored by the request logger, or an
+// empty string when ctx does not originate from an HTTP request.
+func GetContextClientIP(ctx context.Context) string {
+	ip, _ := ctx.Value(ClientIPKey).(string)
+	return ip
+}
@@ -0,0 +1,56 @@
+package utils
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// NormalizeName lowercases a person or company name, drops punctuation and
+// digits and sorts the remaining words, so "ABEBE, Kebede" and "kebede abebe"
+// normalize to the same string.
+func NormalizeName(name string) string {
+	words := strings.FieldsFunc(strings.ToLower(name), func(r rune) bool {
+		return !unicode.IsLetter(r)
+	})
+	sort.Strings(words)
+
+	return strings.Join(words, " ")
+}
+
+// NameSimilarity scores how alike two names are, from 0 for nothing in common
+// to 1 for names that normalize to the same string. The score is the
+// Levenshtein distance of the normalized names relative to the longer one.
+func NameSimilarity(a, b string) float64 {
+	ra, rb := []rune(NormalizeName(a)), []rune(NormalizeName(b))
+
+	longest := max(len(ra), len(rb))
+	if longest == 0 {
+		return 0
+	}
+
+	return 1 - float64(levenshtein(ra, rb))/float64(longest)
+}
+
+func levenshtein(a, b []rune) int {
+	prev := make([]int, len(b)+1)
+	curr := make([]int, len(b)+1)
+
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(a); i++ {
+		curr[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+
+	return prev[len(b)]
+}
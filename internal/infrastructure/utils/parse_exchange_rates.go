@@ -0,0 +1,153 @@
+package utils
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/latiiLA/coop-forex-server/internal/domain/model"
+)
+
+var ErrUnsupportedRateFile = errors.New("rate file must be .csv or .json")
+
+var rateRequiredColumns = []string{"currency", "buying_rate", "selling_rate"}
+
+// ParseExchangeRateUpload reads a bulk rate file, choosing the format from the
+// file extension. Rows that cannot be read are reported as row errors rather
+// than aborting the whole file, so the publisher sees every problem at once.
+func ParseExchangeRateUpload(filename string, r io.Reader) ([]model.ExchangeRateRowDTO, []model.ExchangeRateRowError, error) {
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".csv":
+		return ParseExchangeRateCSV(r)
+	case ".json":
+		rows, err := ParseExchangeRateJSON(r)
+		return rows, nil, err
+	}
+
+	return nil, nil, ErrUnsupportedRateFile
+}
+
+// ParseExchangeRateJSON reads a JSON array of rate rows.
+func ParseExchangeRateJSON(r io.Reader) ([]model.ExchangeRateRowDTO, error) {
+	var rows []model.ExchangeRateRowDTO
+	if err := json.NewDecoder(r).Decode(&rows); err != nil {
+		return nil, fmt.Errorf("invalid rate file: %w", err)
+	}
+
+	return rows, nil
+}
+
+// ParseExchangeRateCSV reads a CSV file with a header row. The currency,
+// buying_rate and selling_rate columns are required; mid_rate, valid_from and
+// valid_to are optional and may appear in any order.
+func ParseExchangeRateCSV(r io.Reader) ([]model.ExchangeRateRowDTO, []model.ExchangeRateRowError, error) {
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+	reader.FieldsPerRecord = -1
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid rate file: %w", err)
+	}
+
+	columns := map[string]int{}
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))] = i
+	}
+	for _, name := range rateRequiredColumns {
+		if _, ok := columns[name]; !ok {
+			return nil, nil, fmt.Errorf("invalid rate file: missing %s column", name)
+		}
+	}
+
+	var (
+		rows      []model.ExchangeRateRowDTO
+		rowErrors []model.ExchangeRateRowError
+	)
+
+	for line := 1; ; line++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid rate file: %w", err)
+		}
+
+		field := func(name string) string {
+			i, ok := columns[name]
+			if !ok || i >= len(record) {
+				return ""
+			}
+			return strings.TrimSpace(record[i])
+		}
+
+		row, err := parseRateRecord(field)
+		if err != nil {
+			rowErrors = append(rowErrors, model.ExchangeRateRowError{Row: line, Message: err.Error()})
+			continue
+		}
+
+		rows = append(rows, row)
+	}
+
+	return rows, rowErrors, nil
+}
+
+func parseRateRecord(field func(string) string) (model.ExchangeRateRowDTO, error) {
+	row := model.ExchangeRateRowDTO{Currency: field("currency")}
+
+	var err error
+	if row.BuyingRate, err = parseRate(field("buying_rate")); err != nil {
+		return row, fmt.Errorf("buying_rate: %w", err)
+	}
+	if row.SellingRate, err = parseRate(field("selling_rate")); err != nil {
+		return row, fmt.Errorf("selling_rate: %w", err)
+	}
+	if value := field("mid_rate"); value != "" {
+		if row.MidRate, err = parseRate(value); err != nil {
+			return row, fmt.Errorf("mid_rate: %w", err)
+		}
+	}
+	if row.ValidFrom, err = parseRateTime(field("valid_from")); err != nil {
+		return row, fmt.Errorf("valid_from: %w", err)
+	}
+	if row.ValidTo, err = parseRateTime(field("valid_to")); err != nil {
+		return row, fmt.Errorf("valid_to: %w", err)
+	}
+
+	return row, nil
+}
+
+func parseRate(value string) (float64, error) {
+	if value == "" {
+		return 0, errors.New("value is required")
+	}
+
+	rate, err := strconv.ParseFloat(strings.ReplaceAll(value, ",", ""), 64)
+	if err != nil {
+		return 0, fmt.Errorf("%q is not a number", value)
+	}
+
+	return rate, nil
+}
+
+func parseRateTime(value string) (*time.Time, error) {
+	if value == "" {
+		return nil, nil
+	}
+
+	for _, layout := range []string{time.RFC3339, "2006-01-02 15:04", "2006-01-02"} {
+		if t, err := time.Parse(layout, value); err == nil {
+			return &t, nil
+		}
+	}
+
+	return nil, fmt.Errorf("%q is not a date", value)
+}
@@ -2,11 +2,21 @@ package utils
 
 import (
 	"fmt"
-
-	"github.com/google/uuid"
 )
 
-func GenerateRequestCode() string {
-	id := uuid.New().String()[:8]
-	return fmt.Sprintf("REQ-%s", id)
+// HeadOfficeRequestCode stands in for the branch code of requests raised by
+// head office departments, which have no branch code of their own.
+const HeadOfficeRequestCode = "HO"
+
+// FormatRequestCode builds the human readable code of a request from the code
+// of the branch that raised it, the year and the request's number within that
+// branch and year, e.g. REQ-ET0010001-2026-000451.
+func FormatRequestCode(orgCode string, year int, seq int64) string {
+	return fmt.Sprintf("REQ-%s-%d-%06d", orgCode, year, seq)
+}
+
+// RequestCodeCounter names the counter that numbers the requests of one
+// branch in one year.
+func RequestCodeCounter(orgCode string, year int) string {
+	return fmt.Sprintf("request_code:%s:%d", orgCode, year)
 }
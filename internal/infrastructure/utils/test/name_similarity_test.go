@@ -0,0 +1,48 @@
+package utils_test
+
+import (
+	"testing"
+
+	"github.com/latiiLA/coop-forex-server/internal/infrastructure/utils"
+)
+
+func TestNormalizeName(t *testing.T) {
+	cases := map[string]string{
+		"Abebe Kebede":      "abebe kebede",
+		"KEBEDE, Abebe":     "abebe kebede",
+		"  abebe   kebede ": "abebe kebede",
+		"Abebe Kebede 2":    "abebe kebede",
+		"":                  "",
+	}
+
+	for input, expected := range cases {
+		t.Run(input, func(t *testing.T) {
+			actual := utils.NormalizeName(input)
+			if actual != expected {
+				t.Errorf("NormalizeName(%q) = %q; expected %q", input, actual, expected)
+			}
+		})
+	}
+}
+
+func TestNameSimilarity(t *testing.T) {
+	cases := []struct {
+		a, b     string
+		min, max float64
+	}{
+		{"Abebe Kebede", "abebe kebede", 1, 1},
+		{"Abebe Kebede", "Kebede Abebe", 1, 1},
+		{"Abebe Kebede", "Abebe Kebde", 0.9, 0.99},
+		{"Abebe Kebede", "Almaz Tesfaye", 0, 0.5},
+		{"", "", 0, 0},
+	}
+
+	for _, c := range cases {
+		t.Run(c.a+"/"+c.b, func(t *testing.T) {
+			actual := utils.NameSimilarity(c.a, c.b)
+			if actual < c.min || actual > c.max {
+				t.Errorf("NameSimilarity(%q, %q) = %.3f; expected between %.2f and %.2f", c.a, c.b, actual, c.min, c.max)
+			}
+		})
+	}
+}
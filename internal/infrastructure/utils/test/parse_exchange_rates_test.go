@@ -0,0 +1,55 @@
+package utils_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/latiiLA/coop-forex-server/internal/infrastructure/utils"
+)
+
+func TestParseExchangeRateCSV(t *testing.T) {
+	file := "Currency,Selling_Rate,Buying_Rate,Valid_From\n" +
+		"USD,\"1,570.50\",150.25,2026-10-17\n" +
+		"EUR,abc,160,\n" +
+		"GBP,190.1,185.9,yesterday\n"
+
+	rows, rowErrors, err := utils.ParseExchangeRateUpload("rates.CSV", strings.NewReader(file))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(rows) != 1 {
+		t.Fatalf("expected 1 row, got %d: %+v", len(rows), rows)
+	}
+	usd := rows[0]
+	if usd.Currency != "USD" || usd.SellingRate != 1570.50 || usd.BuyingRate != 150.25 {
+		t.Errorf("USD row = %+v", usd)
+	}
+	if usd.ValidFrom == nil || usd.ValidFrom.Format("2006-01-02") != "2026-10-17" {
+		t.Errorf("USD valid_from = %v", usd.ValidFrom)
+	}
+	if usd.ValidTo != nil {
+		t.Errorf("USD valid_to = %v; expected none", usd.ValidTo)
+	}
+
+	if len(rowErrors) != 2 || rowErrors[0].Row != 2 || rowErrors[1].Row != 3 {
+		t.Errorf("row errors = %+v", rowErrors)
+	}
+}
+
+func TestParseExchangeRateUploadRejects(t *testing.T) {
+	cases := map[string]string{
+		"rates.xlsx": "currency,buying_rate,selling_rate\n",
+		"rates.csv":  "currency,buying_rate\nUSD,150\n",
+		"rates.json": "{\"currency\":\"USD\"}",
+	}
+
+	for name, content := range cases {
+		t.Run(name, func(t *testing.T) {
+			_, _, err := utils.ParseExchangeRateUpload(name, strings.NewReader(content))
+			if err == nil {
+				t.Errorf("ParseExchangeRateUpload(%q) succeeded; expected an error", name)
+			}
+		})
+	}
+}
@@ -0,0 +1,35 @@
+package utils_test
+
+import (
+	"testing"
+
+	"github.com/latiiLA/coop-forex-server/internal/infrastructure/utils"
+)
+
+func TestFormatRequestCode(t *testing.T) {
+	cases := []struct {
+		orgCode  string
+		year     int
+		seq      int64
+		expected string
+	}{
+		{"ET0010001", 2026, 451, "REQ-ET0010001-2026-000451"},
+		{utils.HeadOfficeRequestCode, 2026, 1, "REQ-HO-2026-000001"},
+		{"ET0010001", 2027, 1234567, "REQ-ET0010001-2027-1234567"},
+	}
+
+	for _, c := range cases {
+		t.Run(c.expected, func(t *testing.T) {
+			actual := utils.FormatRequestCode(c.orgCode, c.year, c.seq)
+			if actual != c.expected {
+				t.Errorf("FormatRequestCode(%q, %d, %d) = %q; expected %q", c.orgCode, c.year, c.seq, actual, c.expected)
+			}
+		})
+	}
+}
+
+func TestRequestCodeCounter(t *testing.T) {
+	if actual := utils.RequestCodeCounter("ET0010001", 2026); actual != "request_code:ET0010001:2026" {
+		t.Errorf("RequestCodeCounter() = %q", actual)
+	}
+}
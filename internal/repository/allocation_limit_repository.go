@@ -0,0 +1,131 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/latiiLA/coop-forex-server/internal/domain/model"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type allocationLimitRepository struct {
+	collection *mongo.Collection
+}
+
+func NewAllocationLimitRepository(db *mongo.Database) model.AllocationLimitRepository {
+	return &allocationLimitRepository{
+		collection: db.Collection("allocation_limits"),
+	}
+}
+
+func (ar *allocationLimitRepository) Create(ctx context.Context, limit *model.AllocationLimit) error {
+	_, err := ar.collection.InsertOne(ctx, limit)
+
+	return err
+}
+
+func (ar *allocationLimitRepository) FindByID(ctx context.Context, limitID primitive.ObjectID) (*model.AllocationLimit, error) {
+	var limit model.AllocationLimit
+	filter := bson.M{"_id": limitID, "is_deleted": false}
+
+	err := ar.collection.FindOne(ctx, filter).Decode(&limit)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &limit, nil
+}
+
+func (ar *allocationLimitRepository) FindAll(ctx context.Context) ([]model.AllocationLimit, error) {
+	return ar.find(ctx, bson.M{"is_deleted": false})
+}
+
+func (ar *allocationLimitRepository) FindActive(ctx context.Context) ([]model.AllocationLimit, error) {
+	return ar.find(ctx, bson.M{"is_deleted": false, "is_active": true})
+}
+
+func (ar *allocationLimitRepository) Update(ctx context.Context, limitID primitive.ObjectID, limit *model.AllocationLimit) error {
+	filter := bson.M{"_id": limitID, "is_deleted": false}
+
+	set := bson.M{
+		"name":          limit.Name,
+		"scope":         limit.Scope,
+		"requesting_as": limit.RequestingAs,
+		"max_amount":    limit.MaxAmount,
+		"is_active":     limit.IsActive,
+		"updated_at":    limit.UpdatedAt,
+		"updated_by":    limit.UpdatedBy,
+	}
+	unset := bson.M{}
+
+	optional := map[string]*primitive.ObjectID{
+		"travel_purpose_id": limit.TravelPurposeID,
+		"customer_type_id":  limit.CustomerTypeID,
+		"country_id":        limit.CountryID,
+		"currency_id":       limit.CurrencyID,
+	}
+	for field, value := range optional {
+		if value != nil {
+			set[field] = value
+		} else {
+			unset[field] = ""
+		}
+	}
+
+	update := bson.M{"$set": set}
+	if len(unset) > 0 {
+		update["$unset"] = unset
+	}
+
+	_, err := ar.collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return fmt.Errorf("failed to update allocation limit: %w", err)
+	}
+
+	return nil
+}
+
+func (ar *allocationLimitRepository) Delete(ctx context.Context, limitID primitive.ObjectID, limit *model.AllocationLimit) error {
+	filter := bson.M{"_id": limitID}
+	update := bson.M{
+		"$set": bson.M{
+			"is_deleted": limit.IsDeleted,
+			"deleted_at": limit.DeletedAt,
+			"deleted_by": limit.DeletedBy,
+		},
+	}
+
+	_, err := ar.collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return fmt.Errorf("failed to soft-delete allocation limit: %w", err)
+	}
+
+	return nil
+}
+
+func (ar *allocationLimitRepository) find(ctx context.Context, filter bson.M) ([]model.AllocationLimit, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "scope", Value: 1}, {Key: "name", Value: 1}})
+
+	cursor, err := ar.collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var limits []model.AllocationLimit
+	if err := cursor.All(ctx, &limits); err != nil {
+		return nil, err
+	}
+
+	if len(limits) == 0 {
+		return []model.AllocationLimit{}, nil
+	}
+
+	return limits, nil
+}
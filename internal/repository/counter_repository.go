@@ -0,0 +1,39 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/latiiLA/coop-forex-server/internal/domain/model"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type counterRepository struct {
+	collection *mongo.Collection
+}
+
+func NewCounterRepository(db *mongo.Database) model.CounterRepository {
+	return &counterRepository{
+		collection: db.Collection("counters"),
+	}
+}
+
+func (cr *counterRepository) Next(ctx context.Context, name string) (int64, error) {
+	filter := bson.M{"_id": name}
+	update := bson.M{"$inc": bson.M{"seq": 1}}
+	opts := options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After)
+
+	var counter model.Counter
+	err := cr.collection.FindOneAndUpdate(ctx, filter, update, opts).Decode(&counter)
+	if mongo.IsDuplicateKeyError(err) {
+		// Two first uses raced to insert the counter; the loser retries
+		// against the document the winner created.
+		err = cr.collection.FindOneAndUpdate(ctx, filter, update, opts).Decode(&counter)
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	return counter.Seq, nil
+}
@@ -25,7 +25,33 @@ func (cr *currencyRepository) Create(ctx context.Context, currency *model.Curren
 }
 
 func (cr *currencyRepository) FindByID(ctx context.Context, currency_id primitive.ObjectID) (*model.Currency, error) {
-	return nil, errors.New("not implemented yet")
+	var currency model.Currency
+	filter := bson.M{"_id": currency_id, "is_deleted": false}
+
+	err := cr.collection.FindOne(ctx, filter).Decode(&currency)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &currency, nil
+}
+
+func (cr *currencyRepository) FindByShortCode(ctx context.Context, shortCode string) (*model.Currency, error) {
+	var currency model.Currency
+	filter := bson.M{"short_code": shortCode, "is_deleted": false}
+
+	err := cr.collection.FindOne(ctx, filter).Decode(&currency)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &currency, nil
 }
 
 func (cr *currencyRepository) FindAll(ctx context.Context) ([]model.Currency, error) {
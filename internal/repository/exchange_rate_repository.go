@@ -0,0 +1,270 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/latiiLA/coop-forex-server/internal/domain/model"
+	"github.com/latiiLA/coop-forex-server/internal/infrastructure/utils"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type exchangeRateRepository struct {
+	collection *mongo.Collection
+}
+
+func NewExchangeRateRepository(db *mongo.Database) model.ExchangeRateRepository {
+	return &exchangeRateRepository{
+		collection: db.Collection("exchange_rates"),
+	}
+}
+
+func (er *exchangeRateRepository) Create(ctx context.Context, rate *model.ExchangeRate) error {
+	_, err := er.collection.InsertOne(ctx, rate)
+
+	return err
+}
+
+func (er *exchangeRateRepository) CreateMany(ctx context.Context, rates []model.ExchangeRate) error {
+	docs := make([]interface{}, len(rates))
+	for i := range rates {
+		docs[i] = rates[i]
+	}
+
+	_, err := er.collection.InsertMany(ctx, docs)
+
+	return err
+}
+
+func (er *exchangeRateRepository) FindByID(ctx context.Context, rateID primitive.ObjectID) (*model.ExchangeRate, error) {
+	rates, err := er.aggregate(ctx, bson.D{
+		{Key: "_id", Value: rateID},
+		{Key: "is_deleted", Value: false},
+	}, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(rates) == 0 {
+		return nil, nil
+	}
+
+	return &rates[0], nil
+}
+
+// FindEffective returns the rate in force for the currency at the given time.
+// When windows overlap the most recently started one wins.
+func (er *exchangeRateRepository) FindEffective(ctx context.Context, currencyID primitive.ObjectID, at time.Time) (*model.ExchangeRate, error) {
+	filter := effectiveRateFilter(at)
+	filter = append(filter, bson.E{Key: "currency_id", Value: currencyID})
+
+	opts := options.FindOne().SetSort(bson.D{
+		{Key: "valid_from", Value: -1},
+		{Key: "_id", Value: -1},
+	})
+
+	var rate model.ExchangeRate
+	err := er.collection.FindOne(ctx, filter, opts).Decode(&rate)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &rate, nil
+}
+
+// FindCurrent returns the rate in force at the given time for every currency
+// that has one.
+func (er *exchangeRateRepository) FindCurrent(ctx context.Context, at time.Time) ([]model.ExchangeRate, error) {
+	pipeline := mongo.Pipeline{
+		bson.D{{Key: "$match", Value: effectiveRateFilter(at)}},
+		bson.D{{Key: "$sort", Value: bson.D{
+			{Key: "valid_from", Value: -1},
+			{Key: "_id", Value: -1},
+		}}},
+		bson.D{{Key: "$group", Value: bson.D{
+			{Key: "_id", Value: "$currency_id"},
+			{Key: "rate", Value: bson.D{{Key: "$first", Value: "$$ROOT"}}},
+		}}},
+		bson.D{{Key: "$replaceRoot", Value: bson.D{{Key: "newRoot", Value: "$rate"}}}},
+	}
+	pipeline = append(pipeline, exchangeRateLookupStages()...)
+	pipeline = append(pipeline, bson.D{{Key: "$sort", Value: bson.D{{Key: "currency.short_code", Value: 1}}}})
+
+	return er.run(ctx, pipeline)
+}
+
+// FindHistory returns every rate published for the currency whose validity
+// overlaps the given period, newest first.
+func (er *exchangeRateRepository) FindHistory(ctx context.Context, currencyID primitive.ObjectID, from *time.Time, to *time.Time) ([]model.ExchangeRate, error) {
+	match := bson.D{
+		{Key: "currency_id", Value: currencyID},
+		{Key: "is_deleted", Value: false},
+	}
+
+	if to != nil {
+		match = append(match, bson.E{Key: "valid_from", Value: bson.D{{Key: "$lte", Value: *to}}})
+	}
+	if from != nil {
+		match = append(match, bson.E{Key: "$or", Value: bson.A{
+			bson.D{{Key: "valid_to", Value: nil}},
+			bson.D{{Key: "valid_to", Value: bson.D{{Key: "$gt", Value: *from}}}},
+		}})
+	}
+
+	return er.aggregate(ctx, match, bson.D{
+		{Key: "valid_from", Value: -1},
+		{Key: "_id", Value: -1},
+	})
+}
+
+// CloseOpenRates ends every open-ended rate of the currency that started
+// before the given time, so a newly published rate takes over from it.
+func (er *exchangeRateRepository) CloseOpenRates(ctx context.Context, currencyID primitive.ObjectID, at time.Time) error {
+	filter := bson.M{
+		"currency_id": currencyID,
+		"is_deleted":  false,
+		"valid_to":    nil,
+		"valid_from":  bson.M{"$lt": at},
+	}
+
+	_, err := er.collection.UpdateMany(ctx, filter, bson.M{"$set": bson.M{"valid_to": at}})
+	if err != nil {
+		return fmt.Errorf("failed to close open exchange rates: %w", err)
+	}
+
+	return nil
+}
+
+// MovePredecessorEnd re-points the rates of the currency that end where
+// the given rate starts, from, so they end at to instead; a nil to reopens
+// them. It keeps the preceding rate in force when a scheduled rate is moved
+// or withdrawn.
+func (er *exchangeRateRepository) MovePredecessorEnd(ctx context.Context, currencyID primitive.ObjectID, rateID primitive.ObjectID, from time.Time, to *time.Time) error {
+	startedBefore := bson.M{"$lt": from}
+	update := bson.M{"$unset": bson.M{"valid_to": ""}}
+	if to != nil {
+		startedBefore["$lt"] = minTime(from, *to)
+		update = bson.M{"$set": bson.M{"valid_to": *to}}
+	}
+
+	filter := bson.M{
+		"_id":         bson.M{"$ne": rateID},
+		"currency_id": currencyID,
+		"is_deleted":  false,
+		"valid_to":    from,
+		"valid_from":  startedBefore,
+	}
+
+	_, err := er.collection.UpdateMany(ctx, filter, update)
+	if err != nil {
+		return fmt.Errorf("failed to move preceding exchange rate end: %w", err)
+	}
+
+	return nil
+}
+
+func (er *exchangeRateRepository) Update(ctx context.Context, rateID primitive.ObjectID, rate *model.ExchangeRate) error {
+	filter := bson.M{"_id": rateID, "is_deleted": false}
+
+	set := bson.M{
+		"buying_rate":  rate.BuyingRate,
+		"selling_rate": rate.SellingRate,
+		"mid_rate":     rate.MidRate,
+		"valid_from":   rate.ValidFrom,
+		"updated_at":   rate.UpdatedAt,
+		"updated_by":   rate.UpdatedBy,
+	}
+	update := bson.M{"$set": set}
+	if rate.ValidTo != nil {
+		set["valid_to"] = rate.ValidTo
+	} else {
+		update["$unset"] = bson.M{"valid_to": ""}
+	}
+
+	_, err := er.collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return fmt.Errorf("failed to update exchange rate: %w", err)
+	}
+
+	return nil
+}
+
+func (er *exchangeRateRepository) Delete(ctx context.Context, rateID primitive.ObjectID, rate *model.ExchangeRate) error {
+	filter := bson.M{"_id": rateID}
+	update := bson.M{
+		"$set": bson.M{
+			"is_deleted": rate.IsDeleted,
+			"deleted_at": rate.DeletedAt,
+			"deleted_by": rate.DeletedBy,
+		},
+	}
+
+	_, err := er.collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return fmt.Errorf("failed to soft-delete exchange rate: %w", err)
+	}
+
+	return nil
+}
+
+func (er *exchangeRateRepository) aggregate(ctx context.Context, match bson.D, sort bson.D) ([]model.ExchangeRate, error) {
+	pipeline := mongo.Pipeline{bson.D{{Key: "$match", Value: match}}}
+	if sort != nil {
+		pipeline = append(pipeline, bson.D{{Key: "$sort", Value: sort}})
+	}
+	pipeline = append(pipeline, exchangeRateLookupStages()...)
+
+	return er.run(ctx, pipeline)
+}
+
+func (er *exchangeRateRepository) run(ctx context.Context, pipeline mongo.Pipeline) ([]model.ExchangeRate, error) {
+	cursor, err := er.collection.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var rates []model.ExchangeRate
+	if err := cursor.All(ctx, &rates); err != nil {
+		return nil, err
+	}
+
+	if len(rates) == 0 {
+		return []model.ExchangeRate{}, nil
+	}
+
+	return rates, nil
+}
+
+func effectiveRateFilter(at time.Time) bson.D {
+	return bson.D{
+		{Key: "is_deleted", Value: false},
+		{Key: "valid_from", Value: bson.D{{Key: "$lte", Value: at}}},
+		{Key: "$or", Value: bson.A{
+			bson.D{{Key: "valid_to", Value: nil}},
+			bson.D{{Key: "valid_to", Value: bson.D{{Key: "$gt", Value: at}}}},
+		}},
+	}
+}
+
+func exchangeRateLookupStages() mongo.Pipeline {
+	pipeline := mongo.Pipeline{}
+	pipeline = append(pipeline, utils.LookupAndUnwind("currencies", "currency_id", "currency")...)
+	pipeline = append(pipeline, utils.LookupUserWithProfile("published_by", "publisher")...)
+
+	return pipeline
+}
+
+func minTime(a, b time.Time) time.Time {
+	if b.Before(a) {
+		return b
+	}
+	return a
+}
@@ -3,11 +3,13 @@ package repository
 import (
 	"context"
 	"fmt"
+	"time"
 
 	"github.com/latiiLA/coop-forex-server/internal/domain/model"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
 type fileRepository struct {
@@ -43,3 +45,85 @@ func (fr *fileRepository) FindByID(ctx context.Context, file_id primitive.Object
 
 	return &file, nil
 }
+
+// fileReferenceEventFields are the request_events paths that keep the files
+// a request referenced before an attachment was replaced.
+var fileReferenceEventFields = []string{"changes.before", "changes.after"}
+
+// FindUnreferenced drops the files referenced by each request attachment
+// field, then by request history, one indexed equality lookup at a time, so
+// every lookup only checks the files still left.
+func (fr *fileRepository) FindUnreferenced(ctx context.Context, createdBefore time.Time, limit int64) ([]model.File, error) {
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{"created_at": bson.M{"$lt": createdBefore}}}},
+		{{Key: "$sort", Value: bson.M{"created_at": 1}}},
+	}
+	for _, field := range model.RequestAttachmentFields {
+		pipeline = append(pipeline, unreferencedBy("requests", field)...)
+	}
+	for _, field := range fileReferenceEventFields {
+		pipeline = append(pipeline, unreferencedBy("request_events", field)...)
+	}
+	pipeline = append(pipeline,
+		bson.D{{Key: "$limit", Value: limit}},
+		bson.D{{Key: "$project", Value: bson.M{"references": 0}}},
+	)
+
+	cursor, err := fr.collection.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	files := []model.File{}
+	if err := cursor.All(ctx, &files); err != nil {
+		return nil, err
+	}
+
+	return files, nil
+}
+
+// unreferencedBy keeps the files that no document of from references in
+// field.
+func unreferencedBy(from, field string) mongo.Pipeline {
+	return mongo.Pipeline{
+		{{Key: "$lookup", Value: bson.M{
+			"from":         from,
+			"localField":   "_id",
+			"foreignField": field,
+			"pipeline": bson.A{
+				bson.M{"$limit": 1},
+				bson.M{"$project": bson.M{"_id": 1}},
+			},
+			"as": "references",
+		}}},
+		{{Key: "$match", Value: bson.M{"references": bson.M{"$size": 0}}}},
+	}
+}
+
+func (fr *fileRepository) FindNames(ctx context.Context, names []string) (map[string]bool, error) {
+	opts := options.Find().SetProjection(bson.M{"name": 1})
+
+	cursor, err := fr.collection.Find(ctx, bson.M{"name": bson.M{"$in": names}}, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	found := map[string]bool{}
+	for cursor.Next(ctx) {
+		var file model.File
+		if err := cursor.Decode(&file); err != nil {
+			return nil, err
+		}
+		found[file.Name] = true
+	}
+
+	return found, cursor.Err()
+}
+
+func (fr *fileRepository) Delete(ctx context.Context, fileID primitive.ObjectID) error {
+	_, err := fr.collection.DeleteOne(ctx, bson.M{"_id": fileID})
+
+	return err
+}
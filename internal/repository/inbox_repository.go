@@ -0,0 +1,128 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/latiiLA/coop-forex-server/internal/domain/model"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type inboxRepository struct {
+	collection *mongo.Collection
+}
+
+func NewInboxRepository(db *mongo.Database) model.InboxRepository {
+	return &inboxRepository{
+		collection: db.Collection("inbox"),
+	}
+}
+
+func (ir *inboxRepository) CreateMany(ctx context.Context, items []*model.InboxItem) error {
+	if len(items) == 0 {
+		return nil
+	}
+
+	documents := make([]interface{}, 0, len(items))
+	for _, item := range items {
+		if item.ID.IsZero() {
+			item.ID = primitive.NewObjectID()
+		}
+		documents = append(documents, item)
+	}
+
+	_, err := ir.collection.InsertMany(ctx, documents)
+
+	return err
+}
+
+func (ir *inboxRepository) FindByUser(ctx context.Context, userID primitive.ObjectID, query *model.InboxQuery) ([]model.InboxItem, error) {
+	filter := bson.M{"user_id": userID}
+	if query.UnreadOnly {
+		filter["read_at"] = bson.M{"$exists": false}
+	}
+	if query.Before != nil {
+		filter["_id"] = bson.M{"$lt": *query.Before}
+	}
+
+	opts := options.Find().
+		SetSort(bson.D{{Key: "_id", Value: -1}}).
+		SetLimit(query.Limit)
+
+	cursor, err := ir.collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	items := []model.InboxItem{}
+	if err := cursor.All(ctx, &items); err != nil {
+		return nil, err
+	}
+
+	return items, nil
+}
+
+func (ir *inboxRepository) CountUnread(ctx context.Context, userID primitive.ObjectID) (int64, error) {
+	return ir.collection.CountDocuments(ctx, bson.M{"user_id": userID, "read_at": bson.M{"$exists": false}})
+}
+
+func (ir *inboxRepository) MarkRead(ctx context.Context, userID primitive.ObjectID, itemIDs []primitive.ObjectID, at time.Time) (int64, error) {
+	if len(itemIDs) == 0 {
+		return 0, nil
+	}
+
+	filter := bson.M{
+		"_id":     bson.M{"$in": itemIDs},
+		"user_id": userID,
+		"read_at": bson.M{"$exists": false},
+	}
+
+	result, err := ir.collection.UpdateMany(ctx, filter, bson.M{"$set": bson.M{"read_at": at}})
+	if err != nil {
+		return 0, err
+	}
+
+	return result.ModifiedCount, nil
+}
+
+func (ir *inboxRepository) MarkAllRead(ctx context.Context, userID primitive.ObjectID, at time.Time) (int64, error) {
+	filter := bson.M{"user_id": userID, "read_at": bson.M{"$exists": false}}
+
+	result, err := ir.collection.UpdateMany(ctx, filter, bson.M{"$set": bson.M{"read_at": at}})
+	if err != nil {
+		return 0, err
+	}
+
+	return result.ModifiedCount, nil
+}
+
+// Watch follows the inserts into the inbox through a change stream, so items
+// written by any server instance reach the streams open on this one.
+func (ir *inboxRepository) Watch(ctx context.Context, deliver func(item *model.InboxItem)) error {
+	pipeline := mongo.Pipeline{
+		bson.D{{Key: "$match", Value: bson.D{{Key: "operationType", Value: "insert"}}}},
+	}
+
+	stream, err := ir.collection.Watch(ctx, pipeline)
+	if err != nil {
+		return err
+	}
+	defer stream.Close(context.WithoutCancel(ctx))
+
+	for stream.Next(ctx) {
+		var change struct {
+			FullDocument model.InboxItem `bson:"fullDocument"`
+		}
+		if err := stream.Decode(&change); err != nil {
+			return err
+		}
+
+		deliver(&change.FullDocument)
+	}
+
+	return stream.Err()
+}
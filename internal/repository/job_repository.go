@@ -0,0 +1,192 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/latiiLA/coop-forex-server/internal/common"
+	"github.com/latiiLA/coop-forex-server/internal/domain/model"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type jobRepository struct {
+	collection *mongo.Collection
+}
+
+// NewJobRepository creates the repository of the job states the scheduler
+// instances share and lease.
+func NewJobRepository(db *mongo.Database) model.JobRepository {
+	return &jobRepository{
+		collection: db.Collection("jobs"),
+	}
+}
+
+func (jr *jobRepository) Register(ctx context.Context, name string, schedule string, next time.Time) error {
+	now := time.Now()
+
+	_, err := jr.collection.UpdateOne(ctx,
+		bson.M{"_id": name},
+		bson.M{"$setOnInsert": bson.M{
+			"schedule":    schedule,
+			"paused":      false,
+			"next_run_at": next,
+			"updated_at":  now,
+		}},
+		options.Update().SetUpsert(true),
+	)
+	if err != nil {
+		return err
+	}
+
+	_, err = jr.collection.UpdateOne(ctx,
+		bson.M{"_id": name, "schedule": bson.M{"$ne": schedule}},
+		bson.M{"$set": bson.M{
+			"schedule":    schedule,
+			"next_run_at": next,
+			"updated_at":  now,
+		}},
+	)
+
+	return err
+}
+
+func (jr *jobRepository) FindAll(ctx context.Context) ([]model.Job, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "_id", Value: 1}})
+
+	cursor, err := jr.collection.Find(ctx, bson.M{}, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	jobs := []model.Job{}
+	if err := cursor.All(ctx, &jobs); err != nil {
+		return nil, err
+	}
+
+	return jobs, nil
+}
+
+func (jr *jobRepository) FindByName(ctx context.Context, name string) (*model.Job, error) {
+	var job model.Job
+	err := jr.collection.FindOne(ctx, bson.M{"_id": name}).Decode(&job)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &job, nil
+}
+
+func (jr *jobRepository) ClaimDue(ctx context.Context, name string, holder string, now time.Time, until time.Time, next time.Time) (bool, error) {
+	filter := bson.M{
+		"_id":         name,
+		"paused":      false,
+		"next_run_at": bson.M{"$lte": now},
+		"$or":         leaseFreeFilter(now),
+	}
+	update := bson.M{"$set": bson.M{
+		"lease_holder": holder,
+		"lease_until":  until,
+		"next_run_at":  next,
+		"updated_at":   now,
+	}}
+
+	return jr.claim(ctx, filter, update)
+}
+
+func (jr *jobRepository) Claim(ctx context.Context, name string, holder string, now time.Time, until time.Time) (bool, error) {
+	filter := bson.M{
+		"_id": name,
+		"$or": leaseFreeFilter(now),
+	}
+	update := bson.M{"$set": bson.M{
+		"lease_holder": holder,
+		"lease_until":  until,
+		"updated_at":   now,
+	}}
+
+	return jr.claim(ctx, filter, update)
+}
+
+func (jr *jobRepository) claim(ctx context.Context, filter bson.M, update bson.M) (bool, error) {
+	result, err := jr.collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return false, err
+	}
+
+	return result.ModifiedCount == 1, nil
+}
+
+// leaseFreeFilter matches jobs no instance holds an unexpired lease on.
+func leaseFreeFilter(now time.Time) bson.A {
+	return bson.A{
+		bson.M{"lease_until": bson.M{"$exists": false}},
+		bson.M{"lease_until": bson.M{"$lte": now}},
+	}
+}
+
+func (jr *jobRepository) RenewLease(ctx context.Context, name string, holder string, until time.Time) (bool, error) {
+	result, err := jr.collection.UpdateOne(ctx,
+		bson.M{"_id": name, "lease_holder": holder},
+		bson.M{"$set": bson.M{"lease_until": until}},
+	)
+	if err != nil {
+		return false, err
+	}
+
+	return result.MatchedCount == 1, nil
+}
+
+func (jr *jobRepository) ReleaseLease(ctx context.Context, name string, holder string, finishedAt time.Time, status model.JobStatus) error {
+	_, err := jr.collection.UpdateOne(ctx,
+		bson.M{"_id": name, "lease_holder": holder},
+		bson.M{
+			"$set": bson.M{
+				"last_run_at": finishedAt,
+				"last_status": status,
+				"updated_at":  finishedAt,
+			},
+			"$unset": bson.M{
+				"lease_holder": "",
+				"lease_until":  "",
+			},
+		},
+	)
+
+	return err
+}
+
+func (jr *jobRepository) SetPaused(ctx context.Context, name string, paused bool, userID primitive.ObjectID, at time.Time) error {
+	update := bson.M{
+		"$set": bson.M{
+			"paused":     true,
+			"paused_by":  userID,
+			"paused_at":  at,
+			"updated_at": at,
+		},
+	}
+	if !paused {
+		update = bson.M{
+			"$set":   bson.M{"paused": false, "updated_at": at},
+			"$unset": bson.M{"paused_by": "", "paused_at": ""},
+		}
+	}
+
+	result, err := jr.collection.UpdateOne(ctx, bson.M{"_id": name}, update)
+	if err != nil {
+		return err
+	}
+
+	if result.MatchedCount == 0 {
+		return common.ErrJobNotFound
+	}
+
+	return nil
+}
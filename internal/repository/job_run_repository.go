@@ -0,0 +1,65 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/latiiLA/coop-forex-server/internal/domain/model"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type jobRunRepository struct {
+	collection *mongo.Collection
+}
+
+// NewJobRunRepository creates the repository of the job run history.
+func NewJobRunRepository(db *mongo.Database) model.JobRunRepository {
+	return &jobRunRepository{
+		collection: db.Collection("job_runs"),
+	}
+}
+
+func (jr *jobRunRepository) Create(ctx context.Context, run *model.JobRun) error {
+	if run.ID.IsZero() {
+		run.ID = primitive.NewObjectID()
+	}
+
+	_, err := jr.collection.InsertOne(ctx, run)
+
+	return err
+}
+
+func (jr *jobRunRepository) Finish(ctx context.Context, run *model.JobRun) error {
+	_, err := jr.collection.UpdateOne(ctx,
+		bson.M{"_id": run.ID},
+		bson.M{"$set": bson.M{
+			"status":      run.Status,
+			"summary":     run.Summary,
+			"error":       run.Error,
+			"finished_at": run.FinishedAt,
+		}},
+	)
+
+	return err
+}
+
+func (jr *jobRunRepository) FindByJob(ctx context.Context, name string, limit int64) ([]model.JobRun, error) {
+	opts := options.Find().
+		SetSort(bson.D{{Key: "started_at", Value: -1}, {Key: "_id", Value: -1}}).
+		SetLimit(limit)
+
+	cursor, err := jr.collection.Find(ctx, bson.M{"job": name}, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	runs := []model.JobRun{}
+	if err := cursor.All(ctx, &runs); err != nil {
+		return nil, err
+	}
+
+	return runs, nil
+}
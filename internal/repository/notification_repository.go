@@ -0,0 +1,162 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/latiiLA/coop-forex-server/internal/common"
+	"github.com/latiiLA/coop-forex-server/internal/domain/model"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type notificationRepository struct {
+	collection *mongo.Collection
+}
+
+// NewNotificationRepository creates the repository of the notification
+// outbox that the dispatcher delivers from.
+func NewNotificationRepository(db *mongo.Database) model.NotificationRepository {
+	return &notificationRepository{
+		collection: db.Collection("notifications"),
+	}
+}
+
+func (nr *notificationRepository) Create(ctx context.Context, notification *model.Notification) error {
+	if notification.ID.IsZero() {
+		notification.ID = primitive.NewObjectID()
+	}
+
+	_, err := nr.collection.InsertOne(ctx, notification)
+
+	return err
+}
+
+func (nr *notificationRepository) FindByID(ctx context.Context, notificationID primitive.ObjectID) (*model.Notification, error) {
+	var notification model.Notification
+	err := nr.collection.FindOne(ctx, bson.M{"_id": notificationID}).Decode(&notification)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &notification, nil
+}
+
+func (nr *notificationRepository) FindByRequestID(ctx context.Context, requestID primitive.ObjectID) ([]model.Notification, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "created_at", Value: 1}, {Key: "_id", Value: 1}})
+
+	cursor, err := nr.collection.Find(ctx, bson.M{"request_id": requestID}, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	notifications := []model.Notification{}
+	if err := cursor.All(ctx, &notifications); err != nil {
+		return nil, err
+	}
+
+	return notifications, nil
+}
+
+func (nr *notificationRepository) ClaimDue(ctx context.Context, now time.Time, leaseUntil time.Time) (*model.Notification, error) {
+	filter := bson.M{
+		"$or": bson.A{
+			bson.M{
+				"status":          bson.M{"$in": bson.A{model.NotificationPending, model.NotificationFailed}},
+				"next_attempt_at": bson.M{"$lte": now},
+			},
+			bson.M{
+				"status":      model.NotificationSending,
+				"lease_until": bson.M{"$lte": now},
+			},
+		},
+	}
+	update := bson.M{
+		"$set": bson.M{
+			"status":      model.NotificationSending,
+			"lease_until": leaseUntil,
+			"updated_at":  now,
+		},
+		"$inc": bson.M{"attempts": 1},
+	}
+	opts := options.FindOneAndUpdate().
+		SetSort(bson.D{{Key: "next_attempt_at", Value: 1}}).
+		SetReturnDocument(options.After)
+
+	var notification model.Notification
+	err := nr.collection.FindOneAndUpdate(ctx, filter, update, opts).Decode(&notification)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &notification, nil
+}
+
+func (nr *notificationRepository) SaveDelivery(ctx context.Context, notification *model.Notification) error {
+	set := bson.M{
+		"status":          notification.Status,
+		"next_attempt_at": notification.NextAttemptAt,
+		"last_error":      notification.LastError,
+		"updated_at":      notification.UpdatedAt,
+	}
+	if notification.SentAt != nil {
+		set["sent_at"] = notification.SentAt
+	}
+	if notification.DeadAt != nil {
+		set["dead_at"] = notification.DeadAt
+	}
+
+	// Only the dispatcher holding the claim may record its outcome.
+	filter := bson.M{
+		"_id":      notification.ID,
+		"status":   model.NotificationSending,
+		"attempts": notification.Attempts,
+	}
+	update := bson.M{
+		"$set":   set,
+		"$unset": bson.M{"lease_until": ""},
+	}
+
+	result, err := nr.collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return common.ErrNotificationClaimLost
+	}
+
+	return nil
+}
+
+func (nr *notificationRepository) Requeue(ctx context.Context, notificationID primitive.ObjectID, at time.Time) error {
+	filter := bson.M{"_id": notificationID, "status": model.NotificationDead}
+	update := bson.M{
+		"$set": bson.M{
+			"status":          model.NotificationPending,
+			"attempts":        0,
+			"next_attempt_at": at,
+			"updated_at":      at,
+		},
+		"$unset": bson.M{"dead_at": ""},
+	}
+
+	result, err := nr.collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return common.ErrNotificationNotDead
+	}
+
+	return nil
+}
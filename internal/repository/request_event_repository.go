@@ -0,0 +1,68 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/latiiLA/coop-forex-server/internal/domain/model"
+	"github.com/latiiLA/coop-forex-server/internal/infrastructure/utils"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+type requestEventRepository struct {
+	collection *mongo.Collection
+}
+
+// NewRequestEventRepository creates the repository of the append-only request
+// history; it deliberately offers no way to change or remove events.
+func NewRequestEventRepository(db *mongo.Database) model.RequestEventRepository {
+	return &requestEventRepository{
+		collection: db.Collection("request_events"),
+	}
+}
+
+func (rer *requestEventRepository) Create(ctx context.Context, event *model.RequestEvent) error {
+	if event.ID.IsZero() {
+		event.ID = primitive.NewObjectID()
+	}
+
+	_, err := rer.collection.InsertOne(ctx, event)
+
+	return err
+}
+
+func (rer *requestEventRepository) FindByRequestID(ctx context.Context, requestID primitive.ObjectID) ([]model.RequestEvent, error) {
+	pipeline := mongo.Pipeline{
+		bson.D{
+			{Key: "$match", Value: bson.D{
+				{Key: "request_id", Value: requestID},
+			}},
+		},
+		bson.D{
+			{Key: "$sort", Value: bson.D{
+				{Key: "created_at", Value: 1},
+				{Key: "_id", Value: 1},
+			}},
+		},
+	}
+
+	pipeline = append(pipeline, utils.LookupUserWithProfile("actor_id", "actor")...)
+
+	cursor, err := rer.collection.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var events []model.RequestEvent
+	if err := cursor.All(ctx, &events); err != nil {
+		return nil, err
+	}
+
+	if len(events) == 0 {
+		return []model.RequestEvent{}, nil
+	}
+
+	return events, nil
+}
@@ -2,14 +2,18 @@ package repository
 
 import (
 	"context"
+	"encoding/base64"
+	"errors"
+	"regexp"
+	"time"
 
 	"github.com/latiiLA/coop-forex-server/internal/common"
 	"github.com/latiiLA/coop-forex-server/internal/domain/model"
 	"github.com/latiiLA/coop-forex-server/internal/infrastructure/utils"
-	"github.com/sirupsen/logrus"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
 type requestRepository struct {
@@ -28,19 +32,19 @@ func (rr *requestRepository) Create(ctx context.Context, request *model.Request)
 	return err
 }
 
-func (rr *requestRepository) FindAll(ctx context.Context, populate bool) ([]model.Request, error) {
+func (rr *requestRepository) FindByID(ctx context.Context, requestID primitive.ObjectID, populate bool) (*model.Request, error) {
+	var results []model.Request
+
 	pipeline := mongo.Pipeline{
 		bson.D{
 			{Key: "$match", Value: bson.D{
+				{Key: "_id", Value: requestID},
 				{Key: "is_deleted", Value: false},
-				{Key: "request_status", Value: bson.D{
-					{Key: "$nin", Value: bson.A{"New", "Rejected", "Drafted"}},
-				}},
 			}},
 		},
 	}
 
-	pipeline = append(pipeline, utils.BuildCommonRequestPipelineStages(true)...)
+	pipeline = append(pipeline, utils.BuildCommonRequestPipelineStages(populate)...)
 
 	cursor, err := rr.collection.Aggregate(ctx, pipeline)
 	if err != nil {
@@ -48,46 +52,24 @@ func (rr *requestRepository) FindAll(ctx context.Context, populate bool) ([]mode
 	}
 	defer cursor.Close(ctx)
 
-	var requests []model.Request
-	if err := cursor.All(ctx, &requests); err != nil {
+	if err := cursor.All(ctx, &results); err != nil {
 		return nil, err
 	}
 
-	if len(requests) == 0 {
-		return []model.Request{}, nil
-	}
-
-	return requests, nil
-}
-
-func (rr *requestRepository) Validate(ctx context.Context, request_id primitive.ObjectID, request *model.Request) error {
-	// Prepare the update document using $set to only update the fields you pass
-	update := bson.M{
-		"$set": request,
-	}
-
-	// Perform the update
-	filter := bson.M{"_id": request_id}
-	result, err := rr.collection.UpdateOne(ctx, filter, update)
-	if err != nil {
-		return err
-	}
-
-	// Check if any document was actually modified
-	if result.MatchedCount == 0 {
-		return common.ErrRequestNotFound
+	if len(results) == 0 {
+		return nil, nil
 	}
 
-	return nil
+	return &results[0], nil
 }
 
-func (rr *requestRepository) FindByID(ctx context.Context, requestID primitive.ObjectID, populate bool) (*model.Request, error) {
+func (rr *requestRepository) FindByCode(ctx context.Context, requestCode string, populate bool) (*model.Request, error) {
 	var results []model.Request
 
 	pipeline := mongo.Pipeline{
 		bson.D{
 			{Key: "$match", Value: bson.D{
-				{Key: "_id", Value: requestID},
+				{Key: "request_code", Value: requestCode},
 				{Key: "is_deleted", Value: false},
 			}},
 		},
@@ -112,17 +94,45 @@ func (rr *requestRepository) FindByID(ctx context.Context, requestID primitive.O
 	return &results[0], nil
 }
 
-func (rr *requestRepository) FindAllByOrgID(ctx context.Context, orgKey string, orgID primitive.ObjectID, populate bool) ([]model.Request, error) {
-	pipeline := mongo.Pipeline{
-		bson.D{
-			{Key: "$match", Value: bson.D{
-				{Key: "is_deleted", Value: false},
-				{Key: orgKey, Value: orgID},
-			}},
-		},
+// Search returns one page of requests matching the search, with the total
+// number of matches computed in the same round trip. The lookup chain only
+// runs for the requests on the page.
+func (rr *requestRepository) Search(ctx context.Context, search *model.RequestSearch) (*model.RequestPage, error) {
+	match := requestSearchFilter(search)
+
+	direction := 1
+	if search.SortDesc {
+		direction = -1
+	}
+
+	items := mongo.Pipeline{}
+	if search.Cursor != "" {
+		after, err := requestCursorFilter(search.Cursor, search.SortField, direction)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, bson.D{{Key: "$match", Value: after}})
+	}
+
+	items = append(items, bson.D{{Key: "$sort", Value: bson.D{
+		{Key: search.SortField, Value: direction},
+		{Key: "_id", Value: direction},
+	}}})
+
+	if search.Cursor == "" && search.Page > 1 {
+		items = append(items, bson.D{{Key: "$skip", Value: int64(search.Page-1) * int64(search.PageSize)}})
 	}
 
-	pipeline = append(pipeline, utils.BuildCommonRequestPipelineStages(true)...)
+	items = append(items, bson.D{{Key: "$limit", Value: search.PageSize}})
+	items = append(items, utils.BuildCommonRequestPipelineStages(true)...)
+
+	pipeline := mongo.Pipeline{
+		bson.D{{Key: "$match", Value: match}},
+		bson.D{{Key: "$facet", Value: bson.D{
+			{Key: "items", Value: items},
+			{Key: "total", Value: bson.A{bson.D{{Key: "$count", Value: "count"}}}},
+		}}},
+	}
 
 	cursor, err := rr.collection.Aggregate(ctx, pipeline)
 	if err != nil {
@@ -130,30 +140,113 @@ func (rr *requestRepository) FindAllByOrgID(ctx context.Context, orgKey string,
 	}
 	defer cursor.Close(ctx)
 
-	var requests []model.Request
-	if err := cursor.All(ctx, &requests); err != nil {
+	var results []struct {
+		Items []model.Request `bson:"items"`
+		Total []struct {
+			Count int64 `bson:"count"`
+		} `bson:"total"`
+	}
+	if err := cursor.All(ctx, &results); err != nil {
 		return nil, err
 	}
 
-	if len(requests) == 0 {
-		return []model.Request{}, nil
+	page := &model.RequestPage{Items: []model.Request{}, PageSize: search.PageSize}
+	if search.Cursor == "" {
+		page.Page = max(search.Page, 1)
 	}
 
-	return requests, nil
+	if len(results) == 0 {
+		return page, nil
+	}
+
+	if results[0].Items != nil {
+		page.Items = results[0].Items
+	}
+	if len(results[0].Total) > 0 {
+		page.Total = results[0].Total[0].Count
+	}
+
+	if len(page.Items) == search.PageSize {
+		next, err := encodeRequestCursor(&page.Items[len(page.Items)-1], search.SortField)
+		if err != nil {
+			return nil, err
+		}
+		page.NextCursor = next
+	}
+
+	return page, nil
 }
 
-func (rr *requestRepository) FindOrgByRequestStatus(ctx context.Context, orgID primitive.ObjectID, orgKey, request_status string, populate bool) ([]model.Request, error) {
+// SumAllocations totals, per currency, what approved and accepted requests
+// matching the query were allocated. Accepted amounts replace approved ones
+// once a request is accepted.
+func (rr *requestRepository) SumAllocations(ctx context.Context, query *model.AllocationUsageQuery) ([]model.AllocationUsage, error) {
+	match := bson.D{
+		{Key: "is_deleted", Value: false},
+		{Key: "request_status", Value: bson.D{{Key: "$in", Value: bson.A{model.ReqStatusApproved, model.ReqStatusAccepted}}}},
+		{Key: "approved_at", Value: bson.D{{Key: "$gte", Value: query.Since}}},
+		{Key: "_id", Value: bson.D{{Key: "$ne", Value: query.ExcludeRequestID}}},
+	}
+
+	if query.ApplicantAccountNumber != "" {
+		match = append(match, bson.E{Key: "applicant_account_number", Value: query.ApplicantAccountNumber})
+	}
+	if query.TravelPurposeID != nil {
+		match = append(match, bson.E{Key: "travel_purpose_id", Value: *query.TravelPurposeID})
+	}
+	if query.CustomerTypeID != nil {
+		match = append(match, bson.E{Key: "customer_type_id", Value: *query.CustomerTypeID})
+	}
+	if query.RequestingAs != "" {
+		match = append(match, bson.E{Key: "requesting_as", Value: query.RequestingAs})
+	}
+	if query.CountryID != nil {
+		match = append(match, bson.E{Key: "travel_country_id", Value: *query.CountryID})
+	}
+
 	pipeline := mongo.Pipeline{
-		bson.D{
-			{Key: "$match", Value: bson.D{
-				{Key: "is_deleted", Value: false},
-				{Key: "request_status", Value: request_status},
-				{Key: orgKey, Value: orgID},
-			}},
-		},
+		bson.D{{Key: "$match", Value: match}},
+		bson.D{{Key: "$project", Value: bson.D{
+			{Key: "lines", Value: bson.D{{Key: "$cond", Value: bson.A{
+				bson.D{{Key: "$gt", Value: bson.A{
+					bson.D{{Key: "$size", Value: bson.D{{Key: "$ifNull", Value: bson.A{"$accepted_rates", bson.A{}}}}}},
+					0,
+				}}},
+				"$accepted_rates",
+				bson.D{{Key: "$ifNull", Value: bson.A{"$approved_rates", bson.A{}}}},
+			}}}},
+		}}},
+		bson.D{{Key: "$unwind", Value: "$lines"}},
+		bson.D{{Key: "$group", Value: bson.D{
+			{Key: "_id", Value: "$lines.currency_id"},
+			{Key: "amount", Value: bson.D{{Key: "$sum", Value: "$lines.amount"}}},
+			{Key: "etb_equivalent", Value: bson.D{{Key: "$sum", Value: "$lines.etb_equivalent"}}},
+		}}},
 	}
 
-	pipeline = append(pipeline, utils.BuildCommonRequestPipelineStages(true)...)
+	cursor, err := rr.collection.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var usage []model.AllocationUsage
+	if err := cursor.All(ctx, &usage); err != nil {
+		return nil, err
+	}
+
+	return usage, nil
+}
+
+// CountByStatus counts the requests that are not deleted per status.
+func (rr *requestRepository) CountByStatus(ctx context.Context) (map[model.RequestStatus]int64, error) {
+	pipeline := mongo.Pipeline{
+		bson.D{{Key: "$match", Value: bson.D{{Key: "is_deleted", Value: false}}}},
+		bson.D{{Key: "$group", Value: bson.D{
+			{Key: "_id", Value: "$request_status"},
+			{Key: "count", Value: bson.D{{Key: "$sum", Value: 1}}},
+		}}},
+	}
 
 	cursor, err := rr.collection.Aggregate(ctx, pipeline)
 	if err != nil {
@@ -161,64 +254,454 @@ func (rr *requestRepository) FindOrgByRequestStatus(ctx context.Context, orgID p
 	}
 	defer cursor.Close(ctx)
 
+	var groups []struct {
+		Status model.RequestStatus `bson:"_id"`
+		Count  int64               `bson:"count"`
+	}
+	if err := cursor.All(ctx, &groups); err != nil {
+		return nil, err
+	}
+
+	counts := make(map[model.RequestStatus]int64, len(groups))
+	for _, group := range groups {
+		counts[group.Status] = group.Count
+	}
+
+	return counts, nil
+}
+
+// duplicateCandidateLimit bounds how many requests one duplicate check compares
+// against; the newest ones are kept.
+const duplicateCandidateLimit = 1000
+
+// FindDuplicateCandidates returns the requests another request may duplicate:
+// every request that is still open, and finished ones created since the given
+// time. Only the fields needed for the comparison are loaded.
+func (rr *requestRepository) FindDuplicateCandidates(ctx context.Context, excludeID primitive.ObjectID, since time.Time) ([]model.Request, error) {
+	filter := bson.D{
+		{Key: "is_deleted", Value: false},
+		{Key: "_id", Value: bson.D{{Key: "$ne", Value: excludeID}}},
+		{Key: "$or", Value: bson.A{
+			bson.D{{Key: "request_status", Value: bson.D{{Key: "$in", Value: model.RequestOpenStatuses}}}},
+			bson.D{{Key: "created_at", Value: bson.D{{Key: "$gte", Value: since}}}},
+		}},
+	}
+
+	opts := options.Find().
+		SetSort(bson.D{{Key: "created_at", Value: -1}}).
+		SetLimit(duplicateCandidateLimit).
+		SetProjection(bson.D{
+			{Key: "_id", Value: 1},
+			{Key: "request_code", Value: 1},
+			{Key: "applicant_name", Value: 1},
+			{Key: "applicant_account_number", Value: 1},
+			{Key: "request_status", Value: 1},
+			{Key: "branch_id", Value: 1},
+			{Key: "department_id", Value: 1},
+			{Key: "created_at", Value: 1},
+		})
+
+	cursor, err := rr.collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
 	var requests []model.Request
 	if err := cursor.All(ctx, &requests); err != nil {
 		return nil, err
 	}
 
-	if len(requests) == 0 {
-		return []model.Request{}, nil
+	return requests, nil
+}
+
+// requestSearchFilter builds the $match document of a search, always limited
+// to the statuses and organization the scope grants.
+func requestSearchFilter(search *model.RequestSearch) bson.D {
+	scope := search.Scope
+	f := search.Filter
+
+	visible := bson.A{}
+	if len(scope.AllStatuses) > 0 {
+		visible = append(visible, bson.D{{Key: "request_status", Value: bson.D{{Key: "$in", Value: scope.AllStatuses}}}})
+	}
+	if len(scope.OrgStatuses) > 0 && scope.OrgKey != "" {
+		visible = append(visible, bson.D{
+			{Key: "request_status", Value: bson.D{{Key: "$in", Value: scope.OrgStatuses}}},
+			{Key: scope.OrgKey, Value: scope.OrgID},
+		})
+	}
+
+	and := bson.A{
+		bson.D{{Key: "is_deleted", Value: false}},
+		bson.D{{Key: "$or", Value: visible}},
 	}
 
-	return requests, nil
+	if len(f.Statuses) > 0 {
+		and = append(and, bson.D{{Key: "request_status", Value: bson.D{{Key: "$in", Value: f.Statuses}}}})
+	}
+
+	if f.CreatedFrom != nil || f.CreatedTo != nil {
+		created := bson.D{}
+		if f.CreatedFrom != nil {
+			created = append(created, bson.E{Key: "$gte", Value: *f.CreatedFrom})
+		}
+		if f.CreatedTo != nil {
+			created = append(created, bson.E{Key: "$lte", Value: *f.CreatedTo})
+		}
+		and = append(and, bson.D{{Key: "created_at", Value: created}})
+	}
+
+	if f.BranchID != nil {
+		and = append(and, bson.D{{Key: "branch_id", Value: *f.BranchID}})
+	}
+	if f.DepartmentID != nil {
+		and = append(and, bson.D{{Key: "department_id", Value: *f.DepartmentID}})
+	}
+
+	if f.CurrencyID != nil {
+		and = append(and, bson.D{{Key: "$or", Value: bson.A{
+			bson.D{{Key: "fcy_requested_id", Value: *f.CurrencyID}},
+			bson.D{{Key: "approved_currency_ids", Value: *f.CurrencyID}},
+			bson.D{{Key: "accepted_currency_ids", Value: *f.CurrencyID}},
+		}}})
+	}
+
+	if f.MinAmount != nil || f.MaxAmount != nil {
+		amount := bson.D{}
+		if f.MinAmount != nil {
+			amount = append(amount, bson.E{Key: "$gte", Value: *f.MinAmount})
+		}
+		if f.MaxAmount != nil {
+			amount = append(amount, bson.E{Key: "$lte", Value: *f.MaxAmount})
+		}
+		and = append(and, bson.D{{Key: "fcy_requested_amount", Value: amount}})
+	}
+
+	if f.Applicant != "" {
+		quoted := regexp.QuoteMeta(f.Applicant)
+		and = append(and, bson.D{{Key: "$or", Value: bson.A{
+			bson.D{{Key: "applicant_name", Value: primitive.Regex{Pattern: quoted, Options: "i"}}},
+			bson.D{{Key: "applicant_account_number", Value: primitive.Regex{Pattern: "^" + quoted}}},
+		}}})
+	}
+
+	if f.RequestCode != "" {
+		and = append(and, bson.D{{Key: "request_code", Value: primitive.Regex{Pattern: "^" + regexp.QuoteMeta(f.RequestCode), Options: "i"}}})
+	}
+
+	return bson.D{{Key: "$and", Value: and}}
 }
 
-func (rr *requestRepository) Update(ctx context.Context, requestID primitive.ObjectID, request *model.RequestUpdate) error {
+// encodeRequestCursor captures the sort key of the last request on a page as
+// an opaque, URL-safe token.
+func encodeRequestCursor(request *model.Request, sortField string) (string, error) {
+	var value interface{}
+	switch sortField {
+	case "created_at":
+		value = request.CreatedAt
+	case "updated_at":
+		value = request.UpdatedAt
+	case "fcy_requested_amount":
+		value = request.FcyRequestedAmount
+	case "request_code":
+		value = request.RequestCode
+	case "applicant_name":
+		value = request.ApplicantName
+	default:
+		return "", common.ErrInvalidRequestCursor
+	}
+
+	raw, err := bson.Marshal(bson.D{
+		{Key: "f", Value: sortField},
+		{Key: "v", Value: value},
+		{Key: "id", Value: request.ID},
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(raw), nil
+}
+
+// requestCursorFilter matches the requests that sort after the cursor.
+func requestCursorFilter(token string, sortField string, direction int) (bson.D, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return nil, common.ErrInvalidRequestCursor
+	}
+
+	var cursor struct {
+		Field string             `bson:"f"`
+		Value bson.RawValue      `bson:"v"`
+		ID    primitive.ObjectID `bson:"id"`
+	}
+	if err := bson.Unmarshal(raw, &cursor); err != nil || cursor.Field != sortField || cursor.ID.IsZero() {
+		return nil, common.ErrInvalidRequestCursor
+	}
+
+	op := "$gt"
+	if direction < 0 {
+		op = "$lt"
+	}
+
+	return bson.D{{Key: "$or", Value: bson.A{
+		bson.D{{Key: sortField, Value: bson.D{{Key: op, Value: cursor.Value}}}},
+		bson.D{
+			{Key: sortField, Value: cursor.Value},
+			{Key: "_id", Value: bson.D{{Key: op, Value: cursor.ID}}},
+		},
+	}}}, nil
+}
+
+// lockAvailableFilter matches requests that are unlocked, locked by userID or
+// whose lock has already expired.
+func lockAvailableFilter(userID primitive.ObjectID, now time.Time) bson.A {
+	return bson.A{
+		bson.M{"locked_by": nil},
+		bson.M{"locked_by": userID},
+		bson.M{"lock_expires_at": nil},
+		bson.M{"lock_expires_at": bson.M{"$lte": now}},
+	}
+}
+
+// explainMiss tells apart a missing request from one held by someone else
+// after a conditional write matched nothing.
+func (rr *requestRepository) explainMiss(ctx context.Context, requestID primitive.ObjectID, userID primitive.ObjectID, fallback error) error {
+	var request model.Request
+	err := rr.collection.FindOne(ctx, bson.M{"_id": requestID, "is_deleted": false}).Decode(&request)
+	if err == mongo.ErrNoDocuments {
+		return common.ErrRequestNotFound
+	}
+	if err != nil {
+		return err
+	}
+
+	if request.IsLockedByOther(userID, time.Now()) {
+		return common.ErrRequestIsLocked
+	}
+
+	return fallback
+}
+
+// UpdateIfUnchanged applies the update only when the stored request still has
+// the expected status and version and is not locked by another user, and
+// bumps the version on success.
+func (rr *requestRepository) UpdateIfUnchanged(ctx context.Context, requestID primitive.ObjectID, actorID primitive.ObjectID, expectedStatus model.RequestStatus, expectedVersion int64, request *model.RequestUpdate) error {
+	filter := bson.M{
+		"_id":            requestID,
+		"request_status": expectedStatus,
+		"version":        expectedVersion,
+		"$or":            lockAvailableFilter(actorID, time.Now()),
+	}
+
+	// Requests written before versioning have no version field
+	if expectedVersion == 0 {
+		filter["version"] = bson.M{"$in": bson.A{0, nil}}
+	}
+
 	update := bson.M{
 		"$set": request,
+		"$inc": bson.M{"version": 1},
+	}
+
+	// A request leaving the SLA stages no longer has a due date.
+	if request.DueDate == nil {
+		update["$unset"] = bson.M{"due_date": "", "sla": ""}
 	}
-	// Perform the update
-	filter := bson.M{"_id": requestID}
+
 	result, err := rr.collection.UpdateOne(ctx, filter, update)
 	if err != nil {
 		return err
 	}
 
-	// Check if any document was actually modified
 	if result.MatchedCount == 0 {
-		return common.ErrRequestNotFound
+		return rr.explainMiss(ctx, requestID, actorID, common.ErrRequestConflict)
 	}
 
 	return nil
 }
 
-func (rr *requestRepository) FindByRequestStatus(ctx context.Context, request_status string, populate bool) ([]model.Request, error) {
-	pipeline := mongo.Pipeline{
-		bson.D{
-			{Key: "$match", Value: bson.D{
-				{Key: "is_deleted", Value: false},
-				{Key: "request_status", Value: request_status},
-			}},
+// AcquireLock atomically locks the request for userID unless another user
+// holds a lock that has not expired yet.
+func (rr *requestRepository) AcquireLock(ctx context.Context, requestID primitive.ObjectID, userID primitive.ObjectID, ttl time.Duration) (*time.Time, error) {
+	now := time.Now()
+	expiry := now.Add(ttl)
+
+	filter := bson.M{
+		"_id":        requestID,
+		"is_deleted": false,
+		"$or":        lockAvailableFilter(userID, now),
+	}
+
+	update := bson.M{
+		"$set": bson.M{
+			"locked_by":       userID,
+			"locked_at":       now,
+			"lock_expires_at": expiry,
 		},
 	}
 
-	pipeline = append(pipeline, utils.BuildCommonRequestPipelineStages(true)...)
+	result, err := rr.collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return nil, err
+	}
 
-	cursor, err := rr.collection.Aggregate(ctx, pipeline)
+	if result.MatchedCount == 0 {
+		return nil, rr.explainMiss(ctx, requestID, userID, common.ErrRequestIsLocked)
+	}
+
+	return &expiry, nil
+}
+
+// RenewLock extends a lock that userID still holds.
+func (rr *requestRepository) RenewLock(ctx context.Context, requestID primitive.ObjectID, userID primitive.ObjectID, ttl time.Duration) (*time.Time, error) {
+	now := time.Now()
+	expiry := now.Add(ttl)
+
+	filter := bson.M{
+		"_id":             requestID,
+		"is_deleted":      false,
+		"locked_by":       userID,
+		"lock_expires_at": bson.M{"$gt": now},
+	}
+
+	update := bson.M{
+		"$set": bson.M{"lock_expires_at": expiry},
+	}
+
+	result, err := rr.collection.UpdateOne(ctx, filter, update)
 	if err != nil {
 		return nil, err
 	}
-	defer cursor.Close(ctx)
 
-	var requests []model.Request
-	if err := cursor.All(ctx, &requests); err != nil {
+	if result.MatchedCount == 0 {
+		return nil, rr.explainMiss(ctx, requestID, userID, common.ErrRequestLockNotHeld)
+	}
+
+	return &expiry, nil
+}
+
+// ReleaseLock removes the lock unless another user holds it and it is still valid.
+func (rr *requestRepository) ReleaseLock(ctx context.Context, requestID primitive.ObjectID, userID primitive.ObjectID) error {
+	filter := bson.M{
+		"_id":        requestID,
+		"is_deleted": false,
+		"$or":        lockAvailableFilter(userID, time.Now()),
+	}
+
+	result, err := rr.collection.UpdateOne(ctx, filter, unsetLockUpdate())
+	if err != nil {
+		return err
+	}
+
+	if result.MatchedCount == 0 {
+		return rr.explainMiss(ctx, requestID, userID, common.ErrRequestIsLocked)
+	}
+
+	return nil
+}
+
+// ForceReleaseLock removes the lock regardless of who holds it.
+func (rr *requestRepository) ForceReleaseLock(ctx context.Context, requestID primitive.ObjectID) error {
+	result, err := rr.collection.UpdateOne(ctx, bson.M{"_id": requestID, "is_deleted": false}, unsetLockUpdate())
+	if err != nil {
+		return err
+	}
+
+	if result.MatchedCount == 0 {
+		return common.ErrRequestNotFound
+	}
+
+	return nil
+}
+
+func (rr *requestRepository) ReleaseExpiredLocks(ctx context.Context, now time.Time) (int64, error) {
+	result, err := rr.collection.UpdateMany(ctx, bson.M{"lock_expires_at": bson.M{"$lte": now}}, unsetLockUpdate())
+	if err != nil {
+		return 0, err
+	}
+
+	return result.ModifiedCount, nil
+}
+
+func unsetLockUpdate() bson.M {
+	return bson.M{
+		"$unset": bson.M{
+			"locked_by":       "",
+			"locked_at":       "",
+			"lock_expires_at": "",
+		},
+	}
+}
+
+func (rr *requestRepository) ClaimSLAReminder(ctx context.Context, now time.Time) (*model.Request, error) {
+	filter := bson.M{
+		"is_deleted":      false,
+		"request_status":  bson.M{"$in": model.SLAStages},
+		"sla.remind_at":   bson.M{"$lte": now},
+		"sla.reminded_at": bson.M{"$exists": false},
+		"due_date":        bson.M{"$gt": now},
+	}
+	update := bson.M{"$set": bson.M{"sla.reminded_at": now}}
+
+	return rr.claimSLA(ctx, filter, update)
+}
+
+func (rr *requestRepository) ClaimSLAEscalation(ctx context.Context, now time.Time) (*model.Request, error) {
+	filter := bson.M{
+		"is_deleted":       false,
+		"request_status":   bson.M{"$in": model.SLAStages},
+		"due_date":         bson.M{"$lte": now},
+		"sla.escalated_at": bson.M{"$exists": false},
+		// Approvals that decline themselves are not escalated.
+		"sla.auto_decline": bson.M{"$ne": true},
+	}
+	update := bson.M{"$set": bson.M{"sla.escalated_at": now}}
+
+	return rr.claimSLA(ctx, filter, update)
+}
+
+// claimSLA stamps the first matching request, oldest due date first. The
+// stamp does not bump the version, so it never conflicts with users working
+// on the request.
+func (rr *requestRepository) claimSLA(ctx context.Context, filter bson.M, update bson.M) (*model.Request, error) {
+	opts := options.FindOneAndUpdate().
+		SetSort(bson.D{{Key: "due_date", Value: 1}}).
+		SetReturnDocument(options.After)
+
+	var request model.Request
+	err := rr.collection.FindOneAndUpdate(ctx, filter, update, opts).Decode(&request)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, nil
+	}
+	if err != nil {
 		return nil, err
 	}
 
-	logrus.Println("requests", len(requests), requests)
+	return &request, nil
+}
+
+func (rr *requestRepository) FindExpiredApprovals(ctx context.Context, now time.Time, limit int64) ([]model.Request, error) {
+	filter := bson.M{
+		"is_deleted":       false,
+		"request_status":   model.ReqStatusApproved,
+		"sla.auto_decline": true,
+		"due_date":         bson.M{"$lte": now},
+	}
+	opts := options.Find().
+		SetSort(bson.D{{Key: "due_date", Value: 1}}).
+		SetLimit(limit)
 
-	if len(requests) == 0 {
-		return []model.Request{}, nil
+	cursor, err := rr.collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	requests := []model.Request{}
+	if err := cursor.All(ctx, &requests); err != nil {
+		return nil, err
 	}
 
 	return requests, nil
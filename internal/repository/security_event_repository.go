@@ -0,0 +1,29 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/latiiLA/coop-forex-server/internal/domain/model"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+type securityEventRepository struct {
+	collection *mongo.Collection
+}
+
+func NewSecurityEventRepository(db *mongo.Database) model.SecurityEventRepository {
+	return &securityEventRepository{
+		collection: db.Collection("security_events"),
+	}
+}
+
+func (ser *securityEventRepository) Create(ctx context.Context, event *model.SecurityEvent) error {
+	if event.ID.IsZero() {
+		event.ID = primitive.NewObjectID()
+	}
+
+	_, err := ser.collection.InsertOne(ctx, event)
+
+	return err
+}
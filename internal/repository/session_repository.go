@@ -0,0 +1,157 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/latiiLA/coop-forex-server/internal/domain/model"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type sessionRepository struct {
+	collection *mongo.Collection
+}
+
+// NewSessionRepository creates the registry of user logins. Sessions are
+// removed by a TTL index once their tokens have expired.
+func NewSessionRepository(db *mongo.Database) model.SessionRepository {
+	return &sessionRepository{
+		collection: db.Collection("sessions"),
+	}
+}
+
+func (sr *sessionRepository) Create(ctx context.Context, session *model.Session) error {
+	if session.ID.IsZero() {
+		session.ID = primitive.NewObjectID()
+	}
+
+	_, err := sr.collection.InsertOne(ctx, session)
+	return err
+}
+
+func (sr *sessionRepository) FindByID(ctx context.Context, sessionID primitive.ObjectID) (*model.Session, error) {
+	var session model.Session
+	err := sr.collection.FindOne(ctx, bson.M{"_id": sessionID}).Decode(&session)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &session, nil
+}
+
+func (sr *sessionRepository) FindActiveByUserID(ctx context.Context, userID primitive.ObjectID, now time.Time) ([]model.Session, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "last_used_at", Value: -1}})
+	cursor, err := sr.collection.Find(ctx, activeSession(bson.M{"user_id": userID}, now), opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	sessions := []model.Session{}
+	if err := cursor.All(ctx, &sessions); err != nil {
+		return nil, err
+	}
+
+	return sessions, nil
+}
+
+func (sr *sessionRepository) Rotate(ctx context.Context, sessionID primitive.ObjectID, oldJTI string, newJTI string, ip string, now time.Time, expiresAt time.Time) (bool, error) {
+	result, err := sr.collection.UpdateOne(ctx,
+		activeSession(bson.M{"_id": sessionID, "refresh_jti": oldJTI}, now),
+		bson.M{
+			"$set": bson.M{
+				"refresh_jti":          newJTI,
+				"previous_refresh_jti": oldJTI,
+				"rotated_at":           now,
+				"last_ip":              ip,
+				"last_used_at":         now,
+				"expires_at":           expiresAt,
+			},
+			"$inc": bson.M{"rotations": 1},
+		},
+	)
+	if err != nil {
+		return false, err
+	}
+
+	return result.ModifiedCount == 1, nil
+}
+
+func (sr *sessionRepository) Revoke(ctx context.Context, sessionID primitive.ObjectID, userID primitive.ObjectID, reason model.SessionRevokeReason, revokedBy primitive.ObjectID, at time.Time) (*model.RevokedSession, error) {
+	opts := options.FindOneAndUpdate().
+		SetProjection(bson.M{"expires_at": 1}).
+		SetReturnDocument(options.After)
+
+	var revoked model.RevokedSession
+	err := sr.collection.FindOneAndUpdate(ctx,
+		activeSession(bson.M{"_id": sessionID, "user_id": userID}, at),
+		revokeUpdate(reason, revokedBy, at),
+		opts,
+	).Decode(&revoked)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &revoked, nil
+}
+
+func (sr *sessionRepository) RevokeAll(ctx context.Context, userID primitive.ObjectID, reason model.SessionRevokeReason, revokedBy primitive.ObjectID, at time.Time) ([]model.RevokedSession, error) {
+	filter := activeSession(bson.M{"user_id": userID}, at)
+	if _, err := sr.collection.UpdateMany(ctx, filter, revokeUpdate(reason, revokedBy, at)); err != nil {
+		return nil, err
+	}
+
+	// The sessions revoked here are the ones stamped with at; one revoked at
+	// the same moment by another call is revoked either way.
+	return sr.findRevoked(ctx, bson.M{"user_id": userID, "revoked_at": at, "expires_at": bson.M{"$gt": at}})
+}
+
+func (sr *sessionRepository) FindRevokedSince(ctx context.Context, since time.Time, now time.Time) ([]model.RevokedSession, error) {
+	return sr.findRevoked(ctx, bson.M{
+		"revoked_at": bson.M{"$gte": since},
+		"expires_at": bson.M{"$gt": now},
+	})
+}
+
+func (sr *sessionRepository) findRevoked(ctx context.Context, filter bson.M) ([]model.RevokedSession, error) {
+	cursor, err := sr.collection.Find(ctx, filter, options.Find().SetProjection(bson.M{"expires_at": 1}))
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	revoked := []model.RevokedSession{}
+	if err := cursor.All(ctx, &revoked); err != nil {
+		return nil, err
+	}
+
+	return revoked, nil
+}
+
+func activeSession(filter bson.M, now time.Time) bson.M {
+	filter["revoked_at"] = bson.M{"$exists": false}
+	filter["expires_at"] = bson.M{"$gt": now}
+	return filter
+}
+
+func revokeUpdate(reason model.SessionRevokeReason, revokedBy primitive.ObjectID, at time.Time) bson.M {
+	set := bson.M{
+		"revoked_at":    at,
+		"revoke_reason": reason,
+	}
+	if !revokedBy.IsZero() {
+		set["revoked_by"] = revokedBy
+	}
+
+	return bson.M{"$set": set}
+}
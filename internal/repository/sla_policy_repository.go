@@ -0,0 +1,119 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/latiiLA/coop-forex-server/internal/domain/model"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type slaPolicyRepository struct {
+	collection *mongo.Collection
+}
+
+func NewSLAPolicyRepository(db *mongo.Database) model.SLAPolicyRepository {
+	return &slaPolicyRepository{
+		collection: db.Collection("sla_policies"),
+	}
+}
+
+func (sr *slaPolicyRepository) Create(ctx context.Context, policy *model.SLAPolicy) error {
+	_, err := sr.collection.InsertOne(ctx, policy)
+
+	return err
+}
+
+func (sr *slaPolicyRepository) FindByID(ctx context.Context, policyID primitive.ObjectID) (*model.SLAPolicy, error) {
+	var policy model.SLAPolicy
+	filter := bson.M{"_id": policyID, "is_deleted": false}
+
+	err := sr.collection.FindOne(ctx, filter).Decode(&policy)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &policy, nil
+}
+
+func (sr *slaPolicyRepository) FindAll(ctx context.Context) ([]model.SLAPolicy, error) {
+	return sr.find(ctx, bson.M{"is_deleted": false})
+}
+
+func (sr *slaPolicyRepository) FindActive(ctx context.Context) ([]model.SLAPolicy, error) {
+	return sr.find(ctx, bson.M{"is_deleted": false, "is_active": true})
+}
+
+func (sr *slaPolicyRepository) Update(ctx context.Context, policyID primitive.ObjectID, policy *model.SLAPolicy) error {
+	filter := bson.M{"_id": policyID, "is_deleted": false}
+
+	set := bson.M{
+		"name":                policy.Name,
+		"stage":               policy.Stage,
+		"due_after_hours":     policy.DueAfterHours,
+		"remind_before_hours": policy.RemindBeforeHours,
+		"auto_decline":        policy.AutoDecline,
+		"is_active":           policy.IsActive,
+		"updated_at":          policy.UpdatedAt,
+		"updated_by":          policy.UpdatedBy,
+	}
+
+	update := bson.M{"$set": set}
+	if policy.TravelPurposeID != nil {
+		set["travel_purpose_id"] = policy.TravelPurposeID
+	} else {
+		update["$unset"] = bson.M{"travel_purpose_id": ""}
+	}
+
+	_, err := sr.collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return fmt.Errorf("failed to update SLA policy: %w", err)
+	}
+
+	return nil
+}
+
+func (sr *slaPolicyRepository) Delete(ctx context.Context, policyID primitive.ObjectID, policy *model.SLAPolicy) error {
+	filter := bson.M{"_id": policyID}
+	update := bson.M{
+		"$set": bson.M{
+			"is_deleted": policy.IsDeleted,
+			"deleted_at": policy.DeletedAt,
+			"deleted_by": policy.DeletedBy,
+		},
+	}
+
+	_, err := sr.collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return fmt.Errorf("failed to soft-delete SLA policy: %w", err)
+	}
+
+	return nil
+}
+
+func (sr *slaPolicyRepository) find(ctx context.Context, filter bson.M) ([]model.SLAPolicy, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "stage", Value: 1}, {Key: "name", Value: 1}})
+
+	cursor, err := sr.collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var policies []model.SLAPolicy
+	if err := cursor.All(ctx, &policies); err != nil {
+		return nil, err
+	}
+
+	if len(policies) == 0 {
+		return []model.SLAPolicy{}, nil
+	}
+
+	return policies, nil
+}
@@ -0,0 +1,44 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/latiiLA/coop-forex-server/internal/domain/model"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+type streamTicketRepository struct {
+	collection *mongo.Collection
+}
+
+func NewStreamTicketRepository(db *mongo.Database) model.StreamTicketRepository {
+	return &streamTicketRepository{
+		collection: db.Collection("stream_tickets"),
+	}
+}
+
+func (sr *streamTicketRepository) Create(ctx context.Context, ticket *model.StreamTicket) error {
+	_, err := sr.collection.InsertOne(ctx, ticket)
+
+	return err
+}
+
+// Redeem deletes the ticket as it reads it, so two streams racing for the
+// same ticket cannot both get it. Expired tickets are left to the TTL index.
+func (sr *streamTicketRepository) Redeem(ctx context.Context, hash string, at time.Time) (*model.StreamTicket, error) {
+	filter := bson.M{"_id": hash, "expires_at": bson.M{"$gt": at}}
+
+	var ticket model.StreamTicket
+	err := sr.collection.FindOneAndDelete(ctx, filter).Decode(&ticket)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &ticket, nil
+}
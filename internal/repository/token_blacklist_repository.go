@@ -52,3 +52,12 @@ func (r *tokenBlacklistRepository) IsBlacklisted(ctx context.Context, token stri
 	}
 	return count > 0, nil
 }
+
+// DeleteExpired removes tokens that can no longer be used anyway
+func (r *tokenBlacklistRepository) DeleteExpired(ctx context.Context, now time.Time) (int64, error) {
+	result, err := r.collection.DeleteMany(ctx, bson.M{"expiresAt": bson.M{"$lte": now}})
+	if err != nil {
+		return 0, err
+	}
+	return result.DeletedCount, nil
+}
@@ -2,12 +2,16 @@ package repository
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"time"
 
+	"github.com/latiiLA/coop-forex-server/internal/common"
 	"github.com/latiiLA/coop-forex-server/internal/domain/model"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
 type userRepository struct {
@@ -148,6 +152,7 @@ func (ur *userRepository) FindByUsername(ctx context.Context, username string) (
 			{Key: "status", Value: 1},
 			{Key: "password", Value: 1},
 			{Key: "permissions", Value: 1},
+			{Key: "permissions_version", Value: 1},
 			{Key: "department", Value: 1},
 			{Key: "branch", Value: 1},
 			{Key: "role_id", Value: 1},
@@ -405,6 +410,7 @@ func (ur *userRepository) FindByID(ctx context.Context, user_id primitive.Object
 				{Key: "status", Value: 1},
 				{Key: "signature", Value: 1},
 				{Key: "permissions", Value: 1},
+				{Key: "permissions_version", Value: 1},
 				{Key: "department", Value: 1},
 				{Key: "branch", Value: 1},
 				{Key: "created_at", Value: 1},
@@ -435,6 +441,67 @@ func (ur *userRepository) FindByID(ctx context.Context, user_id primitive.Object
 	return &users[0], nil
 }
 
+func (ur *userRepository) FindByPermission(ctx context.Context, permission string, branchID *primitive.ObjectID, departmentID *primitive.ObjectID) ([]model.User, error) {
+	pipeline := mongo.Pipeline{
+		bson.D{{Key: "$match", Value: bson.D{
+			{Key: "is_deleted", Value: false},
+			{Key: "status", Value: bson.D{{Key: "$in", Value: bson.A{model.StatusNew, model.StatusActive}}}},
+		}}},
+		bson.D{{Key: "$lookup", Value: bson.D{
+			{Key: "from", Value: "roles"},
+			{Key: "localField", Value: "role_id"},
+			{Key: "foreignField", Value: "_id"},
+			{Key: "as", Value: "role"},
+		}}},
+		bson.D{{Key: "$unwind", Value: bson.D{
+			{Key: "path", Value: "$role"},
+			{Key: "preserveNullAndEmptyArrays", Value: true},
+		}}},
+		bson.D{{Key: "$match", Value: bson.D{
+			{Key: "$or", Value: bson.A{
+				bson.D{{Key: "permissions", Value: permission}},
+				bson.D{{Key: "role.permissions", Value: permission}},
+			}},
+		}}},
+		bson.D{{Key: "$lookup", Value: bson.D{
+			{Key: "from", Value: "profiles"},
+			{Key: "localField", Value: "profile_id"},
+			{Key: "foreignField", Value: "_id"},
+			{Key: "as", Value: "profile"},
+		}}},
+		bson.D{{Key: "$unwind", Value: "$profile"}},
+	}
+
+	orgFilter := bson.D{}
+	if branchID != nil {
+		orgFilter = append(orgFilter, bson.E{Key: "profile.branch_id", Value: *branchID})
+	}
+	if departmentID != nil {
+		orgFilter = append(orgFilter, bson.E{Key: "profile.department_id", Value: *departmentID})
+	}
+	if len(orgFilter) > 0 {
+		pipeline = append(pipeline, bson.D{{Key: "$match", Value: orgFilter}})
+	}
+
+	pipeline = append(pipeline, bson.D{{Key: "$project", Value: bson.D{
+		{Key: "password", Value: 0},
+		{Key: "role", Value: 0},
+	}}})
+
+	cursor, err := ur.collection.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	users := []model.User{}
+	if err := cursor.All(ctx, &users); err != nil {
+		return nil, err
+	}
+
+	return users, nil
+}
+
 func (ur *userRepository) FindAll(ctx context.Context) (*[]model.UserResponseDTO, error) {
 
 	pipeline := mongo.Pipeline{
@@ -711,6 +778,78 @@ func (ur *userRepository) Update(ctx context.Context, user_id primitive.ObjectID
 	return &updatedUser, nil
 }
 
+func (ur *userRepository) UpdateStatus(ctx context.Context, user_id primitive.ObjectID, status model.UserStatus, updatedBy primitive.ObjectID, at time.Time) error {
+	result, err := ur.collection.UpdateOne(ctx,
+		bson.M{"_id": user_id, "is_deleted": false},
+		bson.M{"$set": bson.M{
+			"status":     status,
+			"updated_by": updatedBy,
+			"updated_at": at,
+		}},
+	)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return common.ErrUserNotFound
+	}
+
+	return nil
+}
+
+func (ur *userRepository) BumpPermissionsVersion(ctx context.Context, user_id primitive.ObjectID, at time.Time) (*model.PermissionsVersion, error) {
+	opts := options.FindOneAndUpdate().
+		SetProjection(bson.M{"permissions_version": 1, "permissions_changed_at": 1}).
+		SetReturnDocument(options.After)
+
+	var version model.PermissionsVersion
+	err := ur.collection.FindOneAndUpdate(ctx, bson.M{"_id": user_id}, bumpPermissionsVersion(at), opts).Decode(&version)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, common.ErrUserNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &version, nil
+}
+
+func (ur *userRepository) BumpRolePermissionsVersion(ctx context.Context, roleID primitive.ObjectID, at time.Time) ([]model.PermissionsVersion, error) {
+	if _, err := ur.collection.UpdateMany(ctx, bson.M{"role_id": roleID}, bumpPermissionsVersion(at)); err != nil {
+		return nil, err
+	}
+
+	// A user bumped at the same moment by another call is stale either way.
+	return ur.findPermissionsVersions(ctx, bson.M{"role_id": roleID, "permissions_changed_at": at})
+}
+
+func (ur *userRepository) FindPermissionsChangedSince(ctx context.Context, since time.Time) ([]model.PermissionsVersion, error) {
+	return ur.findPermissionsVersions(ctx, bson.M{"permissions_changed_at": bson.M{"$gte": since}})
+}
+
+func (ur *userRepository) findPermissionsVersions(ctx context.Context, filter bson.M) ([]model.PermissionsVersion, error) {
+	opts := options.Find().SetProjection(bson.M{"permissions_version": 1, "permissions_changed_at": 1})
+	cursor, err := ur.collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	versions := []model.PermissionsVersion{}
+	if err := cursor.All(ctx, &versions); err != nil {
+		return nil, err
+	}
+
+	return versions, nil
+}
+
+func bumpPermissionsVersion(at time.Time) bson.M {
+	return bson.M{
+		"$inc": bson.M{"permissions_version": 1},
+		"$set": bson.M{"permissions_changed_at": at},
+	}
+}
+
 func (ur *userRepository) Delete(ctx context.Context, user_id primitive.ObjectID, user *model.User) error {
 	filter := bson.M{"_id": user_id, "is_deleted": false}
 	update := bson.M{
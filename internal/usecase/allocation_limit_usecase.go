@@ -0,0 +1,166 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/latiiLA/coop-forex-server/internal/common"
+	"github.com/latiiLA/coop-forex-server/internal/domain/model"
+	"github.com/sirupsen/logrus"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type AllocationLimitUsecase interface {
+	AddAllocationLimit(ctx context.Context, authUserID primitive.ObjectID, limit *model.CreateAllocationLimitDTO) (*model.AllocationLimit, error)
+	GetAllAllocationLimits(ctx context.Context) ([]model.AllocationLimit, error)
+	GetAllocationLimitByID(ctx context.Context, limitID primitive.ObjectID) (*model.AllocationLimit, error)
+	UpdateAllocationLimit(ctx context.Context, authUserID primitive.ObjectID, limitID primitive.ObjectID, limit *model.UpdateAllocationLimitDTO) error
+	DeleteAllocationLimit(ctx context.Context, authUserID primitive.ObjectID, limitID primitive.ObjectID) error
+}
+
+type allocationLimitUsecase struct {
+	allocationLimitRepository model.AllocationLimitRepository
+	contextTimeout            time.Duration
+}
+
+func NewAllocationLimitUsecase(allocationLimitRepository model.AllocationLimitRepository, timeout time.Duration) AllocationLimitUsecase {
+	return &allocationLimitUsecase{
+		allocationLimitRepository: allocationLimitRepository,
+		contextTimeout:            timeout,
+	}
+}
+
+func (au *allocationLimitUsecase) AddAllocationLimit(ctx context.Context, authUserID primitive.ObjectID, limit *model.CreateAllocationLimitDTO) (*model.AllocationLimit, error) {
+	ctx, cancel := context.WithTimeout(ctx, au.contextTimeout)
+	defer cancel()
+
+	createdLimit := model.AllocationLimit{IsActive: true}
+	if err := applyAllocationLimitDTO(&createdLimit, limit); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	createdLimit.ID = primitive.NewObjectID()
+	createdLimit.CreatedAt = now
+	createdLimit.UpdatedAt = now
+	createdLimit.CreatedBy = authUserID
+	createdLimit.IsDeleted = false
+
+	if err := au.allocationLimitRepository.Create(ctx, &createdLimit); err != nil {
+		return nil, err
+	}
+
+	logrus.WithField("limit", createdLimit).Info("allocation limit created successfully")
+
+	return &createdLimit, nil
+}
+
+func (au *allocationLimitUsecase) GetAllAllocationLimits(ctx context.Context) ([]model.AllocationLimit, error) {
+	ctx, cancel := context.WithTimeout(ctx, au.contextTimeout)
+	defer cancel()
+
+	return au.allocationLimitRepository.FindAll(ctx)
+}
+
+func (au *allocationLimitUsecase) GetAllocationLimitByID(ctx context.Context, limitID primitive.ObjectID) (*model.AllocationLimit, error) {
+	ctx, cancel := context.WithTimeout(ctx, au.contextTimeout)
+	defer cancel()
+
+	limit, err := au.allocationLimitRepository.FindByID(ctx, limitID)
+	if err != nil {
+		return nil, err
+	}
+	if limit == nil {
+		return nil, common.ErrAllocationLimitNotFound
+	}
+
+	return limit, nil
+}
+
+func (au *allocationLimitUsecase) UpdateAllocationLimit(ctx context.Context, authUserID primitive.ObjectID, limitID primitive.ObjectID, limitUpdate *model.UpdateAllocationLimitDTO) error {
+	ctx, cancel := context.WithTimeout(ctx, au.contextTimeout)
+	defer cancel()
+
+	limit, err := au.allocationLimitRepository.FindByID(ctx, limitID)
+	if err != nil {
+		return err
+	}
+	if limit == nil {
+		return common.ErrAllocationLimitNotFound
+	}
+
+	if err := applyAllocationLimitDTO(limit, limitUpdate); err != nil {
+		return err
+	}
+
+	limit.UpdatedAt = time.Now()
+	limit.UpdatedBy = &authUserID
+
+	return au.allocationLimitRepository.Update(ctx, limitID, limit)
+}
+
+func (au *allocationLimitUsecase) DeleteAllocationLimit(ctx context.Context, authUserID primitive.ObjectID, limitID primitive.ObjectID) error {
+	ctx, cancel := context.WithTimeout(ctx, au.contextTimeout)
+	defer cancel()
+
+	limit, err := au.allocationLimitRepository.FindByID(ctx, limitID)
+	if err != nil {
+		return err
+	}
+	if limit == nil {
+		return common.ErrAllocationLimitNotFound
+	}
+
+	now := time.Now()
+	limit.IsDeleted = true
+	limit.DeletedAt = &now
+	limit.DeletedBy = &authUserID
+
+	return au.allocationLimitRepository.Delete(ctx, limitID, limit)
+}
+
+// applyAllocationLimitDTO copies the criteria of a create or update request
+// onto the limit. Empty criteria are cleared so they match every request.
+func applyAllocationLimitDTO(limit *model.AllocationLimit, dto *model.CreateAllocationLimitDTO) error {
+	var err error
+	if limit.TravelPurposeID, err = optionalObjectID(dto.TravelPurposeID); err != nil {
+		return err
+	}
+	if limit.CustomerTypeID, err = optionalObjectID(dto.CustomerTypeID); err != nil {
+		return err
+	}
+	if limit.CountryID, err = optionalObjectID(dto.CountryID); err != nil {
+		return err
+	}
+	if limit.CurrencyID, err = optionalObjectID(dto.CurrencyID); err != nil {
+		return err
+	}
+
+	limit.Name = dto.Name
+	limit.Scope = model.AllocationLimitScope(dto.Scope)
+	limit.RequestingAs = dto.RequestingAs
+	limit.MaxAmount = dto.MaxAmount
+	if dto.IsActive != nil {
+		limit.IsActive = *dto.IsActive
+	}
+
+	if limit.Scope == model.LimitDailyBudget && limit.CurrencyID == nil {
+		return fmt.Errorf("%w: daily budgets must name a currency", common.ErrInvalidAllocationLimit)
+	}
+
+	return nil
+}
+
+func optionalObjectID(hex string) (*primitive.ObjectID, error) {
+	if hex == "" {
+		return nil, nil
+	}
+
+	id, err := primitive.ObjectIDFromHex(hex)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", common.ErrInvalidAllocationLimit, err)
+	}
+
+	return &id, nil
+}
@@ -10,19 +10,19 @@ import (
 	"github.com/latiiLA/coop-forex-server/internal/common"
 	"github.com/latiiLA/coop-forex-server/internal/domain/model"
 	"github.com/latiiLA/coop-forex-server/internal/infrastructure"
-	"github.com/latiiLA/coop-forex-server/internal/infrastructure/utils"
+	"github.com/latiiLA/coop-forex-server/internal/infrastructure/metrics"
 	"github.com/sirupsen/logrus"
-	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
 )
 
 type AuthUsecase interface {
-	Authenticate(ctx context.Context, username, password, ip string) (*model.LoginResponseDTO, error)
+	Authenticate(ctx context.Context, username, password string, client model.ClientInfo) (*model.LoginResponseDTO, error)
 	GetUserDetails(ctx context.Context, username string) (*model.User, error)
 }
 
 type ldapAuthUsecase struct {
 	userRepo     model.UserRepository
+	sessionRepo  model.SessionRepository
 	Host         string
 	Port         string
 	BasedDN      string
@@ -32,9 +32,10 @@ type ldapAuthUsecase struct {
 	timeout      time.Duration
 }
 
-func NewLDAPAuthUsecase(userRepo model.UserRepository, host string, port, baseDN, bindUser, bindPassword, userFilter string, timeout time.Duration) AuthUsecase {
+func NewLDAPAuthUsecase(userRepo model.UserRepository, sessionRepo model.SessionRepository, host string, port, baseDN, bindUser, bindPassword, userFilter string, timeout time.Duration) AuthUsecase {
 	return &ldapAuthUsecase{
 		userRepo:     userRepo,
+		sessionRepo:  sessionRepo,
 		Host:         host,
 		Port:         port,
 		BasedDN:      baseDN,
@@ -45,7 +46,7 @@ func NewLDAPAuthUsecase(userRepo model.UserRepository, host string, port, baseDN
 	}
 }
 
-func (s *ldapAuthUsecase) Authenticate(ctx context.Context, username, password, ip string) (*model.LoginResponseDTO, error) {
+func (s *ldapAuthUsecase) Authenticate(ctx context.Context, username, password string, client model.ClientInfo) (*model.LoginResponseDTO, error) {
 	ctx, cancel := context.WithTimeout(ctx, s.timeout)
 	defer cancel()
 
@@ -68,6 +69,7 @@ func (s *ldapAuthUsecase) Authenticate(ctx context.Context, username, password,
 	l, err := ldap.DialURL(fmt.Sprintf("ldap://%s", s.Host))
 	if err != nil {
 		log.Println("LDAP: Connection failed")
+		metrics.RecordLDAPAuthentication(metrics.LDAPError)
 		return nil, fmt.Errorf("failed to connect to LDAP: %w", err)
 	}
 	defer l.Close()
@@ -85,6 +87,7 @@ func (s *ldapAuthUsecase) Authenticate(ctx context.Context, username, password,
 	err = l.Bind(s.BindUser, s.BindPassword)
 	if err != nil {
 		log.Println(fmt.Errorf("bind failed: %w", err))
+		metrics.RecordLDAPAuthentication(metrics.LDAPError)
 		return nil, fmt.Errorf("bind failed: %w", err)
 	}
 	log.Println("LDAP: Initial bind successful")
@@ -92,10 +95,12 @@ func (s *ldapAuthUsecase) Authenticate(ctx context.Context, username, password,
 	sr, err := l.Search(searchRequest)
 	if err != nil {
 		log.Println(fmt.Errorf("LDAP search error: %w", err))
+		metrics.RecordLDAPAuthentication(metrics.LDAPError)
 		return nil, fmt.Errorf("LDAP search error: %w", err)
 	}
 	if len(sr.Entries) == 0 {
 		log.Println(fmt.Errorf("user not found"))
+		metrics.RecordLDAPAuthentication(metrics.LDAPRejected)
 		return nil, common.ErrADUserNotFound
 	}
 
@@ -106,38 +111,24 @@ func (s *ldapAuthUsecase) Authenticate(ctx context.Context, username, password,
 	err = l.Bind(userDN, password)
 	if err != nil {
 		log.Println(fmt.Errorf("user authentication failed: %w", err))
+		metrics.RecordLDAPAuthentication(metrics.LDAPRejected)
 		return nil, common.ErrInvalidCredentials
 	}
 	log.Println("✅ User authentication successful")
+	metrics.RecordLDAPAuthentication(metrics.LDAPSuccess)
 
 	// Prepare response and reply
-	var perms []string
-	if existingUser.Role != nil && existingUser.Permissions != nil {
-		perms = existingUser.Permissions
-	}
-
-	effectivePerms := utils.MergePermissions(existingUser.Role.Permissions, perms)
-
-	var branchID primitive.ObjectID
-	if existingUser.Profile.BranchID != nil {
-		branchID = *existingUser.Profile.BranchID
-	} else {
-		branchID = primitive.NilObjectID // fallback
-	}
-
-	var departmentID primitive.ObjectID
-	if existingUser.Profile.DepartmentID != nil {
-		departmentID = *existingUser.Profile.DepartmentID
-	} else {
-		departmentID = primitive.NilObjectID // fallback
+	claims, err := accessClaims(existingUser)
+	if err != nil {
+		return nil, err
 	}
 
-	accessToken, err := infrastructure.GenerateToken(existingUser.ID, existingUser.Role.Name, branchID, departmentID, effectivePerms, ip)
+	session, refreshToken, err := startSession(ctx, s.sessionRepo, existingUser.ID, client)
 	if err != nil {
 		return nil, err
 	}
 
-	refreshToken, err := infrastructure.GenerateRefreshToken(existingUser.ID, ip)
+	accessToken, err := infrastructure.GenerateToken(claims, client, session.ID)
 	if err != nil {
 		return nil, err
 	}
@@ -149,7 +140,7 @@ func (s *ldapAuthUsecase) Authenticate(ctx context.Context, username, password,
 		Username:     existingUser.Username,
 		Email:        existingUser.Profile.Email,
 		Role:         existingUser.Role.Name,
-		Permissions:  effectivePerms,
+		Permissions:  claims.Permissions,
 		Token:        accessToken,
 		RefreshToken: refreshToken,
 	}
@@ -0,0 +1,345 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/latiiLA/coop-forex-server/internal/common"
+	"github.com/latiiLA/coop-forex-server/internal/domain/model"
+	"github.com/sirupsen/logrus"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+type ExchangeRateUsecase interface {
+	AddExchangeRate(ctx context.Context, authUserID primitive.ObjectID, rate *model.CreateExchangeRateDTO) (*model.ExchangeRate, error)
+	UploadExchangeRates(ctx context.Context, authUserID primitive.ObjectID, rows []model.ExchangeRateRowDTO) (*model.ExchangeRateUploadResultDTO, error)
+	GetExchangeRateByID(ctx context.Context, rateID primitive.ObjectID) (*model.ExchangeRate, error)
+	GetCurrentExchangeRates(ctx context.Context) ([]model.ExchangeRate, error)
+	GetExchangeRateHistory(ctx context.Context, currencyID primitive.ObjectID, from *time.Time, to *time.Time) ([]model.ExchangeRate, error)
+	UpdateExchangeRate(ctx context.Context, authUserID primitive.ObjectID, rateID primitive.ObjectID, rate *model.UpdateExchangeRateDTO) error
+	DeleteExchangeRate(ctx context.Context, authUserID primitive.ObjectID, rateID primitive.ObjectID) error
+}
+
+type exchangeRateUsecase struct {
+	exchangeRateRepository model.ExchangeRateRepository
+	currencyRepository     model.CurrencyRepository
+	contextTimeout         time.Duration
+	client                 *mongo.Client
+}
+
+func NewExchangeRateUsecase(exchangeRateRepository model.ExchangeRateRepository, currencyRepository model.CurrencyRepository, timeout time.Duration, client *mongo.Client) ExchangeRateUsecase {
+	return &exchangeRateUsecase{
+		exchangeRateRepository: exchangeRateRepository,
+		currencyRepository:     currencyRepository,
+		contextTimeout:         timeout,
+		client:                 client,
+	}
+}
+
+func (eu *exchangeRateUsecase) AddExchangeRate(ctx context.Context, authUserID primitive.ObjectID, rate *model.CreateExchangeRateDTO) (*model.ExchangeRate, error) {
+	ctx, cancel := context.WithTimeout(ctx, eu.contextTimeout)
+	defer cancel()
+
+	currencyID, err := primitive.ObjectIDFromHex(rate.CurrencyID)
+	if err != nil {
+		return nil, common.ErrCurrencyNotFound
+	}
+
+	currency, err := eu.currencyRepository.FindByID(ctx, currencyID)
+	if err != nil {
+		return nil, err
+	}
+	if currency == nil {
+		return nil, common.ErrCurrencyNotFound
+	}
+
+	now := time.Now().UTC()
+	exchangeRate, err := newExchangeRate(currencyID, rate.BuyingRate, rate.SellingRate, rate.MidRate, rate.ValidFrom, rate.ValidTo, now)
+	if err != nil {
+		return nil, err
+	}
+	exchangeRate.Source = model.ExchangeRateSourceManual
+	exchangeRate.PublishedBy = authUserID
+	exchangeRate.CreatedBy = authUserID
+
+	// The new rate is stored before its predecessor is closed, so a failure
+	// leaves two overlapping rates, where the later one wins, rather than
+	// none.
+	if err := eu.exchangeRateRepository.Create(ctx, exchangeRate); err != nil {
+		return nil, err
+	}
+
+	if exchangeRate.ValidTo == nil {
+		if err := eu.exchangeRateRepository.CloseOpenRates(ctx, currencyID, exchangeRate.ValidFrom); err != nil {
+			return nil, err
+		}
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"currency":     currency.ShortCode,
+		"buying_rate":  exchangeRate.BuyingRate,
+		"selling_rate": exchangeRate.SellingRate,
+		"valid_from":   exchangeRate.ValidFrom,
+	}).Info("exchange rate published")
+
+	exchangeRate.Currency = currency
+
+	return exchangeRate, nil
+}
+
+// UploadExchangeRates publishes a batch of rates. The batch is all or nothing:
+// when any row is invalid nothing is stored and every problem is reported.
+func (eu *exchangeRateUsecase) UploadExchangeRates(ctx context.Context, authUserID primitive.ObjectID, rows []model.ExchangeRateRowDTO) (*model.ExchangeRateUploadResultDTO, error) {
+	ctx, cancel := context.WithTimeout(ctx, eu.contextTimeout)
+	defer cancel()
+
+	if len(rows) == 0 {
+		return nil, common.ErrEmptyRateUpload
+	}
+
+	now := time.Now().UTC()
+	result := &model.ExchangeRateUploadResultDTO{}
+	currencies := map[string]*model.Currency{}
+	seen := map[string]int{}
+	rates := make([]model.ExchangeRate, 0, len(rows))
+
+	for i, row := range rows {
+		line := i + 1
+		code := strings.ToUpper(strings.TrimSpace(row.Currency))
+
+		if code == "" {
+			result.Errors = append(result.Errors, model.ExchangeRateRowError{Row: line, Message: "currency is required"})
+			continue
+		}
+		if first, ok := seen[code]; ok {
+			result.Errors = append(result.Errors, model.ExchangeRateRowError{Row: line, Message: fmt.Sprintf("%s is already listed on row %d", code, first)})
+			continue
+		}
+		seen[code] = line
+
+		currency, ok := currencies[code]
+		if !ok {
+			found, err := eu.currencyRepository.FindByShortCode(ctx, code)
+			if err != nil {
+				return nil, err
+			}
+			currency, currencies[code] = found, found
+		}
+		if currency == nil {
+			result.Errors = append(result.Errors, model.ExchangeRateRowError{Row: line, Message: fmt.Sprintf("unknown currency %s", code)})
+			continue
+		}
+
+		rate, err := newExchangeRate(currency.ID, row.BuyingRate, row.SellingRate, row.MidRate, row.ValidFrom, row.ValidTo, now)
+		if err != nil {
+			result.Errors = append(result.Errors, model.ExchangeRateRowError{Row: line, Message: err.Error()})
+			continue
+		}
+		rate.Source = model.ExchangeRateSourceUpload
+		rate.PublishedBy = authUserID
+		rate.CreatedBy = authUserID
+
+		rates = append(rates, *rate)
+	}
+
+	if len(result.Errors) > 0 {
+		return result, common.ErrInvalidRateUpload
+	}
+
+	// Old rates are only closed once the whole batch is stored, so no
+	// currency is ever left without a rate in force.
+	if err := eu.exchangeRateRepository.CreateMany(ctx, rates); err != nil {
+		return nil, err
+	}
+
+	for _, rate := range rates {
+		if rate.ValidTo != nil {
+			continue
+		}
+		if err := eu.exchangeRateRepository.CloseOpenRates(ctx, rate.CurrencyID, rate.ValidFrom); err != nil {
+			return nil, err
+		}
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"publishedBy": authUserID,
+		"count":       len(rates),
+	}).Info("exchange rates uploaded")
+
+	result.Published = len(rates)
+
+	return result, nil
+}
+
+func (eu *exchangeRateUsecase) GetExchangeRateByID(ctx context.Context, rateID primitive.ObjectID) (*model.ExchangeRate, error) {
+	ctx, cancel := context.WithTimeout(ctx, eu.contextTimeout)
+	defer cancel()
+
+	rate, err := eu.exchangeRateRepository.FindByID(ctx, rateID)
+	if err != nil {
+		return nil, err
+	}
+	if rate == nil {
+		return nil, common.ErrExchangeRateNotFound
+	}
+
+	return rate, nil
+}
+
+func (eu *exchangeRateUsecase) GetCurrentExchangeRates(ctx context.Context) ([]model.ExchangeRate, error) {
+	ctx, cancel := context.WithTimeout(ctx, eu.contextTimeout)
+	defer cancel()
+
+	return eu.exchangeRateRepository.FindCurrent(ctx, time.Now().UTC())
+}
+
+func (eu *exchangeRateUsecase) GetExchangeRateHistory(ctx context.Context, currencyID primitive.ObjectID, from *time.Time, to *time.Time) ([]model.ExchangeRate, error) {
+	ctx, cancel := context.WithTimeout(ctx, eu.contextTimeout)
+	defer cancel()
+
+	return eu.exchangeRateRepository.FindHistory(ctx, currencyID, from, to)
+}
+
+// UpdateExchangeRate corrects a rate that has not taken effect yet. Window
+// bounds that are not sent keep their stored values.
+func (eu *exchangeRateUsecase) UpdateExchangeRate(ctx context.Context, authUserID primitive.ObjectID, rateID primitive.ObjectID, rateUpdate *model.UpdateExchangeRateDTO) error {
+	ctx, cancel := context.WithTimeout(ctx, eu.contextTimeout)
+	defer cancel()
+
+	rate, err := eu.exchangeRateRepository.FindByID(ctx, rateID)
+	if err != nil {
+		return err
+	}
+	if rate == nil {
+		return common.ErrExchangeRateNotFound
+	}
+
+	// Rates that have taken effect may already be snapshotted on requests and
+	// are part of the rate history; a correction is published as a new rate.
+	now := time.Now().UTC()
+	if !rate.ValidFrom.After(now) {
+		return common.ErrExchangeRateInEffect
+	}
+
+	validFrom := rateUpdate.ValidFrom
+	if validFrom == nil {
+		validFrom = &rate.ValidFrom
+	}
+	if !validFrom.After(now) {
+		return common.ErrExchangeRateInEffect
+	}
+
+	validTo := rateUpdate.ValidTo
+	if validTo == nil {
+		validTo = rate.ValidTo
+	}
+
+	updated, err := newExchangeRate(rate.CurrencyID, rateUpdate.BuyingRate, rateUpdate.SellingRate, rateUpdate.MidRate, validFrom, validTo, now)
+	if err != nil {
+		return err
+	}
+	updated.UpdatedBy = &authUserID
+
+	// The rate it took over from was closed at the old start; moving the
+	// start moves that end with it, so neither a gap nor an overlap opens.
+	return eu.inTransaction(ctx, func(ctx context.Context) error {
+		if err := eu.exchangeRateRepository.Update(ctx, rateID, updated); err != nil {
+			return err
+		}
+		if updated.ValidFrom.Equal(rate.ValidFrom) {
+			return nil
+		}
+		return eu.exchangeRateRepository.MovePredecessorEnd(ctx, rate.CurrencyID, rateID, rate.ValidFrom, &updated.ValidFrom)
+	})
+}
+
+// DeleteExchangeRate withdraws a rate that has not taken effect yet.
+func (eu *exchangeRateUsecase) DeleteExchangeRate(ctx context.Context, authUserID primitive.ObjectID, rateID primitive.ObjectID) error {
+	ctx, cancel := context.WithTimeout(ctx, eu.contextTimeout)
+	defer cancel()
+
+	rate, err := eu.exchangeRateRepository.FindByID(ctx, rateID)
+	if err != nil {
+		return err
+	}
+	if rate == nil {
+		return common.ErrExchangeRateNotFound
+	}
+
+	// Like edits, only rates that have not taken effect yet may be withdrawn.
+	now := time.Now().UTC()
+	if !rate.ValidFrom.After(now) {
+		return common.ErrExchangeRateInEffect
+	}
+
+	rate.IsDeleted = true
+	rate.DeletedAt = &now
+	rate.DeletedBy = &authUserID
+
+	// The rate it would have taken over from stays in force for the window
+	// it leaves behind, or reopens when that window was open-ended.
+	return eu.inTransaction(ctx, func(ctx context.Context) error {
+		if err := eu.exchangeRateRepository.Delete(ctx, rateID, rate); err != nil {
+			return err
+		}
+		return eu.exchangeRateRepository.MovePredecessorEnd(ctx, rate.CurrencyID, rateID, rate.ValidFrom, rate.ValidTo)
+	})
+}
+
+// inTransaction runs fn in a MongoDB transaction, retrying it on transient
+// errors.
+func (eu *exchangeRateUsecase) inTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	session, err := eu.client.StartSession()
+	if err != nil {
+		return err
+	}
+	defer session.EndSession(ctx)
+
+	_, err = session.WithTransaction(ctx, func(sessCtx mongo.SessionContext) (interface{}, error) {
+		return nil, fn(sessCtx)
+	})
+
+	return err
+}
+
+// newExchangeRate validates the rates and validity window and fills in the
+// defaults: the mid rate is the average of buying and selling and the rate
+// takes effect immediately.
+func newExchangeRate(currencyID primitive.ObjectID, buying, selling, mid float64, validFrom, validTo *time.Time, now time.Time) (*model.ExchangeRate, error) {
+	if mid == 0 {
+		mid = (buying + selling) / 2
+	}
+	if buying <= 0 || selling < buying || mid < buying || mid > selling {
+		return nil, common.ErrInvalidExchangeRate
+	}
+
+	from := now
+	if validFrom != nil {
+		from = validFrom.UTC()
+	}
+
+	var to *time.Time
+	if validTo != nil {
+		end := validTo.UTC()
+		if !end.After(from) {
+			return nil, common.ErrInvalidRateWindow
+		}
+		to = &end
+	}
+
+	return &model.ExchangeRate{
+		ID:          primitive.NewObjectID(),
+		CurrencyID:  currencyID,
+		BuyingRate:  buying,
+		SellingRate: selling,
+		MidRate:     mid,
+		ValidFrom:   from,
+		ValidTo:     to,
+		PublishedAt: now,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}, nil
+}
@@ -19,8 +19,12 @@ import (
 type FileUsecase interface {
 	AddFile(ctx context.Context, file *multipart.FileHeader, prefix string) (*primitive.ObjectID, error)
 	GetFileByID(ctx context.Context, file_id primitive.ObjectID) (*model.File, error)
+	SweepOrphans(ctx context.Context, createdBefore time.Time) (*model.FileSweep, error)
 }
 
+// orphanBatch is how many file records a sweep checks at a time.
+const orphanBatch = 100
+
 type fileUsecase struct {
 	fileRepository model.FileRepository
 	contextTimeout time.Duration
@@ -87,3 +91,107 @@ func (ru *fileUsecase) GetFileByID(ctx context.Context, file_id primitive.Object
 	defer cancel()
 	return ru.fileRepository.FindByID(ctx, file_id)
 }
+
+// SweepOrphans deletes the uploads created before createdBefore that belong
+// to no request: records no request references, now or in its history,
+// together with their files, and files in the upload directory without a
+// record. Uploads are stored
+// before the request referencing them, so createdBefore must leave time for
+// requests that are still being submitted.
+func (fu *fileUsecase) SweepOrphans(ctx context.Context, createdBefore time.Time) (*model.FileSweep, error) {
+	sweep := &model.FileSweep{}
+
+	for ctx.Err() == nil {
+		files, err := fu.findUnreferenced(ctx, createdBefore)
+		if err != nil {
+			return sweep, err
+		}
+
+		for _, file := range files {
+			if err := removeUpload(file.Name); err != nil {
+				return sweep, err
+			}
+			if err := fu.deleteFile(ctx, file.ID); err != nil {
+				return sweep, err
+			}
+			sweep.Records++
+		}
+
+		if len(files) < orphanBatch {
+			break
+		}
+	}
+
+	entries, err := os.ReadDir(configs.FileUploadPath)
+	if os.IsNotExist(err) {
+		return sweep, ctx.Err()
+	}
+	if err != nil {
+		return sweep, err
+	}
+
+	var names []string
+	for _, entry := range entries {
+		if !entry.Type().IsRegular() {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil || !info.ModTime().Before(createdBefore) {
+			continue
+		}
+		names = append(names, entry.Name())
+	}
+
+	for len(names) > 0 && ctx.Err() == nil {
+		batch := names[:min(len(names), orphanBatch)]
+		names = names[len(batch):]
+
+		known, err := fu.findNames(ctx, batch)
+		if err != nil {
+			return sweep, err
+		}
+		for _, name := range batch {
+			if known[name] {
+				continue
+			}
+			if err := removeUpload(name); err != nil {
+				return sweep, err
+			}
+			sweep.Files++
+		}
+	}
+
+	return sweep, ctx.Err()
+}
+
+func (fu *fileUsecase) findUnreferenced(ctx context.Context, createdBefore time.Time) ([]model.File, error) {
+	ctx, cancel := context.WithTimeout(ctx, fu.contextTimeout)
+	defer cancel()
+
+	return fu.fileRepository.FindUnreferenced(ctx, createdBefore, orphanBatch)
+}
+
+func (fu *fileUsecase) findNames(ctx context.Context, names []string) (map[string]bool, error) {
+	ctx, cancel := context.WithTimeout(ctx, fu.contextTimeout)
+	defer cancel()
+
+	return fu.fileRepository.FindNames(ctx, names)
+}
+
+func (fu *fileUsecase) deleteFile(ctx context.Context, fileID primitive.ObjectID) error {
+	ctx, cancel := context.WithTimeout(ctx, fu.contextTimeout)
+	defer cancel()
+
+	return fu.fileRepository.Delete(ctx, fileID)
+}
+
+// removeUpload deletes the named file from the upload directory. Only the
+// base name is used, so a record can never point the sweep elsewhere.
+func removeUpload(name string) error {
+	err := os.Remove(filepath.Join(configs.FileUploadPath, filepath.Base(name)))
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,154 @@
+package usecase
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"time"
+
+	"github.com/latiiLA/coop-forex-server/internal/common"
+	"github.com/latiiLA/coop-forex-server/internal/domain/model"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type InboxUsecase interface {
+	GetInbox(ctx context.Context, authUserID primitive.ObjectID, query *model.InboxQuery) (*model.InboxPage, error)
+	CountUnread(ctx context.Context, authUserID primitive.ObjectID) (int64, error)
+	MarkRead(ctx context.Context, authUserID primitive.ObjectID, itemIDs []primitive.ObjectID) (int64, error)
+	MarkAllRead(ctx context.Context, authUserID primitive.ObjectID) (int64, error)
+	Subscribe(authUserID primitive.ObjectID) (<-chan model.InboxItem, func())
+	IssueStreamTicket(ctx context.Context, authUserID primitive.ObjectID, sessionID primitive.ObjectID) (*model.StreamTicketDTO, error)
+	// RedeemStreamTicket returns the user and session a stream ticket was
+	// issued to and invalidates it.
+	RedeemStreamTicket(ctx context.Context, ticket string) (primitive.ObjectID, primitive.ObjectID, error)
+	// IsSessionRevoked reports whether the session a stream was opened in has
+	// been revoked since.
+	IsSessionRevoked(sessionID primitive.ObjectID) bool
+}
+
+type inboxUsecase struct {
+	inboxRepository        model.InboxRepository
+	streamTicketRepository model.StreamTicketRepository
+	inboxBroker            model.InboxBroker
+	revocations            model.SessionRevocations
+	contextTimeout         time.Duration
+}
+
+func NewInboxUsecase(inboxRepository model.InboxRepository, streamTicketRepository model.StreamTicketRepository, inboxBroker model.InboxBroker, revocations model.SessionRevocations, timeout time.Duration) InboxUsecase {
+	return &inboxUsecase{
+		inboxRepository:        inboxRepository,
+		streamTicketRepository: streamTicketRepository,
+		inboxBroker:            inboxBroker,
+		revocations:            revocations,
+		contextTimeout:         timeout,
+	}
+}
+
+func (iu *inboxUsecase) GetInbox(ctx context.Context, authUserID primitive.ObjectID, query *model.InboxQuery) (*model.InboxPage, error) {
+	ctx, cancel := context.WithTimeout(ctx, iu.contextTimeout)
+	defer cancel()
+
+	query.Normalize()
+
+	items, err := iu.inboxRepository.FindByUser(ctx, authUserID, query)
+	if err != nil {
+		return nil, err
+	}
+
+	unread, err := iu.inboxRepository.CountUnread(ctx, authUserID)
+	if err != nil {
+		return nil, err
+	}
+
+	page := &model.InboxPage{Items: items, UnreadCount: unread}
+	if int64(len(items)) == query.Limit {
+		page.NextBefore = &items[len(items)-1].ID
+	}
+
+	return page, nil
+}
+
+func (iu *inboxUsecase) CountUnread(ctx context.Context, authUserID primitive.ObjectID) (int64, error) {
+	ctx, cancel := context.WithTimeout(ctx, iu.contextTimeout)
+	defer cancel()
+
+	return iu.inboxRepository.CountUnread(ctx, authUserID)
+}
+
+// MarkRead marks the given items of the caller's inbox read. Items of other
+// users are ignored.
+func (iu *inboxUsecase) MarkRead(ctx context.Context, authUserID primitive.ObjectID, itemIDs []primitive.ObjectID) (int64, error) {
+	ctx, cancel := context.WithTimeout(ctx, iu.contextTimeout)
+	defer cancel()
+
+	return iu.inboxRepository.MarkRead(ctx, authUserID, itemIDs, time.Now())
+}
+
+func (iu *inboxUsecase) MarkAllRead(ctx context.Context, authUserID primitive.ObjectID) (int64, error) {
+	ctx, cancel := context.WithTimeout(ctx, iu.contextTimeout)
+	defer cancel()
+
+	return iu.inboxRepository.MarkAllRead(ctx, authUserID, time.Now())
+}
+
+// Subscribe follows the items added to the caller's inbox from now on. The
+// subscription is not bound by the usecase timeout; it lasts until the
+// returned function is called.
+func (iu *inboxUsecase) Subscribe(authUserID primitive.ObjectID) (<-chan model.InboxItem, func()) {
+	return iu.inboxBroker.Subscribe(authUserID)
+}
+
+// IssueStreamTicket hands the caller a ticket to open their inbox stream with
+// within model.StreamTicketTTL.
+func (iu *inboxUsecase) IssueStreamTicket(ctx context.Context, authUserID primitive.ObjectID, sessionID primitive.ObjectID) (*model.StreamTicketDTO, error) {
+	ctx, cancel := context.WithTimeout(ctx, iu.contextTimeout)
+	defer cancel()
+
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return nil, err
+	}
+	ticket := base64.RawURLEncoding.EncodeToString(secret)
+
+	expiresAt := time.Now().Add(model.StreamTicketTTL)
+	err := iu.streamTicketRepository.Create(ctx, &model.StreamTicket{
+		Hash:      hashStreamTicket(ticket),
+		UserID:    authUserID,
+		SessionID: sessionID,
+		ExpiresAt: expiresAt,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.StreamTicketDTO{Ticket: ticket, ExpiresAt: expiresAt}, nil
+}
+
+func (iu *inboxUsecase) RedeemStreamTicket(ctx context.Context, ticket string) (primitive.ObjectID, primitive.ObjectID, error) {
+	ctx, cancel := context.WithTimeout(ctx, iu.contextTimeout)
+	defer cancel()
+
+	if ticket == "" {
+		return primitive.NilObjectID, primitive.NilObjectID, common.ErrStreamTicketInvalid
+	}
+
+	redeemed, err := iu.streamTicketRepository.Redeem(ctx, hashStreamTicket(ticket), time.Now())
+	if err != nil {
+		return primitive.NilObjectID, primitive.NilObjectID, err
+	}
+	if redeemed == nil || iu.revocations.IsRevoked(redeemed.SessionID) {
+		return primitive.NilObjectID, primitive.NilObjectID, common.ErrStreamTicketInvalid
+	}
+
+	return redeemed.UserID, redeemed.SessionID, nil
+}
+
+func (iu *inboxUsecase) IsSessionRevoked(sessionID primitive.ObjectID) bool {
+	return iu.revocations.IsRevoked(sessionID)
+}
+
+func hashStreamTicket(ticket string) string {
+	sum := sha256.Sum256([]byte(ticket))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
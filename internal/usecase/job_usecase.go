@@ -0,0 +1,98 @@
+package usecase
+
+import (
+	"context"
+	"time"
+
+	"github.com/latiiLA/coop-forex-server/internal/common"
+	"github.com/latiiLA/coop-forex-server/internal/domain/model"
+	"github.com/sirupsen/logrus"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type JobUsecase interface {
+	GetJobs(ctx context.Context) ([]model.Job, error)
+	GetJobRuns(ctx context.Context, name string, query *model.JobRunQuery) ([]model.JobRun, error)
+	TriggerJob(ctx context.Context, authUserID primitive.ObjectID, name string) (*model.JobRun, error)
+	PauseJob(ctx context.Context, authUserID primitive.ObjectID, name string) error
+	ResumeJob(ctx context.Context, authUserID primitive.ObjectID, name string) error
+}
+
+type jobUsecase struct {
+	jobRepository    model.JobRepository
+	jobRunRepository model.JobRunRepository
+	jobRunner        model.JobRunner
+	contextTimeout   time.Duration
+}
+
+func NewJobUsecase(jobRepository model.JobRepository, jobRunRepository model.JobRunRepository, jobRunner model.JobRunner, timeout time.Duration) JobUsecase {
+	return &jobUsecase{
+		jobRepository:    jobRepository,
+		jobRunRepository: jobRunRepository,
+		jobRunner:        jobRunner,
+		contextTimeout:   timeout,
+	}
+}
+
+func (ju *jobUsecase) GetJobs(ctx context.Context) ([]model.Job, error) {
+	ctx, cancel := context.WithTimeout(ctx, ju.contextTimeout)
+	defer cancel()
+
+	return ju.jobRepository.FindAll(ctx)
+}
+
+func (ju *jobUsecase) GetJobRuns(ctx context.Context, name string, query *model.JobRunQuery) ([]model.JobRun, error) {
+	ctx, cancel := context.WithTimeout(ctx, ju.contextTimeout)
+	defer cancel()
+
+	job, err := ju.jobRepository.FindByName(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	if job == nil {
+		return nil, common.ErrJobNotFound
+	}
+
+	query.Normalize()
+
+	return ju.jobRunRepository.FindByJob(ctx, name, query.Limit)
+}
+
+// TriggerJob starts the job on this instance and returns its run without
+// waiting for it to finish.
+func (ju *jobUsecase) TriggerJob(ctx context.Context, authUserID primitive.ObjectID, name string) (*model.JobRun, error) {
+	ctx, cancel := context.WithTimeout(ctx, ju.contextTimeout)
+	defer cancel()
+
+	run, err := ju.jobRunner.Trigger(ctx, name, authUserID)
+	if err != nil {
+		return nil, err
+	}
+
+	logrus.WithFields(logrus.Fields{"job": name, "runID": run.ID.Hex(), "userID": authUserID}).Info("Job triggered")
+
+	return run, nil
+}
+
+// PauseJob stops the job from running on its schedule on every instance. A
+// run in progress finishes, and a paused job can still be triggered.
+func (ju *jobUsecase) PauseJob(ctx context.Context, authUserID primitive.ObjectID, name string) error {
+	return ju.setPaused(ctx, authUserID, name, true)
+}
+
+func (ju *jobUsecase) ResumeJob(ctx context.Context, authUserID primitive.ObjectID, name string) error {
+	return ju.setPaused(ctx, authUserID, name, false)
+}
+
+func (ju *jobUsecase) setPaused(ctx context.Context, authUserID primitive.ObjectID, name string, paused bool) error {
+	ctx, cancel := context.WithTimeout(ctx, ju.contextTimeout)
+	defer cancel()
+
+	if err := ju.jobRepository.SetPaused(ctx, name, paused, authUserID, time.Now()); err != nil {
+		return err
+	}
+
+	logrus.WithFields(logrus.Fields{"job": name, "paused": paused, "userID": authUserID}).Info("Job schedule changed")
+
+	return nil
+}
@@ -0,0 +1,126 @@
+package usecase
+
+import (
+	"context"
+	"slices"
+	"time"
+
+	"github.com/latiiLA/coop-forex-server/internal/common"
+	"github.com/latiiLA/coop-forex-server/internal/domain/model"
+	"github.com/sirupsen/logrus"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type NotificationUsecase interface {
+	GetRequestNotifications(ctx context.Context, requestID primitive.ObjectID) ([]model.Notification, error)
+	RetryNotification(ctx context.Context, authUserID primitive.ObjectID, notificationID primitive.ObjectID) error
+	PreviewNotification(ctx context.Context, authUserID primitive.ObjectID, event model.NotificationEvent, requestID *primitive.ObjectID) (*model.RenderedNotification, error)
+}
+
+type notificationUsecase struct {
+	notificationRepository model.NotificationRepository
+	requestRepository      model.RequestRepository
+	currencyRepository     model.CurrencyRepository
+	userRepository         model.UserRepository
+	notificationRenderer   model.NotificationRenderer
+	contextTimeout         time.Duration
+}
+
+func NewNotificationUsecase(notificationRepository model.NotificationRepository, requestRepository model.RequestRepository, currencyRepository model.CurrencyRepository, userRepository model.UserRepository, notificationRenderer model.NotificationRenderer, timeout time.Duration) NotificationUsecase {
+	return &notificationUsecase{
+		notificationRepository: notificationRepository,
+		requestRepository:      requestRepository,
+		currencyRepository:     currencyRepository,
+		userRepository:         userRepository,
+		notificationRenderer:   notificationRenderer,
+		contextTimeout:         timeout,
+	}
+}
+
+func (nu *notificationUsecase) GetRequestNotifications(ctx context.Context, requestID primitive.ObjectID) ([]model.Notification, error) {
+	ctx, cancel := context.WithTimeout(ctx, nu.contextTimeout)
+	defer cancel()
+
+	existingRequest, err := nu.requestRepository.FindByID(ctx, requestID, false)
+	if err != nil || existingRequest == nil {
+		return nil, common.ErrRequestNotFound
+	}
+
+	return nu.notificationRepository.FindByRequestID(ctx, requestID)
+}
+
+// RetryNotification gives a dead-lettered notification a fresh set of
+// delivery attempts, typically after the cause, such as a bad address or a
+// mail server outage, has been fixed.
+func (nu *notificationUsecase) RetryNotification(ctx context.Context, authUserID primitive.ObjectID, notificationID primitive.ObjectID) error {
+	ctx, cancel := context.WithTimeout(ctx, nu.contextTimeout)
+	defer cancel()
+
+	notification, err := nu.notificationRepository.FindByID(ctx, notificationID)
+	if err != nil {
+		return err
+	}
+	if notification == nil {
+		return common.ErrNotificationNotFound
+	}
+
+	if err := nu.notificationRepository.Requeue(ctx, notificationID, time.Now()); err != nil {
+		return err
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"notificationID": notificationID.Hex(),
+		"requestID":      notification.RequestID.Hex(),
+		"userID":         authUserID.Hex(),
+	}).Info("Dead-lettered notification requeued")
+
+	return nil
+}
+
+// PreviewNotification renders the templates of event for the request with
+// requestID as it is now, acted on by the caller, or for sample data when no
+// request is given.
+func (nu *notificationUsecase) PreviewNotification(ctx context.Context, authUserID primitive.ObjectID, event model.NotificationEvent, requestID *primitive.ObjectID) (*model.RenderedNotification, error) {
+	ctx, cancel := context.WithTimeout(ctx, nu.contextTimeout)
+	defer cancel()
+
+	if !slices.Contains(model.NotificationEvents, event) {
+		return nil, common.ErrUnknownNotificationEvent
+	}
+
+	if requestID == nil {
+		return nu.notificationRenderer.Render(event, model.SampleNotificationData(event))
+	}
+
+	existingRequest, err := nu.requestRepository.FindByID(ctx, *requestID, false)
+	if err != nil || existingRequest == nil {
+		return nil, common.ErrRequestNotFound
+	}
+
+	existingUser, err := nu.userRepository.FindByID(ctx, authUserID)
+	if err != nil {
+		return nil, common.ErrUnauthorized
+	}
+
+	codes, err := currencyCodes(ctx, nu.currencyRepository)
+	if err != nil {
+		return nil, err
+	}
+
+	return nu.notificationRenderer.Render(event, model.NewNotificationData(event, existingRequest, existingUser, codes, time.Now()))
+}
+
+// currencyCodes maps the ID of every currency to its short code.
+func currencyCodes(ctx context.Context, currencyRepository model.CurrencyRepository) (map[primitive.ObjectID]string, error) {
+	currencies, err := currencyRepository.FindAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	codes := make(map[primitive.ObjectID]string, len(currencies))
+	for _, currency := range currencies {
+		codes[currency.ID] = currency.ShortCode
+	}
+
+	return codes, nil
+}
@@ -0,0 +1,187 @@
+package usecase
+
+import (
+	"context"
+	"time"
+
+	"github.com/jinzhu/copier"
+	"github.com/latiiLA/coop-forex-server/internal/domain/model"
+	"github.com/sirupsen/logrus"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// expiredApprovalsBatch bounds how many overdue approvals one pass declines.
+const expiredApprovalsBatch = 100
+
+// scheduleSLA gives a request entering the status of update the due date of
+// the SLA policy of that stage and its travel purpose. Requests entering a
+// status without a policy have no due date.
+func (ru *requestUsecase) scheduleSLA(ctx context.Context, update *model.RequestUpdate, travelPurposeID primitive.ObjectID, at time.Time) error {
+	update.DueDate, update.SLA = nil, nil
+
+	stage := model.RequestStatus(update.RequestStatus)
+	if !model.IsSLAStage(stage) {
+		return nil
+	}
+
+	policies, err := ru.slaPolicyRepository.FindActive(ctx)
+	if err != nil {
+		return err
+	}
+
+	policy := model.SelectSLAPolicy(policies, stage, travelPurposeID)
+	if policy == nil {
+		return nil
+	}
+
+	due, sla := policy.Schedule(at)
+	update.DueDate = &due
+	update.SLA = sla
+
+	return nil
+}
+
+// CheckDeadlines reminds whoever a request waits for before it breaches its
+// SLA, escalates requests that breached it and declines approvals that were
+// not accepted in time. Every reminder and escalation is claimed before it
+// is sent, so running it on several instances sends each only once.
+func (ru *requestUsecase) CheckDeadlines(ctx context.Context) (*model.SLARun, error) {
+	run := &model.SLARun{}
+	now := time.Now()
+
+	for ctx.Err() == nil {
+		announced, err := ru.announceDeadline(ctx, now, ru.requestRepository.ClaimSLAReminder, model.NotifyRequestDueSoon, model.ReqEventSLAReminded)
+		if err != nil {
+			return run, err
+		}
+		if !announced {
+			break
+		}
+		run.Reminded++
+	}
+
+	for ctx.Err() == nil {
+		announced, err := ru.announceDeadline(ctx, now, ru.requestRepository.ClaimSLAEscalation, model.NotifyRequestOverdue, model.ReqEventSLAEscalated)
+		if err != nil {
+			return run, err
+		}
+		if !announced {
+			break
+		}
+		run.Escalated++
+	}
+
+	expired, err := ru.findExpiredApprovals(ctx, now)
+	if err != nil {
+		return run, err
+	}
+	for _, request := range expired {
+		if ctx.Err() != nil {
+			break
+		}
+		// Someone is working on it; the next pass declines it if they do not.
+		if request.IsLockedByOther(primitive.NilObjectID, now) {
+			continue
+		}
+
+		if err := ru.expireApproval(ctx, &request, now); err != nil {
+			logrus.WithError(err).WithField("requestID", request.ID.Hex()).Warn("Failed to decline expired approval")
+			continue
+		}
+		run.Declined++
+	}
+
+	return run, ctx.Err()
+}
+
+// announceDeadline claims one request with claim and notifies event about
+// it in the same transaction, so a claimed request is never left unannounced.
+// It reports false once nothing is left to claim.
+func (ru *requestUsecase) announceDeadline(ctx context.Context, now time.Time, claim func(ctx context.Context, now time.Time) (*model.Request, error), event model.NotificationEvent, eventType model.RequestEventType) (bool, error) {
+	ctx, cancel := context.WithTimeout(ctx, ru.contextTimeout)
+	defer cancel()
+
+	var claimed *model.Request
+	err := ru.inTransaction(ctx, func(ctx context.Context) error {
+		request, err := claim(ctx, now)
+		if err != nil || request == nil {
+			claimed = nil
+			return err
+		}
+		claimed = request
+
+		t, err := systemTransition(request, now)
+		if err != nil {
+			return err
+		}
+		if err := ru.notify(ctx, t, event); err != nil {
+			return err
+		}
+
+		return ru.saveNotifications(ctx, t)
+	})
+	if err != nil || claimed == nil {
+		return false, err
+	}
+
+	historyEvent := newRequestEvent(ctx, claimed.ID, primitive.NilObjectID, eventType)
+	historyEvent.ActorID = nil
+	historyEvent.FromStatus = claimed.RequestStatus
+	historyEvent.CreatedAt = now
+	ru.recordEvent(ctx, historyEvent)
+
+	return true, nil
+}
+
+func (ru *requestUsecase) findExpiredApprovals(ctx context.Context, now time.Time) ([]model.Request, error) {
+	ctx, cancel := context.WithTimeout(ctx, ru.contextTimeout)
+	defer cancel()
+
+	return ru.requestRepository.FindExpiredApprovals(ctx, now, expiredApprovalsBatch)
+}
+
+// expireApproval declines an approved request that was not accepted by its
+// due date and stamps SystemDeclinedAt.
+func (ru *requestUsecase) expireApproval(ctx context.Context, request *model.Request, now time.Time) error {
+	ctx, cancel := context.WithTimeout(ctx, ru.contextTimeout)
+	defer cancel()
+
+	t, err := systemTransition(request, now)
+	if err != nil {
+		return err
+	}
+	t.action = model.ReqActionExpire
+	t.reason = "Not accepted within its SLA"
+	t.update.RequestStatus = string(model.ReqStatusDeclined)
+	t.update.DeclinedAt = &now
+	t.update.SystemDeclinedAt = &now
+	t.update.UpdatedAt = now
+
+	// The notification still quotes the due date that was missed.
+	if err := ru.notify(ctx, t, model.NotifyRequestExpired); err != nil {
+		return err
+	}
+	t.update.DueDate, t.update.SLA = nil, nil
+
+	return ru.commitTransition(ctx, t)
+}
+
+// systemTransition starts a transition of request taken by the system, which
+// leaves the request as it is until the caller changes the update.
+func systemTransition(request *model.Request, at time.Time) (*requestTransition, error) {
+	before := model.RequestUpdate{}
+	update := model.RequestUpdate{}
+	if err := copier.Copy(&before, request); err != nil {
+		return nil, err
+	}
+	if err := copier.Copy(&update, request); err != nil {
+		return nil, err
+	}
+
+	return &requestTransition{
+		existing: request,
+		before:   before,
+		update:   &update,
+		at:       at,
+	}, nil
+}
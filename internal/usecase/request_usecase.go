@@ -2,63 +2,88 @@ package usecase
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"slices"
+	"strings"
 	"time"
 
 	"github.com/jinzhu/copier"
-	"github.com/latiiLA/coop-forex-server/configs"
 	"github.com/latiiLA/coop-forex-server/internal/common"
 	"github.com/latiiLA/coop-forex-server/internal/domain/model"
+	"github.com/latiiLA/coop-forex-server/internal/infrastructure/metrics"
 	"github.com/latiiLA/coop-forex-server/internal/infrastructure/utils"
 	"github.com/sirupsen/logrus"
-	log "github.com/sirupsen/logrus"
 	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
 )
 
 type RequestUsecase interface {
 	AddRequest(ctx context.Context, authUserID primitive.ObjectID, request *model.Request) error
 	UpdateRequest(ctx context.Context, authUserID primitive.ObjectID, requestID primitive.ObjectID, request *model.Request) error
-	GetAllRequests(ctx context.Context) ([]model.Request, error)
+	SearchRequests(ctx context.Context, authUserID primitive.ObjectID, search *model.RequestSearch) (*model.RequestPage, error)
 	ValidateRequest(ctx context.Context, authUserID primitive.ObjectID, request_id primitive.ObjectID, validated_currency_id primitive.ObjectID, request *model.RequestValidationDTO) error
 	ApproveRequest(ctx context.Context, authUserID primitive.ObjectID, request_id primitive.ObjectID, request *model.RequestApprovalDTO) error
-	GetAllOrgRequests(ctx context.Context, orgKey string, orgID primitive.ObjectID) ([]model.Request, error)
-	GetAuthorizedRequests(ctx context.Context) ([]model.Request, error)
-	GetNewOrgRequests(ctx context.Context, orgKey string, orgID primitive.ObjectID) ([]model.Request, error)
-	GetApprovedOrgRequests(ctx context.Context, orgKey string, orgID primitive.ObjectID) ([]model.Request, error)
-	GetRejectedOrgRequests(ctx context.Context, orgKey string, orgID primitive.ObjectID) ([]model.Request, error)
-	GetRejectedRequests(ctx context.Context) ([]model.Request, error)
-	GetAcceptedOrgRequests(ctx context.Context, orgKey string, orgID primitive.ObjectID) ([]model.Request, error)
-	GetDeclinedOrgRequests(ctx context.Context, orgKey string, orgID primitive.ObjectID) ([]model.Request, error)
-	GetAuthorizedOrgRequests(ctx context.Context, orgKey string, orgID primitive.ObjectID) ([]model.Request, error)
-	GetDraftedOrgRequests(ctx context.Context, orgKey string, orgID primitive.ObjectID) ([]model.Request, error)
 
 	AuthorizeOrgRequest(ctx context.Context, authUserID primitive.ObjectID, requestID primitive.ObjectID) error
 	RejectRequest(ctx context.Context, authUserID primitive.ObjectID, requestID primitive.ObjectID, rejection_reason string) error
-	LockRequest(ctx context.Context, authUserID primitive.ObjectID, requestID primitive.ObjectID) error
+	LockRequest(ctx context.Context, authUserID primitive.ObjectID, requestID primitive.ObjectID) (*time.Time, error)
+	RenewRequestLock(ctx context.Context, authUserID primitive.ObjectID, requestID primitive.ObjectID) (*time.Time, error)
 	UnLockRequest(ctx context.Context, authUserID primitive.ObjectID, requestID primitive.ObjectID) error
+	ForceUnlockRequest(ctx context.Context, authUserID primitive.ObjectID, requestID primitive.ObjectID) error
+	ReleaseExpiredLocks(ctx context.Context) (int64, error)
 
 	DeleteRequest(ctx context.Context, authUserID primitive.ObjectID, requestID primitive.ObjectID) error
 	AcceptRequest(ctx context.Context, authUserID primitive.ObjectID, requestID primitive.ObjectID, request *model.RequestAcceptanceDTO) error
 	SendRequest(ctx context.Context, authUserID primitive.ObjectID, requestID primitive.ObjectID) error
 	DeclineOrgRequest(ctx context.Context, authUserID primitive.ObjectID, requestID primitive.ObjectID) error
 
-	GetValidatedRequests(c context.Context) ([]model.Request, error)
-	GetApprovedRequests(c context.Context) ([]model.Request, error)
-	GetAcceptedRequests(c context.Context) ([]model.Request, error)
-	GetDeclinedRequests(c context.Context) ([]model.Request, error)
+	GetRequest(ctx context.Context, authUserID primitive.ObjectID, requestID primitive.ObjectID) (*model.Request, error)
+	GetRequestByCode(ctx context.Context, authUserID primitive.ObjectID, requestCode string) (*model.Request, error)
+	GetRequestHistory(ctx context.Context, authUserID primitive.ObjectID, requestID primitive.ObjectID) ([]model.RequestEvent, error)
+
+	CheckDeadlines(ctx context.Context) (*model.SLARun, error)
 }
 
 type requestUsecase struct {
-	requestRepository model.RequestRepository
-	userRepository    model.UserRepository
-	contextTimeout    time.Duration
+	requestRepository         model.RequestRepository
+	requestEventRepository    model.RequestEventRepository
+	exchangeRateRepository    model.ExchangeRateRepository
+	allocationLimitRepository model.AllocationLimitRepository
+	counterRepository         model.CounterRepository
+	branchRepository          model.BranchRepository
+	userRepository            model.UserRepository
+	notificationRepository    model.NotificationRepository
+	inboxRepository           model.InboxRepository
+	slaPolicyRepository       model.SLAPolicyRepository
+	currencyRepository        model.CurrencyRepository
+	notificationRenderer      model.NotificationRenderer
+	notificationRoutes        model.NotificationRoutes
+	client                    *mongo.Client
+	contextTimeout            time.Duration
+	lockTTL                   time.Duration
+	duplicatePolicy           model.DuplicatePolicy
 }
 
-func NewRequestUsecase(requestRepository model.RequestRepository, userRepository model.UserRepository, timeout time.Duration) RequestUsecase {
+func NewRequestUsecase(requestRepository model.RequestRepository, requestEventRepository model.RequestEventRepository, exchangeRateRepository model.ExchangeRateRepository, allocationLimitRepository model.AllocationLimitRepository, counterRepository model.CounterRepository, branchRepository model.BranchRepository, userRepository model.UserRepository, notificationRepository model.NotificationRepository, inboxRepository model.InboxRepository, slaPolicyRepository model.SLAPolicyRepository, currencyRepository model.CurrencyRepository, notificationRenderer model.NotificationRenderer, notificationRoutes model.NotificationRoutes, timeout time.Duration, lockTTL time.Duration, duplicatePolicy model.DuplicatePolicy, client *mongo.Client) RequestUsecase {
 	return &requestUsecase{
-		requestRepository: requestRepository,
-		userRepository:    userRepository,
-		contextTimeout:    timeout,
+		requestRepository:         requestRepository,
+		requestEventRepository:    requestEventRepository,
+		exchangeRateRepository:    exchangeRateRepository,
+		allocationLimitRepository: allocationLimitRepository,
+		counterRepository:         counterRepository,
+		branchRepository:          branchRepository,
+		userRepository:            userRepository,
+		notificationRepository:    notificationRepository,
+		inboxRepository:           inboxRepository,
+		slaPolicyRepository:       slaPolicyRepository,
+		currencyRepository:        currencyRepository,
+		notificationRenderer:      notificationRenderer,
+		notificationRoutes:        notificationRoutes,
+		client:                    client,
+		contextTimeout:            timeout,
+		lockTTL:                   lockTTL,
+		duplicatePolicy:           duplicatePolicy,
 	}
 }
 
@@ -82,21 +107,66 @@ func (ru *requestUsecase) AddRequest(ctx context.Context, authUserID primitive.O
 		request.DepartmentID = existingUser.Profile.DepartmentID
 	}
 
-	request.RequestCode = utils.GenerateRequestCode()
+	if request.ID.IsZero() {
+		request.ID = primitive.NewObjectID()
+	}
 	request.CreatedAt = time.Now()
-	request.UpdatedAt = time.Now()
+	request.UpdatedAt = request.CreatedAt
+	request.RequestCode, err = ru.nextRequestCode(ctx, request.BranchID, request.CreatedAt)
+	if err != nil {
+		return err
+	}
 	request.CreatedBy = authUserID
 	request.RequestStatus = model.ReqStatusDrafted
 	request.IsDeleted = false
 
+	check, err := ru.checkDuplicates(ctx, request, model.ReqActionCreate, request.CreatedAt)
+	if err != nil {
+		return err
+	}
+	request.DuplicateCheck = check
+
 	err = ru.requestRepository.Create(ctx, request)
 	if err != nil {
 		return err
 	}
 
+	event := newRequestEvent(ctx, request.ID, authUserID, model.ReqEventCreated)
+	event.ToStatus = request.RequestStatus
+	ru.recordEvent(ctx, event)
+	metrics.RecordTransition(model.ReqActionCreate, "", request.RequestStatus, ru.branchLabel(ctx, request.BranchID))
+	ru.recordDuplicateCheck(ctx, request.ID, authUserID, check)
+
 	return nil
 }
 
+// nextRequestCode numbers a new request within its branch and year. Requests
+// raised by head office departments, or by branches without a code, share
+// one sequence. A request cannot be raised for a branch that no longer exists.
+func (ru *requestUsecase) nextRequestCode(ctx context.Context, branchID *primitive.ObjectID, at time.Time) (string, error) {
+	orgCode := utils.HeadOfficeRequestCode
+	if branchID != nil {
+		branch, err := ru.branchRepository.FindByID(ctx, *branchID)
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return "", common.ErrBranchNotFound
+		}
+		if err != nil {
+			return "", err
+		}
+		if branch.BranchCode != "" {
+			orgCode = branch.BranchCode
+		}
+	}
+
+	year := at.UTC().Year()
+	seq, err := ru.counterRepository.Next(ctx, utils.RequestCodeCounter(orgCode, year))
+	if err != nil {
+		return "", err
+	}
+
+	return utils.FormatRequestCode(orgCode, year, seq), nil
+}
+
 func (ru *requestUsecase) UpdateRequest(ctx context.Context, authUserID primitive.ObjectID, requestID primitive.ObjectID, request *model.Request) error {
 	ctx, cancel := context.WithTimeout(ctx, ru.contextTimeout)
 	defer cancel()
@@ -110,11 +180,19 @@ func (ru *requestUsecase) UpdateRequest(ctx context.Context, authUserID primitiv
 
 	// 2. Get existing request
 	existingRequest, err := ru.requestRepository.FindByID(ctx, requestID, false)
-	if err != nil {
+	if err != nil || existingRequest == nil {
 		logrus.WithError(err).WithField("requestID", requestID).Error("Failed to find request")
 		return common.ErrRequestNotFound
 	}
 
+	if !existingRequest.RequestStatus.IsEditable() {
+		return common.ErrRequestNotEditable
+	}
+
+	if existingRequest.IsLockedByOther(authUserID, time.Now()) {
+		return common.ErrRequestIsLocked
+	}
+
 	// 3. Authorization check - fixed logical condition
 	if existingUser.Profile.BranchID != nil && existingRequest.BranchID != nil &&
 		*existingUser.Profile.BranchID != *existingRequest.BranchID {
@@ -139,9 +217,11 @@ func (ru *requestUsecase) UpdateRequest(ctx context.Context, authUserID primitiv
 	}
 
 	// 4. Create update object
+	before := model.RequestUpdate{}
 	forexRequest := model.RequestUpdate{}
 
 	// Copy existing request data
+	copier.Copy(&before, existingRequest)
 	copier.Copy(&forexRequest, existingRequest)
 
 	// 5. Apply updates
@@ -158,6 +238,7 @@ func (ru *requestUsecase) UpdateRequest(ctx context.Context, authUserID primitiv
 	forexRequest.TravelPurposeID = request.TravelPurposeID
 	forexRequest.TravelCountryID = request.TravelCountryID
 	forexRequest.RequestingAs = request.RequestingAs
+	forexRequest.CustomerTypeID = request.CustomerTypeID
 	forexRequest.AccountCurrencyID = request.AccountCurrencyID
 	forexRequest.FcyRequestedID = request.FcyRequestedID
 
@@ -192,11 +273,32 @@ func (ru *requestUsecase) UpdateRequest(ctx context.Context, authUserID primitiv
 		forexRequest.DepartmentID = existingUser.Profile.DepartmentID
 	}
 
-	if err := ru.requestRepository.Update(ctx, requestID, &forexRequest); err != nil {
+	if err := ru.requestRepository.UpdateIfUnchanged(ctx, requestID, authUserID, existingRequest.RequestStatus, existingRequest.Version, &forexRequest); err != nil {
 		logrus.WithError(err).WithField("requestID", requestID).Error("Failed to update request")
 		return err
 	}
 
+	var edits, attachments []model.RequestFieldChange
+	for _, change := range model.DiffRequestUpdates(&before, &forexRequest) {
+		if model.IsAttachmentField(change.Field) {
+			attachments = append(attachments, change)
+		} else {
+			edits = append(edits, change)
+		}
+	}
+
+	if len(edits) > 0 {
+		event := newRequestEvent(ctx, requestID, authUserID, model.ReqEventEdited)
+		event.Changes = edits
+		ru.recordEvent(ctx, event)
+	}
+
+	if len(attachments) > 0 {
+		event := newRequestEvent(ctx, requestID, authUserID, model.ReqEventAttachmentChanged)
+		event.Changes = attachments
+		ru.recordEvent(ctx, event)
+	}
+
 	logrus.WithFields(logrus.Fields{
 		"requestID": requestID,
 		"userID":    authUserID,
@@ -205,554 +307,1114 @@ func (ru *requestUsecase) UpdateRequest(ctx context.Context, authUserID primitiv
 	return nil
 }
 
-func (ru *requestUsecase) GetAllRequests(ctx context.Context) ([]model.Request, error) {
-	ctx, cancel := context.WithTimeout(ctx, ru.contextTimeout)
-	defer cancel()
-
-	return ru.requestRepository.FindAll(ctx, true)
+// Org Related Fetches
+// requestTransition carries a request through one step of the workflow: the
+// stored request, the actor performing the step, nil when it is the system,
+// the update document already stamped for the target status and the
+// notifications announcing it.
+type requestTransition struct {
+	action        model.RequestAction
+	existing      *model.Request
+	before        model.RequestUpdate
+	update        *model.RequestUpdate
+	actor         *model.User
+	at            time.Time
+	reason        string
+	notifications []*model.Notification
+	inbox         []*model.InboxItem
 }
 
-func (ru *requestUsecase) GetAuthorizedRequests(ctx context.Context) ([]model.Request, error) {
-	ctx, cancel := context.WithTimeout(ctx, ru.contextTimeout)
-	defer cancel()
+// beginTransition loads the request and the acting user and checks the
+// workflow rules for action before any change is made.
+func (ru *requestUsecase) beginTransition(ctx context.Context, authUserID primitive.ObjectID, requestID primitive.ObjectID, action model.RequestAction) (*requestTransition, error) {
+	transition, ok := model.RequestTransitions[action]
+	if !ok {
+		return nil, fmt.Errorf("unknown request action %q", action)
+	}
 
-	return ru.requestRepository.FindByRequestStatus(ctx, string(model.ReqStatusAuthorized), true)
-}
+	existingRequest, err := ru.requestRepository.FindByID(ctx, requestID, false)
+	if err != nil || existingRequest == nil {
+		return nil, common.ErrRequestNotFound
+	}
 
-func (ru *requestUsecase) GetValidatedRequests(ctx context.Context) ([]model.Request, error) {
-	ctx, cancel := context.WithTimeout(ctx, ru.contextTimeout)
-	defer cancel()
+	actor, err := ru.userRepository.FindByID(ctx, authUserID)
+	if err != nil {
+		return nil, common.ErrUnauthorized
+	}
 
-	return ru.requestRepository.FindByRequestStatus(ctx, string(model.ReqStatusValidated), true)
-}
+	if !hasPermission(actor, transition.Permission) {
+		logrus.WithFields(logrus.Fields{
+			"userID":     authUserID,
+			"requestID":  requestID,
+			"action":     action,
+			"permission": transition.Permission,
+		}).Warn("Request transition attempted without permission")
+		return nil, common.ErrUnauthorized
+	}
 
-func (ru *requestUsecase) GetApprovedRequests(ctx context.Context) ([]model.Request, error) {
-	ctx, cancel := context.WithTimeout(ctx, ru.contextTimeout)
-	defer cancel()
+	now := time.Now()
+	if existingRequest.IsLockedByOther(authUserID, now) {
+		return nil, common.ErrRequestIsLocked
+	}
 
-	return ru.requestRepository.FindByRequestStatus(ctx, string(model.ReqStatusApproved), true)
-}
+	if !transition.Allows(existingRequest.RequestStatus) {
+		return nil, &common.StatusTransitionError{
+			Action: string(action),
+			From:   string(existingRequest.RequestStatus),
+			To:     string(transition.To),
+		}
+	}
 
-func (ru *requestUsecase) GetAcceptedRequests(ctx context.Context) ([]model.Request, error) {
-	ctx, cancel := context.WithTimeout(ctx, ru.contextTimeout)
-	defer cancel()
+	before := model.RequestUpdate{}
+	forexRequest := model.RequestUpdate{}
+	copier.Copy(&before, existingRequest)
+	copier.Copy(&forexRequest, existingRequest)
 
-	return ru.requestRepository.FindByRequestStatus(ctx, string(model.ReqStatusAccepted), true)
-}
+	transition.Apply(&forexRequest, authUserID, now)
 
-func (ru *requestUsecase) GetDeclinedRequests(ctx context.Context) ([]model.Request, error) {
-	ctx, cancel := context.WithTimeout(ctx, ru.contextTimeout)
-	defer cancel()
+	if err := ru.scheduleSLA(ctx, &forexRequest, existingRequest.TravelPurposeID, now); err != nil {
+		return nil, err
+	}
 
-	return ru.requestRepository.FindByRequestStatus(ctx, string(model.ReqStatusDeclined), true)
+	return &requestTransition{
+		action:   action,
+		existing: existingRequest,
+		before:   before,
+		update:   &forexRequest,
+		actor:    actor,
+		at:       now,
+	}, nil
 }
 
-// Org Related Fetches
-func (ru *requestUsecase) GetAllOrgRequests(ctx context.Context, orgKey string, orgID primitive.ObjectID) ([]model.Request, error) {
-	ctx, cancel := context.WithTimeout(ctx, ru.contextTimeout)
-	defer cancel()
+// commitTransition persists the transition only if nobody else changed the
+// request since it was read, and appends it to the request history. Queued
+// notifications and inbox items are written in the same transaction, so none
+// is lost or sent for a change that did not happen.
+func (ru *requestUsecase) commitTransition(ctx context.Context, t *requestTransition) error {
+	// The system holds no lock, so it only changes requests nobody holds.
+	actorID := primitive.NilObjectID
+	if t.actor != nil {
+		actorID = t.actor.ID
+	}
+
+	update := func(ctx context.Context) error {
+		err := ru.requestRepository.UpdateIfUnchanged(ctx, t.existing.ID, actorID, t.existing.RequestStatus, t.existing.Version, t.update)
+		if err != nil {
+			return err
+		}
 
-	return ru.requestRepository.FindAllByOrgID(ctx, orgKey, orgID, true)
+		return ru.saveNotifications(ctx, t)
+	}
+
+	var err error
+	if len(t.notifications) == 0 && len(t.inbox) == 0 {
+		err = update(ctx)
+	} else {
+		err = ru.inTransaction(ctx, update)
+	}
+	if err != nil {
+		return err
+	}
+
+	event := newRequestEvent(ctx, t.existing.ID, actorID, model.ReqEventTransitioned)
+	if t.actor == nil {
+		event.ActorID = nil
+	}
+	event.Action = t.action
+	event.FromStatus = t.existing.RequestStatus
+	event.ToStatus = model.RequestStatus(t.update.RequestStatus)
+	event.Changes = model.DiffRequestUpdates(&t.before, t.update)
+	event.Reason = t.reason
+	event.CreatedAt = t.at
+	ru.recordEvent(ctx, event)
+	ru.recordTransitionMetrics(ctx, t)
+
+	return nil
 }
 
-func (ru *requestUsecase) GetNewOrgRequests(ctx context.Context, orgKey string, orgID primitive.ObjectID) ([]model.Request, error) {
-	ctx, cancel := context.WithTimeout(ctx, ru.contextTimeout)
-	defer cancel()
+// recordTransitionMetrics counts the transition and the foreign currency it
+// approved or accepted. A failed lookup only leaves a label unresolved.
+func (ru *requestUsecase) recordTransitionMetrics(ctx context.Context, t *requestTransition) {
+	to := model.RequestStatus(t.update.RequestStatus)
+	metrics.RecordTransition(t.action, t.existing.RequestStatus, to, ru.branchLabel(ctx, t.existing.BranchID))
+
+	var currencyIDs []primitive.ObjectID
+	var amounts []float64
+	switch to {
+	case model.ReqStatusApproved:
+		currencyIDs, amounts = t.update.ApprovedCurrencyIDs, t.update.ApprovedAmounts
+	case model.ReqStatusAccepted:
+		currencyIDs, amounts = t.update.AcceptedCurrencyIDs, t.update.AcceptedAmounts
+	default:
+		return
+	}
 
-	return ru.requestRepository.FindOrgByRequestStatus(ctx, orgID, orgKey, string(model.ReqStatusNew), true)
+	codes, err := currencyCodes(ctx, ru.currencyRepository)
+	if err != nil {
+		logrus.WithError(err).Warn("Failed to look up currencies for metrics")
+	}
+	for i, currencyID := range currencyIDs {
+		if i >= len(amounts) {
+			break
+		}
+		code, ok := codes[currencyID]
+		if !ok {
+			code = currencyID.Hex()
+		}
+		metrics.AddFCYVolume(to, code, amounts[i])
+	}
 }
 
-func (ru *requestUsecase) GetApprovedOrgRequests(ctx context.Context, orgKey string, orgID primitive.ObjectID) ([]model.Request, error) {
-	ctx, cancel := context.WithTimeout(ctx, ru.contextTimeout)
-	defer cancel()
+// branchLabel names a branch in metrics by its code.
+func (ru *requestUsecase) branchLabel(ctx context.Context, branchID *primitive.ObjectID) string {
+	if branchID == nil {
+		return "none"
+	}
 
-	return ru.requestRepository.FindOrgByRequestStatus(ctx, orgID, orgKey, string(model.ReqStatusApproved), true)
+	branch, err := ru.branchRepository.FindByID(ctx, *branchID)
+	if err != nil || branch == nil {
+		return branchID.Hex()
+	}
+
+	return branch.BranchCode
 }
 
-func (ru *requestUsecase) GetAcceptedOrgRequests(ctx context.Context, orgKey string, orgID primitive.ObjectID) ([]model.Request, error) {
-	ctx, cancel := context.WithTimeout(ctx, ru.contextTimeout)
-	defer cancel()
+// saveNotifications stores the notifications and inbox items queued for t.
+func (ru *requestUsecase) saveNotifications(ctx context.Context, t *requestTransition) error {
+	for _, notification := range t.notifications {
+		if err := ru.notificationRepository.Create(ctx, notification); err != nil {
+			return err
+		}
+	}
 
-	return ru.requestRepository.FindOrgByRequestStatus(ctx, orgID, orgKey, string(model.ReqStatusAccepted), true)
+	return ru.inboxRepository.CreateMany(ctx, t.inbox)
 }
 
-func (ru *requestUsecase) GetDeclinedOrgRequests(ctx context.Context, orgKey string, orgID primitive.ObjectID) ([]model.Request, error) {
-	ctx, cancel := context.WithTimeout(ctx, ru.contextTimeout)
-	defer cancel()
+// inTransaction runs fn in a MongoDB transaction, retrying it on transient
+// errors.
+func (ru *requestUsecase) inTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	session, err := ru.client.StartSession()
+	if err != nil {
+		return err
+	}
+	defer session.EndSession(ctx)
+
+	_, err = session.WithTransaction(ctx, func(sessCtx mongo.SessionContext) (interface{}, error) {
+		return nil, fn(sessCtx)
+	})
 
-	return ru.requestRepository.FindOrgByRequestStatus(ctx, orgID, orgKey, string(model.ReqStatusDeclined), true)
+	return err
 }
 
-func (ru *requestUsecase) GetAuthorizedOrgRequests(ctx context.Context, orgKey string, orgID primitive.ObjectID) ([]model.Request, error) {
-	ctx, cancel := context.WithTimeout(ctx, ru.contextTimeout)
-	defer cancel()
+// notify announces t as event: it is added to the inbox of the users
+// concerned and emailed to the configured addresses plus the recipients
+// resolved from the request. Both are stored when the transition is
+// committed.
+func (ru *requestUsecase) notify(ctx context.Context, t *requestTransition, event model.NotificationEvent) error {
+	rendered, err := ru.renderNotification(ctx, t, event)
+	if err != nil {
+		return err
+	}
+
+	route := ru.notificationRoutes[event]
+	audience := ru.resolveAudience(ctx, t, event, append(append([]model.NotificationRecipient{}, route.Dynamic...), model.InboxRecipients[event]...))
+
+	ru.queueInbox(t, event, rendered, audience)
+	ru.queueEmail(ctx, t, event, rendered, route, audience)
 
-	return ru.requestRepository.FindOrgByRequestStatus(ctx, orgID, orgKey, string(model.ReqStatusAuthorized), true)
+	return nil
 }
 
-func (ru *requestUsecase) GetDraftedOrgRequests(ctx context.Context, orgKey string, orgID primitive.ObjectID) ([]model.Request, error) {
-	ctx, cancel := context.WithTimeout(ctx, ru.contextTimeout)
-	defer cancel()
+// renderNotification renders the templates of event for the request as the
+// transition leaves it.
+func (ru *requestUsecase) renderNotification(ctx context.Context, t *requestTransition, event model.NotificationEvent) (*model.RenderedNotification, error) {
+	after := *t.existing
+	if err := copier.CopyWithOption(&after, t.update, copier.Option{DeepCopy: true}); err != nil {
+		return nil, err
+	}
+
+	codes, err := currencyCodes(ctx, ru.currencyRepository)
+	if err != nil {
+		return nil, err
+	}
 
-	return ru.requestRepository.FindOrgByRequestStatus(ctx, orgID, orgKey, string(model.ReqStatusDrafted), true)
+	return ru.notificationRenderer.Render(event, model.NewNotificationData(event, &after, t.actor, codes, t.at))
 }
 
-func (ru *requestUsecase) GetRejectedOrgRequests(ctx context.Context, orgKey string, orgID primitive.ObjectID) ([]model.Request, error) {
-	ctx, cancel := context.WithTimeout(ctx, ru.contextTimeout)
-	defer cancel()
+// notificationAudience is who a transition concerns beyond the configured
+// addresses.
+type notificationAudience struct {
+	branchEmail string
+	users       map[model.NotificationRecipient][]model.User
+}
+
+// resolveAudience looks up the recipients worked out from the request. A
+// lookup that fails is logged and skipped, so a missing branch or user never
+// blocks the transition itself.
+func (ru *requestUsecase) resolveAudience(ctx context.Context, t *requestTransition, event model.NotificationEvent, recipients []model.NotificationRecipient) *notificationAudience {
+	audience := &notificationAudience{users: make(map[model.NotificationRecipient][]model.User)}
 
-	return ru.requestRepository.FindOrgByRequestStatus(ctx, orgID, orgKey, string(model.ReqStatusRejected), true)
+	for _, recipient := range recipients {
+		if _, done := audience.users[recipient]; done {
+			continue
+		}
+
+		var (
+			users []model.User
+			err   error
+		)
+
+		switch recipient {
+		case model.RecipientBranch:
+			audience.branchEmail, err = ru.branchEmail(ctx, t)
+		case model.RecipientRequester:
+			users, err = ru.requester(ctx, t)
+		case model.RecipientNextStep:
+			users, err = ru.stepUsers(ctx, t, func(step model.NotificationNextStep) string { return step.Permission })
+		case model.RecipientEscalation:
+			users, err = ru.stepUsers(ctx, t, func(model.NotificationNextStep) string { return model.RequestEscalationPermission })
+		}
+		if err != nil {
+			logrus.WithError(err).WithFields(logrus.Fields{
+				"requestID": t.existing.ID.Hex(),
+				"event":     event,
+				"recipient": recipient,
+			}).Warn("Failed to resolve notification recipients")
+		}
+
+		audience.users[recipient] = users
+	}
+
+	return audience
 }
 
-func (ru *requestUsecase) GetRejectedRequests(ctx context.Context) ([]model.Request, error) {
-	ctx, cancel := context.WithTimeout(ctx, ru.contextTimeout)
-	defer cancel()
+// branchEmail is the mailbox of the branch that raised the request. Requests
+// raised by head office departments have none.
+func (ru *requestUsecase) branchEmail(ctx context.Context, t *requestTransition) (string, error) {
+	branch := t.existing.Branch
+	if branch == nil {
+		if t.existing.BranchID == nil {
+			return "", nil
+		}
+
+		var err error
+		branch, err = ru.branchRepository.FindByID(ctx, *t.existing.BranchID)
+		if err != nil {
+			return "", err
+		}
+	}
 
-	return ru.requestRepository.FindByRequestStatus(ctx, string(model.ReqStatusRejected), true)
+	return branch.Email, nil
 }
 
-// Request operations
-func (ru *requestUsecase) AuthorizeOrgRequest(ctx context.Context, authUserID primitive.ObjectID, requestID primitive.ObjectID) error {
-	ctx, cancel := context.WithTimeout(ctx, ru.contextTimeout)
-	defer cancel()
+// requester is the user who sent the request, or the one who created it
+// while it was never sent.
+func (ru *requestUsecase) requester(ctx context.Context, t *requestTransition) ([]model.User, error) {
+	requesterID := t.existing.CreatedBy
+	if t.update.RequestedBy != nil {
+		requesterID = *t.update.RequestedBy
+	}
 
-	existingRequest, err := ru.requestRepository.FindByID(ctx, requestID, false)
+	if t.actor != nil && requesterID == t.actor.ID {
+		return []model.User{*t.actor}, nil
+	}
+
+	requester, err := ru.userRepository.FindByID(ctx, requesterID)
 	if err != nil {
-		return common.ErrRequestNotFound
+		return nil, err
 	}
 
-	// 4. Create update object
-	forexRequest := model.RequestUpdate{}
+	return []model.User{*requester}, nil
+}
 
-	// Copy existing request data
-	copier.Copy(&forexRequest, existingRequest)
+// stepUsers are the users holding the permission picked from the step the
+// request waits for once t is done. Steps taken within the branch or
+// department only concern users of the request's own branch or department.
+func (ru *requestUsecase) stepUsers(ctx context.Context, t *requestTransition, permission func(step model.NotificationNextStep) string) ([]model.User, error) {
+	step, ok := model.RequestNextSteps[model.RequestStatus(t.update.RequestStatus)]
+	if !ok {
+		return nil, nil
+	}
 
-	now := time.Now()
-	forexRequest.AuthorizedAt = &now
-	forexRequest.AuthorizedBy = &authUserID
-	forexRequest.RequestStatus = string(model.ReqStatusAuthorized)
+	var branchID, departmentID *primitive.ObjectID
+	if step.WithinOrg {
+		branchID, departmentID = t.existing.BranchID, t.existing.DepartmentID
+		if branchID == nil && departmentID == nil {
+			return nil, nil
+		}
+	}
 
-	// Fetch sender
-	sender, err := ru.userRepository.FindByID(ctx, authUserID)
-	if err != nil {
-		return err
+	return ru.userRepository.FindByPermission(ctx, permission(step), branchID, departmentID)
+}
+
+// queueInbox adds the notification to the inbox of the users concerned by
+// event, except the actor.
+func (ru *requestUsecase) queueInbox(t *requestTransition, event model.NotificationEvent, rendered *model.RenderedNotification, audience *notificationAudience) {
+	seen := map[primitive.ObjectID]bool{}
+	item := model.InboxItem{
+		RequestID:   t.existing.ID,
+		RequestCode: t.existing.RequestCode,
+		Event:       event,
+		Title:       rendered.Subject,
+		Status:      model.RequestStatus(t.update.RequestStatus),
+		CreatedAt:   t.at,
+	}
+	if t.actor != nil {
+		seen[t.actor.ID] = true
+		item.ActorID = &t.actor.ID
+		item.ActorName = model.UserDisplayName(t.actor)
 	}
 
-	// First update DB
-	err = ru.requestRepository.Update(ctx, requestID, &forexRequest)
-	if err != nil {
-		return err
+	for _, recipient := range model.InboxRecipients[event] {
+		for _, user := range audience.users[recipient] {
+			if seen[user.ID] {
+				continue
+			}
+			seen[user.ID] = true
+
+			userItem := item
+			userItem.UserID = user.ID
+			t.inbox = append(t.inbox, &userItem)
+		}
 	}
+}
 
-	to := append([]string{}, configs.MailRequestAuthorizedTo...)
-	cc := append([]string{}, configs.MailRequestAuthorizedCc...)
-	bcc := append([]string{}, configs.MailRequestAuthorizedBcc...)
+// queueEmail adds the email announcing t to the outbox, addressed to route.
+// Nothing is queued when nobody is left to send it to.
+func (ru *requestUsecase) queueEmail(ctx context.Context, t *requestTransition, event model.NotificationEvent, rendered *model.RenderedNotification, route model.NotificationRoute, audience *notificationAudience) {
+	to := append([]string{}, route.To...)
+	for _, recipient := range route.Dynamic {
+		if recipient == model.RecipientBranch {
+			to = append(to, audience.branchEmail)
+			continue
+		}
 
-	if sender.Profile.Branch != nil && sender.Profile.Branch.Email != "" {
-		to = append(to, sender.Profile.Branch.Email)
+		for _, user := range audience.users[recipient] {
+			if user.Profile != nil {
+				to = append(to, user.Profile.Email)
+			}
+		}
+	}
+
+	to, cc, bcc := uniqueAddresses(to, route.Cc, route.Bcc)
+	if len(to) == 0 && len(cc) == 0 && len(bcc) == 0 {
+		logrus.WithFields(logrus.Fields{
+			"requestID": t.existing.ID.Hex(),
+			"event":     event,
+		}).Warn("No recipients for request notification")
+		return
 	}
 
-	if sender.Profile != nil && sender.Profile.Email != "" {
-		to = append(to, sender.Profile.Email)
+	notification := &model.Notification{
+		RequestID:     t.existing.ID,
+		RequestCode:   t.existing.RequestCode,
+		Event:         event,
+		Channel:       model.NotificationChannelEmail,
+		To:            to,
+		Cc:            cc,
+		Bcc:           bcc,
+		Subject:       rendered.Subject,
+		Body:          rendered.HTML,
+		TextBody:      rendered.Text,
+		Status:        model.NotificationPending,
+		NextAttemptAt: t.at,
+		TraceID:       utils.GetTraceID(ctx),
+		CreatedAt:     t.at,
+		UpdatedAt:     t.at,
+	}
+	if t.actor != nil {
+		notification.CreatedBy = &t.actor.ID
 	}
 
-	// Then send email (fail-safe)
-	subject := "Foreign Currency Request"
-	body := fmt.Sprintf("A new fcy request with request code %s has been created at %s.", forexRequest.RequestCode, now.Format("2006-01-02 15:04:05"))
+	t.notifications = append(t.notifications, notification)
+}
 
-	go func() {
-		err := utils.SendEmail(to, cc, bcc, subject, body, *existingRequest)
-		if err != nil {
-			log.Warnf("Failed to send email for request %s: %v", requestID.Hex(), err)
+// uniqueAddresses drops blank and repeated addresses, comparing them without
+// regard to case, so nobody receives the same notification twice. An address
+// is kept in the first of to, cc and bcc it appears in.
+func uniqueAddresses(to, cc, bcc []string) ([]string, []string, []string) {
+	seen := make(map[string]bool)
+	unique := func(addresses []string) []string {
+		var kept []string
+		for _, address := range addresses {
+			address = strings.TrimSpace(address)
+			key := strings.ToLower(address)
+			if address == "" || seen[key] {
+				continue
+			}
+			seen[key] = true
+			kept = append(kept, address)
 		}
-	}()
+		return kept
+	}
 
-	return nil
+	to = unique(to)
+	cc = unique(cc)
+	bcc = unique(bcc)
+
+	return to, cc, bcc
 }
 
-func (ru *requestUsecase) ValidateRequest(ctx context.Context, authUserID primitive.ObjectID, request_id primitive.ObjectID, validated_account_currency_id primitive.ObjectID, request *model.RequestValidationDTO) error {
+// newRequestEvent starts a history entry carrying the client IP and trace ID
+// of the HTTP request behind ctx.
+func newRequestEvent(ctx context.Context, requestID primitive.ObjectID, actorID primitive.ObjectID, eventType model.RequestEventType) *model.RequestEvent {
+	return &model.RequestEvent{
+		RequestID: requestID,
+		Type:      eventType,
+		ActorID:   &actorID,
+		ActorIP:   utils.GetContextClientIP(ctx),
+		TraceID:   utils.GetTraceID(ctx),
+		CreatedAt: time.Now(),
+	}
+}
+
+// recordEvent appends event to the request history. A failure is logged
+// rather than returned because the change it describes is already stored.
+func (ru *requestUsecase) recordEvent(ctx context.Context, event *model.RequestEvent) {
+	if err := ru.requestEventRepository.Create(ctx, event); err != nil {
+		logrus.WithError(err).WithFields(logrus.Fields{
+			"requestID": event.RequestID,
+			"type":      event.Type,
+			"traceID":   event.TraceID,
+		}).Error("Failed to record request event")
+	}
+}
+
+func hasPermission(user *model.User, permission string) bool {
+	var rolePerms []string
+	if user.Role != nil {
+		rolePerms = user.Role.Permissions
+	}
+
+	return slices.Contains(utils.MergePermissions(rolePerms, user.Permissions), permission)
+}
+
+// Request operations
+func (ru *requestUsecase) AuthorizeOrgRequest(ctx context.Context, authUserID primitive.ObjectID, requestID primitive.ObjectID) error {
 	ctx, cancel := context.WithTimeout(ctx, ru.contextTimeout)
 	defer cancel()
 
-	forexRequest, err := ru.requestRepository.FindByID(ctx, request_id, false)
+	t, err := ru.beginTransition(ctx, authUserID, requestID, model.ReqActionAuthorize)
 	if err != nil {
-		return common.ErrRequestNotFound
+		return err
 	}
 
-	if forexRequest.LockedBy != nil && *forexRequest.LockedBy != authUserID {
-		return common.ErrRequestIsLocked
+	err = ru.notify(ctx, t, model.NotifyRequestAuthorized)
+	if err != nil {
+		return err
 	}
 
-	forexRequest.ValidatedBy = &authUserID
-	now := time.Now()
-	forexRequest.ValidatedAt = &now
+	return ru.commitTransition(ctx, t)
+}
+
+func (ru *requestUsecase) ValidateRequest(ctx context.Context, authUserID primitive.ObjectID, request_id primitive.ObjectID, validated_account_currency_id primitive.ObjectID, request *model.RequestValidationDTO) error {
+	ctx, cancel := context.WithTimeout(ctx, ru.contextTimeout)
+	defer cancel()
+
+	t, err := ru.beginTransition(ctx, authUserID, request_id, model.ReqActionValidate)
+	if err != nil {
+		return err
+	}
+
+	forexRequest := t.update
 	forexRequest.ValidatedCurrentBalance = &request.ValidatedCurrentBalance
 	forexRequest.ValidatedAverageDeposit = &request.ValidatedAverageDeposit
 	forexRequest.ValidatedAccountCurrencyID = &validated_account_currency_id
-	forexRequest.RequestStatus = model.ReqStatusValidated
 
-	return ru.requestRepository.Validate(ctx, request_id, forexRequest)
+	err = ru.notify(ctx, t, model.NotifyRequestValidated)
+	if err != nil {
+		return err
+	}
+
+	return ru.commitTransition(ctx, t)
 }
 
 func (ru *requestUsecase) ApproveRequest(ctx context.Context, authUserID primitive.ObjectID, requestID primitive.ObjectID, request *model.RequestApprovalDTO) error {
 	ctx, cancel := context.WithTimeout(ctx, ru.contextTimeout)
 	defer cancel()
 
-	existingRequest, err := ru.requestRepository.FindByID(ctx, requestID, false)
+	t, err := ru.beginTransition(ctx, authUserID, requestID, model.ReqActionApprove)
 	if err != nil {
-		return common.ErrRequestNotFound
+		return err
 	}
 
-	// Copy the data
-	forexRequest := model.RequestUpdate{}
-	copier.Copy(&forexRequest, existingRequest)
-
-	if forexRequest.LockedBy != nil && *forexRequest.LockedBy != authUserID {
-		return common.ErrRequestIsLocked
+	if !sameLength(len(request.ApprovedCurrencyIDs), request.ApprovedAmounts, request.ApprovedAmountInCash, request.ApprovedAmountInCard) {
+		return common.ErrAllocationLinesMismatch
 	}
 
-	forexRequest.ApprovedBy = &authUserID
-	now := time.Now()
-	forexRequest.ApprovedAt = &now
+	forexRequest := t.update
 	forexRequest.ApprovedCurrencyIDs = request.ApprovedCurrencyIDs
 	forexRequest.ApprovedAmounts = request.ApprovedAmounts
 	forexRequest.ApprovedAmountInCash = request.ApprovedAmountInCash
 	forexRequest.ApprovedAmountInCard = request.ApprovedAmountInCard
-	forexRequest.RequestStatus = string(model.ReqStatusApproved)
 
-	// Fetch sender
-	sender, err := ru.userRepository.FindByID(ctx, authUserID)
+	forexRequest.ApprovedRates, err = ru.snapshotRates(ctx, request.ApprovedCurrencyIDs, request.ApprovedAmounts, t.at)
 	if err != nil {
 		return err
 	}
 
-	err = ru.requestRepository.Update(ctx, requestID, &forexRequest)
+	err = ru.enforceAllocationLimits(ctx, t, forexRequest.ApprovedRates, &request.AllocationOverrideDTO)
 	if err != nil {
 		return err
 	}
 
-	to := append([]string{}, configs.MailRequestAuthorizedTo...)
-	cc := append([]string{}, configs.MailRequestAuthorizedCc...)
-	bcc := append([]string{}, configs.MailRequestAuthorizedBcc...)
-
-	if sender.Profile.Branch != nil && sender.Profile.Branch.Email != "" {
-		to = append(to, sender.Profile.Branch.Email)
+	err = ru.notify(ctx, t, model.NotifyRequestApproved)
+	if err != nil {
+		return err
 	}
 
-	if sender.Profile != nil && sender.Profile.Email != "" {
-		to = append(to, sender.Profile.Email)
-	}
+	return ru.commitTransition(ctx, t)
+}
 
-	// Then send email (fail-safe)
-	subject := "Foreign Currency Request"
-	body := fmt.Sprintf("A fcy request with request code %s has been approved at %s.", forexRequest.RequestCode, now.Format("2006-01-02 15:04:05"))
+func (ru *requestUsecase) DeleteRequest(ctx context.Context, authUserID primitive.ObjectID, requestID primitive.ObjectID) error {
+	ctx, cancel := context.WithTimeout(ctx, ru.contextTimeout)
+	defer cancel()
 
-	go func() {
-		err := utils.SendEmail(to, cc, bcc, subject, body, *existingRequest)
-		if err != nil {
-			log.Warnf("Failed to send email for request %s: %v", requestID.Hex(), err)
+	t, err := ru.beginTransition(ctx, authUserID, requestID, model.ReqActionDelete)
+	if err != nil {
+		if errors.Is(err, common.ErrInvalidStatusTransition) {
+			return common.ErrRequestCannotBeDeleted
 		}
-	}()
+		return err
+	}
 
-	return nil
+	return ru.commitTransition(ctx, t)
 }
 
-func (ru *requestUsecase) DeleteRequest(ctx context.Context, authUserID primitive.ObjectID, requestID primitive.ObjectID) error {
+func (ru *requestUsecase) SendRequest(ctx context.Context, authUserID primitive.ObjectID, requestID primitive.ObjectID) error {
 	ctx, cancel := context.WithTimeout(ctx, ru.contextTimeout)
 	defer cancel()
 
-	existingRequest, err := ru.requestRepository.FindByID(ctx, requestID, false)
+	t, err := ru.beginTransition(ctx, authUserID, requestID, model.ReqActionSend)
 	if err != nil {
-		return common.ErrRequestNotFound
+		return err
 	}
 
-	if existingRequest.RequestStatus != model.ReqStatusDrafted {
-		return common.ErrRequestCannotBeDeleted
+	check, err := ru.checkDuplicates(ctx, t.existing, t.action, t.at)
+	if err != nil {
+		return err
+	}
+	if check != nil {
+		t.update.DuplicateCheck = check
 	}
 
-	// Copy the data
-	forexRequest := model.RequestUpdate{}
-	copier.Copy(&forexRequest, existingRequest)
+	err = ru.notify(ctx, t, model.NotifyRequestSent)
+	if err != nil {
+		return err
+	}
 
-	now := time.Now()
-	forexRequest.DeletedAt = &now
-	forexRequest.DeletedBy = &authUserID
-	forexRequest.IsDeleted = true
-	forexRequest.RequestStatus = string(model.ReqStatusDrafted)
+	err = ru.commitTransition(ctx, t)
+	if err != nil {
+		return err
+	}
+	ru.recordDuplicateCheck(ctx, requestID, authUserID, check)
 
-	return ru.requestRepository.Update(ctx, requestID, &forexRequest)
+	return nil
 }
 
-func (ru *requestUsecase) SendRequest(ctx context.Context, authUserID primitive.ObjectID, requestID primitive.ObjectID) error {
+func (ru *requestUsecase) DeclineOrgRequest(ctx context.Context, authUserID primitive.ObjectID, requestID primitive.ObjectID) error {
 	ctx, cancel := context.WithTimeout(ctx, ru.contextTimeout)
 	defer cancel()
 
-	existingRequest, err := ru.requestRepository.FindByID(ctx, requestID, false)
+	t, err := ru.beginTransition(ctx, authUserID, requestID, model.ReqActionDecline)
 	if err != nil {
-		return common.ErrRequestNotFound
+		return err
 	}
 
-	// Fetch sender
-	sender, err := ru.userRepository.FindByID(ctx, authUserID)
+	err = ru.notify(ctx, t, model.NotifyRequestDeclined)
 	if err != nil {
 		return err
 	}
 
-	// Copy the data
-	forexRequest := model.RequestUpdate{}
-	copier.Copy(&forexRequest, existingRequest)
+	return ru.commitTransition(ctx, t)
+}
 
-	now := time.Now()
-	forexRequest.RequestedAt = &now
-	forexRequest.RequestedBy = &authUserID
-	forexRequest.RequestStatus = string(model.ReqStatusNew)
+func (ru *requestUsecase) RejectRequest(ctx context.Context, authUserID primitive.ObjectID, requestID primitive.ObjectID, rejection_reason string) error {
+	ctx, cancel := context.WithTimeout(ctx, ru.contextTimeout)
+	defer cancel()
 
-	// First update DB
-	err = ru.requestRepository.Update(ctx, requestID, &forexRequest)
+	t, err := ru.beginTransition(ctx, authUserID, requestID, model.ReqActionReject)
 	if err != nil {
 		return err
 	}
 
-	to := append([]string{}, configs.MailRequestSentTo...)
-	cc := append([]string{}, configs.MailRequestSentCc...)
-	bcc := append([]string{}, configs.MailRequestSentBcc...)
+	existingUser := t.actor
+	existingRequest := t.existing
+	if existingUser.Role != nil && existingUser.Role.Name == "BRANCHAUTHORIZER" {
+		if existingUser.Profile.BranchID != existingRequest.BranchID && existingUser.Profile.DepartmentID != existingRequest.DepartmentID {
+			return common.ErrUnauthorized
+		}
+	}
 
-	if sender.Profile.Branch != nil && sender.Profile.Branch.Email != "" {
-		to = append(to, sender.Profile.Branch.Email)
+	forexRequest := t.update
+	forexRequest.RejectionReason = rejection_reason
+	t.reason = rejection_reason
+
+	err = ru.notify(ctx, t, model.NotifyRequestRejected)
+	if err != nil {
+		return err
 	}
 
-	if sender.Profile != nil && sender.Profile.Email != "" {
-		to = append(to, sender.Profile.Email)
+	return ru.commitTransition(ctx, t)
+}
+
+func (ru *requestUsecase) LockRequest(ctx context.Context, authUserID primitive.ObjectID, requestID primitive.ObjectID) (*time.Time, error) {
+	ctx, cancel := context.WithTimeout(ctx, ru.contextTimeout)
+	defer cancel()
+
+	expiresAt, err := ru.requestRepository.AcquireLock(ctx, requestID, authUserID, ru.lockTTL)
+	if err != nil {
+		return nil, err
 	}
 
-	// Then send email (fail-safe)
-	subject := "Foreign Currency Request"
-	body := fmt.Sprintf("A new fcy request with request code %s has been initiated at %s.", forexRequest.RequestCode, now.Format("2006-01-02 15:04:05"))
+	ru.recordEvent(ctx, newRequestEvent(ctx, requestID, authUserID, model.ReqEventLocked))
 
-	// Send email async (fail-safe)
-	go func() {
-		err := utils.SendAcknowledgementEmail(
-			to,
-			cc,
-			bcc,
-			subject,
-			body,
-			*existingRequest,
-		)
-		if err != nil {
-			log.Warnf(
-				"Failed to send email for request %s: %v",
-				requestID.Hex(),
-				err,
-			)
-		}
-	}()
+	return expiresAt, nil
+}
+
+func (ru *requestUsecase) RenewRequestLock(ctx context.Context, authUserID primitive.ObjectID, requestID primitive.ObjectID) (*time.Time, error) {
+	ctx, cancel := context.WithTimeout(ctx, ru.contextTimeout)
+	defer cancel()
+
+	return ru.requestRepository.RenewLock(ctx, requestID, authUserID, ru.lockTTL)
+}
+
+func (ru *requestUsecase) UnLockRequest(ctx context.Context, authUserID primitive.ObjectID, requestID primitive.ObjectID) error {
+	ctx, cancel := context.WithTimeout(ctx, ru.contextTimeout)
+	defer cancel()
+
+	if err := ru.requestRepository.ReleaseLock(ctx, requestID, authUserID); err != nil {
+		return err
+	}
+
+	ru.recordEvent(ctx, newRequestEvent(ctx, requestID, authUserID, model.ReqEventUnlocked))
 
 	return nil
 }
 
-func (ru *requestUsecase) DeclineOrgRequest(ctx context.Context, authUserID primitive.ObjectID, requestID primitive.ObjectID) error {
+func (ru *requestUsecase) ForceUnlockRequest(ctx context.Context, authUserID primitive.ObjectID, requestID primitive.ObjectID) error {
 	ctx, cancel := context.WithTimeout(ctx, ru.contextTimeout)
 	defer cancel()
 
 	existingRequest, err := ru.requestRepository.FindByID(ctx, requestID, false)
-	if err != nil {
+	if err != nil || existingRequest == nil {
 		return common.ErrRequestNotFound
 	}
 
-	// Copy the data
-	forexRequest := model.RequestUpdate{}
-	copier.Copy(&forexRequest, existingRequest)
+	if err := ru.requestRepository.ForceReleaseLock(ctx, requestID); err != nil {
+		return err
+	}
 
-	now := time.Now()
-	forexRequest.DeclinedAt = &now
-	forexRequest.DeclinedBy = &authUserID
-	forexRequest.RequestStatus = string(model.ReqStatusDeclined)
+	logrus.WithFields(logrus.Fields{
+		"requestID": requestID,
+		"userID":    authUserID,
+		"lockedBy":  existingRequest.LockedBy,
+	}).Warn("Request lock force released")
 
-	// // Then send email (fail-safe)
-	// subject := "Foreign Currency Request Rejected"
-	// body := fmt.Sprintf("A request with code %s has been rejected at %s.", requestID.Hex(), now.Format("2006-01-02 15:04:05"))
+	event := newRequestEvent(ctx, requestID, authUserID, model.ReqEventForceUnlocked)
+	if existingRequest.LockedBy != nil {
+		event.Changes = []model.RequestFieldChange{{Field: "locked_by", Before: *existingRequest.LockedBy}}
+	}
+	ru.recordEvent(ctx, event)
 
-	// go func() {
-	// 	err := utils.SendEmail(configs.MailReciever, subject, body, *forexRequest)
-	// 	if err != nil {
-	// 		log.Warnf("Failed to send rejection email for request %s: %v", requestID.Hex(), err)
-	// 	}
-	// }()
+	return nil
+}
 
-	return ru.requestRepository.Update(ctx, requestID, &forexRequest)
+// ReleaseExpiredLocks clears the locks whose holders stopped renewing them.
+// Expired locks no longer stop anyone; clearing them keeps the lock fields
+// of a request meaningful to whoever reads it.
+func (ru *requestUsecase) ReleaseExpiredLocks(ctx context.Context) (int64, error) {
+	ctx, cancel := context.WithTimeout(ctx, ru.contextTimeout)
+	defer cancel()
+
+	return ru.requestRepository.ReleaseExpiredLocks(ctx, time.Now())
 }
 
-func (ru *requestUsecase) RejectRequest(ctx context.Context, authUserID primitive.ObjectID, requestID primitive.ObjectID, rejection_reason string) error {
+func (ru *requestUsecase) AcceptRequest(ctx context.Context, authUserID primitive.ObjectID, requestID primitive.ObjectID, request *model.RequestAcceptanceDTO) error {
 	ctx, cancel := context.WithTimeout(ctx, ru.contextTimeout)
 	defer cancel()
 
-	existingRequest, err := ru.requestRepository.FindByID(ctx, requestID, false)
+	t, err := ru.beginTransition(ctx, authUserID, requestID, model.ReqActionAccept)
 	if err != nil {
-		return common.ErrRequestNotFound
+		return err
 	}
 
-	existingUser, err := ru.userRepository.FindByID(ctx, authUserID)
-	if err != nil {
-		return common.ErrUnauthorized
+	existingRequest := t.existing
+	if len(request.AcceptedCurrencyIDs) != len(existingRequest.ApprovedCurrencyIDs) {
+		return common.ErrInvalidAcceptedCurrency
+	}
+	if !sameLength(len(request.AcceptedCurrencyIDs), request.AcceptedAmounts, request.AcceptedAmountInCash, request.AcceptedAmountInCard) ||
+		!sameLength(len(existingRequest.ApprovedCurrencyIDs), existingRequest.ApprovedAmounts, existingRequest.ApprovedAmountInCash, existingRequest.ApprovedAmountInCard) {
+		return common.ErrAllocationLinesMismatch
 	}
 
-	if existingUser.Role.Name == "BRANCHAUTHORIZER" {
-		if existingUser.Profile.BranchID != existingRequest.BranchID && existingUser.Profile.DepartmentID != existingRequest.DepartmentID {
-			return common.ErrUnauthorized
+	for i := range existingRequest.ApprovedCurrencyIDs {
+		if existingRequest.ApprovedCurrencyIDs[i] != request.AcceptedCurrencyIDs[i] {
+			return common.ErrInvalidAcceptedCurrency
+		}
+
+		if existingRequest.ApprovedAmounts[i] < request.AcceptedAmounts[i] ||
+			existingRequest.ApprovedAmountInCard[i] < request.AcceptedAmountInCard[i] ||
+			existingRequest.ApprovedAmountInCash[i] < request.AcceptedAmountInCash[i] {
+			return common.ErrInvalidAcceptedAmount
 		}
 	}
 
-	// Copy the data
-	forexRequest := model.RequestUpdate{}
-	copier.Copy(&forexRequest, existingRequest)
+	forexRequest := t.update
+	forexRequest.AcceptedCurrencyIDs = request.AcceptedCurrencyIDs
+	forexRequest.AcceptedAmounts = request.AcceptedAmounts
+	forexRequest.AcceptedAmountInCash = request.AcceptedAmountInCash
+	forexRequest.AcceptedAmountInCard = request.AcceptedAmountInCard
 
-	if forexRequest.LockedBy != nil && *forexRequest.LockedBy != authUserID {
-		return common.ErrRequestIsLocked
+	forexRequest.AcceptedRates, err = ru.snapshotRates(ctx, request.AcceptedCurrencyIDs, request.AcceptedAmounts, t.at)
+	if err != nil {
+		return err
 	}
 
-	now := time.Now()
-	forexRequest.RejectedAt = &now
-	forexRequest.RejectedBy = &authUserID
-	forexRequest.RejectionReason = rejection_reason
-	forexRequest.RequestStatus = string(model.ReqStatusRejected)
+	err = ru.enforceAllocationLimits(ctx, t, forexRequest.AcceptedRates, &request.AllocationOverrideDTO)
+	if err != nil {
+		return err
+	}
 
-	err = ru.requestRepository.Update(ctx, requestID, &forexRequest)
+	err = ru.notify(ctx, t, model.NotifyRequestAccepted)
 	if err != nil {
 		return err
 	}
 
-	// Fetch sender
-	sender, err := ru.userRepository.FindByID(ctx, authUserID)
+	return ru.commitTransition(ctx, t)
+}
+
+// sameLength reports whether each of lists has n entries, so the amounts of
+// a decision line up with its currencies.
+func sameLength(n int, lists ...[]float64) bool {
+	for _, list := range lists {
+		if len(list) != n {
+			return false
+		}
+	}
+
+	return true
+}
+
+// enforceAllocationLimits checks the allocation of a decision against every
+// active limit that applies to the request. Violations fail the decision
+// unless the actor may override limits and explains why; the override is then
+// kept on the request and its justification on the history event.
+func (ru *requestUsecase) enforceAllocationLimits(ctx context.Context, t *requestTransition, lines []model.RequestRateSnapshot, override *model.AllocationOverrideDTO) error {
+	violations, err := ru.allocationViolations(ctx, t.existing, lines, t.at)
 	if err != nil {
 		return err
 	}
+	if len(violations) == 0 {
+		return nil
+	}
 
-	to := append([]string{}, configs.MailRequestRejectedTo...)
-	cc := append([]string{}, configs.MailRequestRejectedCc...)
-	bcc := append([]string{}, configs.MailRequestRejectedBcc...)
+	if !override.OverrideLimits {
+		return &model.AllocationLimitError{Violations: violations}
+	}
+	if !hasPermission(t.actor, "request:override-limit") {
+		return common.ErrLimitOverrideNotAllowed
+	}
 
-	if sender.Profile.Branch != nil && sender.Profile.Branch.Email != "" {
-		to = append(to, sender.Profile.Branch.Email)
+	justification := strings.TrimSpace(override.OverrideJustification)
+	if justification == "" {
+		return common.ErrOverrideJustificationRequired
 	}
 
-	if sender.Profile != nil && sender.Profile.Email != "" {
-		to = append(to, sender.Profile.Email)
+	t.update.LimitOverrides = append(append([]model.AllocationOverride{}, t.update.LimitOverrides...), model.AllocationOverride{
+		Action:        t.action,
+		Violations:    violations,
+		Justification: justification,
+		OverriddenBy:  t.actor.ID,
+		OverriddenAt:  t.at,
+	})
+	t.reason = justification
+
+	logrus.WithFields(logrus.Fields{
+		"requestID":  t.existing.ID,
+		"userID":     t.actor.ID,
+		"action":     t.action,
+		"violations": len(violations),
+	}).Warn("Allocation limits overridden")
+
+	return nil
+}
+
+func (ru *requestUsecase) allocationViolations(ctx context.Context, request *model.Request, lines []model.RequestRateSnapshot, at time.Time) ([]model.AllocationViolation, error) {
+	limits, err := ru.allocationLimitRepository.FindActive(ctx)
+	if err != nil {
+		return nil, err
 	}
 
-	// Then send email (fail-safe)
-	subject := "Foreign Currency Request"
-	body := fmt.Sprintf("A new fcy request with request code %s has been rejected at %s.", forexRequest.RequestCode, now.Format("2006-01-02 15:04:05"))
+	var violations []model.AllocationViolation
+	for _, limit := range limits {
+		if !limit.Matches(request) {
+			continue
+		}
 
-	// Send email async (fail-safe)
-	go func() {
-		err := utils.SendEmail(
-			to,
-			cc,
-			bcc,
-			subject,
-			body,
-			*existingRequest,
-		)
-		if err != nil {
-			log.Warnf(
-				"Failed to send email for request %s: %v",
-				requestID.Hex(),
-				err,
-			)
+		requested, counted := limit.Measure(lines)
+		if !counted {
+			continue
 		}
-	}()
-	return nil
+
+		used := 0.0
+		if since := limit.WindowStart(at); since != nil {
+			query := model.AllocationUsageQuery{
+				Since:            *since,
+				ExcludeRequestID: request.ID,
+				TravelPurposeID:  limit.TravelPurposeID,
+				CustomerTypeID:   limit.CustomerTypeID,
+				RequestingAs:     limit.RequestingAs,
+				CountryID:        limit.CountryID,
+			}
+			if limit.Scope != model.LimitDailyBudget {
+				query.ApplicantAccountNumber = request.ApplicantAccountNumber
+			}
+
+			usage, err := ru.requestRepository.SumAllocations(ctx, &query)
+			if err != nil {
+				return nil, err
+			}
+			used = limit.MeasureUsage(usage)
+		}
+
+		if used+requested > limit.MaxAmount {
+			violations = append(violations, model.AllocationViolation{
+				LimitID:    limit.ID,
+				LimitName:  limit.Name,
+				Scope:      limit.Scope,
+				CurrencyID: limit.CurrencyID,
+				MaxAmount:  limit.MaxAmount,
+				Used:       used,
+				Requested:  requested,
+			})
+		}
+	}
+
+	return violations, nil
 }
 
-func (ru *requestUsecase) LockRequest(ctx context.Context, authUserID primitive.ObjectID, requestID primitive.ObjectID) error {
-	ctx, cancel := context.WithTimeout(ctx, ru.contextTimeout)
-	defer cancel()
+// checkDuplicates looks for other requests of the same applicant before the
+// request is added or sent. It returns nil when duplicate detection is off and
+// a DuplicateRequestError when the policy blocks the request.
+func (ru *requestUsecase) checkDuplicates(ctx context.Context, request *model.Request, action model.RequestAction, at time.Time) (*model.RequestDuplicateCheck, error) {
+	check, err := ru.findDuplicates(ctx, request, at)
+	if err != nil || check == nil {
+		return nil, err
+	}
+	check.Action = action
 
-	existingRequest, err := ru.requestRepository.FindByID(ctx, requestID, false)
+	if check.Blocks(ru.duplicatePolicy) {
+		logrus.WithFields(logrus.Fields{
+			"requestID": request.ID,
+			"action":    action,
+			"links":     len(check.Links),
+			"velocity":  check.Velocity,
+		}).Warn("Duplicate request blocked")
+		return nil, &model.DuplicateRequestError{Check: check}
+	}
+
+	return check, nil
+}
+
+// findDuplicates links the request to every open request, and every request
+// created within the lookback, that has the same applicant account or a
+// similar applicant name.
+func (ru *requestUsecase) findDuplicates(ctx context.Context, request *model.Request, at time.Time) (*model.RequestDuplicateCheck, error) {
+	policy := ru.duplicatePolicy
+	if policy.Mode == "" || policy.Mode == model.DuplicatePolicyOff {
+		return nil, nil
+	}
+
+	since := at.Add(-policy.Lookback)
+	candidates, err := ru.requestRepository.FindDuplicateCandidates(ctx, request.ID, since)
 	if err != nil {
-		return common.ErrRequestNotFound
+		return nil, err
 	}
 
-	// Copy the data
-	forexRequest := model.RequestUpdate{}
-	copier.Copy(&forexRequest, existingRequest)
+	account := strings.TrimSpace(request.ApplicantAccountNumber)
+	check := &model.RequestDuplicateCheck{Links: []model.RequestDuplicateLink{}, Velocity: 1, CheckedAt: at}
+
+	for _, candidate := range candidates {
+		link := model.RequestDuplicateLink{
+			RequestID:              candidate.ID,
+			RequestCode:            candidate.RequestCode,
+			ApplicantName:          candidate.ApplicantName,
+			ApplicantAccountNumber: candidate.ApplicantAccountNumber,
+			RequestStatus:          candidate.RequestStatus,
+			BranchID:               candidate.BranchID,
+			DepartmentID:           candidate.DepartmentID,
+			CreatedAt:              candidate.CreatedAt,
+		}
 
-	// If already locked by someone else
-	if forexRequest.LockedBy != nil && *forexRequest.LockedBy != authUserID {
-		return common.ErrRequestIsLocked
+		if account != "" && strings.TrimSpace(candidate.ApplicantAccountNumber) == account {
+			if !candidate.CreatedAt.Before(since) {
+				check.Velocity++
+			}
+			link.Reason = model.DuplicateSameAccount
+			link.Score = 1
+		} else if score := utils.NameSimilarity(request.ApplicantName, candidate.ApplicantName); score >= policy.NameThreshold {
+			link.Reason = model.DuplicateSimilarName
+			link.Score = score
+		} else {
+			continue
+		}
+
+		check.Links = append(check.Links, link)
 	}
 
-	now := time.Now()
-	expiry := now.Add(15 * time.Minute)
-	forexRequest.LockedAt = &now
-	forexRequest.LockedBy = &authUserID
-	forexRequest.LockExpiresAt = &expiry
+	check.Flagged = len(check.Links) > 0 || (policy.VelocityMax > 0 && check.Velocity > policy.VelocityMax)
 
-	return ru.requestRepository.Update(ctx, requestID, &forexRequest)
+	return check, nil
 }
 
-func (ru *requestUsecase) UnLockRequest(ctx context.Context, authUserID primitive.ObjectID, requestID primitive.ObjectID) error {
+// recordDuplicateCheck adds a history entry when a check flagged the request.
+func (ru *requestUsecase) recordDuplicateCheck(ctx context.Context, requestID primitive.ObjectID, actorID primitive.ObjectID, check *model.RequestDuplicateCheck) {
+	if check == nil || !check.Flagged {
+		return
+	}
+
+	codes := make([]string, len(check.Links))
+	for i, link := range check.Links {
+		codes[i] = link.RequestCode
+	}
+
+	event := newRequestEvent(ctx, requestID, actorID, model.ReqEventDuplicateFlagged)
+	event.Action = check.Action
+	event.Reason = fmt.Sprintf("%d requests for the account within the lookback; linked requests: %s", check.Velocity, strings.Join(codes, ", "))
+	ru.recordEvent(ctx, event)
+}
+
+// snapshotRates prices every currency of an approval or acceptance at the rate
+// in force when the decision is taken. A decision cannot be recorded for a
+// currency that has no published rate or no amount.
+func (ru *requestUsecase) snapshotRates(ctx context.Context, currencyIDs []primitive.ObjectID, amounts []float64, at time.Time) ([]model.RequestRateSnapshot, error) {
+	if len(amounts) != len(currencyIDs) {
+		return nil, common.ErrAllocationLinesMismatch
+	}
+
+	snapshots := make([]model.RequestRateSnapshot, 0, len(currencyIDs))
+
+	for i, currencyID := range currencyIDs {
+		rate, err := ru.exchangeRateRepository.FindEffective(ctx, currencyID, at)
+		if err != nil {
+			return nil, err
+		}
+		if rate == nil {
+			return nil, fmt.Errorf("%w: %s", common.ErrExchangeRateNotFound, currencyID.Hex())
+		}
+
+		snapshots = append(snapshots, model.NewRequestRateSnapshot(rate, amounts[i], at))
+	}
+
+	return snapshots, nil
+}
+
+func (ru *requestUsecase) SearchRequests(ctx context.Context, authUserID primitive.ObjectID, search *model.RequestSearch) (*model.RequestPage, error) {
 	ctx, cancel := context.WithTimeout(ctx, ru.contextTimeout)
 	defer cancel()
 
-	existingRequest, err := ru.requestRepository.FindByID(ctx, requestID, false)
+	existingUser, err := ru.userRepository.FindByID(ctx, authUserID)
 	if err != nil {
-		return common.ErrRequestNotFound
+		return nil, common.ErrUnauthorized
 	}
 
-	// Copy the data
-	forexRequest := model.RequestUpdate{}
-	copier.Copy(&forexRequest, existingRequest)
-
-	if forexRequest.LockedBy != nil && *forexRequest.LockedBy != authUserID {
-		return common.ErrRequestIsLocked
+	search.Scope = requestScopeFor(existingUser).Restrict(search.Filter.Statuses)
+	if search.Scope.IsEmpty() {
+		return &model.RequestPage{Items: []model.Request{}, Page: search.Page, PageSize: search.PageSize}, nil
 	}
 
-	forexRequest.LockedAt = nil
-	forexRequest.LockedBy = nil
-	forexRequest.LockExpiresAt = nil
+	return ru.requestRepository.Search(ctx, search)
+}
+
+func (ru *requestUsecase) GetRequest(ctx context.Context, authUserID primitive.ObjectID, requestID primitive.ObjectID) (*model.Request, error) {
+	ctx, cancel := context.WithTimeout(ctx, ru.contextTimeout)
+	defer cancel()
+
+	existingRequest, err := ru.requestRepository.FindByID(ctx, requestID, true)
+	if err != nil || existingRequest == nil {
+		return nil, common.ErrRequestNotFound
+	}
 
-	return ru.requestRepository.Update(ctx, requestID, &forexRequest)
+	return ru.viewRequest(ctx, authUserID, existingRequest)
 }
 
-func (ru *requestUsecase) AcceptRequest(ctx context.Context, authUserID primitive.ObjectID, requestID primitive.ObjectID, request *model.RequestAcceptanceDTO) error {
+func (ru *requestUsecase) GetRequestByCode(ctx context.Context, authUserID primitive.ObjectID, requestCode string) (*model.Request, error) {
 	ctx, cancel := context.WithTimeout(ctx, ru.contextTimeout)
 	defer cancel()
 
-	existingRequest, err := ru.requestRepository.FindByID(ctx, requestID, false)
+	existingRequest, err := ru.requestRepository.FindByCode(ctx, requestCode, true)
+	if err != nil || existingRequest == nil {
+		return nil, common.ErrRequestNotFound
+	}
+
+	return ru.viewRequest(ctx, authUserID, existingRequest)
+}
+
+// viewRequest checks that the user may read the request and prepares it for
+// display.
+func (ru *requestUsecase) viewRequest(ctx context.Context, authUserID primitive.ObjectID, existingRequest *model.Request) (*model.Request, error) {
+	existingUser, err := ru.userRepository.FindByID(ctx, authUserID)
 	if err != nil {
-		return common.ErrRequestNotFound
+		return nil, common.ErrUnauthorized
 	}
 
-	for i := range existingRequest.ApprovedAmounts {
-		if existingRequest.ApprovedCurrencyIDs[i] != request.AcceptedCurrencyIDs[i] {
-			return common.ErrInvalidAcceptedCurrency
-		}
+	if !requestScopeFor(existingUser).Allows(existingRequest) {
+		logrus.WithFields(logrus.Fields{
+			"userID":    authUserID,
+			"requestID": existingRequest.ID,
+		}).Warn("Unauthorized request access attempt")
+		return nil, common.ErrUnauthorized
+	}
 
-		if existingRequest.ApprovedAmounts[i] < request.AcceptedAmounts[i] ||
-			existingRequest.ApprovedAmountInCard[i] < request.AcceptedAmountInCard[i] ||
-			existingRequest.ApprovedAmountInCash[i] < request.AcceptedAmountInCash[i] {
-			return common.ErrInvalidAcceptedAmount
+	// Validators and approvers see the duplicates as they stand now rather
+	// than as they were when the request was sent.
+	if slices.Contains(model.RequestOpenStatuses, existingRequest.RequestStatus) &&
+		(hasPermission(existingUser, "request:validate") || hasPermission(existingUser, "request:approve")) {
+		check, err := ru.findDuplicates(ctx, existingRequest, time.Now())
+		if err != nil {
+			return nil, err
+		}
+		if check != nil {
+			existingRequest.DuplicateCheck = check
 		}
 	}
 
-	// Copy the data
-	forexRequest := model.RequestUpdate{}
-	copier.Copy(&forexRequest, existingRequest)
+	return existingRequest, nil
+}
 
-	if forexRequest.LockedBy != nil && *forexRequest.LockedBy != authUserID {
-		return common.ErrRequestIsLocked
+func (ru *requestUsecase) GetRequestHistory(ctx context.Context, authUserID primitive.ObjectID, requestID primitive.ObjectID) ([]model.RequestEvent, error) {
+	ctx, cancel := context.WithTimeout(ctx, ru.contextTimeout)
+	defer cancel()
+
+	existingRequest, err := ru.requestRepository.FindByID(ctx, requestID, false)
+	if err != nil || existingRequest == nil {
+		return nil, common.ErrRequestNotFound
 	}
 
-	now := time.Now()
-	forexRequest.AcceptedAt = &now
-	forexRequest.AcceptedBy = &authUserID
-	forexRequest.AcceptedCurrencyIDs = request.AcceptedCurrencyIDs
-	forexRequest.AcceptedAmounts = request.AcceptedAmounts
-	forexRequest.AcceptedAmountInCash = request.AcceptedAmountInCash
-	forexRequest.AcceptedAmountInCard = request.AcceptedAmountInCard
-	forexRequest.RequestStatus = string(model.ReqStatusAccepted)
+	existingUser, err := ru.userRepository.FindByID(ctx, authUserID)
+	if err != nil {
+		return nil, common.ErrUnauthorized
+	}
+
+	if !requestScopeFor(existingUser).Allows(existingRequest) {
+		logrus.WithFields(logrus.Fields{
+			"userID":    authUserID,
+			"requestID": requestID,
+		}).Warn("Unauthorized request history access attempt")
+		return nil, common.ErrUnauthorized
+	}
+
+	return ru.requestEventRepository.FindByRequestID(ctx, requestID)
+}
+
+// requestScopeFor is the read scope of user, shared by request listings and
+// single-request views so both show the same requests.
+func requestScopeFor(user *model.User) model.RequestScope {
+	var rolePerms []string
+	if user.Role != nil {
+		rolePerms = user.Role.Permissions
+	}
 
-	return ru.requestRepository.Update(ctx, requestID, &forexRequest)
+	return model.NewRequestScope(utils.MergePermissions(rolePerms, user.Permissions), user.Profile)
 }
@@ -22,14 +22,18 @@ type RoleUsecase interface {
 }
 
 type roleUsecase struct {
-	roleRepository model.RoleRepository
-	contextTimeout time.Duration
+	roleRepository     model.RoleRepository
+	userRepository     model.UserRepository
+	permissionVersions model.PermissionVersions
+	contextTimeout     time.Duration
 }
 
-func NewRoleUsecase(roleRepository model.RoleRepository, timeout time.Duration) RoleUsecase {
+func NewRoleUsecase(roleRepository model.RoleRepository, userRepository model.UserRepository, permissionVersions model.PermissionVersions, timeout time.Duration) RoleUsecase {
 	return &roleUsecase{
-		roleRepository: roleRepository,
-		contextTimeout: timeout,
+		roleRepository:     roleRepository,
+		userRepository:     userRepository,
+		permissionVersions: permissionVersions,
+		contextTimeout:     timeout,
 	}
 }
 
@@ -115,7 +119,11 @@ func (ru *roleUsecase) UpdateRole(ctx context.Context, authUserID primitive.Obje
 	role.UpdatedAt = now
 	role.UpdatedBy = &authUserID
 
-	return ru.roleRepository.Update(ctx, roleID, role)
+	if err := ru.roleRepository.Update(ctx, roleID, role); err != nil {
+		return err
+	}
+
+	return ru.bumpRoleUsers(ctx, roleID)
 }
 
 func (ru *roleUsecase) DeleteRole(ctx context.Context, authUserID primitive.ObjectID, roleID primitive.ObjectID) error {
@@ -132,7 +140,23 @@ func (ru *roleUsecase) DeleteRole(ctx context.Context, authUserID primitive.Obje
 	role.DeletedBy = &authUserID
 	role.IsDeleted = true
 
-	return ru.roleRepository.Delete(ctx, roleID, role)
+	if err := ru.roleRepository.Delete(ctx, roleID, role); err != nil {
+		return err
+	}
+
+	return ru.bumpRoleUsers(ctx, roleID)
+}
+
+// bumpRoleUsers makes the access tokens of every user of the role stale, so
+// the changed permissions apply within seconds.
+func (ru *roleUsecase) bumpRoleUsers(ctx context.Context, roleID primitive.ObjectID) error {
+	versions, err := ru.userRepository.BumpRolePermissionsVersion(ctx, roleID, time.Now())
+	if err != nil {
+		return err
+	}
+	ru.permissionVersions.Add(versions...)
+
+	return nil
 }
 
 func (ru *roleUsecase) GetDeletedRoles(ctx context.Context) ([]model.Role, error) {
@@ -0,0 +1,235 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/latiiLA/coop-forex-server/configs"
+	"github.com/latiiLA/coop-forex-server/internal/common"
+	"github.com/latiiLA/coop-forex-server/internal/domain/model"
+	"github.com/latiiLA/coop-forex-server/internal/infrastructure"
+	"github.com/latiiLA/coop-forex-server/internal/infrastructure/metrics"
+	"github.com/latiiLA/coop-forex-server/internal/infrastructure/utils"
+	"github.com/sirupsen/logrus"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type SessionUsecase interface {
+	// ListSessions lists the active sessions of the caller, marking the one
+	// the caller is authenticated with.
+	ListSessions(ctx context.Context, authUserID primitive.ObjectID, currentSessionID primitive.ObjectID) ([]model.Session, error)
+	// Logout ends the session the caller is authenticated with.
+	Logout(ctx context.Context, authUserID primitive.ObjectID, sessionID primitive.ObjectID) error
+	// LogoutAll ends every session of the caller and returns how many.
+	LogoutAll(ctx context.Context, authUserID primitive.ObjectID) (int, error)
+	// KillUserSessions ends every session of another user and returns how
+	// many.
+	KillUserSessions(ctx context.Context, authUserID primitive.ObjectID, userID primitive.ObjectID) (int, error)
+}
+
+type sessionUsecase struct {
+	sessionRepository model.SessionRepository
+	revocations       model.SessionRevocations
+	contextTimeout    time.Duration
+}
+
+func NewSessionUsecase(sessionRepository model.SessionRepository, revocations model.SessionRevocations, timeout time.Duration) SessionUsecase {
+	return &sessionUsecase{
+		sessionRepository: sessionRepository,
+		revocations:       revocations,
+		contextTimeout:    timeout,
+	}
+}
+
+func (su *sessionUsecase) ListSessions(ctx context.Context, authUserID primitive.ObjectID, currentSessionID primitive.ObjectID) ([]model.Session, error) {
+	ctx, cancel := context.WithTimeout(ctx, su.contextTimeout)
+	defer cancel()
+
+	sessions, err := su.sessionRepository.FindActiveByUserID(ctx, authUserID, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	for i := range sessions {
+		sessions[i].Current = sessions[i].ID == currentSessionID
+	}
+
+	return sessions, nil
+}
+
+func (su *sessionUsecase) Logout(ctx context.Context, authUserID primitive.ObjectID, sessionID primitive.ObjectID) error {
+	ctx, cancel := context.WithTimeout(ctx, su.contextTimeout)
+	defer cancel()
+
+	revoked, err := su.sessionRepository.Revoke(ctx, sessionID, authUserID, model.SessionLogout, authUserID, time.Now())
+	if err != nil {
+		return err
+	}
+	// Logging out twice is not an error.
+	if revoked != nil {
+		su.revocations.Add(*revoked)
+	}
+
+	return nil
+}
+
+func (su *sessionUsecase) LogoutAll(ctx context.Context, authUserID primitive.ObjectID) (int, error) {
+	ctx, cancel := context.WithTimeout(ctx, su.contextTimeout)
+	defer cancel()
+
+	return revokeSessions(ctx, su.sessionRepository, su.revocations, authUserID, model.SessionLogoutAll, authUserID)
+}
+
+func (su *sessionUsecase) KillUserSessions(ctx context.Context, authUserID primitive.ObjectID, userID primitive.ObjectID) (int, error) {
+	ctx, cancel := context.WithTimeout(ctx, su.contextTimeout)
+	defer cancel()
+
+	return revokeSessions(ctx, su.sessionRepository, su.revocations, userID, model.SessionKilled, authUserID)
+}
+
+// revokeSessions ends every session of userID and refuses their tokens on
+// this instance at once.
+func revokeSessions(ctx context.Context, sessionRepository model.SessionRepository, revocations model.SessionRevocations, userID primitive.ObjectID, reason model.SessionRevokeReason, revokedBy primitive.ObjectID) (int, error) {
+	revoked, err := sessionRepository.RevokeAll(ctx, userID, reason, revokedBy, time.Now())
+	if err != nil {
+		return 0, err
+	}
+	revocations.Add(revoked...)
+
+	return len(revoked), nil
+}
+
+// startSession registers a login of userID from client and issues its first
+// refresh token.
+func startSession(ctx context.Context, sessionRepository model.SessionRepository, userID primitive.ObjectID, client model.ClientInfo) (*model.Session, string, error) {
+	now := time.Now()
+	session := &model.Session{
+		ID:         primitive.NewObjectID(),
+		UserID:     userID,
+		RefreshJTI: uuid.New().String(),
+		IP:         client.IP,
+		LastIP:     client.IP,
+		UserAgent:  client.UserAgent,
+		CreatedAt:  now,
+		LastUsedAt: now,
+		ExpiresAt:  now.Add(configs.RefreshTokenExpiry),
+	}
+
+	refreshToken, err := infrastructure.GenerateRefreshToken(userID, client, session.ID, session.RefreshJTI, session.ExpiresAt)
+	if err != nil {
+		return nil, "", err
+	}
+
+	if err := sessionRepository.Create(ctx, session); err != nil {
+		return nil, "", err
+	}
+
+	return session, refreshToken, nil
+}
+
+// accessClaims builds the claims of an access token from the user's current
+// status, role and permissions. user must have its role and profile loaded.
+func accessClaims(user *model.User) (*model.AccessClaims, error) {
+	if user.Status != model.StatusNew && user.Status != model.StatusActive {
+		return nil, common.ErrUserAccessRevoked
+	}
+	if user.Role == nil || user.Role.IsDeleted {
+		return nil, common.ErrRoleNotFound
+	}
+
+	permissions := utils.MergePermissions(user.Role.Permissions, user.Permissions)
+	slices.Sort(permissions)
+
+	claims := &model.AccessClaims{
+		UserID:             user.ID,
+		Role:               user.Role.Name,
+		BranchID:           primitive.NilObjectID,
+		DepartmentID:       primitive.NilObjectID,
+		Permissions:        permissions,
+		PermissionsVersion: user.PermissionsVersion,
+	}
+	if user.Profile != nil && user.Profile.BranchID != nil {
+		claims.BranchID = *user.Profile.BranchID
+	}
+	if user.Profile != nil && user.Profile.DepartmentID != nil {
+		claims.DepartmentID = *user.Profile.DepartmentID
+	}
+
+	return claims, nil
+}
+
+// bumpPermissions makes the access tokens of userID stale, at once on this
+// instance.
+func bumpPermissions(ctx context.Context, userRepository model.UserRepository, versions model.PermissionVersions, userID primitive.ObjectID) error {
+	version, err := userRepository.BumpPermissionsVersion(ctx, userID, time.Now())
+	if err != nil {
+		return err
+	}
+	versions.Add(*version)
+
+	return nil
+}
+
+// rotateSession exchanges the refresh token jti of a session for a new one
+// and returns the new token's ID and expiry. The token the current one
+// replaced is accepted for model.RefreshReuseGrace after the rotation and
+// yields the current token's ID and expiry, so a concurrent or retried
+// refresh receives the current pair instead of revoking the session. Any
+// other token that was already exchanged has been replayed, so the whole
+// session is revoked, a security event is raised and
+// common.ErrRefreshTokenReused is returned.
+func rotateSession(ctx context.Context, sessionRepository model.SessionRepository, securityEventRepository model.SecurityEventRepository, revocations model.SessionRevocations, userID primitive.ObjectID, sessionID primitive.ObjectID, jti string, ip string) (string, time.Time, error) {
+	now := time.Now()
+	newJTI := uuid.New().String()
+	expiresAt := now.Add(configs.RefreshTokenExpiry)
+
+	rotated, err := sessionRepository.Rotate(ctx, sessionID, jti, newJTI, ip, now, expiresAt)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	if rotated {
+		return newJTI, expiresAt, nil
+	}
+
+	session, err := sessionRepository.FindByID(ctx, sessionID)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	if session == nil || session.UserID != userID || !session.IsActive(now) || session.RefreshJTI == jti {
+		return "", time.Time{}, common.ErrSessionEnded
+	}
+	if session.IsRecentlyRotated(jti, now) {
+		return session.RefreshJTI, session.ExpiresAt, nil
+	}
+
+	revoked, err := sessionRepository.Revoke(ctx, sessionID, userID, model.SessionRefreshReused, primitive.NilObjectID, now)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	if revoked != nil {
+		revocations.Add(*revoked)
+	}
+
+	event := &model.SecurityEvent{
+		Type:      model.SecurityRefreshTokenReused,
+		UserID:    userID,
+		SessionID: &sessionID,
+		IP:        ip,
+		UserAgent: session.UserAgent,
+		Detail:    fmt.Sprintf("refresh token %s replayed after %d rotations", jti, session.Rotations),
+		CreatedAt: now,
+	}
+	if err := securityEventRepository.Create(ctx, event); err != nil {
+		logrus.WithError(err).Error("Failed to record security event")
+	}
+	metrics.RecordSecurityEvent(event.Type)
+	logrus.WithFields(logrus.Fields{
+		"user_id":    userID.Hex(),
+		"session_id": sessionID.Hex(),
+		"ip":         ip,
+	}).Warn("Refresh token reused, session revoked")
+
+	return "", time.Time{}, common.ErrRefreshTokenReused
+}
@@ -0,0 +1,153 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/latiiLA/coop-forex-server/internal/common"
+	"github.com/latiiLA/coop-forex-server/internal/domain/model"
+	"github.com/sirupsen/logrus"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type SLAPolicyUsecase interface {
+	AddSLAPolicy(ctx context.Context, authUserID primitive.ObjectID, policy *model.CreateSLAPolicyDTO) (*model.SLAPolicy, error)
+	GetAllSLAPolicies(ctx context.Context) ([]model.SLAPolicy, error)
+	GetSLAPolicyByID(ctx context.Context, policyID primitive.ObjectID) (*model.SLAPolicy, error)
+	UpdateSLAPolicy(ctx context.Context, authUserID primitive.ObjectID, policyID primitive.ObjectID, policy *model.UpdateSLAPolicyDTO) error
+	DeleteSLAPolicy(ctx context.Context, authUserID primitive.ObjectID, policyID primitive.ObjectID) error
+}
+
+type slaPolicyUsecase struct {
+	slaPolicyRepository model.SLAPolicyRepository
+	contextTimeout      time.Duration
+}
+
+func NewSLAPolicyUsecase(slaPolicyRepository model.SLAPolicyRepository, timeout time.Duration) SLAPolicyUsecase {
+	return &slaPolicyUsecase{
+		slaPolicyRepository: slaPolicyRepository,
+		contextTimeout:      timeout,
+	}
+}
+
+func (su *slaPolicyUsecase) AddSLAPolicy(ctx context.Context, authUserID primitive.ObjectID, policy *model.CreateSLAPolicyDTO) (*model.SLAPolicy, error) {
+	ctx, cancel := context.WithTimeout(ctx, su.contextTimeout)
+	defer cancel()
+
+	createdPolicy := model.SLAPolicy{IsActive: true}
+	if err := applySLAPolicyDTO(&createdPolicy, policy); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	createdPolicy.ID = primitive.NewObjectID()
+	createdPolicy.CreatedAt = now
+	createdPolicy.UpdatedAt = now
+	createdPolicy.CreatedBy = authUserID
+	createdPolicy.IsDeleted = false
+
+	if err := su.slaPolicyRepository.Create(ctx, &createdPolicy); err != nil {
+		return nil, err
+	}
+
+	logrus.WithField("policy", createdPolicy).Info("SLA policy created successfully")
+
+	return &createdPolicy, nil
+}
+
+func (su *slaPolicyUsecase) GetAllSLAPolicies(ctx context.Context) ([]model.SLAPolicy, error) {
+	ctx, cancel := context.WithTimeout(ctx, su.contextTimeout)
+	defer cancel()
+
+	return su.slaPolicyRepository.FindAll(ctx)
+}
+
+func (su *slaPolicyUsecase) GetSLAPolicyByID(ctx context.Context, policyID primitive.ObjectID) (*model.SLAPolicy, error) {
+	ctx, cancel := context.WithTimeout(ctx, su.contextTimeout)
+	defer cancel()
+
+	policy, err := su.slaPolicyRepository.FindByID(ctx, policyID)
+	if err != nil {
+		return nil, err
+	}
+	if policy == nil {
+		return nil, common.ErrSLAPolicyNotFound
+	}
+
+	return policy, nil
+}
+
+// UpdateSLAPolicy changes a policy for requests entering its stage from now
+// on; requests already waiting keep the due date they were given.
+func (su *slaPolicyUsecase) UpdateSLAPolicy(ctx context.Context, authUserID primitive.ObjectID, policyID primitive.ObjectID, policyUpdate *model.UpdateSLAPolicyDTO) error {
+	ctx, cancel := context.WithTimeout(ctx, su.contextTimeout)
+	defer cancel()
+
+	policy, err := su.slaPolicyRepository.FindByID(ctx, policyID)
+	if err != nil {
+		return err
+	}
+	if policy == nil {
+		return common.ErrSLAPolicyNotFound
+	}
+
+	if err := applySLAPolicyDTO(policy, policyUpdate); err != nil {
+		return err
+	}
+
+	policy.UpdatedAt = time.Now()
+	policy.UpdatedBy = &authUserID
+
+	return su.slaPolicyRepository.Update(ctx, policyID, policy)
+}
+
+func (su *slaPolicyUsecase) DeleteSLAPolicy(ctx context.Context, authUserID primitive.ObjectID, policyID primitive.ObjectID) error {
+	ctx, cancel := context.WithTimeout(ctx, su.contextTimeout)
+	defer cancel()
+
+	policy, err := su.slaPolicyRepository.FindByID(ctx, policyID)
+	if err != nil {
+		return err
+	}
+	if policy == nil {
+		return common.ErrSLAPolicyNotFound
+	}
+
+	now := time.Now()
+	policy.IsDeleted = true
+	policy.DeletedAt = &now
+	policy.DeletedBy = &authUserID
+
+	return su.slaPolicyRepository.Delete(ctx, policyID, policy)
+}
+
+// applySLAPolicyDTO copies a create or update request onto the policy.
+func applySLAPolicyDTO(policy *model.SLAPolicy, dto *model.CreateSLAPolicyDTO) error {
+	policy.TravelPurposeID = nil
+	if dto.TravelPurposeID != "" {
+		travelPurposeID, err := primitive.ObjectIDFromHex(dto.TravelPurposeID)
+		if err != nil {
+			return fmt.Errorf("%w: %v", common.ErrInvalidSLAPolicy, err)
+		}
+		policy.TravelPurposeID = &travelPurposeID
+	}
+
+	policy.Name = dto.Name
+	policy.Stage = model.RequestStatus(dto.Stage)
+	policy.DueAfterHours = dto.DueAfterHours
+	policy.RemindBeforeHours = dto.RemindBeforeHours
+	policy.AutoDecline = dto.AutoDecline
+	if dto.IsActive != nil {
+		policy.IsActive = *dto.IsActive
+	}
+
+	if !model.IsSLAStage(policy.Stage) {
+		return fmt.Errorf("%w: requests do not wait in %s", common.ErrInvalidSLAPolicy, policy.Stage)
+	}
+	if policy.AutoDecline && policy.Stage != model.ReqStatusApproved {
+		return fmt.Errorf("%w: only approved requests can be declined automatically", common.ErrInvalidSLAPolicy)
+	}
+
+	return nil
+}
@@ -0,0 +1,33 @@
+package usecase
+
+import (
+	"context"
+	"time"
+
+	"github.com/latiiLA/coop-forex-server/internal/domain/model"
+)
+
+type TokenUsecase interface {
+	PurgeExpired(ctx context.Context) (int64, error)
+}
+
+type tokenUsecase struct {
+	tokenBlacklistRepo model.TokenBlacklistRepository
+	contextTimeout     time.Duration
+}
+
+func NewTokenUsecase(tokenBlacklistRepo model.TokenBlacklistRepository, timeout time.Duration) TokenUsecase {
+	return &tokenUsecase{
+		tokenBlacklistRepo: tokenBlacklistRepo,
+		contextTimeout:     timeout,
+	}
+}
+
+// PurgeExpired removes the blacklisted tokens that expired; they are refused
+// by their expiry alone and only take up space.
+func (tu *tokenUsecase) PurgeExpired(ctx context.Context) (int64, error) {
+	ctx, cancel := context.WithTimeout(ctx, tu.contextTimeout)
+	defer cancel()
+
+	return tu.tokenBlacklistRepo.DeleteExpired(ctx, time.Now())
+}
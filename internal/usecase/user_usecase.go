@@ -10,7 +10,6 @@ import (
 	"github.com/latiiLA/coop-forex-server/internal/common"
 	"github.com/latiiLA/coop-forex-server/internal/domain/model"
 	"github.com/latiiLA/coop-forex-server/internal/infrastructure"
-	"github.com/latiiLA/coop-forex-server/internal/infrastructure/utils"
 	"github.com/sirupsen/logrus"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
@@ -18,30 +17,41 @@ import (
 
 type UserUsecase interface {
 	Register(c context.Context, authUserID primitive.ObjectID, registerReq *model.RegisterUsecaseRequestDTO) error
-	Login(c context.Context, userReq model.LoginRequestDTO, ip string) (*model.LoginResponseDTO, error)
+	Login(c context.Context, userReq model.LoginRequestDTO, client model.ClientInfo) (*model.LoginResponseDTO, error)
 	GetUserByID(c context.Context, userID primitive.ObjectID) (*model.User, error)
 	UpdateUserByID(c context.Context, userID primitive.ObjectID, authUserID primitive.ObjectID, user *model.UpdateUserRequestDTO) (*model.UserResponseDTO, error)
 	GetAllUsers(c context.Context) (*[]model.UserResponseDTO, error)
-	RefreshToken(c context.Context, refresh model.RefreshTokenDTO, clientIP string) (string, string, error)
+	RefreshToken(c context.Context, refresh model.RefreshTokenDTO, client model.ClientInfo) (string, string, error)
+	// UpdateUserStatus changes whether a user may log in, ending their
+	// sessions unless they are active.
+	UpdateUserStatus(c context.Context, userID primitive.ObjectID, authUserID primitive.ObjectID, status model.UserStatus) error
 }
 
 type userUsecase struct {
-	userRepository     model.UserRepository
-	roleRepository     model.RoleRepository
-	profileRepository  model.ProfileRepository
-	tokenBlacklistRepo model.TokenBlacklistRepository
-	contextTimeout     time.Duration
-	client             *mongo.Client
+	userRepository          model.UserRepository
+	roleRepository          model.RoleRepository
+	profileRepository       model.ProfileRepository
+	sessionRepository       model.SessionRepository
+	securityEventRepository model.SecurityEventRepository
+	revocations             model.SessionRevocations
+	permissionVersions      model.PermissionVersions
+	ipBindingGuard          model.IPBindingGuard
+	contextTimeout          time.Duration
+	client                  *mongo.Client
 }
 
-func NewUserUsecase(userRepository model.UserRepository, roleRepository model.RoleRepository, profileRepository model.ProfileRepository, tokenBlacklistRepo model.TokenBlacklistRepository, timeout time.Duration, client *mongo.Client) UserUsecase {
+func NewUserUsecase(userRepository model.UserRepository, roleRepository model.RoleRepository, profileRepository model.ProfileRepository, sessionRepository model.SessionRepository, securityEventRepository model.SecurityEventRepository, revocations model.SessionRevocations, permissionVersions model.PermissionVersions, ipBindingGuard model.IPBindingGuard, timeout time.Duration, client *mongo.Client) UserUsecase {
 	return &userUsecase{
-		userRepository:     userRepository,
-		roleRepository:     roleRepository,
-		profileRepository:  profileRepository,
-		tokenBlacklistRepo: tokenBlacklistRepo,
-		contextTimeout:     timeout,
-		client:             client,
+		userRepository:          userRepository,
+		roleRepository:          roleRepository,
+		profileRepository:       profileRepository,
+		sessionRepository:       sessionRepository,
+		securityEventRepository: securityEventRepository,
+		revocations:             revocations,
+		permissionVersions:      permissionVersions,
+		ipBindingGuard:          ipBindingGuard,
+		contextTimeout:          timeout,
+		client:                  client,
 	}
 }
 
@@ -115,7 +125,7 @@ func (uc *userUsecase) Register(c context.Context, authUserID primitive.ObjectID
 	return err
 }
 
-func (uc *userUsecase) Login(c context.Context, userReq model.LoginRequestDTO, ip string) (*model.LoginResponseDTO, error) {
+func (uc *userUsecase) Login(c context.Context, userReq model.LoginRequestDTO, client model.ClientInfo) (*model.LoginResponseDTO, error) {
 	ctx, cancel := context.WithTimeout(c, uc.contextTimeout)
 	defer cancel()
 
@@ -135,33 +145,17 @@ func (uc *userUsecase) Login(c context.Context, userReq model.LoginRequestDTO, i
 		return nil, common.ErrInvalidCredentials
 	}
 
-	var perms []string
-	if existingUser.Permissions != nil {
-		perms = existingUser.Permissions
-	}
-
-	effectivePerms := utils.MergePermissions(existingUser.Role.Permissions, perms)
-
-	var branchID primitive.ObjectID
-	if existingUser.Profile.BranchID != nil {
-		branchID = *existingUser.Profile.BranchID
-	} else {
-		branchID = primitive.NilObjectID // fallback
-	}
-
-	var departmentID primitive.ObjectID
-	if existingUser.Profile.DepartmentID != nil {
-		departmentID = *existingUser.Profile.DepartmentID
-	} else {
-		departmentID = primitive.NilObjectID // fallback
+	claims, err := accessClaims(existingUser)
+	if err != nil {
+		return nil, err
 	}
 
-	accessToken, err := infrastructure.GenerateToken(existingUser.ID, existingUser.Role.Name, branchID, departmentID, effectivePerms, ip)
+	session, refeshToken, err := startSession(ctx, uc.sessionRepository, existingUser.ID, client)
 	if err != nil {
 		return nil, err
 	}
 
-	refeshToken, err := infrastructure.GenerateRefreshToken(existingUser.ID, ip)
+	accessToken, err := infrastructure.GenerateToken(claims, client, session.ID)
 	if err != nil {
 		return nil, err
 	}
@@ -181,7 +175,7 @@ func (uc *userUsecase) Login(c context.Context, userReq model.LoginRequestDTO, i
 		Username:     existingUser.Username,
 		Email:        existingUser.Profile.Email,
 		Role:         existingUser.Role.Name,
-		Permissions:  effectivePerms,
+		Permissions:  claims.Permissions,
 		Signature:    existingUser.Signature,
 		Token:        accessToken,
 		RefreshToken: refeshToken,
@@ -302,6 +296,12 @@ func (uc *userUsecase) UpdateUserByID(ctx context.Context, user_id primitive.Obj
 	if err != nil {
 		return nil, err
 	}
+
+	// The role, branch or department in the user's tokens may have changed
+	if err := bumpPermissions(ctx, uc.userRepository, uc.permissionVersions, user_id); err != nil {
+		return nil, err
+	}
+
 	return &responseUser, nil
 }
 
@@ -311,23 +311,17 @@ func (uc *userUsecase) GetAllUsers(ctx context.Context) (*[]model.UserResponseDT
 	return uc.userRepository.FindAll(ctx)
 }
 
-func (a *userUsecase) RefreshToken(ctx context.Context, refreshToken model.RefreshTokenDTO, clientIP string) (string, string, error) {
-	// 1. Validate the refresh token
-	claims, err := infrastructure.ValidateRefreshToken(refreshToken.RefreshToken, clientIP)
-	if err != nil {
-		return "", "", err
-	}
+func (a *userUsecase) RefreshToken(ctx context.Context, refreshToken model.RefreshTokenDTO, client model.ClientInfo) (string, string, error) {
+	ctx, cancel := context.WithTimeout(ctx, a.contextTimeout)
+	defer cancel()
 
-	// 2. Check if token is blacklisted
-	isBlacklisted, err := a.tokenBlacklistRepo.IsBlacklisted(ctx, refreshToken.RefreshToken)
+	// 1. Validate the refresh token
+	claims, err := infrastructure.ValidateRefreshToken(refreshToken.RefreshToken)
 	if err != nil {
 		return "", "", err
 	}
-	if isBlacklisted {
-		return "", "", fmt.Errorf("Jwt is already black listed")
-	}
 
-	// 4. Retrieve user to generate new tokens and blacklist
+	// 2. Retrieve user to generate new tokens
 	userIDHex, ok := claims["userID"].(string)
 	if !ok {
 		return "", "", fmt.Errorf("userID missing or invalid in token")
@@ -338,17 +332,6 @@ func (a *userUsecase) RefreshToken(ctx context.Context, refreshToken model.Refre
 		return "", "", err
 	}
 
-	// 3. Blacklist the current refresh token
-	expUnix, ok := claims["exp"].(float64)
-	if !ok {
-		return "", "", fmt.Errorf("invalid expiration in token")
-	}
-	expTime := time.Unix(int64(expUnix), 0)
-
-	if err := a.tokenBlacklistRepo.BlacklistToken(ctx, refreshToken.RefreshToken, expTime, userObjID, clientIP); err != nil {
-		return "", "", fmt.Errorf("failed to blacklist token")
-	}
-
 	user, err := a.userRepository.FindByID(ctx, userObjID)
 	if err != nil {
 		return "", "", err
@@ -357,40 +340,61 @@ func (a *userUsecase) RefreshToken(ctx context.Context, refreshToken model.Refre
 		return "", "", fmt.Errorf("user not found")
 	}
 
-	// 5. Retrieve user role
-	role, err := a.roleRepository.FindByID(ctx, user.Role.ID)
+	// 3. Build the claims from the user as they are now, so a disabled user
+	// cannot refresh and permission changes take effect.
+	newClaims, err := accessClaims(user)
 	if err != nil {
 		return "", "", err
 	}
-	if role == nil {
-		return "", "", fmt.Errorf("role not found")
-	}
 
-	// 6. Generate new token pair (access + refresh)
-	branchID := primitive.NilObjectID
-	if user.Profile.BranchID != nil {
-		branchID = *user.Profile.BranchID
+	// 4. Replace the refresh token of the session; replaying one that was
+	// already exchanged revokes the session.
+	sessionID, err := infrastructure.SessionID(claims)
+	if err != nil {
+		return "", "", err
 	}
+	jti, _ := claims["jti"].(string)
 
-	departmentID := primitive.NilObjectID
-	if user.Profile.DepartmentID != nil {
-		departmentID = *user.Profile.DepartmentID
+	// A refresh token is bound like the access tokens it yields, so a stolen
+	// one cannot be exchanged from another network.
+	boundIP, boundFingerprint := infrastructure.BoundClient(claims)
+	if err := a.ipBindingGuard.Verify(ctx, newClaims.Role, user.ID, sessionID, boundIP, boundFingerprint, client); err != nil {
+		return "", "", err
 	}
 
-	permissions := []string{}
-	if user.Permissions != nil {
-		permissions = user.Permissions
+	newJTI, expiresAt, err := rotateSession(ctx, a.sessionRepository, a.securityEventRepository, a.revocations, user.ID, sessionID, jti, client.IP)
+	if err != nil {
+		return "", "", err
 	}
 
-	newAccessToken, err := infrastructure.GenerateToken(user.ID, role.Name, branchID, departmentID, permissions, clientIP)
+	// 5. Generate new token pair (access + refresh)
+	newAccessToken, err := infrastructure.GenerateToken(newClaims, client, sessionID)
 	if err != nil {
 		return "", "", err
 	}
 
-	newRefreshToken, err := infrastructure.GenerateRefreshToken(user.ID, clientIP)
+	newRefreshToken, err := infrastructure.GenerateRefreshToken(user.ID, client, sessionID, newJTI, expiresAt)
 	if err != nil {
 		return "", "", err
 	}
 
 	return newAccessToken, newRefreshToken, nil
 }
+
+func (uc *userUsecase) UpdateUserStatus(ctx context.Context, user_id primitive.ObjectID, authUserID primitive.ObjectID, status model.UserStatus) error {
+	ctx, cancel := context.WithTimeout(ctx, uc.contextTimeout)
+	defer cancel()
+
+	if err := uc.userRepository.UpdateStatus(ctx, user_id, status, authUserID, time.Now()); err != nil {
+		return err
+	}
+	if err := bumpPermissions(ctx, uc.userRepository, uc.permissionVersions, user_id); err != nil {
+		return err
+	}
+	if status == model.StatusActive {
+		return nil
+	}
+
+	_, err := revokeSessions(ctx, uc.sessionRepository, uc.revocations, user_id, model.SessionUserDisabled, authUserID)
+	return err
+}
@@ -0,0 +1,102 @@
+package repository_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/latiiLA/coop-forex-server/internal/domain/model"
+	"github.com/latiiLA/coop-forex-server/internal/repository"
+	"github.com/stretchr/testify/suite"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type exchangeRateRepositoryTestSuite struct {
+	suite.Suite
+	db     *mongo.Database
+	repo   model.ExchangeRateRepository
+	ctx    context.Context
+	cancel context.CancelFunc
+}
+
+func (s *exchangeRateRepositoryTestSuite) SetupSuite() {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	client, err := mongo.Connect(ctx, options.Client().ApplyURI("mongodb://localhost:27017"))
+	s.Require().NoError(err)
+
+	s.db = client.Database("test_db")
+
+	s.ctx, s.cancel = context.WithTimeout(context.Background(), 5*time.Second)
+	s.repo = repository.NewExchangeRateRepository(s.db)
+}
+
+func (s *exchangeRateRepositoryTestSuite) TearDownSuite() {
+	_ = s.db.Drop(s.ctx)
+	s.cancel()
+}
+
+// publish stores a rate the way publishing one does: the open-ended rate it
+// takes over from is closed at its start.
+func (s *exchangeRateRepositoryTestSuite) publish(currencyID primitive.ObjectID, validFrom time.Time) *model.ExchangeRate {
+	rate := &model.ExchangeRate{
+		ID:          primitive.NewObjectID(),
+		CurrencyID:  currencyID,
+		BuyingRate:  120,
+		SellingRate: 125,
+		MidRate:     122.5,
+		ValidFrom:   validFrom,
+		Source:      model.ExchangeRateSourceManual,
+	}
+	s.Require().NoError(s.repo.Create(s.ctx, rate))
+	s.Require().NoError(s.repo.CloseOpenRates(s.ctx, currencyID, validFrom))
+
+	return rate
+}
+
+func (s *exchangeRateRepositoryTestSuite) TestMovePredecessorEndFollowsRescheduledRate() {
+	now := time.Now().UTC().Truncate(time.Millisecond)
+	currencyID := primitive.NewObjectID()
+	current := s.publish(currencyID, now.Add(-time.Hour))
+	scheduled := s.publish(currencyID, now.Add(time.Hour))
+
+	newFrom := now.Add(2 * time.Hour)
+	scheduled.ValidFrom = newFrom
+	s.Require().NoError(s.repo.Update(s.ctx, scheduled.ID, scheduled))
+	s.Require().NoError(s.repo.MovePredecessorEnd(s.ctx, currencyID, scheduled.ID, now.Add(time.Hour), &newFrom))
+
+	effective, err := s.repo.FindEffective(s.ctx, currencyID, now.Add(90*time.Minute))
+	s.Require().NoError(err)
+	s.Require().NotNil(effective)
+	s.Require().Equal(current.ID, effective.ID)
+
+	effective, err = s.repo.FindEffective(s.ctx, currencyID, newFrom)
+	s.Require().NoError(err)
+	s.Require().NotNil(effective)
+	s.Require().Equal(scheduled.ID, effective.ID)
+}
+
+func (s *exchangeRateRepositoryTestSuite) TestMovePredecessorEndReopensWithdrawnRate() {
+	now := time.Now().UTC().Truncate(time.Millisecond)
+	currencyID := primitive.NewObjectID()
+	current := s.publish(currencyID, now.Add(-time.Hour))
+	scheduled := s.publish(currencyID, now.Add(time.Hour))
+
+	deletedAt := now
+	scheduled.IsDeleted = true
+	scheduled.DeletedAt = &deletedAt
+	s.Require().NoError(s.repo.Delete(s.ctx, scheduled.ID, scheduled))
+	s.Require().NoError(s.repo.MovePredecessorEnd(s.ctx, currencyID, scheduled.ID, scheduled.ValidFrom, scheduled.ValidTo))
+
+	effective, err := s.repo.FindEffective(s.ctx, currencyID, now.Add(2*time.Hour))
+	s.Require().NoError(err)
+	s.Require().NotNil(effective)
+	s.Require().Equal(current.ID, effective.ID)
+	s.Require().Nil(effective.ValidTo)
+}
+
+func TestExchangeRateRepositoryTestSuite(t *testing.T) {
+	suite.Run(t, new(exchangeRateRepositoryTestSuite))
+}